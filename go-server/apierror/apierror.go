@@ -0,0 +1,115 @@
+// Package apierror provides a typed error taxonomy for API handlers so that
+// HTTP status codes are attached to errors at the point they are created,
+// instead of being re-derived from err.Error() string comparisons in route
+// handlers.
+package apierror
+
+import (
+	"errors"
+	"net/http"
+)
+
+// Code is a machine-readable error code clients can safely branch on.
+type Code string
+
+const (
+	CodeNotFound     Code = "not_found"
+	CodeUnauthorized Code = "unauthorized"
+	CodeForbidden    Code = "forbidden"
+	CodeValidation   Code = "validation_error"
+	CodeConflict     Code = "conflict"
+	CodeRateLimited  Code = "rate_limited"
+	CodeInternal     Code = "internal_error"
+)
+
+// APIError is a domain error carrying everything middleware.ErrorHandler
+// needs to render a models.APIResponse: the HTTP status, a machine-readable
+// code, a message that is safe to show to the user, and an optional cause
+// kept only for logging.
+type APIError struct {
+	Status  int
+	Code    Code
+	Message string
+	Details string
+	Cause   error
+}
+
+func (e *APIError) Error() string {
+	if e.Cause != nil {
+		return e.Message + ": " + e.Cause.Error()
+	}
+	return e.Message
+}
+
+// Unwrap lets errors.Is/errors.As see through to the underlying cause.
+func (e *APIError) Unwrap() error {
+	return e.Cause
+}
+
+// WithDetails attaches extra machine-readable detail, e.g. validation field errors.
+func (e *APIError) WithDetails(details string) *APIError {
+	clone := *e
+	clone.Details = details
+	return &clone
+}
+
+// WithCause attaches the underlying error for logging without changing the
+// user-facing message.
+func (e *APIError) WithCause(cause error) *APIError {
+	clone := *e
+	clone.Cause = cause
+	return &clone
+}
+
+// NotFound builds a 404 for the named resource, e.g. NotFound("course").
+func NotFound(resource string) *APIError {
+	return &APIError{Status: http.StatusNotFound, Code: CodeNotFound, Message: resource + " not found"}
+}
+
+// Unauthorized builds a 401. An empty message falls back to "unauthorized".
+func Unauthorized(message string) *APIError {
+	if message == "" {
+		message = "unauthorized"
+	}
+	return &APIError{Status: http.StatusUnauthorized, Code: CodeUnauthorized, Message: message}
+}
+
+// Forbidden builds a 403. An empty message falls back to "forbidden".
+func Forbidden(message string) *APIError {
+	if message == "" {
+		message = "forbidden"
+	}
+	return &APIError{Status: http.StatusForbidden, Code: CodeForbidden, Message: message}
+}
+
+// Validation builds a 400 for a failed input validation.
+func Validation(message string) *APIError {
+	return &APIError{Status: http.StatusBadRequest, Code: CodeValidation, Message: message}
+}
+
+// Conflict builds a 409, e.g. for duplicate resources.
+func Conflict(message string) *APIError {
+	return &APIError{Status: http.StatusConflict, Code: CodeConflict, Message: message}
+}
+
+// RateLimited builds a 429. An empty message falls back to "rate limit exceeded".
+func RateLimited(message string) *APIError {
+	if message == "" {
+		message = "rate limit exceeded"
+	}
+	return &APIError{Status: http.StatusTooManyRequests, Code: CodeRateLimited, Message: message}
+}
+
+// Internal builds a 500 wrapping an unexpected cause.
+func Internal(message string, cause error) *APIError {
+	return &APIError{Status: http.StatusInternalServerError, Code: CodeInternal, Message: message, Cause: cause}
+}
+
+// From unwraps err looking for an *APIError, the way errors.As(err, &apiErr) would.
+func From(err error) (*APIError, bool) {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr, true
+	}
+	return nil, false
+}
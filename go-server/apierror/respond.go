@@ -0,0 +1,133 @@
+package apierror
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"go-server/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+const (
+	CodeTokenExpired       Code = "token_expired"
+	CodeStorageUnavailable Code = "storage_unavailable"
+	CodeGenerationQuota    Code = "generation_quota_exceeded"
+	CodeContentBlocked     Code = "content_blocked"
+)
+
+// RequestIDHeader is the header a request ID is read from and echoed on, so
+// a client-supplied ID (e.g. from a load balancer) survives end to end.
+const RequestIDHeader = "X-Request-ID"
+
+// RequestIDContextKey is where middleware.RequestIDMiddleware stores the
+// current request's ID for Abort to read back out.
+const RequestIDContextKey = "request_id"
+
+// TokenExpired builds a 401 for an expired JWT.
+func TokenExpired() *APIError {
+	return &APIError{Status: http.StatusUnauthorized, Code: CodeTokenExpired, Message: "token has expired"}
+}
+
+// StorageUnavailable builds a 503 wrapping a storage backend failure.
+func StorageUnavailable(cause error) *APIError {
+	return &APIError{Status: http.StatusServiceUnavailable, Code: CodeStorageUnavailable, Message: "storage backend unavailable", Cause: cause}
+}
+
+// GenerationQuota builds a 429 for an exhausted image/content generation quota.
+func GenerationQuota(message string) *APIError {
+	if message == "" {
+		message = "generation quota exceeded"
+	}
+	return &APIError{Status: http.StatusTooManyRequests, Code: CodeGenerationQuota, Message: message}
+}
+
+// ContentBlocked builds a 422 for LLM-generated content that
+// utils.ValidateContentSafety rejected before it could be persisted.
+func ContentBlocked(message string) *APIError {
+	if message == "" {
+		message = "generated content failed safety validation"
+	}
+	return &APIError{Status: http.StatusUnprocessableEntity, Code: CodeContentBlocked, Message: message}
+}
+
+// resolve turns any error into an *APIError: err itself if it already is
+// one, a mapped sentinel if it's one of the well-known errors packages
+// raised before APIError existed, or a generic 500 otherwise.
+func resolve(err error) *APIError {
+	if apiErr, ok := From(err); ok {
+		return apiErr
+	}
+
+	switch {
+	case errors.Is(err, utils.ErrTokenExpired):
+		return TokenExpired()
+	case errors.Is(err, utils.ErrTokenInvalid):
+		return Unauthorized("invalid token")
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return NotFound("resource")
+	}
+
+	return Internal("internal server error", err)
+}
+
+// problemDetails is the RFC 7807 application/problem+json body Abort writes
+// when the client's Accept header asks for it.
+type problemDetails struct {
+	Type      string `json:"type"`
+	Title     string `json:"title"`
+	Status    int    `json:"status"`
+	Detail    string `json:"detail,omitempty"`
+	Code      string `json:"code"`
+	RequestID string `json:"request_id,omitempty"`
+}
+
+// responseBody is the default (non-problem+json) error shape, matching
+// models.APIResponse's error fields plus the request_id correlation ID.
+type responseBody struct {
+	Success   bool   `json:"success"`
+	Code      string `json:"code"`
+	Message   string `json:"message"`
+	Details   string `json:"details,omitempty"`
+	RequestID string `json:"request_id,omitempty"`
+}
+
+// Abort resolves err to an *APIError (mapping sentinel errors from packages
+// that predate this taxonomy), logs its cause if any, and renders it as
+// either application/problem+json or this API's usual JSON error shape
+// depending on the request's Accept header, tagging either with the current
+// request's ID so logs and frontend errors can be correlated. It aborts the
+// gin context, so handlers should return immediately after calling it.
+func Abort(c *gin.Context, err error) {
+	apiErr := resolve(err)
+	if apiErr.Cause != nil {
+		logrus.Errorf("%s: %v", apiErr.Message, apiErr.Cause)
+	}
+
+	requestID, _ := c.Get(RequestIDContextKey)
+	requestIDStr, _ := requestID.(string)
+
+	if strings.Contains(c.GetHeader("Accept"), "application/problem+json") {
+		c.Header("Content-Type", "application/problem+json")
+		c.AbortWithStatusJSON(apiErr.Status, problemDetails{
+			Type:      "about:blank",
+			Title:     http.StatusText(apiErr.Status),
+			Status:    apiErr.Status,
+			Detail:    apiErr.Message,
+			Code:      string(apiErr.Code),
+			RequestID: requestIDStr,
+		})
+		return
+	}
+
+	c.AbortWithStatusJSON(apiErr.Status, responseBody{
+		Success:   false,
+		Code:      string(apiErr.Code),
+		Message:   apiErr.Message,
+		Details:   apiErr.Details,
+		RequestID: requestIDStr,
+	})
+}
@@ -4,6 +4,8 @@ import (
 	"go-server/config"
 	"go-server/middleware"
 	"go-server/routes"
+	"go-server/services"
+	"go-server/storage"
 	"log"
 	"net/http"
 	"time"
@@ -29,6 +31,13 @@ func main() {
 		logrus.Fatalf("Failed to setup database: %v", err)
 	}
 
+	// Create the roles/user_roles tables and seed the built-in roles, so
+	// RequireRole/RequireScope have something to check against on a fresh
+	// database.
+	if err := config.Bootstrap(db); err != nil {
+		logrus.Fatalf("Failed to bootstrap database: %v", err)
+	}
+
 	// Set Gin mode
 	if cfg.Environment == "production" {
 		gin.SetMode(gin.ReleaseMode)
@@ -37,16 +46,12 @@ func main() {
 	// Create Gin router
 	router := gin.New()
 
-	// Setup CORS middleware
+	// Setup CORS middleware. AllowOrigins is cfg.CORSAllowedOrigins, a
+	// strict allow-list rather than a wildcard, since AllowCredentials
+	// below requires the API to echo back one specific origin per request
+	// (a browser won't send credentials to a wildcard-origin response).
 	router.Use(cors.New(cors.Config{
-		AllowOrigins: []string{
-			"*",
-			"http://localhost:4200",
-			"http://127.0.0.1:4200",
-			"https://coursewagon-backend.victoriousforest-3a334815.southeastasia.azurecontainerapps.io",
-			"https://www.coursewagon.live",
-			"https://coursewagon.web.app",
-		},
+		AllowOrigins: cfg.CORSAllowedOrigins,
 		AllowMethods: []string{
 			"GET", "POST", "PUT", "DELETE", "OPTIONS",
 		},
@@ -67,9 +72,23 @@ func main() {
 		c.JSON(http.StatusOK, gin.H{"status": "healthy"})
 	})
 
+	// Serves images directly from disk when STORAGE_PROVIDER=local
+	storage.MountStaticRoutes(router, cfg)
+
 	// Setup routes
 	api := router.Group("/api")
-	routes.SetupRoutes(api, db, cfg)
+	keyManager, digestService := routes.SetupRoutes(api, db, cfg)
+
+	// Public JWKS endpoint, only served when JWT_SIGNING_ALGORITHM is RS256/
+	// ES256; keyManager is nil (and this a no-op) in the default HS256 mode.
+	if keyManager != nil {
+		routes.SetupJWKSRoutes(router, keyManager)
+	}
+
+	// Periodically email every opted-in user a digest of new activity on
+	// their courses; RunDigest itself tracks each user's last-sent
+	// watermark, so overlapping or skipped ticks are harmless.
+	go runDigestScheduler(cfg, digestService)
 
 	// Start server
 	port := cfg.Port
@@ -83,6 +102,26 @@ func main() {
 	}
 }
 
+// runDigestScheduler calls DigestService.RunDigest on cfg.DigestInterval
+// until the process exits. It's meant to run in its own goroutine: a single
+// slow or failing tick only delays that tick's send, not the next one.
+func runDigestScheduler(cfg *config.Config, digestService services.DigestService) {
+	ticker := time.NewTicker(cfg.DigestInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if err := digestService.RunDigest(); err != nil {
+			logrus.Errorf("Error running digest: %v", err)
+		}
+	}
+}
+
+// setupLogging configures logrus's level and output format. Debug mode logs
+// human-readable text, since that's what a developer is actually reading in
+// a terminal; everywhere else logs structured JSON, so every line
+// (including the request_id/user_id/trace_id fields logger.FromContext
+// attaches) is a single parseable JSON object for a log aggregator to
+// index and correlate across a request's lifetime.
 func setupLogging(cfg *config.Config) {
 	// Set log level
 	if cfg.Debug {
@@ -92,8 +131,15 @@ func setupLogging(cfg *config.Config) {
 	}
 
 	// Set log format
-	logrus.SetFormatter(&logrus.TextFormatter{
-		FullTimestamp: true,
-		TimestampFormat: "2006-01-02 15:04:05",
+	if cfg.Debug {
+		logrus.SetFormatter(&logrus.TextFormatter{
+			FullTimestamp:   true,
+			TimestampFormat: "2006-01-02 15:04:05",
+		})
+		return
+	}
+
+	logrus.SetFormatter(&logrus.JSONFormatter{
+		TimestampFormat: time.RFC3339,
 	})
-}
\ No newline at end of file
+}
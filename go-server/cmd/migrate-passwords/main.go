@@ -0,0 +1,48 @@
+// Command migrate-passwords is a one-shot audit tool: it reports how many
+// user accounts are still on a hash utils.NeedsRehash would upgrade (a
+// legacy bcrypt hash, or an argon2id one hashed under weaker-than-current
+// parameters), without touching any row. Neither scheme is reversible, so
+// there's no plaintext to rehash with offline; the actual upgrade happens
+// transparently in services.AuthServiceImpl.Login the next time each of
+// these accounts signs in. Run this periodically to track how much of the
+// user base has migrated since a parameter or scheme change.
+package main
+
+import (
+	"fmt"
+	"log"
+
+	"go-server/config"
+	"go-server/models"
+	"go-server/utils"
+
+	"github.com/sirupsen/logrus"
+)
+
+func main() {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	db, err := config.SetupDatabase(cfg)
+	if err != nil {
+		log.Fatalf("Failed to setup database: %v", err)
+	}
+
+	var users []models.User
+	if err := db.Select("id", "email", "password_hash").Find(&users).Error; err != nil {
+		log.Fatalf("Failed to list users: %v", err)
+	}
+
+	params := utils.Argon2ParamsFromConfig(cfg)
+	pending := 0
+	for _, user := range users {
+		if utils.NeedsRehash(user.PasswordHash, params) {
+			pending++
+			logrus.Infof("Pending rehash: user %d (%s)", user.ID, user.Email)
+		}
+	}
+
+	fmt.Printf("%d of %d accounts are pending a password rehash\n", pending, len(users))
+}
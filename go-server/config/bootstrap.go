@@ -0,0 +1,147 @@
+package config
+
+import (
+	"fmt"
+
+	"go-server/models"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// defaultRoleScopes seeds the built-in roles a fresh deployment needs
+// before any admin can grant further roles through the admin API.
+var defaultRoleScopes = map[string]string{
+	"admin": "testimonial:approve,role:manage",
+}
+
+// Bootstrap creates the tables introduced alongside newer features (RBAC,
+// asymmetric JWT signing keys, content embeddings, cached idempotent
+// responses, unified one-time tokens, TOTP 2FA, linked oauth identities,
+// per-type notification preferences, the learning digest event log and its
+// per-user send watermark, content-addressed image metadata), seeds the roles in defaultRoleScopes if they don't already exist, and
+// ensures the FULLTEXT indexes repositories.SearchRepository depends on and
+// the unique index repositories.SubjectRepository.CreateOrGetSubjects
+// depends on are present. The rest of the schema is managed outside this
+// codebase; models.Content is the one exception, included here solely so
+// AutoMigrate adds its new prompt_version column without having to take
+// over the rest of that table.
+func Bootstrap(db *gorm.DB) error {
+	if err := db.AutoMigrate(
+		&models.Role{},
+		&models.UserRole{},
+		&models.SigningKey{},
+		&models.ContentEmbedding{},
+		&models.IdempotencyKey{},
+		&models.Token{},
+		&models.UserTOTP{},
+		&models.TOTPRecoveryCode{},
+		&models.UserIdentity{},
+		&models.NotificationPreference{},
+		&models.DigestEvent{},
+		&models.UserDigestState{},
+		&models.Content{},
+		&models.ImageAsset{},
+	); err != nil {
+		return err
+	}
+
+	for name, scopes := range defaultRoleScopes {
+		var role models.Role
+		err := db.Where("name = ?", name).First(&role).Error
+		if err == gorm.ErrRecordNotFound {
+			if err := db.Create(&models.Role{Name: name, Scopes: scopes}).Error; err != nil {
+				return err
+			}
+			logrus.Infof("Seeded built-in role: %s", name)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := ensureFulltextIndexes(db); err != nil {
+		return err
+	}
+
+	return ensureUniqueIndexes(db)
+}
+
+// fulltextIndexes lists the FULLTEXT indexes repositories.SearchRepository
+// depends on. ensureFulltextIndexes creates any that are missing; it never
+// drops or rebuilds one that already exists.
+var fulltextIndexes = []struct {
+	name    string
+	table   string
+	columns string
+}{
+	{"ft_courses_name_description", "courses", "name, description"},
+	{"ft_subjects_name", "subjects", "name"},
+	{"ft_chapters_name", "chapters", "name"},
+	{"ft_topics_name", "topics", "name"},
+}
+
+// ensureFulltextIndexes creates the FULLTEXT indexes in fulltextIndexes,
+// skipping any that are already present. GORM's AutoMigrate doesn't support
+// FULLTEXT indexes, so this runs them as raw SQL instead.
+func ensureFulltextIndexes(db *gorm.DB) error {
+	for _, idx := range fulltextIndexes {
+		var count int64
+		err := db.Raw(
+			"SELECT COUNT(*) FROM information_schema.statistics WHERE table_schema = DATABASE() AND table_name = ? AND index_name = ?",
+			idx.table, idx.name,
+		).Scan(&count).Error
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			continue
+		}
+
+		if err := db.Exec(fmt.Sprintf("CREATE FULLTEXT INDEX %s ON %s (%s)", idx.name, idx.table, idx.columns)).Error; err != nil {
+			return err
+		}
+		logrus.Infof("Created fulltext index: %s", idx.name)
+	}
+	return nil
+}
+
+// uniqueIndexes lists the unique indexes repositories.SubjectRepository's
+// CreateOrGetSubjects depends on as a race backstop. These use a
+// functional key part (LOWER(name)) that GORM struct tags can't express,
+// so, like the FULLTEXT indexes above, they're created as raw SQL.
+var uniqueIndexes = []struct {
+	name    string
+	table   string
+	columns string
+}{
+	{"uq_subjects_course_name", "subjects", "course_id, (LOWER(name))"},
+}
+
+// ensureUniqueIndexes creates the indexes in uniqueIndexes, skipping any
+// that are already present. If a deployment already has case-insensitive
+// duplicate subject names under the same course, this will fail — that
+// pre-existing duplication needs to be cleaned up before the index can be
+// added; it is not resolved automatically here.
+func ensureUniqueIndexes(db *gorm.DB) error {
+	for _, idx := range uniqueIndexes {
+		var count int64
+		err := db.Raw(
+			"SELECT COUNT(*) FROM information_schema.statistics WHERE table_schema = DATABASE() AND table_name = ? AND index_name = ?",
+			idx.table, idx.name,
+		).Scan(&count).Error
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			continue
+		}
+
+		if err := db.Exec(fmt.Sprintf("CREATE UNIQUE INDEX %s ON %s (%s)", idx.name, idx.table, idx.columns)).Error; err != nil {
+			return err
+		}
+		logrus.Infof("Created unique index: %s", idx.name)
+	}
+	return nil
+}
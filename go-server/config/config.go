@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
@@ -26,14 +27,35 @@ type Config struct {
 	Environment string
 	Debug       bool
 
+	// CORSAllowedOrigins is the strict allow-list cmd/main.go's CORS
+	// middleware checks incoming Origin headers against. The default list
+	// holds no bare "*", since the API requires credentials (cookies/
+	// Authorization headers), which browsers refuse to send cross-origin
+	// to a wildcard-origin response; a "*" supplied through
+	// CORS_ALLOWED_ORIGINS is not filtered out.
+	CORSAllowedOrigins []string
+
 	// JWT configurations
-	JWTSecretKey             string
-	JWTAccessTokenExpires    time.Duration
-	JWTRefreshTokenExpires   time.Duration
+	JWTSecretKey           string
+	JWTAccessTokenExpires  time.Duration
+	JWTRefreshTokenExpires time.Duration
+
+	// PasswordResetTokenExpires and EmailVerificationTokenExpires bound how
+	// long a token issued by AuthService's unified token store (see
+	// models.Token) stays redeemable before TokenRepository.GetToken stops
+	// returning it.
+	PasswordResetTokenExpires     time.Duration
+	EmailVerificationTokenExpires time.Duration
+
+	// JWTSigningAlgorithm is "HS256" (default, shared secret) or "RS256"/
+	// "ES256" (asymmetric, backed by utils.KeyManager's signing_keys table
+	// so other services can verify tokens via GET /.well-known/jwks.json
+	// without sharing a secret).
+	JWTSigningAlgorithm string
 
 	// Security configurations
-	SecretKey             string
-	SecurityPasswordSalt  string
+	SecretKey            string
+	SecurityPasswordSalt string
 
 	// Email configurations
 	MailServer        string
@@ -49,13 +71,87 @@ type Config struct {
 	FrontendURL string
 	AppName     string
 
+	// DigestInterval is how often services.DigestService.RunDigest fires
+	// from the scheduler goroutine main.go starts; it compares each event's
+	// CreatedAt against a per-user watermark, not this interval itself, so
+	// changing it doesn't affect which events land in the next digest.
+	DigestInterval time.Duration
+
 	// API configurations
 	APIKey string
 
+	// Image generation provider configurations, tried in order by
+	// services.ImageService before falling back to the placeholder provider
+	OpenAIImageAPIKey       string
+	StabilityAIAPIKey       string
+	StableDiffusionEndpoint string
+
+	// ImageProviderPriority overrides the default gemini/openai/stability/
+	// stable-diffusion/placeholder try-order. Providers named here run
+	// first, in the given order; any provider left unnamed keeps running
+	// after them in the default order.
+	ImageProviderPriority []string
+
+	// Text-generation LLM provider configurations, tried in order by
+	// services.ContentService's utils.ProviderChain before failing
+	OpenAICompatAPIKey  string
+	OpenAICompatBaseURL string
+	OpenAICompatModel   string
+	AnthropicAPIKey     string
+	AnthropicModel      string
+	// OllamaBaseURL and OllamaModel configure utils.OllamaLLMProvider, a
+	// local/self-hosted backend that needs no API key; it reports itself
+	// unavailable only when OllamaBaseURL is empty.
+	OllamaBaseURL string
+	OllamaModel   string
+
+	// LLMProviderPriority overrides the default gemini/openai/anthropic/
+	// ollama try-order, the same way ImageProviderPriority does for image
+	// providers.
+	LLMProviderPriority []string
+
+	// MaxConcurrentContentGenerationsPerUser caps how many of one user's
+	// content-generation jobs services.ContentService runs at once, so a
+	// single "generate all content for this course" batch can't crowd out
+	// every other user's share of the job queue's worker pool.
+	MaxConcurrentContentGenerationsPerUser int
+
+	// LLMRateLimitPerMinute caps how many LLM calls services.NewLLMProviderChain's
+	// shared ProviderChain issues per minute, process-wide across every
+	// caller (content, subject/chapter/topic-list generation alike), to stay
+	// under a provider's quota regardless of how many requests or batch jobs
+	// are in flight.
+	LLMRateLimitPerMinute int
+
+	// Object storage configurations. StorageProvider selects which Backend
+	// storage.NewBackend builds: "azure" (default), "s3", "gcs", or "local".
+	StorageProvider string
+
 	// Azure Storage configurations
-	AzureStorageAccountName     string
+	AzureStorageAccountName      string
 	AzureStorageConnectionString string
-	AzureStorageContainerName   string
+	AzureStorageContainerName    string
+
+	// S3 storage configurations, used when StorageProvider is "s3"
+	S3Bucket          string
+	S3Region          string
+	S3AccessKeyID     string
+	S3SecretAccessKey string
+
+	// GCS storage configurations, used when StorageProvider is "gcs"
+	GCSBucket          string
+	GCSCredentialsFile string
+
+	// Local filesystem storage configurations, used when StorageProvider is
+	// "local". LocalStorageBaseURL is the origin (e.g. the API's own URL)
+	// that storage.MountStaticRoutes's /static/images/* route is reachable at.
+	LocalStorageDir     string
+	LocalStorageBaseURL string
+
+	// SignedURLCacheSize bounds how many storage.SignedURLCache entries
+	// (one per distinct blob path) stay cached at once before the
+	// least-recently-used one is evicted.
+	SignedURLCacheSize int
 
 	// Azure Deployment configurations
 	AzureResourceGroup     string
@@ -63,6 +159,137 @@ type Config struct {
 	AzureContainerRegistry string
 	AzureContainerAppEnv   string
 	AzureContainerAppName  string
+
+	// Auth configurations
+	Auth AuthConfig
+
+	// Password hashing and strength-policy configurations
+	Password PasswordConfig
+
+	// OAuth2/OIDC social login configurations
+	OAuth OAuthConfig
+
+	// Telegram notification channel configuration
+	Telegram TelegramConfig
+}
+
+// AuthConfig controls which authentication mode protected routes require.
+type AuthConfig struct {
+	// Mode is "jwt" (default, bearer token only), "session" (server-side
+	// session only), or "hybrid" (session first, JWT fallback).
+	Mode string
+
+	// SessionSecret signs and encrypts the session cookie/store data.
+	SessionSecret string
+
+	// SessionStoreDriver is "cookie" (default), "redis", or "memory" (for
+	// tests and local dev, data does not survive a restart).
+	SessionStoreDriver string
+
+	// RedisAddr is used when SessionStoreDriver is "redis".
+	RedisAddr string
+
+	// CSRFCookieName is the double-submit cookie checked against
+	// X-CSRF-Token on state-changing requests authenticated via session.
+	CSRFCookieName string
+
+	// MaxFailures is how many consecutive bad-password login attempts a
+	// single (ip, email) pair may accrue within LockoutWindow before
+	// AuthServiceImpl.Login hard-locks it out for the rest of the window.
+	MaxFailures int
+
+	// LockoutWindow bounds how long a hard lockout (and the failure count
+	// feeding it) lasts before resetting.
+	LockoutWindow time.Duration
+
+	// RateLimitBackend selects ratelimit.NewStore's backend: "memory"
+	// (default, per-instance, data does not survive a restart) or "redis"
+	// (shared across instances, for production).
+	RateLimitBackend string
+}
+
+// PasswordConfig tunes utils.HashPassword's argon2id cost and
+// utils.IsValidPassword's strength policy. The argon2id defaults match the
+// OWASP-recommended baseline (64 MiB, 3 iterations, 2 lanes); operators on
+// constrained hardware can lower Memory, at the cost of weaker resistance
+// to offline cracking.
+type PasswordConfig struct {
+	// Argon2Memory is argon2id's memory cost in KiB.
+	Argon2Memory uint32
+	// Argon2Iterations is argon2id's time cost.
+	Argon2Iterations uint32
+	// Argon2Parallelism is argon2id's degree of parallelism (lanes).
+	Argon2Parallelism uint8
+	// Argon2SaltLen is the random salt length, in bytes.
+	Argon2SaltLen uint32
+	// Argon2KeyLen is the derived key length, in bytes.
+	Argon2KeyLen uint32
+
+	// MinLength is the minimum password length utils.IsValidPassword
+	// accepts.
+	MinLength int
+
+	// CheckPwned gates utils.IsValidPassword's Have I Been Pwned
+	// k-anonymity range-query lookup, off by default so a fresh checkout
+	// doesn't start making outbound calls without an operator opting in.
+	CheckPwned bool
+
+	// Pepper is an application-wide secret utils.HashPassword XORs into
+	// every password before hashing, so a leaked database alone (without
+	// also leaking this env var) isn't enough to run an offline cracking
+	// attack against PasswordHash. Empty by default, which is a no-op.
+	Pepper string
+}
+
+// OAuthConfig holds one env-configured section per social login provider
+// services.OAuthService supports. A provider with an empty ClientID is
+// treated as unconfigured and rejected at login time.
+type OAuthConfig struct {
+	Google OIDCProviderConfig
+	OIDC   OIDCProviderConfig // generic provider, e.g. Auth0/Okta/Keycloak
+	GitHub GitHubOAuthConfig
+}
+
+// OIDCProviderConfig configures one OpenID Connect provider.
+// services.OAuthService's /login endpoint verifies a client-supplied ID
+// token against JWKSURL and checks it was issued by Issuer for ClientID;
+// its /callback endpoint additionally exchanges an authorization code for
+// that ID token at TokenURL, which needs ClientSecret and RedirectURL.
+type OIDCProviderConfig struct {
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Issuer       string
+	JWKSURL      string
+	AuthURL      string
+	TokenURL     string
+}
+
+// GitHubOAuthConfig configures GitHub login. GitHub's OAuth apps don't
+// issue OIDC ID tokens, so services.OAuthService verifies a GitHub login
+// by calling the GitHub API with the access token instead of checking a
+// JWKS signature; AuthURL/TokenURL/user-API endpoints are GitHub
+// constants, not configurable per deployment.
+type GitHubOAuthConfig struct {
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+}
+
+// TelegramConfig configures services.TelegramService, the Telegram delivery
+// route for services.NotificationService. BotToken being empty is treated
+// as the channel being unconfigured: TelegramTransport skips silently
+// rather than erroring, the same way EmailServiceImpl does without Mailgun
+// credentials.
+type TelegramConfig struct {
+	// BotToken authenticates calls to the Telegram Bot API (sendMessage,
+	// and, if WebhookSecret is set, the webhook Telegram posts updates to).
+	BotToken string
+
+	// WebhookSecret, if set, is checked against the
+	// X-Telegram-Bot-Api-Secret-Token header on incoming webhook requests,
+	// so an attacker who finds the webhook URL can't feed it forged updates.
+	WebhookSecret string
 }
 
 func LoadConfig() (*Config, error) {
@@ -83,15 +310,26 @@ func LoadConfig() (*Config, error) {
 		Port:        getEnv("PORT", "8000"),
 		Environment: getEnv("ENVIRONMENT", "development"),
 		Debug:       getBoolEnv("DEBUG", false),
+		CORSAllowedOrigins: getListEnv("CORS_ALLOWED_ORIGINS", []string{
+			"http://localhost:4200",
+			"http://127.0.0.1:4200",
+			"https://coursewagon-backend.victoriousforest-3a334815.southeastasia.azurecontainerapps.io",
+			"https://www.coursewagon.live",
+			"https://coursewagon.web.app",
+		}),
 
 		// JWT
-		JWTSecretKey:             getEnv("JWT_SECRET_KEY", "your-secret-key"),
-		JWTAccessTokenExpires:    getDurationEnv("JWT_ACCESS_TOKEN_EXPIRES_HOURS", 1) * time.Hour,
-		JWTRefreshTokenExpires:   getDurationEnv("JWT_REFRESH_TOKEN_EXPIRES_DAYS", 30) * 24 * time.Hour,
+		JWTSecretKey:           getEnv("JWT_SECRET_KEY", "your-secret-key"),
+		JWTAccessTokenExpires:  getDurationEnv("JWT_ACCESS_TOKEN_EXPIRES_HOURS", 1) * time.Hour,
+		JWTRefreshTokenExpires: getDurationEnv("JWT_REFRESH_TOKEN_EXPIRES_DAYS", 30) * 24 * time.Hour,
+		JWTSigningAlgorithm:    getEnv("JWT_SIGNING_ALGORITHM", "HS256"),
+
+		PasswordResetTokenExpires:     getDurationEnv("PASSWORD_RESET_TOKEN_EXPIRES_HOURS", 1) * time.Hour,
+		EmailVerificationTokenExpires: getDurationEnv("EMAIL_VERIFICATION_TOKEN_EXPIRES_HOURS", 24) * time.Hour,
 
 		// Security
-		SecretKey:             getEnv("SECRET_KEY", "your-secret-key"),
-		SecurityPasswordSalt:  getEnv("SECURITY_PASSWORD_SALT", "your-salt"),
+		SecretKey:            getEnv("SECRET_KEY", "your-secret-key"),
+		SecurityPasswordSalt: getEnv("SECURITY_PASSWORD_SALT", "your-salt"),
 
 		// Email
 		MailServer:        getEnv("MAIL_SERVER", "smtp.mailgun.org"),
@@ -107,13 +345,53 @@ func LoadConfig() (*Config, error) {
 		FrontendURL: getEnv("FRONTEND_URL", "https://coursewagon.live"),
 		AppName:     getEnv("APP_NAME", "Course Wagon"),
 
+		DigestInterval: getDurationEnv("DIGEST_INTERVAL_HOURS", 24) * time.Hour,
+
 		// API
 		APIKey: getEnv("API_KEY", ""),
 
+		// Image generation providers
+		OpenAIImageAPIKey:       getEnv("OPENAI_IMAGE_API_KEY", ""),
+		StabilityAIAPIKey:       getEnv("STABILITY_AI_API_KEY", ""),
+		StableDiffusionEndpoint: getEnv("STABLE_DIFFUSION_ENDPOINT", ""),
+		ImageProviderPriority:   getListEnv("IMAGE_PROVIDER_PRIORITY", nil),
+
+		// Text-generation LLM providers
+		OpenAICompatAPIKey:  getEnv("OPENAI_COMPAT_API_KEY", ""),
+		OpenAICompatBaseURL: getEnv("OPENAI_COMPAT_BASE_URL", "https://api.openai.com/v1"),
+		OpenAICompatModel:   getEnv("OPENAI_COMPAT_MODEL", "gpt-4o-mini"),
+		AnthropicAPIKey:     getEnv("ANTHROPIC_API_KEY", ""),
+		AnthropicModel:      getEnv("ANTHROPIC_MODEL", "claude-3-5-sonnet-20241022"),
+		OllamaBaseURL:       getEnv("OLLAMA_BASE_URL", ""),
+		OllamaModel:         getEnv("OLLAMA_MODEL", "llama3.1"),
+		LLMProviderPriority: getListEnv("LLM_PROVIDER_PRIORITY", nil),
+
+		MaxConcurrentContentGenerationsPerUser: getIntEnv("MAX_CONCURRENT_CONTENT_GENERATIONS_PER_USER", 4),
+		LLMRateLimitPerMinute:                  getIntEnv("LLM_RATE_LIMIT_PER_MINUTE", 60),
+
+		// Object storage
+		StorageProvider: getEnv("STORAGE_PROVIDER", "azure"),
+
 		// Azure Storage
-		AzureStorageAccountName:     getEnv("AZURE_STORAGE_ACCOUNT_NAME", ""),
+		AzureStorageAccountName:      getEnv("AZURE_STORAGE_ACCOUNT_NAME", ""),
 		AzureStorageConnectionString: getEnv("AZURE_STORAGE_CONNECTION_STRING", ""),
-		AzureStorageContainerName:   getEnv("AZURE_STORAGE_CONTAINER_NAME", "coursewagon-images"),
+		AzureStorageContainerName:    getEnv("AZURE_STORAGE_CONTAINER_NAME", "coursewagon-images"),
+
+		// S3 storage
+		S3Bucket:          getEnv("S3_BUCKET", ""),
+		S3Region:          getEnv("S3_REGION", "us-east-1"),
+		S3AccessKeyID:     getEnv("S3_ACCESS_KEY_ID", ""),
+		S3SecretAccessKey: getEnv("S3_SECRET_ACCESS_KEY", ""),
+
+		// GCS storage
+		GCSBucket:          getEnv("GCS_BUCKET", ""),
+		GCSCredentialsFile: getEnv("GCS_CREDENTIALS_FILE", ""),
+
+		// Local filesystem storage
+		LocalStorageDir:     getEnv("LOCAL_STORAGE_DIR", "./uploads"),
+		LocalStorageBaseURL: getEnv("LOCAL_STORAGE_BASE_URL", ""),
+
+		SignedURLCacheSize: getIntEnv("SIGNED_URL_CACHE_SIZE", 1000),
 
 		// Azure Deployment
 		AzureResourceGroup:     getEnv("AZURE_RESOURCE_GROUP", "coursewagon-rg"),
@@ -121,6 +399,63 @@ func LoadConfig() (*Config, error) {
 		AzureContainerRegistry: getEnv("AZURE_CONTAINER_REGISTRY", "coursewagoracr"),
 		AzureContainerAppEnv:   getEnv("AZURE_CONTAINER_APP_ENV", "coursewagon-env"),
 		AzureContainerAppName:  getEnv("AZURE_CONTAINER_APP_NAME", "coursewagon-backend"),
+
+		// Auth
+		Auth: AuthConfig{
+			Mode:               getEnv("AUTH_MODE", "jwt"),
+			SessionSecret:      getEnv("SESSION_SECRET", "your-secret-key"),
+			SessionStoreDriver: getEnv("SESSION_STORE_DRIVER", "cookie"),
+			RedisAddr:          getEnv("SESSION_REDIS_ADDR", "localhost:6379"),
+			CSRFCookieName:     getEnv("CSRF_COOKIE_NAME", "cw_csrf"),
+			MaxFailures:        getIntEnv("AUTH_MAX_FAILURES", 10),
+			LockoutWindow:      getDurationEnv("AUTH_LOCKOUT_WINDOW", 1) * time.Hour,
+			RateLimitBackend:   getEnv("AUTH_RATELIMIT_BACKEND", "memory"),
+		},
+
+		// Password hashing and strength policy
+		Password: PasswordConfig{
+			Argon2Memory:      uint32(getIntEnv("PASSWORD_ARGON2_MEMORY_KB", 64*1024)),
+			Argon2Iterations:  uint32(getIntEnv("PASSWORD_ARGON2_ITERATIONS", 3)),
+			Argon2Parallelism: uint8(getIntEnv("PASSWORD_ARGON2_PARALLELISM", 2)),
+			Argon2SaltLen:     uint32(getIntEnv("PASSWORD_ARGON2_SALT_LEN", 16)),
+			Argon2KeyLen:      uint32(getIntEnv("PASSWORD_ARGON2_KEY_LEN", 32)),
+			MinLength:         getIntEnv("PASSWORD_MIN_LENGTH", 10),
+			CheckPwned:        getBoolEnv("PASSWORD_CHECK_PWNED", false),
+			Pepper:            getEnv("PASSWORD_PEPPER", ""),
+		},
+
+		// OAuth2/OIDC social login
+		OAuth: OAuthConfig{
+			Google: OIDCProviderConfig{
+				ClientID:     getEnv("GOOGLE_OAUTH_CLIENT_ID", ""),
+				ClientSecret: getEnv("GOOGLE_OAUTH_CLIENT_SECRET", ""),
+				RedirectURL:  getEnv("GOOGLE_OAUTH_REDIRECT_URL", ""),
+				Issuer:       getEnv("GOOGLE_OAUTH_ISSUER", "https://accounts.google.com"),
+				JWKSURL:      getEnv("GOOGLE_OAUTH_JWKS_URL", "https://www.googleapis.com/oauth2/v3/certs"),
+				AuthURL:      getEnv("GOOGLE_OAUTH_AUTH_URL", "https://accounts.google.com/o/oauth2/v2/auth"),
+				TokenURL:     getEnv("GOOGLE_OAUTH_TOKEN_URL", "https://oauth2.googleapis.com/token"),
+			},
+			OIDC: OIDCProviderConfig{
+				ClientID:     getEnv("OIDC_CLIENT_ID", ""),
+				ClientSecret: getEnv("OIDC_CLIENT_SECRET", ""),
+				RedirectURL:  getEnv("OIDC_REDIRECT_URL", ""),
+				Issuer:       getEnv("OIDC_ISSUER", ""),
+				JWKSURL:      getEnv("OIDC_JWKS_URL", ""),
+				AuthURL:      getEnv("OIDC_AUTH_URL", ""),
+				TokenURL:     getEnv("OIDC_TOKEN_URL", ""),
+			},
+			GitHub: GitHubOAuthConfig{
+				ClientID:     getEnv("GITHUB_OAUTH_CLIENT_ID", ""),
+				ClientSecret: getEnv("GITHUB_OAUTH_CLIENT_SECRET", ""),
+				RedirectURL:  getEnv("GITHUB_OAUTH_REDIRECT_URL", ""),
+			},
+		},
+
+		// Telegram notification channel
+		Telegram: TelegramConfig{
+			BotToken:      getEnv("TELEGRAM_BOT_TOKEN", ""),
+			WebhookSecret: getEnv("TELEGRAM_WEBHOOK_SECRET", ""),
+		},
 	}
 
 	return config, nil
@@ -169,6 +504,23 @@ func getEnv(key, defaultValue string) string {
 	return defaultValue
 }
 
+// getListEnv splits a comma-separated env var into a trimmed, non-empty
+// string slice, or returns defaultValue if the var is unset.
+func getListEnv(key string, defaultValue []string) []string {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	var items []string
+	for _, part := range strings.Split(value, ",") {
+		if trimmed := strings.TrimSpace(part); trimmed != "" {
+			items = append(items, trimmed)
+		}
+	}
+	return items
+}
+
 func getBoolEnv(key string, defaultValue bool) bool {
 	if value := os.Getenv(key); value != "" {
 		if parsed, err := strconv.ParseBool(value); err == nil {
@@ -194,4 +546,4 @@ func getDurationEnv(key string, defaultValue int) time.Duration {
 		}
 	}
 	return time.Duration(defaultValue)
-}
\ No newline at end of file
+}
@@ -0,0 +1,201 @@
+// Package jobs provides a small GORM-backed persistent queue so
+// long-running work (image generation, eventually content generation and
+// bulk uploads) returns a job ID immediately instead of blocking the HTTP
+// request. A worker pool started from routes.SetupRoutes drains it; jobs survive a
+// process restart since they live in the jobs table, not in memory.
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go-server/models"
+	"go-server/repositories"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Handler runs one job's payload and returns its result. Payload and result
+// are caller-defined JSON strings; Queue treats them as opaque. jobID is
+// passed through so a handler for long-running work can call
+// Queue.UpdateProgress against it mid-run.
+type Handler func(jobID uint, payload string) (result string, err error)
+
+const (
+	defaultMaxAttempts  = 5
+	defaultPollInterval = 2 * time.Second
+	defaultBatchSize    = 10
+	// defaultVisibilityTimeout bounds how long a job may sit "running"
+	// before Queue assumes the worker that claimed it died mid-run and
+	// reclaims it back to pending.
+	defaultVisibilityTimeout = 15 * time.Minute
+)
+
+// Queue dispatches pending models.Job rows to the Handler registered for
+// their Kind, retrying transient failures with exponential backoff up to
+// maxAttempts before marking a job permanently failed.
+type Queue struct {
+	repo              repositories.JobRepository
+	handlers          map[string]Handler
+	workers           int
+	maxAttempts       int
+	visibilityTimeout time.Duration
+	wake              chan struct{}
+}
+
+// NewQueue builds a Queue with workers concurrent workers, each polling repo
+// for pending jobs.
+func NewQueue(repo repositories.JobRepository, workers int) *Queue {
+	if workers <= 0 {
+		workers = 1
+	}
+	return &Queue{
+		repo:              repo,
+		handlers:          make(map[string]Handler),
+		workers:           workers,
+		maxAttempts:       defaultMaxAttempts,
+		visibilityTimeout: defaultVisibilityTimeout,
+		wake:              make(chan struct{}, 1),
+	}
+}
+
+// UpdateProgress records a handler-defined sub-stage label against jobID,
+// for a GET /jobs/:id/watch client to observe while Status is still
+// "running".
+func (q *Queue) UpdateProgress(jobID uint, progress string) error {
+	return q.repo.UpdateProgress(jobID, progress)
+}
+
+// Register associates kind with the handler that processes its jobs. Call
+// this before Start; jobs of an unregistered kind are left pending forever.
+func (q *Queue) Register(kind string, handler Handler) {
+	q.handlers[kind] = handler
+}
+
+// Enqueue creates a job and returns it immediately. If idempotencyKey is
+// non-empty and already has a job, that existing job is returned instead of
+// a new one being created, so a retried POST can't double-submit work.
+func (q *Queue) Enqueue(kind, payload, idempotencyKey string) (*models.Job, error) {
+	if idempotencyKey != "" {
+		existing, err := q.repo.GetByIdempotencyKey(idempotencyKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check idempotency key: %w", err)
+		}
+		if existing != nil {
+			return existing, nil
+		}
+	}
+
+	job := &models.Job{
+		Kind:           kind,
+		Payload:        payload,
+		Status:         models.JobStatusPending,
+		IdempotencyKey: idempotencyKey,
+		NextAttemptAt:  time.Now(),
+	}
+	if err := q.repo.Create(job); err != nil {
+		return nil, fmt.Errorf("failed to create job: %w", err)
+	}
+
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+	return job, nil
+}
+
+// Start launches the worker pool; it runs until ctx is canceled.
+func (q *Queue) Start(ctx context.Context) {
+	for i := 0; i < q.workers; i++ {
+		go q.runWorker(ctx)
+	}
+}
+
+func (q *Queue) runWorker(ctx context.Context) {
+	ticker := time.NewTicker(defaultPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-q.wake:
+		case <-ticker.C:
+			if err := q.repo.ReclaimStale(q.visibilityTimeout); err != nil {
+				logrus.Errorf("Failed to reclaim stale running jobs: %v", err)
+			}
+		}
+		q.drain(ctx)
+	}
+}
+
+// drain processes one batch of ready jobs. Multiple workers calling this
+// concurrently is safe: two workers claiming the same job both run
+// MarkRunning's UPDATE (so attempts is bumped twice), and a duplicate run
+// just repeats idempotent generation work rather than corrupting state.
+func (q *Queue) drain(ctx context.Context) {
+	pending, err := q.repo.GetPending(defaultBatchSize)
+	if err != nil {
+		logrus.Errorf("Failed to fetch pending jobs: %v", err)
+		return
+	}
+
+	for _, job := range pending {
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+		q.process(job)
+	}
+}
+
+func (q *Queue) process(job models.Job) {
+	handler, ok := q.handlers[job.Kind]
+	if !ok {
+		logrus.Errorf("No handler registered for job kind %q, job %d left pending", job.Kind, job.ID)
+		return
+	}
+
+	if err := q.repo.MarkRunning(job.ID); err != nil {
+		return
+	}
+	attempts := job.Attempts + 1
+
+	result, err := handler(job.ID, job.Payload)
+	if err == nil {
+		if err := q.repo.MarkCompleted(job.ID, result); err != nil {
+			logrus.Errorf("Failed to mark job %d completed: %v", job.ID, err)
+		}
+		return
+	}
+
+	if attempts >= q.maxAttempts {
+		if mErr := q.repo.MarkFailed(job.ID, attempts, err.Error()); mErr != nil {
+			logrus.Errorf("Failed to mark job %d failed: %v", job.ID, mErr)
+		}
+		return
+	}
+
+	delay := backoff(attempts)
+	logrus.WithFields(logrus.Fields{
+		"job_id":   job.ID,
+		"kind":     job.Kind,
+		"attempts": attempts,
+		"retry_in": delay,
+	}).Warnf("Job failed, scheduling retry: %v", err)
+	if rErr := q.repo.ScheduleRetry(job.ID, err.Error(), delay); rErr != nil {
+		logrus.Errorf("Failed to schedule retry for job %d: %v", job.ID, rErr)
+	}
+}
+
+// backoff grows 2^attempts seconds, capped at 5 minutes.
+func backoff(attempts int) time.Duration {
+	delay := time.Duration(1<<uint(attempts)) * time.Second
+	const max = 5 * time.Minute
+	if delay > max {
+		return max
+	}
+	return delay
+}
@@ -0,0 +1,46 @@
+// Package logger builds structured logrus.Entry values carrying the
+// fields that tie a log line back to the request that produced it
+// (request_id, user_id, trace_id), so every call site logs through
+// FromContext(ctx) instead of the package-global logrus.
+package logger
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+)
+
+// fieldsKey is the context key FromContext/WithFields store accumulated
+// logrus.Fields under. It's unexported so only this package can read or
+// write it, the same way context keys are conventionally scoped in Go.
+type fieldsKey struct{}
+
+// FromContext returns a logrus.Entry pre-populated with every field
+// WithFields has attached to ctx (request_id, user_id, trace_id, and
+// anything a caller added), or the bare standard logger if ctx carries
+// none. Every log line that can be tied to a single HTTP request should go
+// through this instead of logrus.Errorf/Infof directly.
+func FromContext(ctx context.Context) *logrus.Entry {
+	fields, ok := ctx.Value(fieldsKey{}).(logrus.Fields)
+	if !ok {
+		return logrus.NewEntry(logrus.StandardLogger())
+	}
+	return logrus.WithFields(fields)
+}
+
+// WithFields returns a context carrying fields merged on top of whatever
+// FromContext(ctx) already had, so a deeper call (e.g. a repository call
+// made on behalf of a request) can add its own fields (like a batch ID)
+// without losing request_id/user_id attached further up the call chain.
+func WithFields(ctx context.Context, fields logrus.Fields) context.Context {
+	merged := make(logrus.Fields, len(fields))
+	if existing, ok := ctx.Value(fieldsKey{}).(logrus.Fields); ok {
+		for k, v := range existing {
+			merged[k] = v
+		}
+	}
+	for k, v := range fields {
+		merged[k] = v
+	}
+	return context.WithValue(ctx, fieldsKey{}, merged)
+}
@@ -0,0 +1,60 @@
+// Package metrics holds the small set of process-local counters this
+// service tracks, named in the Prometheus counter convention (a noun phrase
+// ending in _total) so they can be exposed by a /metrics endpoint later
+// without renaming anything that already calls Inc().
+package metrics
+
+import "sync/atomic"
+
+// Counter is a monotonically increasing, concurrency-safe named counter.
+type Counter struct {
+	name  string
+	value int64
+}
+
+// NewCounter builds a zeroed counter under name.
+func NewCounter(name string) *Counter {
+	return &Counter{name: name}
+}
+
+// Inc increments the counter by one.
+func (c *Counter) Inc() {
+	atomic.AddInt64(&c.value, 1)
+}
+
+// Value returns the counter's current total.
+func (c *Counter) Value() int64 {
+	return atomic.LoadInt64(&c.value)
+}
+
+// Name returns the counter's metric name.
+func (c *Counter) Name() string {
+	return c.name
+}
+
+// AuthLoginFailuresTotal counts every bad-password login attempt recorded
+// by services.AuthServiceImpl.Login, across all accounts.
+var AuthLoginFailuresTotal = NewCounter("auth_login_failures_total")
+
+// AuthLoginLockoutsTotal counts every time ratelimit.RateLimitStore's
+// RecordFailure (called from services.AuthServiceImpl.Login) hard-locks an
+// (ip, email) pair out after MaxFailures consecutive failures.
+var AuthLoginLockoutsTotal = NewCounter("auth_login_lockouts_total")
+
+// APIRateLimitExceededTotal counts every request
+// middleware.APIRateLimitMiddleware rejects for having no tokens left in
+// its bucket, across every route and policy.
+var APIRateLimitExceededTotal = NewCounter("api_rate_limit_exceeded_total")
+
+// SignedURLCacheHitsTotal counts storage.SignedURLCache lookups served from
+// cache without calling the backend's PresignedURL.
+var SignedURLCacheHitsTotal = NewCounter("signed_url_cache_hits_total")
+
+// SignedURLCacheMissesTotal counts storage.SignedURLCache lookups for a
+// path with no cached entry at all.
+var SignedURLCacheMissesTotal = NewCounter("signed_url_cache_misses_total")
+
+// SignedURLCacheRefreshesTotal counts storage.SignedURLCache lookups for a
+// path whose cached entry has dropped below its refresh-ahead threshold and
+// was re-signed.
+var SignedURLCacheRefreshesTotal = NewCounter("signed_url_cache_refreshes_total")
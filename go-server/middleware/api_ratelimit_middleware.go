@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go-server/models"
+	"go-server/ratelimit"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DefaultAPIRateLimitPolicy is the bucket every protected route is charged
+// against unless a route group is wrapped in a stricter policy of its own
+// (see GenerateRateLimitPolicy).
+var DefaultAPIRateLimitPolicy = ratelimit.Policy{Burst: 120, Window: time.Minute}
+
+// GenerateRateLimitPolicy caps the LLM/image-generation endpoints much
+// lower than DefaultAPIRateLimitPolicy: each call is expensive (it burns a
+// Gemini/OpenAI/Anthropic/Ollama or image-provider quota) and slow enough
+// that a caller bursting past a handful of them is virtually always a bug
+// or an abuse attempt rather than legitimate traffic.
+var GenerateRateLimitPolicy = ratelimit.Policy{Burst: 10, Window: time.Minute}
+
+// APIRateLimitMiddleware charges one token from store's bucket for the
+// caller identified by rateLimitKey under policy, per (policy, caller)
+// pair, and rejects the request with 429 once the bucket is empty. It sets
+// X-RateLimit-Limit/X-RateLimit-Remaining on every response and Retry-After
+// on a rejected one, the same Retry-After header rateLimitMW (login
+// lockout) sets on its own 429s.
+func APIRateLimitMiddleware(store ratelimit.LimiterStore, policy ratelimit.Policy, name string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := name + ":" + rateLimitCallerKey(c)
+		allowed, remaining, retryAfter := store.Allow(key, policy)
+
+		c.Header("X-RateLimit-Limit", strconv.Itoa(policy.Burst))
+		c.Header("X-RateLimit-Remaining", strconv.Itoa(remaining))
+
+		if !allowed {
+			retrySeconds := int(retryAfter.Seconds())
+			if retrySeconds < 1 {
+				retrySeconds = 1
+			}
+			c.Header("Retry-After", fmt.Sprintf("%d", retrySeconds))
+			c.JSON(http.StatusTooManyRequests, models.APIResponse{
+				Success: false,
+				Error:   "rate limit exceeded; try again later",
+			})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// rateLimitCallerKey identifies the caller a bucket is charged against: an
+// authenticated user ID when one is available (so a single user can't get
+// a fresh quota by rotating IPs), falling back to client IP for
+// unauthenticated requests.
+func rateLimitCallerKey(c *gin.Context) string {
+	if userID, err := GetUserIDFromContext(c); err == nil && userID != 0 {
+		return fmt.Sprintf("user:%d", userID)
+	}
+	return "ip:" + c.ClientIP()
+}
@@ -1,18 +1,25 @@
 package middleware
 
 import (
+	"fmt"
+	"go-server/apierror"
 	"go-server/config"
+	"go-server/models"
 	"go-server/utils"
 	"net/http"
 	"strconv"
 	"strings"
 
+	"github.com/gin-contrib/sessions"
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
 )
 
-// AuthMiddleware validates JWT tokens
-func AuthMiddleware(cfg *config.Config) gin.HandlerFunc {
+// AuthMiddleware validates JWT tokens. sessionCache is consulted against
+// claims.SessionID so an access token survives only until its session is
+// revoked (logout, "sign out everywhere", or reuse/MFA-change detection in
+// services.TokenService.Rotate), not just until its own expiry.
+func AuthMiddleware(cfg *config.Config, sessionCache *utils.SessionRevocationCache) gin.HandlerFunc {
 	jwtUtil := utils.NewJWTUtil(
 		cfg.JWTSecretKey,
 		cfg.JWTAccessTokenExpires,
@@ -23,64 +30,40 @@ func AuthMiddleware(cfg *config.Config) gin.HandlerFunc {
 		// Get token from Authorization header
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"success": false,
-				"error":   "authorization header required",
-			})
-			c.Abort()
+			apierror.Abort(c, fmt.Errorf("%w", apierror.Unauthorized("authorization header required")))
 			return
 		}
 
 		// Extract token
 		token := utils.ExtractTokenFromHeader(authHeader)
 		if token == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"success": false,
-				"error":   "invalid authorization header format",
-			})
-			c.Abort()
+			apierror.Abort(c, fmt.Errorf("%w", apierror.Unauthorized("invalid authorization header format")))
 			return
 		}
 
 		// Validate token
 		claims, err := jwtUtil.ValidateToken(token)
 		if err != nil {
-			var statusCode int
-			var message string
-
-			switch err {
-			case utils.ErrTokenExpired:
-				statusCode = http.StatusUnauthorized
-				message = "token has expired"
-			case utils.ErrTokenInvalid:
-				statusCode = http.StatusUnauthorized
-				message = "invalid token"
-			default:
-				statusCode = http.StatusUnauthorized
-				message = "authentication failed"
-			}
-
-			c.JSON(statusCode, gin.H{
-				"success": false,
-				"error":   message,
-			})
-			c.Abort()
+			apierror.Abort(c, err)
 			return
 		}
 
 		// Check if it's an access token
 		if claims.Subject != "access_token" {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"success": false,
-				"error":   "invalid token type",
-			})
-			c.Abort()
+			apierror.Abort(c, fmt.Errorf("%w", apierror.Unauthorized("invalid token type")))
+			return
+		}
+
+		if sessionCache != nil && sessionCache.IsRevoked(claims.SessionID) {
+			apierror.Abort(c, fmt.Errorf("%w", apierror.Unauthorized("session has been revoked, please log in again")))
 			return
 		}
 
 		// Store user information in context
 		c.Set("user_id", claims.UserID)
 		c.Set("user_email", claims.Email)
+		c.Set("roles", claims.Roles)
+		c.Set("scopes", claims.Scopes)
 
 		c.Next()
 	})
@@ -123,14 +106,24 @@ func OptionalAuthMiddleware(cfg *config.Config) gin.HandlerFunc {
 			// Store user information in context
 			c.Set("user_id", claims.UserID)
 			c.Set("user_email", claims.Email)
+			c.Set("roles", claims.Roles)
+			c.Set("scopes", claims.Scopes)
 		}
 
 		c.Next()
 	})
 }
 
-// GetUserIDFromContext extracts user ID from gin context
+// GetUserIDFromContext extracts the user ID, checking the session (when
+// sessions.Sessions is mounted, i.e. session or hybrid auth mode) before
+// falling back to the JWT claim AuthMiddleware stored in the gin context.
 func GetUserIDFromContext(c *gin.Context) (uint, error) {
+	if sess := currentSession(c); sess != nil {
+		if id, ok := sess.Get(sessionUserIDKey).(uint); ok {
+			return id, nil
+		}
+	}
+
 	userID, exists := c.Get("user_id")
 	if !exists {
 		return 0, gin.Error{Err: http.ErrNotFound, Type: gin.ErrorTypePublic}
@@ -144,8 +137,15 @@ func GetUserIDFromContext(c *gin.Context) (uint, error) {
 	return id, nil
 }
 
-// GetUserEmailFromContext extracts user email from gin context
+// GetUserEmailFromContext extracts the user email, checking the session
+// before falling back to the JWT claim, mirroring GetUserIDFromContext.
 func GetUserEmailFromContext(c *gin.Context) (string, error) {
+	if sess := currentSession(c); sess != nil {
+		if email, ok := sess.Get(sessionUserEmailKey).(string); ok {
+			return email, nil
+		}
+	}
+
 	userEmail, exists := c.Get("user_email")
 	if !exists {
 		return "", gin.Error{Err: http.ErrNotFound, Type: gin.ErrorTypePublic}
@@ -159,14 +159,86 @@ func GetUserEmailFromContext(c *gin.Context) (string, error) {
 	return email, nil
 }
 
-// AdminMiddleware ensures user has admin privileges
-func AdminMiddleware() gin.HandlerFunc {
-	return gin.HandlerFunc(func(c *gin.Context) {
-		// This would require checking user admin status in database
-		// For now, we'll just pass through
-		// TODO: Implement admin check
+// currentSession returns the request's session.Session if sessions.Sessions
+// was mounted (session/hybrid auth mode), or nil in JWT-only mode where no
+// session middleware ran.
+func currentSession(c *gin.Context) sessions.Session {
+	v, exists := c.Get(sessions.DefaultKey)
+	if !exists {
+		return nil
+	}
+	sess, ok := v.(sessions.Session)
+	if !ok {
+		return nil
+	}
+	return sess
+}
+
+// GetAuthContext builds an AuthContext from whatever the active auth
+// middleware populated into c: JWT claims for bearer/hybrid auth, or a
+// roleRepo lookup for session/HTTP-signature auth (see setAuthContext and
+// HTTPSignatureMiddleware). Route handlers pass the result into service
+// methods that enforce scope checks themselves, rather than trusting route
+// wiring alone.
+func GetAuthContext(c *gin.Context) (models.AuthContext, error) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		return models.AuthContext{}, err
+	}
+
+	authCtx := models.AuthContext{UserID: userID}
+	if roles, ok := c.Get("roles"); ok {
+		if r, ok := roles.([]string); ok {
+			authCtx.Roles = r
+		}
+	}
+	if scopes, ok := c.Get("scopes"); ok {
+		if s, ok := scopes.([]string); ok {
+			authCtx.Scopes = s
+		}
+	}
+
+	return authCtx, nil
+}
+
+// RequireRole aborts with 403 unless the caller's AuthContext includes
+// role, e.g. RequireRole("admin") in front of admin-only routes.
+func RequireRole(role string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		authCtx, err := GetAuthContext(c)
+		if err != nil {
+			apierror.Abort(c, fmt.Errorf("%w", apierror.Unauthorized("authentication required")))
+			return
+		}
+		if !authCtx.HasRole(role) {
+			apierror.Abort(c, fmt.Errorf("%w", apierror.Forbidden("requires role: "+role)))
+			return
+		}
 		c.Next()
-	})
+	}
+}
+
+// RequireScope aborts with 403 unless the caller's AuthContext includes
+// scope, e.g. RequireScope("testimonial:approve") in front of the
+// testimonial approval route.
+func RequireScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		authCtx, err := GetAuthContext(c)
+		if err != nil {
+			apierror.Abort(c, fmt.Errorf("%w", apierror.Unauthorized("authentication required")))
+			return
+		}
+		if !authCtx.HasScope(scope) {
+			apierror.Abort(c, fmt.Errorf("%w", apierror.Forbidden("requires scope: "+scope)))
+			return
+		}
+		c.Next()
+	}
+}
+
+// AdminMiddleware restricts a route to callers holding the "admin" role.
+func AdminMiddleware() gin.HandlerFunc {
+	return RequireRole("admin")
 }
 
 // ParseIDParam parses ID parameter from URL
@@ -182,4 +254,4 @@ func ParseIDParam(c *gin.Context, paramName string) (uint, error) {
 	}
 
 	return uint(id), nil
-}
\ No newline at end of file
+}
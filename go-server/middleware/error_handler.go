@@ -0,0 +1,24 @@
+package middleware
+
+import (
+	"go-server/apierror"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ErrorHandler renders the last error pushed onto c.Errors (via c.Error(err))
+// via apierror.Abort, which maps it to an *apierror.APIError (including
+// sentinel errors from packages that predate that taxonomy) and writes
+// either application/problem+json or this API's usual JSON error shape.
+// Handlers call c.Error(err) and return instead of rendering errors themselves.
+func ErrorHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+
+		apierror.Abort(c, c.Errors.Last().Err)
+	}
+}
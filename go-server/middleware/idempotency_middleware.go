@@ -0,0 +1,111 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"time"
+
+	"go-server/models"
+	"go-server/repositories"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// IdempotencyKeyTTL is how long a cached response stays replayable before
+// IdempotencyKeyRepository.DeleteExpired (run by a caller's own maintenance
+// job) is free to reclaim it.
+const IdempotencyKeyTTL = 24 * time.Hour
+
+// responseCapturingWriter tees everything a handler writes through c.Writer
+// into an in-memory buffer as well, so IdempotencyMiddleware can persist the
+// exact response bytes without affecting what the client receives.
+type responseCapturingWriter struct {
+	gin.ResponseWriter
+	body *bytes.Buffer
+}
+
+func (w *responseCapturingWriter) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+// IdempotencyMiddleware caches a mutating request's response keyed by
+// (user ID, Idempotency-Key header) so a client retrying the same request
+// (e.g. after a timed-out response) gets the original result replayed
+// instead of re-executing the handler. Requests without the header pass
+// through unaffected. A replayed key whose body hash doesn't match the one
+// that was cached is rejected with 409 Conflict rather than silently
+// returning a response for a different request. Unauthenticated routes
+// (register, password-reset) scope the cache under user ID 0, since the
+// key still needs to survive across retries before a session exists.
+func IdempotencyMiddleware(repo repositories.IdempotencyKeyRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader("Idempotency-Key")
+		if key == "" {
+			c.Next()
+			return
+		}
+
+		userID, _ := GetUserIDFromContext(c)
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.APIResponse{
+				Success: false,
+				Error:   "failed to read request body",
+			})
+			c.Abort()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		hash := sha256.Sum256(body)
+		requestHash := hex.EncodeToString(hash[:])
+
+		existing, err := repo.GetByUserAndKey(userID, key)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.APIResponse{
+				Success: false,
+				Error:   "failed to check idempotency key",
+			})
+			c.Abort()
+			return
+		}
+
+		if existing != nil {
+			if existing.RequestHash != requestHash {
+				c.JSON(http.StatusConflict, models.APIResponse{
+					Success: false,
+					Error:   "Idempotency-Key was already used with a different request body",
+				})
+				c.Abort()
+				return
+			}
+
+			c.Data(existing.StatusCode, "application/json; charset=utf-8", []byte(existing.ResponseBody))
+			c.Abort()
+			return
+		}
+
+		capture := &responseCapturingWriter{ResponseWriter: c.Writer, body: &bytes.Buffer{}}
+		c.Writer = capture
+
+		c.Next()
+
+		record := &models.IdempotencyKey{
+			UserID:       userID,
+			Key:          key,
+			RequestHash:  requestHash,
+			StatusCode:   capture.Status(),
+			ResponseBody: capture.body.String(),
+			ExpiresAt:    time.Now().Add(IdempotencyKeyTTL),
+		}
+		if err := repo.Create(record); err != nil {
+			logrus.Errorf("Error caching idempotent response for key %s: %v", key, err)
+		}
+	}
+}
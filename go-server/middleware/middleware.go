@@ -4,38 +4,52 @@ import (
 	"net/http"
 	"time"
 
+	"go-server/logger"
+
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
 	"gorm.io/gorm"
 )
 
-// LoggerMiddleware logs HTTP requests
+// LoggerMiddleware logs one structured line per request, through
+// logger.FromContext so it carries the request_id/trace_id
+// RequestIDMiddleware attached to c.Request's context (c.Request is read
+// after c.Next(), so this holds even though RequestIDMiddleware is mounted
+// further down the chain, on the /api group). user_id is added here rather than by
+// RequestIDMiddleware, since auth hasn't run yet at that point in the
+// chain; it's read back out of c after c.Next() so it reflects whichever
+// auth mode authenticated the request, if any.
 func LoggerMiddleware() gin.HandlerFunc {
-	return gin.LoggerWithConfig(gin.LoggerConfig{
-		Formatter: func(param gin.LogFormatterParams) string {
-			logrus.WithFields(logrus.Fields{
-				"status_code":  param.StatusCode,
-				"latency":      param.Latency,
-				"client_ip":    param.ClientIP,
-				"method":       param.Method,
-				"path":         param.Path,
-				"user_agent":   param.Request.UserAgent(),
-				"error":        param.ErrorMessage,
-				"body_size":    param.BodySize,
-			}).Info("HTTP Request")
-			return ""
-		},
-		Output: logrus.StandardLogger().Out,
-	})
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		fields := logrus.Fields{
+			"route":       c.FullPath(),
+			"method":      c.Request.Method,
+			"status_code": c.Writer.Status(),
+			"client_ip":   c.ClientIP(),
+			"latency_ms":  time.Since(start).Milliseconds(),
+		}
+		if userID, err := GetUserIDFromContext(c); err == nil {
+			fields["user_id"] = userID
+		}
+		if len(c.Errors) > 0 {
+			fields["error"] = c.Errors.String()
+		}
+
+		logger.FromContext(c.Request.Context()).WithFields(fields).Info("HTTP request")
+	}
 }
 
 // ErrorHandlerMiddleware handles panics and errors
 func ErrorHandlerMiddleware() gin.HandlerFunc {
 	return gin.RecoveryWithWriter(logrus.StandardLogger().Out, func(c *gin.Context, recovered interface{}) {
+		entry := logger.FromContext(c.Request.Context())
 		if err, ok := recovered.(string); ok {
-			logrus.Errorf("Panic recovered: %s", err)
+			entry.Errorf("Panic recovered: %s", err)
 		} else {
-			logrus.Errorf("Panic recovered: %v", recovered)
+			entry.Errorf("Panic recovered: %v", recovered)
 		}
 
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -63,7 +77,7 @@ func DatabaseErrorMiddleware() gin.HandlerFunc {
 					}
 					return
 				case gorm.ErrInvalidTransaction:
-					logrus.Errorf("Database transaction error: %v", err.Err)
+					logger.FromContext(c.Request.Context()).Errorf("Database transaction error: %v", err.Err)
 					if !c.Writer.Written() {
 						c.JSON(http.StatusInternalServerError, gin.H{
 							"success": false,
@@ -73,7 +87,7 @@ func DatabaseErrorMiddleware() gin.HandlerFunc {
 					return
 				default:
 					if isDBConnectionError(err.Err) {
-						logrus.Errorf("Database connection error: %v", err.Err)
+						logger.FromContext(c.Request.Context()).Errorf("Database connection error: %v", err.Err)
 						if !c.Writer.Written() {
 							c.JSON(http.StatusServiceUnavailable, gin.H{
 								"success": false,
@@ -88,45 +102,6 @@ func DatabaseErrorMiddleware() gin.HandlerFunc {
 	})
 }
 
-// RateLimitMiddleware implements basic rate limiting
-func RateLimitMiddleware() gin.HandlerFunc {
-	// Simple in-memory rate limiting (not suitable for production)
-	clients := make(map[string][]time.Time)
-	maxRequests := 100
-	timeWindow := time.Minute
-
-	return gin.HandlerFunc(func(c *gin.Context) {
-		clientIP := c.ClientIP()
-		now := time.Now()
-
-		// Clean old requests
-		if requests, exists := clients[clientIP]; exists {
-			var validRequests []time.Time
-			for _, reqTime := range requests {
-				if now.Sub(reqTime) < timeWindow {
-					validRequests = append(validRequests, reqTime)
-				}
-			}
-			clients[clientIP] = validRequests
-		}
-
-		// Check rate limit
-		if len(clients[clientIP]) >= maxRequests {
-			c.JSON(http.StatusTooManyRequests, gin.H{
-				"success": false,
-				"error":   "rate limit exceeded",
-			})
-			c.Abort()
-			return
-		}
-
-		// Add current request
-		clients[clientIP] = append(clients[clientIP], now)
-
-		c.Next()
-	})
-}
-
 // SecurityHeadersMiddleware adds security headers
 func SecurityHeadersMiddleware() gin.HandlerFunc {
 	return gin.HandlerFunc(func(c *gin.Context) {
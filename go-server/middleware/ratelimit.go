@@ -0,0 +1,60 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"go-server/models"
+	"go-server/ratelimit"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RateLimitMiddleware guards an authentication endpoint with a progressive
+// cooldown keyed on (client IP, email) extracted from the request body —
+// ratelimit.BackoffSchedule after each consecutive failure, then a hard
+// lockout for the account's configured lockout window once its configured
+// failure threshold is reached. It only checks whether the key is
+// currently cooling down; failures are recorded by AuthServiceImpl.Login,
+// the only place that knows whether an attempt was a bad password (worth
+// penalizing) or an unknown account (which would otherwise let an attacker
+// amplify enumeration by tripping lockouts on emails that don't exist).
+func RateLimitMiddleware(store ratelimit.RateLimitStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.APIResponse{
+				Success: false,
+				Error:   "failed to read request body",
+			})
+			c.Abort()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		var payload struct {
+			Email string `json:"email"`
+		}
+		_ = json.Unmarshal(body, &payload)
+
+		key := ratelimit.Key(c.ClientIP(), payload.Email)
+		if cooldown := store.Cooldown(key); cooldown > 0 {
+			retryAfter := int(cooldown.Seconds())
+			if retryAfter < 1 {
+				retryAfter = 1
+			}
+			c.Header("Retry-After", fmt.Sprintf("%d", retryAfter))
+			c.JSON(http.StatusTooManyRequests, models.APIResponse{
+				Success: false,
+				Error:   "too many attempts; try again later",
+			})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
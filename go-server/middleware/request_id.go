@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"go-server/apierror"
+	"go-server/logger"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// RequestIDMiddleware assigns each request a correlation ID, reusing one the
+// caller already sent via X-Request-ID (e.g. from a load balancer) instead
+// of generating a new one. It stores the ID in the gin context for
+// apierror.Abort to include in error bodies, echoes it as a response
+// header so logs and frontend errors can be tied back to the same request,
+// and attaches it (as both request_id and trace_id, since this service has
+// no distributed tracer of its own yet to supply a separate one) to a
+// derived context.Context so logger.FromContext picks it up in every
+// handler, service, and repository call made for this request.
+func RequestIDMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(apierror.RequestIDHeader)
+		if requestID == "" {
+			requestID = uuid.NewString()
+		}
+
+		c.Set(apierror.RequestIDContextKey, requestID)
+		c.Header(apierror.RequestIDHeader, requestID)
+
+		ctx := logger.WithFields(c.Request.Context(), logrus.Fields{
+			"request_id": requestID,
+			"trace_id":   requestID,
+		})
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+	}
+}
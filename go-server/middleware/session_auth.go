@@ -0,0 +1,166 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+
+	"go-server/apierror"
+	"go-server/config"
+	"go-server/models"
+	"go-server/repositories"
+	"go-server/utils"
+
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	sessionUserIDKey    = "user_id"
+	sessionUserEmailKey = "user_email"
+	sessionCSRFKey      = "csrf_token"
+)
+
+// SessionAuthMiddleware requires a server-side session established by
+// SetSessionUser at login instead of a bearer token, so first-party web
+// clients authenticate via the cw_session cookie alone.
+func SessionAuthMiddleware(cfg *config.Config, roleRepo repositories.RoleRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		sess := sessions.Default(c)
+		if _, ok := sess.Get(sessionUserIDKey).(uint); !ok {
+			apierror.Abort(c, fmt.Errorf("%w", apierror.Unauthorized("authentication required")))
+			return
+		}
+
+		setAuthContext(c, sess, roleRepo)
+		c.Next()
+	}
+}
+
+// HybridAuthMiddleware accepts either a server-side session or a bearer JWT,
+// checking the session first so browser clients need no token handling while
+// API clients keep using AuthMiddleware's bearer-token flow.
+func HybridAuthMiddleware(cfg *config.Config, roleRepo repositories.RoleRepository, sessionCache *utils.SessionRevocationCache) gin.HandlerFunc {
+	jwtAuth := AuthMiddleware(cfg, sessionCache)
+
+	return func(c *gin.Context) {
+		sess := sessions.Default(c)
+		if _, ok := sess.Get(sessionUserIDKey).(uint); ok {
+			setAuthContext(c, sess, roleRepo)
+			c.Next()
+			return
+		}
+
+		jwtAuth(c)
+	}
+}
+
+// setAuthContext copies the session's identity into the gin context and
+// looks up the user's current roles/scopes via roleRepo, so
+// RequireRole/RequireScope work the same for session-authenticated requests
+// as for a bearer JWT's baked-in claims. Unlike a JWT, a session isn't
+// reissued on every request, so this reads roles live rather than from a
+// stored snapshot that could go stale after a grant/revoke.
+func setAuthContext(c *gin.Context, sess sessions.Session, roleRepo repositories.RoleRepository) {
+	userID, ok := sess.Get(sessionUserIDKey).(uint)
+	if ok {
+		c.Set("user_id", userID)
+	}
+	if email, ok := sess.Get(sessionUserEmailKey).(string); ok {
+		c.Set("user_email", email)
+	}
+	if !ok {
+		return
+	}
+
+	roles, err := roleRepo.GetRolesByUserID(userID)
+	if err != nil {
+		logrus.Warnf("Failed to load roles for user %d: %v", userID, err)
+		return
+	}
+	c.Set("roles", models.RoleNames(roles))
+	c.Set("scopes", models.RoleScopes(roles))
+}
+
+var csrfProtectedMethods = map[string]bool{
+	http.MethodPost:   true,
+	http.MethodPut:    true,
+	http.MethodPatch:  true,
+	http.MethodDelete: true,
+}
+
+// CSRFMiddleware enforces a double-submit cookie on state-changing requests
+// that were authenticated via session: the X-CSRF-Token header must match
+// the token SetSessionUser stored in the session at login. Requests with no
+// session-backed auth in play (e.g. a bearer-only client in hybrid mode)
+// have nothing to check and pass through untouched. Only mount this when
+// cfg.Auth.Mode is "session" or "hybrid".
+func CSRFMiddleware(cfg *config.Config) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !csrfProtectedMethods[c.Request.Method] {
+			c.Next()
+			return
+		}
+
+		expected, ok := sessions.Default(c).Get(sessionCSRFKey).(string)
+		if !ok {
+			c.Next()
+			return
+		}
+
+		submitted := c.GetHeader("X-CSRF-Token")
+		if submitted == "" || submitted != expected {
+			apierror.Abort(c, fmt.Errorf("%w", apierror.Forbidden("invalid or missing CSRF token")))
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// SetSessionUser establishes a server-side session for userID/email and
+// issues a fresh double-submit CSRF token as a readable (non-HttpOnly)
+// cookie, so the web client's JS can echo it back as X-CSRF-Token. Called by
+// handleLogin when the server is running in session or hybrid auth mode.
+func SetSessionUser(c *gin.Context, cfg *config.Config, userID uint, email string) (csrfToken string, err error) {
+	sess := sessions.Default(c)
+	sess.Set(sessionUserIDKey, userID)
+	sess.Set(sessionUserEmailKey, email)
+
+	csrfToken, err = generateCSRFToken()
+	if err != nil {
+		return "", fmt.Errorf("failed to generate CSRF token: %w", err)
+	}
+	sess.Set(sessionCSRFKey, csrfToken)
+
+	if err := sess.Save(); err != nil {
+		return "", fmt.Errorf("failed to save session: %w", err)
+	}
+
+	c.SetCookie(cfg.Auth.CSRFCookieName, csrfToken, int(cfg.JWTAccessTokenExpires.Seconds()), "/", "", cfg.Environment == "production", false)
+	return csrfToken, nil
+}
+
+// ClearSession invalidates the current server-side session and its CSRF
+// cookie. Called by handleLogout.
+func ClearSession(c *gin.Context, cfg *config.Config) error {
+	sess := sessions.Default(c)
+	sess.Clear()
+	sess.Options(sessions.Options{Path: "/", MaxAge: -1})
+	if err := sess.Save(); err != nil {
+		return fmt.Errorf("failed to clear session: %w", err)
+	}
+
+	c.SetCookie(cfg.Auth.CSRFCookieName, "", -1, "/", "", cfg.Environment == "production", false)
+	return nil
+}
+
+func generateCSRFToken() (string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(raw), nil
+}
@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+
+	"go-server/config"
+
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-contrib/sessions/cookie"
+	"github.com/gin-contrib/sessions/memstore"
+	"github.com/gin-contrib/sessions/redis"
+)
+
+// NewSessionStore builds the sessions.Store selected by
+// cfg.Auth.SessionStoreDriver: "redis" for a shared store across instances,
+// "memory" for tests/local dev (data does not survive a restart), or the
+// default "cookie" store.
+func NewSessionStore(cfg *config.Config) (sessions.Store, error) {
+	secret := []byte(cfg.Auth.SessionSecret)
+
+	switch cfg.Auth.SessionStoreDriver {
+	case "redis":
+		store, err := redis.NewStore(10, "tcp", cfg.Auth.RedisAddr, "", secret)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create redis session store: %w", err)
+		}
+		store.Options(sessionOptions(cfg))
+		return store, nil
+	case "memory":
+		store := memstore.NewStore(secret)
+		store.Options(sessionOptions(cfg))
+		return store, nil
+	default:
+		store := cookie.NewStore(secret)
+		store.Options(sessionOptions(cfg))
+		return store, nil
+	}
+}
+
+func sessionOptions(cfg *config.Config) sessions.Options {
+	return sessions.Options{
+		Path:     "/",
+		MaxAge:   int(cfg.JWTAccessTokenExpires.Seconds()),
+		HttpOnly: true,
+		Secure:   cfg.Environment == "production",
+		SameSite: http.SameSiteLaxMode,
+	}
+}
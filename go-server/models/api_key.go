@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+// APIKey is a public key registered for verifying draft-cavage / RFC 9421
+// HTTP Signatures on server-to-server requests, used as an alternative to a
+// JWT bearer token by CI bots and federated integrations that sign each
+// request instead of carrying a token.
+type APIKey struct {
+	ID           uint       `json:"id" gorm:"primaryKey;autoIncrement"`
+	UserID       uint       `json:"user_id" gorm:"not null;index"`
+	KeyID        string     `json:"key_id" gorm:"uniqueIndex;not null;size:100"`
+	PublicKeyPEM string     `json:"-" gorm:"not null;type:text"`
+	Algorithm    string     `json:"algorithm" gorm:"not null;size:20"`
+	RevokedAt    *time.Time `json:"revoked_at"`
+	CreatedAt    time.Time  `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// TableName specifies the table name for GORM
+func (APIKey) TableName() string {
+	return "api_keys"
+}
+
+// ToDict converts the key to a map for JSON responses, omitting the PEM
+// itself since there's no reason to echo a public key back to its owner.
+func (k *APIKey) ToDict() map[string]interface{} {
+	return map[string]interface{}{
+		"id":         k.ID,
+		"key_id":     k.KeyID,
+		"algorithm":  k.Algorithm,
+		"created_at": k.CreatedAt.Format(time.RFC3339),
+		"revoked":    k.RevokedAt != nil,
+	}
+}
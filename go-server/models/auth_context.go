@@ -0,0 +1,32 @@
+package models
+
+// AuthContext carries the authenticated caller's identity and granted
+// roles/scopes through a service call chain, so a service method can
+// enforce fine-grained authorization itself instead of relying solely on
+// route-level middleware.
+type AuthContext struct {
+	UserID uint
+	Roles  []string
+	Scopes []string
+}
+
+// HasRole reports whether role was granted to the caller.
+func (a AuthContext) HasRole(role string) bool {
+	for _, r := range a.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// HasScope reports whether scope was granted to the caller, directly or via
+// one of their roles.
+func (a AuthContext) HasScope(scope string) bool {
+	for _, s := range a.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
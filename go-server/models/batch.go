@@ -0,0 +1,76 @@
+package models
+
+import "time"
+
+// Batch scopes identify what a Batch was generated for.
+const (
+	BatchScopeChapter = "chapter"
+	BatchScopeSubject = "subject"
+)
+
+// Batch tracks a single "generate content for every topic in this
+// chapter/subject" request as a group of per-topic jobs, so a client can
+// poll one handle for aggregate progress instead of every underlying job
+// individually.
+type Batch struct {
+	ID        uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	UserID    uint      `json:"user_id" gorm:"not null;index"`
+	Scope     string    `json:"scope" gorm:"not null;size:20"`
+	ScopeID   uint      `json:"scope_id" gorm:"not null;index"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+
+	Items []BatchItem `json:"items,omitempty" gorm:"foreignKey:BatchID"`
+}
+
+// TableName specifies the table name for GORM
+func (Batch) TableName() string {
+	return "batches"
+}
+
+// ToDict summarizes the batch's aggregate progress, deriving status from
+// its items' live job statuses rather than tracking a separate copy.
+func (b *Batch) ToDict() map[string]interface{} {
+	total := len(b.Items)
+	completed, failed := 0, 0
+	for _, item := range b.Items {
+		switch item.Job.Status {
+		case JobStatusCompleted:
+			completed++
+		case JobStatusFailed:
+			failed++
+		}
+	}
+
+	status := JobStatusRunning
+	if total > 0 && completed+failed == total {
+		if failed > 0 {
+			status = JobStatusFailed
+		} else {
+			status = JobStatusCompleted
+		}
+	}
+
+	return map[string]interface{}{
+		"id":         b.ID,
+		"scope":      b.Scope,
+		"scope_id":   b.ScopeID,
+		"status":     status,
+		"total":      total,
+		"completed":  completed,
+		"failed":     failed,
+		"created_at": b.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+// ToDictWithItems is ToDict plus each topic's individual status, for a
+// detailed progress view.
+func (b *Batch) ToDictWithItems() map[string]interface{} {
+	dict := b.ToDict()
+	items := make([]map[string]interface{}, len(b.Items))
+	for i, item := range b.Items {
+		items[i] = item.ToDict()
+	}
+	dict["items"] = items
+	return dict
+}
@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+// BatchItem is one topic's content-generation job within a Batch. Status is
+// read live off the linked Job row rather than duplicated here, so the job
+// itself stays the single source of truth for progress.
+type BatchItem struct {
+	ID        uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	BatchID   uint      `json:"batch_id" gorm:"not null;index"`
+	TopicID   uint      `json:"topic_id" gorm:"not null"`
+	JobID     uint      `json:"job_id" gorm:"not null"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+
+	Job Job `json:"job,omitempty" gorm:"foreignKey:JobID"`
+}
+
+// TableName specifies the table name for GORM
+func (BatchItem) TableName() string {
+	return "batch_items"
+}
+
+// ToDict reports this item's topic and the live status of the job
+// generating its content.
+func (i *BatchItem) ToDict() map[string]interface{} {
+	dict := map[string]interface{}{
+		"topic_id": i.TopicID,
+		"job_id":   i.JobID,
+		"status":   i.Job.Status,
+	}
+	if i.Job.Status == JobStatusFailed {
+		dict["error"] = i.Job.Error
+	}
+	return dict
+}
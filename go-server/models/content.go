@@ -10,9 +10,14 @@ type Content struct {
 	ID        uint           `json:"id" gorm:"primaryKey;autoIncrement"`
 	TopicID   uint           `json:"topic_id" gorm:"not null;index;constraint:OnDelete:CASCADE" validate:"required"`
 	Content   string         `json:"content" gorm:"not null;type:text" validate:"required"`
-	CreatedAt time.Time      `json:"created_at" gorm:"autoCreateTime"`
-	UpdatedAt time.Time      `json:"updated_at" gorm:"autoUpdateTime"`
-	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+	// PromptVersion records which prompts.Registry template version (e.g.
+	// "v1", "v2") generated Content, so an A/B test comparing versions can
+	// be scored after the fact. Empty for content generated before this
+	// field existed.
+	PromptVersion string         `json:"prompt_version" gorm:"size:32"`
+	CreatedAt     time.Time      `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt     time.Time      `json:"updated_at" gorm:"autoUpdateTime"`
+	DeletedAt     gorm.DeletedAt `json:"-" gorm:"index"`
 
 	// Relationships
 	Topic Topic `json:"topic,omitempty" gorm:"foreignKey:TopicID"`
@@ -21,9 +26,10 @@ type Content struct {
 // ToDict converts content to map for JSON response
 func (c *Content) ToDict() map[string]interface{} {
 	return map[string]interface{}{
-		"id":       c.ID,
-		"topic_id": c.TopicID,
-		"content":  c.Content,
+		"id":             c.ID,
+		"topic_id":       c.TopicID,
+		"content":        c.Content,
+		"prompt_version": c.PromptVersion,
 	}
 }
 
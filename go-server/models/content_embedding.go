@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// ContentEmbedding is the semantic-search vector for one Content row.
+// Embedding stores the vector as little-endian float32 bytes rather than a
+// native vector column, since the deployed database has no pgvector
+// extension; SearchContent compares vectors in memory with
+// utils.CosineSimilarity instead of letting the database do a nearest-
+// neighbor query.
+type ContentEmbedding struct {
+	ID        uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	ContentID uint      `json:"content_id" gorm:"not null;uniqueIndex;constraint:OnDelete:CASCADE"`
+	Model     string    `json:"model" gorm:"not null;size:64"`
+	Embedding []byte    `json:"-" gorm:"not null;type:blob"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// TableName specifies the table name for GORM
+func (ContentEmbedding) TableName() string {
+	return "content_embeddings"
+}
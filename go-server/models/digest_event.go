@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+// Digest event types services.DigestService summarizes into a user's
+// learning-digest email.
+const (
+	DigestEventChapterGenerated = "chapter_generated"
+	DigestEventSubjectAdded     = "subject_added"
+	DigestEventContentGenerated = "content_generated"
+)
+
+// DigestEvent is an append-only record of something digest-worthy
+// happening on a course a user owns, written by the chapter/subject/content
+// services as it happens so services.DigestService.RunDigest can summarize
+// a user's activity since their last digest without re-scanning every
+// course, subject, chapter, and content row on each run.
+type DigestEvent struct {
+	ID        uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	UserID    uint      `json:"user_id" gorm:"not null;index"`
+	CourseID  uint      `json:"course_id" gorm:"not null"`
+	EventType string    `json:"event_type" gorm:"not null;size:30"`
+	Message   string    `json:"message" gorm:"not null;size:255"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime;index"`
+}
+
+// TableName specifies the table name for GORM
+func (DigestEvent) TableName() string {
+	return "digest_events"
+}
@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// IdempotencyKey caches a mutating request's response so a client retrying
+// with the same Idempotency-Key header gets the original result back
+// instead of re-executing (and, for generation endpoints, re-billing) the
+// request. RequestHash lets IdempotencyMiddleware detect a key being reused
+// with a different body and reject it with 409 Conflict rather than
+// silently replaying a response that doesn't match what was asked for.
+type IdempotencyKey struct {
+	ID           uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	UserID       uint      `json:"user_id" gorm:"not null;uniqueIndex:idx_idempotency_user_key"`
+	Key          string    `json:"key" gorm:"not null;size:255;uniqueIndex:idx_idempotency_user_key"`
+	RequestHash  string    `json:"request_hash" gorm:"not null;size:64"`
+	StatusCode   int       `json:"status_code" gorm:"not null"`
+	ResponseBody string    `json:"response_body" gorm:"not null;type:text"`
+	ExpiresAt    time.Time `json:"expires_at" gorm:"not null;index"`
+	CreatedAt    time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// TableName specifies the table name for GORM
+func (IdempotencyKey) TableName() string {
+	return "idempotency_keys"
+}
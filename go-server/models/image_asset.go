@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// ImageAsset is the metadata sidecar ImageServiceImpl.UploadImage keeps
+// alongside the content-addressed blob storage.Backend actually holds the
+// bytes under (sha256/<hash><ext>): it maps a logical path (e.g.
+// "courses/42/cover") to the content hash of whatever image currently
+// lives there, so a second upload of byte-identical content can be
+// recognized and skipped without re-uploading, and a logical path's
+// current URL can be looked up without guessing its content hash.
+type ImageAsset struct {
+	ID          uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	LogicalPath string    `json:"logical_path" gorm:"not null;size:255;uniqueIndex"`
+	ContentHash string    `json:"content_hash" gorm:"not null;size:64;index"`
+	ContentType string    `json:"content_type" gorm:"not null;size:64"`
+	URL         string    `json:"url" gorm:"not null;size:512"`
+	CreatedAt   time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt   time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// TableName specifies the table name for GORM
+func (ImageAsset) TableName() string {
+	return "image_assets"
+}
@@ -0,0 +1,66 @@
+package models
+
+import "time"
+
+// Job statuses, tracked through the lifecycle a worker drives a queued job
+// through: pending -> running -> (completed | failed).
+const (
+	JobStatusPending   = "pending"
+	JobStatusRunning   = "running"
+	JobStatusCompleted = "completed"
+	JobStatusFailed    = "failed"
+)
+
+// Job is a unit of background work, e.g. one image generation request.
+// Payload and Result are opaque JSON the owning package's worker handler
+// encodes/decodes itself, so Job stays reusable across job kinds.
+type Job struct {
+	ID             uint   `json:"id" gorm:"primaryKey;autoIncrement"`
+	Kind           string `json:"kind" gorm:"not null;index;size:50"`
+	Payload        string `json:"payload" gorm:"not null;type:text"`
+	Status         string `json:"status" gorm:"not null;index;size:20;default:pending"`
+	Attempts       int    `json:"attempts" gorm:"not null;default:0"`
+	Result         string `json:"result" gorm:"type:text"`
+	Error          string `json:"error" gorm:"type:text"`
+	IdempotencyKey string `json:"idempotency_key" gorm:"uniqueIndex;size:255"`
+	// Progress is an opaque, handler-defined label for a sub-stage reached
+	// while Status is still "running" (e.g. a chapter generation job moving
+	// through "chapters_drafted" then "persisted"), so a client watching
+	// GET /jobs/:id/watch sees more than a single "running" frame for
+	// work that takes a while.
+	Progress string `json:"progress,omitempty" gorm:"size:50"`
+	// NextAttemptAt gates retries: a pending job is only picked up once this
+	// time has passed, implementing the exponential backoff jobs.Queue
+	// applies after a transient failure.
+	NextAttemptAt time.Time `json:"next_attempt_at" gorm:"index"`
+	CreatedAt     time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt     time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// TableName specifies the table name for GORM
+func (Job) TableName() string {
+	return "jobs"
+}
+
+// ToDict converts the job to a map for JSON responses. Result is included
+// only once the job has completed (and Error only once it has failed), so
+// pollers see no result key until there's something to show.
+func (j *Job) ToDict() map[string]interface{} {
+	dict := map[string]interface{}{
+		"id":         j.ID,
+		"kind":       j.Kind,
+		"status":     j.Status,
+		"attempts":   j.Attempts,
+		"created_at": j.CreatedAt.Format(time.RFC3339),
+	}
+	if j.Progress != "" {
+		dict["progress"] = j.Progress
+	}
+	if j.Status == JobStatusCompleted {
+		dict["result"] = j.Result
+	}
+	if j.Status == JobStatusFailed {
+		dict["error"] = j.Error
+	}
+	return dict
+}
@@ -0,0 +1,19 @@
+package models
+
+// NotificationPreference records whether a user wants a given notification
+// type delivered over a given channel (e.g. "email", "telegram"). A user
+// with no row for a (type, channel) pair is treated as opted in on "email"
+// and opted out everywhere else, so NotificationService works without
+// requiring every account to have preferences seeded first.
+type NotificationPreference struct {
+	ID               uint   `json:"id" gorm:"primaryKey;autoIncrement"`
+	UserID           uint   `json:"user_id" gorm:"not null;uniqueIndex:idx_notification_pref"`
+	NotificationType string `json:"notification_type" gorm:"not null;size:30;uniqueIndex:idx_notification_pref"`
+	Channel          string `json:"channel" gorm:"not null;size:20;uniqueIndex:idx_notification_pref"`
+	Enabled          bool   `json:"enabled" gorm:"not null;default:true"`
+}
+
+// TableName specifies the table name for GORM
+func (NotificationPreference) TableName() string {
+	return "notification_preferences"
+}
@@ -0,0 +1,38 @@
+package models
+
+import "time"
+
+// RefreshToken is one link in a session's rotation chain: each successful
+// /auth/refresh revokes the presented row and inserts a new one carrying the
+// same SessionID, so a session survives many rotations while any single jti
+// is only ever valid once. ParentJTI lets a chain be walked backwards for
+// reuse detection.
+type RefreshToken struct {
+	ID        uint       `json:"id" gorm:"primaryKey;autoIncrement"`
+	UserID    uint       `json:"user_id" gorm:"not null;index"`
+	SessionID string     `json:"session_id" gorm:"not null;index;size:36"`
+	JTI       string     `json:"jti" gorm:"uniqueIndex;not null;size:36"`
+	ParentJTI *string    `json:"parent_jti" gorm:"size:36"`
+	IssuedAt  time.Time  `json:"issued_at"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	RevokedAt *time.Time `json:"revoked_at"`
+	UserAgent string     `json:"user_agent" gorm:"size:255"`
+	IP        string     `json:"ip" gorm:"size:45"`
+}
+
+// TableName specifies the table name for GORM
+func (RefreshToken) TableName() string {
+	return "refresh_tokens"
+}
+
+// ToDict converts the token to a map describing its session for
+// TokenService.ListActiveSessions responses.
+func (t *RefreshToken) ToDict() map[string]interface{} {
+	return map[string]interface{}{
+		"session_id": t.SessionID,
+		"issued_at":  t.IssuedAt.Format(time.RFC3339),
+		"expires_at": t.ExpiresAt.Format(time.RFC3339),
+		"user_agent": t.UserAgent,
+		"ip":         t.IP,
+	}
+}
@@ -0,0 +1,65 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// Role groups a named set of scopes (fine-grained permissions, e.g.
+// "testimonial:approve") that can be granted to users via UserRole. Scopes
+// is stored as a comma-separated column rather than its own join table
+// since a role's scope set changes rarely and stays small.
+type Role struct {
+	ID        uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	Name      string    `json:"name" gorm:"not null;uniqueIndex;size:50"`
+	Scopes    string    `json:"scopes" gorm:"not null;type:text"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// TableName specifies the table name for GORM
+func (Role) TableName() string {
+	return "roles"
+}
+
+// ScopeList splits Scopes into its individual scope strings.
+func (r *Role) ScopeList() []string {
+	if r.Scopes == "" {
+		return nil
+	}
+	return strings.Split(r.Scopes, ",")
+}
+
+// ToDict converts role to map for JSON response
+func (r *Role) ToDict() map[string]interface{} {
+	return map[string]interface{}{
+		"id":     r.ID,
+		"name":   r.Name,
+		"scopes": r.ScopeList(),
+	}
+}
+
+// RoleNames returns the Name of every role in roles, for baking into
+// JWTClaims.Roles or an AuthContext.
+func RoleNames(roles []Role) []string {
+	names := make([]string, len(roles))
+	for i, role := range roles {
+		names[i] = role.Name
+	}
+	return names
+}
+
+// RoleScopes flattens every scope granted by any role in roles into one
+// deduplicated list, for baking into JWTClaims.Scopes or an AuthContext.
+func RoleScopes(roles []Role) []string {
+	seen := make(map[string]bool)
+	var scopes []string
+	for _, role := range roles {
+		for _, scope := range role.ScopeList() {
+			if !seen[scope] {
+				seen[scope] = true
+				scopes = append(scopes, scope)
+			}
+		}
+	}
+	return scopes
+}
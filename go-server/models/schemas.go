@@ -54,9 +54,40 @@ type UserLoginRequest struct {
 }
 
 type UserLoginResponse struct {
-	User         map[string]interface{} `json:"user"`
-	AccessToken  string                 `json:"access_token"`
-	RefreshToken string                 `json:"refresh_token"`
+	User         map[string]interface{} `json:"user,omitempty"`
+	AccessToken  string                 `json:"access_token,omitempty"`
+	RefreshToken string                 `json:"refresh_token,omitempty"`
+
+	// MFARequired and MFAToken are set instead of the fields above when the
+	// account has TOTP 2FA enabled: the password step succeeded, but the
+	// caller must resubmit MFAToken with a TOTP code to POST
+	// /auth/2fa/verify before receiving real tokens.
+	MFARequired bool   `json:"mfa_required,omitempty"`
+	MFAToken    string `json:"mfa_token,omitempty"`
+}
+
+// OAuthLoginRequest carries the credential a client already obtained from
+// a social login provider. Google and generic OIDC providers supply
+// IDToken, verified against the provider's JWKS; GitHub, which doesn't
+// issue OIDC ID tokens, supplies AccessToken instead, verified by calling
+// the GitHub API. Provider is also present in the request path
+// (/auth/oauth/:provider/login) but repeated here so the body is
+// self-describing for logging and for OAuthCallbackRequest's JSON shape.
+type OAuthLoginRequest struct {
+	Provider    string `json:"provider"`
+	IDToken     string `json:"id_token"`
+	AccessToken string `json:"access_token"`
+}
+
+// OAuthCallbackRequest carries the authorization code a provider's
+// redirect-based (authorization code) OAuth flow hands back to the
+// frontend, for POST /auth/oauth/:provider/callback to exchange at the
+// provider's token endpoint. State is the value GET
+// /auth/oauth/:provider/authorize issued, checked against
+// utils.ValidateOAuthState as CSRF protection for the redirect.
+type OAuthCallbackRequest struct {
+	Code  string `json:"code" validate:"required"`
+	State string `json:"state" validate:"required"`
 }
 
 type PasswordResetRequest struct {
@@ -80,12 +111,33 @@ type TestimonialUpdateRequest struct {
 	IsApproved *bool   `json:"is_approved"`
 }
 
+// ListQuery is the uniform search/pagination form every collection endpoint
+// binds with c.ShouldBindQuery. Sort is validated against a per-resource
+// column whitelist by BaseRepositoryImpl.Query to avoid SQL injection via
+// the ORDER BY clause.
+type ListQuery struct {
+	Count  int    `form:"count"`
+	Offset int    `form:"offset"`
+	Sort   string `form:"sort"`
+	Order  string `form:"order"`
+	Q      string `form:"q"`
+
+	// Resource-specific filters
+	CourseID      uint `form:"course_id"`
+	PublishedOnly bool `form:"published_only"`
+}
+
 // Generic response types
 type APIResponse struct {
 	Success bool        `json:"success"`
 	Message string      `json:"message,omitempty"`
 	Data    interface{} `json:"data,omitempty"`
 	Error   string      `json:"error,omitempty"`
+	Code    string      `json:"code,omitempty"`
+	Details string      `json:"details,omitempty"`
+	// Meta carries endpoint-specific extras alongside Data, e.g.
+	// handleSearchCourses' facet_counts. Most handlers leave it nil.
+	Meta map[string]interface{} `json:"meta,omitempty"`
 }
 
 type PaginatedResponse struct {
@@ -94,32 +146,40 @@ type PaginatedResponse struct {
 	PerPage    int         `json:"per_page"`
 	Total      int64       `json:"total"`
 	TotalPages int         `json:"total_pages"`
-}
 
-// Gemini API schemas for structured output (based on the provided documentation)
-type GeminiSchema struct {
-	Type              string                   `json:"type"`
-	Format            string                   `json:"format,omitempty"`
-	Description       string                   `json:"description,omitempty"`
-	Nullable          bool                     `json:"nullable,omitempty"`
-	Enum              []string                 `json:"enum,omitempty"`
-	MaxItems          *int                     `json:"maxItems,omitempty"`
-	MinItems          *int                     `json:"minItems,omitempty"`
-	Properties        map[string]*GeminiSchema `json:"properties,omitempty"`
-	Required          []string                 `json:"required,omitempty"`
-	PropertyOrdering  []string                 `json:"propertyOrdering,omitempty"`
-	Items             *GeminiSchema            `json:"items,omitempty"`
-}
-
-// Gemini Content Generation Schemas
-type GeminiSubjectSchema struct {
+	// NextCursor is set by cursor-paginated endpoints (e.g. the cross-entity
+	// search) instead of Page/PerPage/TotalPages; pass it back as the
+	// cursor query parameter to fetch the next page. Empty means there is
+	// no next page.
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// StructuredOutputSchema describes a JSON response shape for a provider's
+// structured-output mode (Gemini's response schema, OpenAI's JSON mode,
+// Anthropic's tool use).
+type StructuredOutputSchema struct {
+	Type             string                             `json:"type"`
+	Format           string                             `json:"format,omitempty"`
+	Description      string                             `json:"description,omitempty"`
+	Nullable         bool                               `json:"nullable,omitempty"`
+	Enum             []string                           `json:"enum,omitempty"`
+	MaxItems         *int                               `json:"maxItems,omitempty"`
+	MinItems         *int                               `json:"minItems,omitempty"`
+	Properties       map[string]*StructuredOutputSchema `json:"properties,omitempty"`
+	Required         []string                           `json:"required,omitempty"`
+	PropertyOrdering []string                           `json:"propertyOrdering,omitempty"`
+	Items            *StructuredOutputSchema            `json:"items,omitempty"`
+}
+
+// Content generation list schemas
+type SubjectListSchema struct {
 	Subjects []string `json:"subjects"`
 }
 
-type GeminiChapterSchema struct {
+type ChapterListSchema struct {
 	Chapters []string `json:"chapters"`
 }
 
-type GeminiTopicSchema struct {
+type TopicListSchema struct {
 	Topics []string `json:"topics"`
-}
\ No newline at end of file
+}
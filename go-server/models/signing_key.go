@@ -0,0 +1,49 @@
+package models
+
+import "time"
+
+// Signing key lifecycle states. Exactly one key is ever "active" (used to
+// sign new tokens); "retiring" keys are kept only so tokens they already
+// signed keep validating until they expire; "retired" keys are no longer
+// accepted at all.
+const (
+	SigningKeyActive   = "active"
+	SigningKeyRetiring = "retiring"
+	SigningKeyRetired  = "retired"
+)
+
+// SigningKey is one versioned asymmetric keypair utils.KeyManager uses to
+// sign and verify JWTs, identified by its JWT "kid" header so a rotation
+// doesn't invalidate tokens signed by the previous key.
+type SigningKey struct {
+	ID            uint       `json:"id" gorm:"primaryKey;autoIncrement"`
+	Kid           string     `json:"kid" gorm:"not null;uniqueIndex;size:36"`
+	Algorithm     string     `json:"algorithm" gorm:"not null;size:10"`
+	PublicKeyPEM  string     `json:"-" gorm:"not null;type:text"`
+	PrivateKeyPEM string     `json:"-" gorm:"not null;type:text"`
+	Status        string     `json:"status" gorm:"not null;size:10;index"`
+	CreatedAt     time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	RetiredAt     *time.Time `json:"retired_at"`
+}
+
+// TableName specifies the table name for GORM
+func (SigningKey) TableName() string {
+	return "signing_keys"
+}
+
+// ToDict converts signing key metadata to map for JSON response (the key
+// material itself is never serialized this way; see utils.KeyManager.JWKS
+// for the public-key JWKS representation).
+func (k *SigningKey) ToDict() map[string]interface{} {
+	dict := map[string]interface{}{
+		"id":         k.ID,
+		"kid":        k.Kid,
+		"algorithm":  k.Algorithm,
+		"status":     k.Status,
+		"created_at": k.CreatedAt.Format(time.RFC3339),
+	}
+	if k.RetiredAt != nil {
+		dict["retired_at"] = k.RetiredAt.Format(time.RFC3339)
+	}
+	return dict
+}
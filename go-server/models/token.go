@@ -0,0 +1,39 @@
+package models
+
+import "time"
+
+// Token kinds stored in the unified tokens table.
+const (
+	TokenTypePasswordRecovery  = "password_recovery"
+	TokenTypeEmailVerification = "email_verification"
+	TokenTypeTeamInvite        = "team_invite"
+	// TokenTypeTelegramLink backs the short-lived PIN services.TelegramService
+	// issues from /link-telegram; the user sends it to the bot to prove they
+	// control both the account and the Telegram chat.
+	TokenTypeTelegramLink = "telegram_link_pin"
+	// TokenTypeDigestUnsubscribe backs the unsubscribe link
+	// services.DigestService embeds in every digest email.
+	TokenTypeDigestUnsubscribe = "digest_unsubscribe"
+)
+
+// Token is a one-time, expiring token backing password reset, email
+// verification, and (future) team/course invites from a single table
+// instead of a repository per flow. TokenHash is a SHA-256 hex digest of
+// the random value actually emailed to the user; the raw value is never
+// persisted, so a database compromise alone can't be used to reset a
+// password or claim an invite. Extra carries whatever payload the
+// consuming flow needs (e.g. an invited email address) as opaque JSON.
+type Token struct {
+	ID        uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	TokenHash string    `json:"-" gorm:"not null;uniqueIndex;size:64"`
+	Type      string    `json:"type" gorm:"not null;index;size:30"`
+	UserID    uint      `json:"user_id" gorm:"not null;index"`
+	Extra     string    `json:"extra" gorm:"type:text"`
+	ExpiresAt time.Time `json:"expires_at" gorm:"not null;index"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// TableName specifies the table name for GORM
+func (Token) TableName() string {
+	return "tokens"
+}
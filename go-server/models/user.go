@@ -3,10 +3,14 @@ package models
 import (
 	"time"
 
-	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
 )
 
+// PasswordHash stores a PHC-format argon2id string (see utils.HashPassword)
+// for accounts created or logged into since the argon2id migration, or a
+// legacy bcrypt hash ("$2a$"/"$2b$"/"$2y$") for an account that hasn't
+// logged in since. PasswordSalt predates both schemes and is unused; it's
+// kept only because the column is gorm:"not null" on existing rows.
 type User struct {
 	ID          uint           `json:"id" gorm:"primaryKey;autoIncrement"`
 	Email       string         `json:"email" gorm:"uniqueIndex;not null;size:120" validate:"required,email"`
@@ -21,33 +25,18 @@ type User struct {
 	IsAdmin     bool           `json:"is_admin" gorm:"default:false"`
 	LastLogin   *time.Time     `json:"last_login"`
 
-	// Relationships
-	Courses []Course `json:"courses,omitempty" gorm:"foreignKey:UserID"`
-}
-
-// SetPassword hashes and sets the user's password
-func (u *User) SetPassword(password string) error {
-	// Generate salt
-	salt, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
-	if err != nil {
-		return err
-	}
+	// TelegramChatID is set once the user links their account via
+	// services.TelegramService's PIN flow, so NotificationService can reach
+	// them on the "telegram" channel. Nil until linked.
+	TelegramChatID *string `json:"-" gorm:"size:64"`
 
-	// Hash password with salt
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
-	if err != nil {
-		return err
-	}
-
-	u.PasswordSalt = string(salt)
-	u.PasswordHash = string(hashedPassword)
-	return nil
-}
+	// DigestOptOut is set via the signed unsubscribe link embedded in every
+	// digest email; once true, DigestServiceImpl.RunDigest skips the user
+	// entirely instead of just omitting content.
+	DigestOptOut bool `json:"-" gorm:"not null;default:false"`
 
-// CheckPassword compares provided password with stored hash
-func (u *User) CheckPassword(password string) bool {
-	err := bcrypt.CompareHashAndPassword([]byte(u.PasswordHash), []byte(password))
-	return err == nil
+	// Relationships
+	Courses []Course `json:"courses,omitempty" gorm:"foreignKey:UserID"`
 }
 
 // ToDict converts user to map for JSON response (excluding sensitive data)
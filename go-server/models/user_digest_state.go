@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// UserDigestState tracks when services.DigestService last emailed userID a
+// digest, so RunDigest only reads DigestEvents newer than LastSentAt
+// instead of re-summarizing a user's entire history on every run.
+type UserDigestState struct {
+	UserID     uint       `json:"user_id" gorm:"primaryKey"`
+	LastSentAt *time.Time `json:"last_sent_at"`
+}
+
+// TableName specifies the table name for GORM
+func (UserDigestState) TableName() string {
+	return "user_digest_state"
+}
@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// UserIdentity links a local User to an external OAuth/OIDC provider
+// account, identified by the provider's stable subject (the OIDC "sub"
+// claim, or GitHub's numeric account id). One user can hold several, one
+// per provider they've signed in with, so the same account stays linked
+// even if its verified email later changes at the provider.
+type UserIdentity struct {
+	ID        uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	UserID    uint      `json:"user_id" gorm:"not null;index"`
+	Provider  string    `json:"provider" gorm:"not null;size:30;uniqueIndex:idx_identity_provider_subject"`
+	Subject   string    `json:"subject" gorm:"not null;size:255;uniqueIndex:idx_identity_provider_subject"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// TableName specifies the table name for GORM
+func (UserIdentity) TableName() string {
+	return "user_identities"
+}
@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// UserRole is the many-to-many join between User and Role, granting role
+// RoleID to user UserID.
+type UserRole struct {
+	UserID    uint      `json:"user_id" gorm:"primaryKey"`
+	RoleID    uint      `json:"role_id" gorm:"primaryKey"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+
+	Role Role `json:"role,omitempty" gorm:"foreignKey:RoleID"`
+}
+
+// TableName specifies the table name for GORM
+func (UserRole) TableName() string {
+	return "user_roles"
+}
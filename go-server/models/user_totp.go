@@ -0,0 +1,37 @@
+package models
+
+import "time"
+
+// UserTOTP holds a user's RFC 6238 TOTP enrollment. Enabled is false between
+// EnrollTOTP generating the secret and ActivateTOTP confirming it with a
+// valid code, so an abandoned enrollment never gates login.
+type UserTOTP struct {
+	ID              uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	UserID          uint      `json:"user_id" gorm:"not null;uniqueIndex"`
+	SecretEncrypted string    `json:"-" gorm:"not null;size:255"`
+	Enabled         bool      `json:"enabled" gorm:"not null;default:false"`
+	CreatedAt       time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt       time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// TableName specifies the table name for GORM
+func (UserTOTP) TableName() string {
+	return "user_totp"
+}
+
+// TOTPRecoveryCode is one of the ten one-time codes generated at
+// ActivateTOTP, any of which can substitute for a TOTP code when the user
+// has lost their authenticator. Only CodeHash is stored; the plaintext code
+// is shown to the user once, at generation time.
+type TOTPRecoveryCode struct {
+	ID        uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	UserID    uint      `json:"user_id" gorm:"not null;index"`
+	CodeHash  string    `json:"-" gorm:"not null;uniqueIndex;size:64"`
+	Used      bool      `json:"used" gorm:"not null;default:false"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// TableName specifies the table name for GORM
+func (TOTPRecoveryCode) TableName() string {
+	return "totp_recovery_codes"
+}
@@ -0,0 +1,187 @@
+// Package prompts loads the LLM prompt templates under templates/ into a
+// Registry, instead of leaving each one as a hardcoded string inside a
+// provider file. A template name may have several versions side by side
+// (e.g. topic_content/v1.tmpl and topic_content/v2.tmpl); Render picks one
+// deterministically by hashing the caller's user ID, so a given user always
+// sees the same variant across calls while different users are split
+// between versions for A/B comparison.
+package prompts
+
+import (
+	"bytes"
+	"context"
+	"embed"
+	"fmt"
+	"hash/fnv"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+//go:embed templates
+var templateFS embed.FS
+
+// templateVersion is one parsed version of a named template.
+type templateVersion struct {
+	version string
+	tmpl    *template.Template
+}
+
+// Registry holds every template's versions, keyed by template name.
+type Registry struct {
+	versions map[string][]templateVersion
+}
+
+// Load parses every templates/<name>/<version>.tmpl file in fsys into a
+// Registry, failing fast if any template doesn't parse. Versions within a
+// name are sorted so version selection is reproducible across process
+// restarts.
+func Load(fsys fs.FS) (*Registry, error) {
+	entries, err := fs.ReadDir(fsys, "templates")
+	if err != nil {
+		return nil, fmt.Errorf("reading templates dir: %w", err)
+	}
+
+	r := &Registry{versions: make(map[string][]templateVersion)}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		name := entry.Name()
+
+		files, err := fs.ReadDir(fsys, path.Join("templates", name))
+		if err != nil {
+			return nil, fmt.Errorf("reading templates/%s: %w", name, err)
+		}
+
+		for _, f := range files {
+			if f.IsDir() || !strings.HasSuffix(f.Name(), ".tmpl") {
+				continue
+			}
+			version := strings.TrimSuffix(f.Name(), ".tmpl")
+
+			body, err := fs.ReadFile(fsys, path.Join("templates", name, f.Name()))
+			if err != nil {
+				return nil, fmt.Errorf("reading templates/%s/%s: %w", name, f.Name(), err)
+			}
+
+			tmpl, err := template.New(name + "/" + version).Parse(string(body))
+			if err != nil {
+				return nil, fmt.Errorf("parsing templates/%s/%s: %w", name, f.Name(), err)
+			}
+
+			r.versions[name] = append(r.versions[name], templateVersion{version: version, tmpl: tmpl})
+		}
+
+		sort.Slice(r.versions[name], func(i, j int) bool {
+			return r.versions[name][i].version < r.versions[name][j].version
+		})
+	}
+
+	return r, nil
+}
+
+// MustLoad is Load, panicking on error; used to build the package-level
+// Default registry at init time, where a malformed embedded template is a
+// build-time bug, not a runtime condition to recover from.
+func MustLoad(fsys fs.FS) *Registry {
+	r, err := Load(fsys)
+	if err != nil {
+		panic(err)
+	}
+	return r
+}
+
+// Default is the Registry over this package's embedded templates. It's the
+// one every LLM provider and cmd/prompts render against; Load/MustLoad are
+// exported separately so a caller can load any other fs.FS instead (e.g. an
+// os.DirFS over a directory being edited, before it's embedded).
+var Default = MustLoad(templateFS)
+
+// selectVersion deterministically picks one of versions for userID, so the
+// same user always lands on the same variant. userID 0 (no authenticated
+// user, e.g. a health check) always gets the first (lowest-sorted) version.
+func selectVersion(versions []templateVersion, userID uint) templateVersion {
+	if len(versions) == 1 || userID == 0 {
+		return versions[0]
+	}
+
+	h := fnv.New32a()
+	fmt.Fprintf(h, "%d", userID)
+	return versions[h.Sum32()%uint32(len(versions))]
+}
+
+// Render renders name's template selected for userID against data, and
+// returns the rendered prompt along with the version that produced it, so
+// a caller generating persisted content can record which template version
+// was used.
+func (r *Registry) Render(name string, userID uint, data any) (rendered, version string, err error) {
+	versions := r.versions[name]
+	if len(versions) == 0 {
+		return "", "", fmt.Errorf("prompts: no template named %q", name)
+	}
+
+	chosen := selectVersion(versions, userID)
+
+	var buf bytes.Buffer
+	if err := chosen.tmpl.Execute(&buf, data); err != nil {
+		return "", "", fmt.Errorf("prompts: rendering %s/%s: %w", name, chosen.version, err)
+	}
+	return buf.String(), chosen.version, nil
+}
+
+// VersionFor reports which version name would render for userID, without
+// rendering it, so a caller that didn't do the rendering itself (e.g.
+// ContentService, which only calls the LLM provider) can still record the
+// version against its own records.
+func (r *Registry) VersionFor(name string, userID uint) (string, error) {
+	versions := r.versions[name]
+	if len(versions) == 0 {
+		return "", fmt.Errorf("prompts: no template named %q", name)
+	}
+	return selectVersion(versions, userID).version, nil
+}
+
+// Names lists every template name the registry holds, sorted, for
+// cmd/prompts to iterate over.
+func (r *Registry) Names() []string {
+	names := make([]string, 0, len(r.versions))
+	for name := range r.versions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Versions lists the versions registered for name, sorted.
+func (r *Registry) Versions(name string) []string {
+	versions := r.versions[name]
+	out := make([]string, len(versions))
+	for i, v := range versions {
+		out[i] = v.version
+	}
+	return out
+}
+
+// userIDKey is the context key Render's callers use to thread the
+// requesting user's ID down to whichever provider renders the prompt,
+// mirroring the unexported-context-key pattern go-server/logger uses for
+// its own per-request fields.
+type userIDKey struct{}
+
+// WithUserID returns a context carrying userID for a later RenderFromContext
+// call to pick up, so ContentService doesn't have to change every
+// utils.LLMProvider method's signature just to pass an A/B selection key
+// through to prompt rendering.
+func WithUserID(ctx context.Context, userID uint) context.Context {
+	return context.WithValue(ctx, userIDKey{}, userID)
+}
+
+// UserIDFromContext returns the user ID WithUserID attached to ctx, or 0 if
+// none was (e.g. a background job with no single owning user).
+func UserIDFromContext(ctx context.Context) uint {
+	userID, _ := ctx.Value(userIDKey{}).(uint)
+	return userID
+}
@@ -0,0 +1,201 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	"go-server/config"
+	"go-server/metrics"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Policy is a token bucket's shape: it holds Burst tokens at most and
+// refills at Burst/Window tokens per second, so a caller may burst up to
+// Burst requests before settling into a steady Burst-per-Window rate.
+type Policy struct {
+	Burst  int
+	Window time.Duration
+}
+
+func (p Policy) refillPerSecond() float64 {
+	return float64(p.Burst) / p.Window.Seconds()
+}
+
+// LimiterStore charges one token against key under policy and reports
+// whether the request may proceed, how many tokens remain, and (when
+// denied) how long the caller must wait before a token is next available.
+// Every route-policy/per-IP/per-user limiter in the app shares one of these
+// per backend, keyed by a caller-chosen string, so unrelated policies don't
+// collide as long as their keys are prefixed distinctly.
+type LimiterStore interface {
+	Allow(key string, policy Policy) (allowed bool, remaining int, retryAfter time.Duration)
+}
+
+// NewLimiterStore builds the LimiterStore selected by
+// cfg.Auth.RateLimitBackend: "redis" for a store shared across instances,
+// or the default in-memory store for a single instance, local dev, and
+// tests (data does not survive a restart). The in-memory store's janitor is
+// started here, so every caller gets garbage collection of idle buckets for
+// free rather than having to remember to start it.
+func NewLimiterStore(cfg *config.Config) LimiterStore {
+	if cfg.Auth.RateLimitBackend == "redis" {
+		return newRedisLimiterStore(redis.NewClient(&redis.Options{Addr: cfg.Auth.RedisAddr}))
+	}
+	store := newMemoryLimiterStore()
+	store.startJanitor(context.Background())
+	return store
+}
+
+// bucket is one key's token count as of the last time it was touched;
+// tokens are refilled lazily on the next Allow call rather than by a
+// ticking goroutine per key.
+type bucket struct {
+	tokens   float64
+	lastSeen time.Time
+}
+
+// memoryLimiterStoreTTL is how long a bucket may go untouched before
+// memoryLimiterStore's janitor reclaims it; a caller that stops making
+// requests stops costing memory soon after, rather than lingering forever.
+const memoryLimiterStoreTTL = 10 * time.Minute
+
+// memoryLimiterStore is the per-instance, in-process LimiterStore used in
+// dev/tests; it does not survive a restart and isn't shared across
+// instances. Its janitor (started by NewLimiterStore) reclaims idle
+// buckets, so the map doesn't grow forever as new keys (e.g. new client
+// IPs) keep appearing.
+type memoryLimiterStore struct {
+	mu      sync.Mutex
+	buckets map[string]*bucket
+}
+
+func newMemoryLimiterStore() *memoryLimiterStore {
+	return &memoryLimiterStore{buckets: make(map[string]*bucket)}
+}
+
+func (s *memoryLimiterStore) Allow(key string, policy Policy) (bool, int, time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(policy.Burst), lastSeen: now}
+		s.buckets[key] = b
+	}
+
+	refill := policy.refillPerSecond()
+	elapsed := now.Sub(b.lastSeen).Seconds()
+	b.tokens = math.Min(float64(policy.Burst), b.tokens+elapsed*refill)
+	b.lastSeen = now
+
+	if b.tokens < 1 {
+		metrics.APIRateLimitExceededTotal.Inc()
+		retryAfter := time.Duration((1 - b.tokens) / refill * float64(time.Second))
+		return false, 0, retryAfter
+	}
+
+	b.tokens--
+	return true, int(b.tokens), 0
+}
+
+// startJanitor runs until ctx is canceled, periodically dropping buckets
+// that have gone untouched for memoryLimiterStoreTTL so a long-running
+// process doesn't accumulate one bucket per client IP/user forever.
+func (s *memoryLimiterStore) startJanitor(ctx context.Context) {
+	ticker := time.NewTicker(memoryLimiterStoreTTL)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				s.mu.Lock()
+				for key, b := range s.buckets {
+					if now.Sub(b.lastSeen) > memoryLimiterStoreTTL {
+						delete(s.buckets, key)
+					}
+				}
+				s.mu.Unlock()
+			}
+		}
+	}()
+}
+
+// redisLimiterStore is the shared LimiterStore used in production, so a
+// policy's limit applies across every instance behind the load balancer
+// rather than separately per instance. The token bucket is read, refilled,
+// and charged atomically via a Lua script so concurrent requests across
+// instances can't race past each other between the read and the write.
+type redisLimiterStore struct {
+	client *redis.Client
+}
+
+func newRedisLimiterStore(client *redis.Client) *redisLimiterStore {
+	return &redisLimiterStore{client: client}
+}
+
+// tokenBucketScript mirrors memoryLimiterStore.Allow: it reads the bucket's
+// last token count and timestamp, refills for elapsed time, and charges one
+// token if available. now is passed in milliseconds since Lua has no
+// monotonic clock of its own; elapsed is derived from it in the script.
+var tokenBucketScript = redis.NewScript(`
+local tokens = tonumber(redis.call("HGET", KEYS[1], "tokens"))
+local lastSeen = tonumber(redis.call("HGET", KEYS[1], "last_seen_ms"))
+local burst = tonumber(ARGV[1])
+local refill_per_sec = tonumber(ARGV[2])
+local now_ms = tonumber(ARGV[3])
+
+if tokens == nil then
+	tokens = burst
+	lastSeen = now_ms
+end
+
+local elapsed_sec = math.max(0, now_ms - lastSeen) / 1000
+tokens = math.min(burst, tokens + elapsed_sec * refill_per_sec)
+
+local allowed = 0
+if tokens >= 1 then
+	allowed = 1
+	tokens = tokens - 1
+end
+
+redis.call("HSET", KEYS[1], "tokens", tokens, "last_seen_ms", now_ms)
+redis.call("PEXPIRE", KEYS[1], math.ceil(burst / refill_per_sec * 1000) + 1000)
+
+return {allowed, string.format("%.6f", tokens)}
+`)
+
+func (s *redisLimiterStore) Allow(key string, policy Policy) (bool, int, time.Duration) {
+	ctx := context.Background()
+	refill := policy.refillPerSecond()
+	now := time.Now()
+
+	result, err := tokenBucketScript.Run(ctx, s.client, []string{"ratelimit:bucket:" + key},
+		policy.Burst, refill, now.UnixMilli()).Result()
+	if err != nil {
+		// Fail open: if Redis is unreachable, don't block every request in
+		// the app over an infrastructure problem.
+		return true, policy.Burst, 0
+	}
+
+	values := result.([]interface{})
+	allowed := values[0].(int64) == 1
+	tokensFloat, _ := strconv.ParseFloat(values[1].(string), 64)
+	remaining := int(tokensFloat)
+
+	if !allowed {
+		metrics.APIRateLimitExceededTotal.Inc()
+		retryAfter := time.Duration((1 - tokensFloat) / refill * float64(time.Second))
+		if retryAfter < 0 {
+			retryAfter = 0
+		}
+		return false, 0, retryAfter
+	}
+	return true, remaining, 0
+}
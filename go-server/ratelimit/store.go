@@ -0,0 +1,187 @@
+// Package ratelimit tracks consecutive authentication failures per key (an
+// (ip, email) pair) behind a pluggable backend, so both
+// middleware.RateLimitMiddleware (which decides whether a request may
+// proceed) and services.AuthServiceImpl.Login (the only place that knows a
+// failure was a bad password rather than an unknown account) can share one
+// view of a caller's standing without either depending on the other.
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go-server/config"
+	"go-server/metrics"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// BackoffSchedule is the cooldown applied after each consecutive failure
+// for a given key, before MaxFailures triggers a hard lockout for the rest
+// of the lockout window. Index 0 is the wait after the first failure; a
+// failure count beyond the schedule's length reuses the last entry.
+var BackoffSchedule = []time.Duration{
+	1 * time.Second,
+	5 * time.Second,
+	30 * time.Second,
+	5 * time.Minute,
+	30 * time.Minute,
+}
+
+// RateLimitStore tracks RecordFailure/Reset/Cooldown for a key under a concrete
+// backend. RecordFailure registers one more failure for key and returns how
+// long the caller must now wait before retrying, and whether this failure
+// was the one that pushed key past maxFailures into a hard lockout for the
+// rest of lockoutWindow. Reset clears key's failure count and any active
+// cooldown, e.g. after a successful login. Cooldown returns how long key
+// must still wait before its next attempt, or zero if it may proceed now.
+type RateLimitStore interface {
+	RecordFailure(key string, maxFailures int, lockoutWindow time.Duration) (cooldown time.Duration, lockedOut bool)
+	Reset(key string)
+	Cooldown(key string) time.Duration
+}
+
+// NewStore builds the RateLimitStore selected by cfg.Auth.RateLimitBackend:
+// "redis" for a store shared across instances, or the default "memory"
+// store for a single instance / local dev (data does not survive a
+// restart).
+func NewStore(cfg *config.Config) RateLimitStore {
+	if cfg.Auth.RateLimitBackend == "redis" {
+		return newRedisStore(redis.NewClient(&redis.Options{Addr: cfg.Auth.RedisAddr}))
+	}
+	return newMemoryStore()
+}
+
+// Key combines ip and email (when present) into the key a Store tracks
+// failures under, so a single attacker can't evade the cooldown by
+// rotating one side while holding the other fixed. Callers that don't have
+// an email to hand (e.g. password-reset/confirm, which is keyed by token
+// rather than email) fall back to ip alone.
+func Key(ip, email string) string {
+	if email == "" {
+		return ip
+	}
+	return ip + "|" + email
+}
+
+func backoffFor(failures int) time.Duration {
+	if failures-1 < len(BackoffSchedule) {
+		return BackoffSchedule[failures-1]
+	}
+	return BackoffSchedule[len(BackoffSchedule)-1]
+}
+
+// memoryEntry is one key's failure count and the window it accrued within.
+type memoryEntry struct {
+	failures      int
+	cooldownUntil time.Time
+	windowStart   time.Time
+}
+
+// memoryStore is the per-instance, in-process RateLimitStore used in
+// dev/tests; it does not survive a restart and isn't shared across
+// instances.
+type memoryStore struct {
+	mu      sync.Mutex
+	entries map[string]*memoryEntry
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{entries: make(map[string]*memoryEntry)}
+}
+
+func (s *memoryStore) RecordFailure(key string, maxFailures int, lockoutWindow time.Duration) (time.Duration, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	entry, ok := s.entries[key]
+	if !ok || now.Sub(entry.windowStart) > lockoutWindow {
+		entry = &memoryEntry{windowStart: now}
+		s.entries[key] = entry
+	}
+	entry.failures++
+
+	if entry.failures >= maxFailures {
+		entry.cooldownUntil = now.Add(lockoutWindow)
+		metrics.AuthLoginLockoutsTotal.Inc()
+		return lockoutWindow, true
+	}
+
+	cooldown := backoffFor(entry.failures)
+	entry.cooldownUntil = now.Add(cooldown)
+	return cooldown, false
+}
+
+func (s *memoryStore) Reset(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.entries, key)
+}
+
+func (s *memoryStore) Cooldown(key string) time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.entries[key]
+	if !ok {
+		return 0
+	}
+	if remaining := time.Until(entry.cooldownUntil); remaining > 0 {
+		return remaining
+	}
+	return 0
+}
+
+// redisStore is the shared RateLimitStore used in production, so a
+// lockout applies across every instance behind the load balancer rather
+// than just the one that happened to handle the failing attempts.
+type redisStore struct {
+	client *redis.Client
+}
+
+func newRedisStore(client *redis.Client) *redisStore {
+	return &redisStore{client: client}
+}
+
+func (s *redisStore) failuresKey(key string) string { return "ratelimit:failures:" + key }
+func (s *redisStore) cooldownKey(key string) string { return "ratelimit:cooldown:" + key }
+
+func (s *redisStore) RecordFailure(key string, maxFailures int, lockoutWindow time.Duration) (time.Duration, bool) {
+	ctx := context.Background()
+
+	failures, err := s.client.Incr(ctx, s.failuresKey(key)).Result()
+	if err != nil {
+		// Fail open: if Redis is unreachable, don't lock legitimate users
+		// out of their own accounts over an infrastructure problem.
+		return 0, false
+	}
+	if failures == 1 {
+		s.client.Expire(ctx, s.failuresKey(key), lockoutWindow)
+	}
+
+	if int(failures) >= maxFailures {
+		s.client.Set(ctx, s.cooldownKey(key), "1", lockoutWindow)
+		metrics.AuthLoginLockoutsTotal.Inc()
+		return lockoutWindow, true
+	}
+
+	cooldown := backoffFor(int(failures))
+	s.client.Set(ctx, s.cooldownKey(key), "1", cooldown)
+	return cooldown, false
+}
+
+func (s *redisStore) Reset(key string) {
+	ctx := context.Background()
+	s.client.Del(ctx, s.failuresKey(key), s.cooldownKey(key))
+}
+
+func (s *redisStore) Cooldown(key string) time.Duration {
+	ctx := context.Background()
+	ttl, err := s.client.PTTL(ctx, s.cooldownKey(key)).Result()
+	if err != nil || ttl < 0 {
+		return 0
+	}
+	return ttl
+}
@@ -0,0 +1,60 @@
+package repositories
+
+import (
+	"go-server/models"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type APIKeyRepository interface {
+	BaseRepository
+	GetByKeyID(keyID string) (*models.APIKey, error)
+	CreateAPIKey(key *models.APIKey) error
+	GetByUserID(userID uint) ([]models.APIKey, error)
+}
+
+type APIKeyRepositoryImpl struct {
+	*BaseRepositoryImpl
+}
+
+func NewAPIKeyRepository(db *gorm.DB) APIKeyRepository {
+	return &APIKeyRepositoryImpl{
+		BaseRepositoryImpl: NewBaseRepository(db),
+	}
+}
+
+// GetByKeyID looks up a registered public key by its Signature header keyId.
+// Returns (nil, nil) if no key is registered under that ID.
+func (r *APIKeyRepositoryImpl) GetByKeyID(keyID string) (*models.APIKey, error) {
+	var key models.APIKey
+	err := r.DB.Where("key_id = ?", keyID).First(&key).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		logrus.Errorf("Error getting API key by key ID: %v", err)
+		return nil, err
+	}
+	return &key, nil
+}
+
+// CreateAPIKey registers a new public key.
+func (r *APIKeyRepositoryImpl) CreateAPIKey(key *models.APIKey) error {
+	err := r.DB.Create(key).Error
+	if err != nil {
+		logrus.Errorf("Error creating API key: %v", err)
+	}
+	return err
+}
+
+// GetByUserID lists the keys a user has registered, newest first.
+func (r *APIKeyRepositoryImpl) GetByUserID(userID uint) ([]models.APIKey, error) {
+	var keys []models.APIKey
+	err := r.DB.Where("user_id = ?", userID).Order("created_at DESC").Find(&keys).Error
+	if err != nil {
+		logrus.Errorf("Error getting API keys for user %d: %v", userID, err)
+		return nil, err
+	}
+	return keys, nil
+}
@@ -1,6 +1,10 @@
 package repositories
 
 import (
+	"fmt"
+	"go-server/models"
+	"strings"
+
 	"gorm.io/gorm"
 )
 
@@ -12,6 +16,7 @@ type BaseRepository interface {
 	Delete(id uint, entity interface{}) error
 	GetAll(entities interface{}) error
 	Count(entity interface{}) (int64, error)
+	Query(entities interface{}, q *models.ListQuery, allowedSortCols, searchCols []string, scope func(*gorm.DB) *gorm.DB) (int64, error)
 }
 
 // BaseRepositoryImpl implements BaseRepository
@@ -56,8 +61,57 @@ func (r *BaseRepositoryImpl) Count(entity interface{}) (int64, error) {
 	return count, err
 }
 
-// GetWithPagination gets entities with pagination
-func (r *BaseRepositoryImpl) GetWithPagination(entities interface{}, page, limit int) error {
-	offset := (page - 1) * limit
-	return r.DB.Offset(offset).Limit(limit).Find(entities).Error
-}
\ No newline at end of file
+const (
+	defaultQueryLimit = 20
+	maxQueryLimit     = 200
+)
+
+// Query fills entities with the page of rows matching q and returns the total
+// row count before pagination was applied. allowedSortCols whitelists which
+// column q.Sort may select, so the value can never reach ORDER BY unescaped.
+// searchCols lists the columns q.Q is LIKE-matched against. scope, if given,
+// narrows the base query (e.g. to a single course's subjects) before search,
+// sort, and pagination are applied.
+func (r *BaseRepositoryImpl) Query(entities interface{}, q *models.ListQuery, allowedSortCols, searchCols []string, scope func(*gorm.DB) *gorm.DB) (int64, error) {
+	db := r.DB.Model(entities)
+	if scope != nil {
+		db = scope(db)
+	}
+
+	if q.Q != "" && len(searchCols) > 0 {
+		pattern := "%" + q.Q + "%"
+		conds := make([]string, len(searchCols))
+		args := make([]interface{}, len(searchCols))
+		for i, col := range searchCols {
+			conds[i] = col + " LIKE ?"
+			args[i] = pattern
+		}
+		db = db.Where(strings.Join(conds, " OR "), args...)
+	}
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return 0, err
+	}
+
+	sortCol := "id"
+	for _, allowed := range allowedSortCols {
+		if q.Sort == allowed {
+			sortCol = allowed
+			break
+		}
+	}
+
+	order := "ASC"
+	if strings.EqualFold(q.Order, "desc") {
+		order = "DESC"
+	}
+
+	limit := q.Count
+	if limit <= 0 || limit > maxQueryLimit {
+		limit = defaultQueryLimit
+	}
+
+	err := db.Order(fmt.Sprintf("%s %s", sortCol, order)).Offset(q.Offset).Limit(limit).Find(entities).Error
+	return total, err
+}
@@ -0,0 +1,49 @@
+package repositories
+
+import (
+	"go-server/models"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type BatchRepository interface {
+	BaseRepository
+	GetWithItems(batchID uint) (*models.Batch, error)
+	CreateItems(items []models.BatchItem) error
+}
+
+type BatchRepositoryImpl struct {
+	*BaseRepositoryImpl
+}
+
+func NewBatchRepository(db *gorm.DB) BatchRepository {
+	return &BatchRepositoryImpl{
+		BaseRepositoryImpl: NewBaseRepository(db),
+	}
+}
+
+// GetWithItems loads a batch along with every topic's BatchItem and each
+// item's underlying Job, for reporting aggregate and per-topic progress.
+func (r *BatchRepositoryImpl) GetWithItems(batchID uint) (*models.Batch, error) {
+	var batch models.Batch
+	err := r.DB.Preload("Items.Job").First(&batch, batchID).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, err
+		}
+		logrus.Errorf("Error getting batch with items: %v", err)
+		return nil, err
+	}
+	return &batch, nil
+}
+
+// CreateItems inserts one BatchItem per topic a batch is generating content
+// for.
+func (r *BatchRepositoryImpl) CreateItems(items []models.BatchItem) error {
+	err := r.DB.CreateInBatches(&items, 100).Error
+	if err != nil {
+		logrus.Errorf("Error creating batch items: %v", err)
+	}
+	return err
+}
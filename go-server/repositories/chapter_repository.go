@@ -10,6 +10,10 @@ import (
 type ChapterRepository interface {
 	BaseRepository
 	GetChaptersBySubjectID(subjectID uint) ([]models.Chapter, error)
+	// GetChaptersBySubjectIDs gets every chapter across all of the given
+	// subjects in a single query, for callers assembling a multi-subject
+	// tree who would otherwise call GetChaptersBySubjectID once per subject.
+	GetChaptersBySubjectIDs(subjectIDs []uint) ([]models.Chapter, error)
 	GetChapterWithTopics(chapterID uint) (*models.Chapter, error)
 	CreateChapters(chapters []models.Chapter) error
 	DeleteChaptersBySubjectID(subjectID uint) error
@@ -36,6 +40,21 @@ func (r *ChapterRepositoryImpl) GetChaptersBySubjectID(subjectID uint) ([]models
 	return chapters, nil
 }
 
+// GetChaptersBySubjectIDs gets every chapter belonging to any of
+// subjectIDs in a single query.
+func (r *ChapterRepositoryImpl) GetChaptersBySubjectIDs(subjectIDs []uint) ([]models.Chapter, error) {
+	var chapters []models.Chapter
+	if len(subjectIDs) == 0 {
+		return chapters, nil
+	}
+	err := r.DB.Where("subject_id IN ?", subjectIDs).Find(&chapters).Error
+	if err != nil {
+		logrus.Errorf("Error getting chapters by subject IDs: %v", err)
+		return nil, err
+	}
+	return chapters, nil
+}
+
 // GetChapterWithTopics gets a chapter with its topics
 func (r *ChapterRepositoryImpl) GetChapterWithTopics(chapterID uint) (*models.Chapter, error) {
 	var chapter models.Chapter
@@ -0,0 +1,89 @@
+package repositories
+
+import (
+	"go-server/models"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ContentEmbeddingRepository interface {
+	BaseRepository
+	// Upsert creates or replaces the embedding for embedding.ContentID, so
+	// Reindex can overwrite a stale vector rather than erroring on the
+	// unique content_id constraint.
+	Upsert(embedding *models.ContentEmbedding) error
+	GetByContentIDs(contentIDs []uint) ([]models.ContentEmbedding, error)
+	// GetMissingContentIDs returns up to limit IDs from contentIDs that
+	// have no content_embeddings row yet, for the background reconciler.
+	GetMissingContentIDs(contentIDs []uint, limit int) ([]uint, error)
+}
+
+type ContentEmbeddingRepositoryImpl struct {
+	*BaseRepositoryImpl
+}
+
+func NewContentEmbeddingRepository(db *gorm.DB) ContentEmbeddingRepository {
+	return &ContentEmbeddingRepositoryImpl{
+		BaseRepositoryImpl: NewBaseRepository(db),
+	}
+}
+
+// Upsert creates or replaces the embedding for embedding.ContentID.
+func (r *ContentEmbeddingRepositoryImpl) Upsert(embedding *models.ContentEmbedding) error {
+	err := r.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "content_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"model", "embedding", "created_at"}),
+	}).Create(embedding).Error
+	if err != nil {
+		logrus.Errorf("Error upserting content embedding: %v", err)
+	}
+	return err
+}
+
+// GetByContentIDs returns every stored embedding for any of contentIDs.
+func (r *ContentEmbeddingRepositoryImpl) GetByContentIDs(contentIDs []uint) ([]models.ContentEmbedding, error) {
+	var embeddings []models.ContentEmbedding
+	if len(contentIDs) == 0 {
+		return embeddings, nil
+	}
+	err := r.DB.Where("content_id IN ?", contentIDs).Find(&embeddings).Error
+	if err != nil {
+		logrus.Errorf("Error getting content embeddings: %v", err)
+		return nil, err
+	}
+	return embeddings, nil
+}
+
+// GetMissingContentIDs returns up to limit IDs from contentIDs with no
+// content_embeddings row yet.
+func (r *ContentEmbeddingRepositoryImpl) GetMissingContentIDs(contentIDs []uint, limit int) ([]uint, error) {
+	if len(contentIDs) == 0 {
+		return nil, nil
+	}
+
+	var embedded []uint
+	err := r.DB.Model(&models.ContentEmbedding{}).Where("content_id IN ?", contentIDs).Pluck("content_id", &embedded).Error
+	if err != nil {
+		logrus.Errorf("Error getting embedded content IDs: %v", err)
+		return nil, err
+	}
+
+	has := make(map[uint]bool, len(embedded))
+	for _, id := range embedded {
+		has[id] = true
+	}
+
+	var missing []uint
+	for _, id := range contentIDs {
+		if has[id] {
+			continue
+		}
+		missing = append(missing, id)
+		if len(missing) >= limit {
+			break
+		}
+	}
+	return missing, nil
+}
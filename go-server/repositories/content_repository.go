@@ -10,10 +10,23 @@ import (
 type ContentRepository interface {
 	BaseRepository
 	GetContentByTopicID(topicID uint) (*models.Content, error)
+	// GetContentsByTopicIDs returns existing content rows for any of
+	// topicIDs in one query, so a bulk caller (e.g. batch content
+	// generation) can skip already-generated topics without an N+1.
+	GetContentsByTopicIDs(topicIDs []uint) ([]models.Content, error)
 	CreateContent(content *models.Content) error
 	UpdateContent(content *models.Content) error
 	DeleteContentByTopicID(topicID uint) error
 	GetContentWithTopic(contentID uint) (*models.Content, error)
+	// GetContentsByUserID returns every content row across every course the
+	// user owns, via a single join, for SearchContent to score against.
+	GetContentsByUserID(userID uint) ([]models.Content, error)
+	// GetContentsByCourseID returns every content row under a course, via a
+	// single join, for Reindex.
+	GetContentsByCourseID(courseID uint) ([]models.Content, error)
+	// GetAllContentIDs returns every content row's ID, for the background
+	// embedding reconciler to scan for rows missing an embedding.
+	GetAllContentIDs() ([]uint, error)
 }
 
 type ContentRepositoryImpl struct {
@@ -40,6 +53,20 @@ func (r *ContentRepositoryImpl) GetContentByTopicID(topicID uint) (*models.Conte
 	return &content, nil
 }
 
+// GetContentsByTopicIDs returns existing content rows for any of topicIDs.
+func (r *ContentRepositoryImpl) GetContentsByTopicIDs(topicIDs []uint) ([]models.Content, error) {
+	var contents []models.Content
+	if len(topicIDs) == 0 {
+		return contents, nil
+	}
+	err := r.DB.Where("topic_id IN ?", topicIDs).Find(&contents).Error
+	if err != nil {
+		logrus.Errorf("Error getting contents by topic IDs: %v", err)
+		return nil, err
+	}
+	return contents, nil
+}
+
 // CreateContent creates new content
 func (r *ContentRepositoryImpl) CreateContent(content *models.Content) error {
 	err := r.DB.Create(content).Error
@@ -76,4 +103,51 @@ func (r *ContentRepositoryImpl) GetContentWithTopic(contentID uint) (*models.Con
 		return nil, err
 	}
 	return &content, nil
-}
\ No newline at end of file
+}
+
+// GetContentsByUserID returns every content row across every course userID
+// owns, joining through topics/chapters/subjects/courses in one query.
+func (r *ContentRepositoryImpl) GetContentsByUserID(userID uint) ([]models.Content, error) {
+	var contents []models.Content
+	err := r.DB.Preload("Topic").
+		Joins("JOIN topics ON topics.id = content.topic_id").
+		Joins("JOIN chapters ON chapters.id = topics.chapter_id").
+		Joins("JOIN subjects ON subjects.id = chapters.subject_id").
+		Joins("JOIN courses ON courses.id = subjects.course_id").
+		Where("courses.user_id = ?", userID).
+		Find(&contents).Error
+	if err != nil {
+		logrus.Errorf("Error getting contents by user ID: %v", err)
+		return nil, err
+	}
+	return contents, nil
+}
+
+// GetContentsByCourseID returns every content row under courseID, joining
+// through topics/chapters/subjects in one query.
+func (r *ContentRepositoryImpl) GetContentsByCourseID(courseID uint) ([]models.Content, error) {
+	var contents []models.Content
+	err := r.DB.Preload("Topic").
+		Joins("JOIN topics ON topics.id = content.topic_id").
+		Joins("JOIN chapters ON chapters.id = topics.chapter_id").
+		Joins("JOIN subjects ON subjects.id = chapters.subject_id").
+		Where("subjects.course_id = ?", courseID).
+		Find(&contents).Error
+	if err != nil {
+		logrus.Errorf("Error getting contents by course ID: %v", err)
+		return nil, err
+	}
+	return contents, nil
+}
+
+// GetAllContentIDs returns every content row's ID, for the background
+// embedding reconciler to diff against content_embeddings.
+func (r *ContentRepositoryImpl) GetAllContentIDs() ([]uint, error) {
+	var ids []uint
+	err := r.DB.Model(&models.Content{}).Pluck("id", &ids).Error
+	if err != nil {
+		logrus.Errorf("Error getting all content IDs: %v", err)
+		return nil, err
+	}
+	return ids, nil
+}
@@ -0,0 +1,51 @@
+package repositories
+
+import (
+	"time"
+
+	"go-server/models"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// DigestEventRepository backs services.DigestService's append-only log of
+// digest-worthy course activity, written by the chapter/subject/content
+// services as it happens so RunDigest never has to re-scan entire tables.
+type DigestEventRepository interface {
+	Create(event *models.DigestEvent) error
+	// ListSince returns userID's digest events strictly after since, oldest
+	// first, for RunDigest to summarize into one email.
+	ListSince(userID uint, since time.Time) ([]models.DigestEvent, error)
+}
+
+type DigestEventRepositoryImpl struct {
+	*BaseRepositoryImpl
+}
+
+func NewDigestEventRepository(db *gorm.DB) DigestEventRepository {
+	return &DigestEventRepositoryImpl{
+		BaseRepositoryImpl: NewBaseRepository(db),
+	}
+}
+
+// Create persists a newly logged digest event.
+func (r *DigestEventRepositoryImpl) Create(event *models.DigestEvent) error {
+	if err := r.DB.Create(event).Error; err != nil {
+		logrus.Errorf("Error creating digest event: %v", err)
+		return err
+	}
+	return nil
+}
+
+// ListSince returns userID's digest events strictly after since, oldest
+// first.
+func (r *DigestEventRepositoryImpl) ListSince(userID uint, since time.Time) ([]models.DigestEvent, error) {
+	var events []models.DigestEvent
+	err := r.DB.Where("user_id = ? AND created_at > ?", userID, since).Order("created_at ASC").Find(&events).Error
+	if err != nil {
+		logrus.Errorf("Error listing digest events: %v", err)
+		return nil, err
+	}
+	return events, nil
+}
@@ -0,0 +1,63 @@
+package repositories
+
+import (
+	"time"
+
+	"go-server/models"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// IdempotencyKeyRepository persists cached responses for
+// middleware.IdempotencyMiddleware.
+type IdempotencyKeyRepository interface {
+	GetByUserAndKey(userID uint, key string) (*models.IdempotencyKey, error)
+	Create(record *models.IdempotencyKey) error
+	DeleteExpired(before time.Time) error
+}
+
+type IdempotencyKeyRepositoryImpl struct {
+	*BaseRepositoryImpl
+}
+
+func NewIdempotencyKeyRepository(db *gorm.DB) IdempotencyKeyRepository {
+	return &IdempotencyKeyRepositoryImpl{
+		BaseRepositoryImpl: NewBaseRepository(db),
+	}
+}
+
+// GetByUserAndKey looks up a cached response for (userID, key). Returns
+// (nil, nil) if none exists yet or the cached one has already expired.
+func (r *IdempotencyKeyRepositoryImpl) GetByUserAndKey(userID uint, key string) (*models.IdempotencyKey, error) {
+	var record models.IdempotencyKey
+	err := r.DB.Where("user_id = ? AND `key` = ? AND expires_at > ?", userID, key, time.Now()).First(&record).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		logrus.Errorf("Error getting idempotency key: %v", err)
+		return nil, err
+	}
+	return &record, nil
+}
+
+// Create stores a newly computed response for future replay.
+func (r *IdempotencyKeyRepositoryImpl) Create(record *models.IdempotencyKey) error {
+	if err := r.DB.Create(record).Error; err != nil {
+		logrus.Errorf("Error creating idempotency key: %v", err)
+		return err
+	}
+	return nil
+}
+
+// DeleteExpired removes cached responses whose TTL has passed, so the table
+// doesn't grow unbounded. Callers run this periodically (e.g. alongside
+// other maintenance jobs); it is not wired to a scheduler here.
+func (r *IdempotencyKeyRepositoryImpl) DeleteExpired(before time.Time) error {
+	err := r.DB.Where("expires_at <= ?", before).Delete(&models.IdempotencyKey{}).Error
+	if err != nil {
+		logrus.Errorf("Error deleting expired idempotency keys: %v", err)
+	}
+	return err
+}
@@ -0,0 +1,65 @@
+package repositories
+
+import (
+	"go-server/models"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// ImageAssetRepository persists the logical-path-to-content-hash mapping
+// ImageServiceImpl uses to dedup content-addressed uploads.
+type ImageAssetRepository interface {
+	GetByLogicalPath(logicalPath string) (*models.ImageAsset, error)
+	Upsert(asset *models.ImageAsset) error
+}
+
+type ImageAssetRepositoryImpl struct {
+	*BaseRepositoryImpl
+}
+
+func NewImageAssetRepository(db *gorm.DB) ImageAssetRepository {
+	return &ImageAssetRepositoryImpl{
+		BaseRepositoryImpl: NewBaseRepository(db),
+	}
+}
+
+// GetByLogicalPath looks up the asset currently stored at logicalPath.
+// Returns (nil, nil) if none exists yet.
+func (r *ImageAssetRepositoryImpl) GetByLogicalPath(logicalPath string) (*models.ImageAsset, error) {
+	var asset models.ImageAsset
+	err := r.DB.Where("logical_path = ?", logicalPath).First(&asset).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		logrus.Errorf("Error getting image asset for %q: %v", logicalPath, err)
+		return nil, err
+	}
+	return &asset, nil
+}
+
+// Upsert records which content hash/URL logicalPath currently points at,
+// overwriting whatever it previously pointed at.
+func (r *ImageAssetRepositoryImpl) Upsert(asset *models.ImageAsset) error {
+	existing, err := r.GetByLogicalPath(asset.LogicalPath)
+	if err != nil {
+		return err
+	}
+	if existing == nil {
+		if err := r.DB.Create(asset).Error; err != nil {
+			logrus.Errorf("Error creating image asset for %q: %v", asset.LogicalPath, err)
+			return err
+		}
+		return nil
+	}
+
+	existing.ContentHash = asset.ContentHash
+	existing.ContentType = asset.ContentType
+	existing.URL = asset.URL
+	if err := r.DB.Save(existing).Error; err != nil {
+		logrus.Errorf("Error updating image asset for %q: %v", asset.LogicalPath, err)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,148 @@
+package repositories
+
+import (
+	"go-server/models"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type JobRepository interface {
+	BaseRepository
+	GetByIdempotencyKey(key string) (*models.Job, error)
+	GetPending(limit int) ([]models.Job, error)
+	MarkRunning(jobID uint) error
+	MarkCompleted(jobID uint, result string) error
+	MarkFailed(jobID uint, attempts int, errMsg string) error
+	ScheduleRetry(jobID uint, errMsg string, delay time.Duration) error
+	// UpdateProgress records a handler-defined sub-stage label for a job
+	// that's still running, for a GET /jobs/:id/watch client to observe.
+	UpdateProgress(jobID uint, progress string) error
+	// ReclaimStale puts jobs stuck in "running" for longer than
+	// visibilityTimeout back to pending, so a worker that crashed mid-job
+	// doesn't leave it stranded forever.
+	ReclaimStale(visibilityTimeout time.Duration) error
+}
+
+type JobRepositoryImpl struct {
+	*BaseRepositoryImpl
+}
+
+func NewJobRepository(db *gorm.DB) JobRepository {
+	return &JobRepositoryImpl{
+		BaseRepositoryImpl: NewBaseRepository(db),
+	}
+}
+
+// GetByIdempotencyKey looks up a previously created job by its
+// Idempotency-Key, so a retried request returns the existing job instead of
+// enqueueing a duplicate. Returns (nil, nil) if key is unset or unmatched.
+func (r *JobRepositoryImpl) GetByIdempotencyKey(key string) (*models.Job, error) {
+	if key == "" {
+		return nil, nil
+	}
+
+	var job models.Job
+	err := r.DB.Where("idempotency_key = ?", key).First(&job).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		logrus.Errorf("Error getting job by idempotency key: %v", err)
+		return nil, err
+	}
+	return &job, nil
+}
+
+// GetPending returns up to limit jobs ready to run (pending, with
+// next_attempt_at already passed), oldest first.
+func (r *JobRepositoryImpl) GetPending(limit int) ([]models.Job, error) {
+	var jobs []models.Job
+	err := r.DB.Where("status = ? AND next_attempt_at <= ?", models.JobStatusPending, time.Now()).
+		Order("created_at ASC").Limit(limit).Find(&jobs).Error
+	if err != nil {
+		logrus.Errorf("Error getting pending jobs: %v", err)
+		return nil, err
+	}
+	return jobs, nil
+}
+
+// MarkRunning transitions a job to running and bumps its attempt count.
+func (r *JobRepositoryImpl) MarkRunning(jobID uint) error {
+	err := r.DB.Model(&models.Job{}).Where("id = ?", jobID).Updates(map[string]interface{}{
+		"status":   models.JobStatusRunning,
+		"attempts": gorm.Expr("attempts + 1"),
+	}).Error
+	if err != nil {
+		logrus.Errorf("Error marking job %d running: %v", jobID, err)
+	}
+	return err
+}
+
+// MarkCompleted records a job's successful result.
+func (r *JobRepositoryImpl) MarkCompleted(jobID uint, result string) error {
+	err := r.DB.Model(&models.Job{}).Where("id = ?", jobID).Updates(map[string]interface{}{
+		"status": models.JobStatusCompleted,
+		"result": result,
+	}).Error
+	if err != nil {
+		logrus.Errorf("Error marking job %d completed: %v", jobID, err)
+	}
+	return err
+}
+
+// ScheduleRetry puts a job back in the pending queue after a transient
+// failure, gated by next_attempt_at so jobs.Queue's exponential backoff is
+// enforced even across worker restarts.
+func (r *JobRepositoryImpl) ScheduleRetry(jobID uint, errMsg string, delay time.Duration) error {
+	err := r.DB.Model(&models.Job{}).Where("id = ?", jobID).Updates(map[string]interface{}{
+		"status":          models.JobStatusPending,
+		"error":           errMsg,
+		"next_attempt_at": time.Now().Add(delay),
+	}).Error
+	if err != nil {
+		logrus.Errorf("Error scheduling retry for job %d: %v", jobID, err)
+	}
+	return err
+}
+
+// UpdateProgress records progress against a running job.
+func (r *JobRepositoryImpl) UpdateProgress(jobID uint, progress string) error {
+	err := r.DB.Model(&models.Job{}).Where("id = ?", jobID).Update("progress", progress).Error
+	if err != nil {
+		logrus.Errorf("Error updating progress for job %d: %v", jobID, err)
+	}
+	return err
+}
+
+// ReclaimStale resets any job that's been "running" for longer than
+// visibilityTimeout back to "pending" with next_attempt_at due immediately,
+// so a crashed worker's in-flight jobs get picked back up rather than
+// stranded. It doesn't touch Attempts: the eventual handler run still counts
+// against maxAttempts the same as any other retry.
+func (r *JobRepositoryImpl) ReclaimStale(visibilityTimeout time.Duration) error {
+	cutoff := time.Now().Add(-visibilityTimeout)
+	err := r.DB.Model(&models.Job{}).
+		Where("status = ? AND updated_at < ?", models.JobStatusRunning, cutoff).
+		Updates(map[string]interface{}{
+			"status":          models.JobStatusPending,
+			"next_attempt_at": time.Now(),
+		}).Error
+	if err != nil {
+		logrus.Errorf("Error reclaiming stale running jobs: %v", err)
+	}
+	return err
+}
+
+// MarkFailed records a job's terminal failure after attempts retries.
+func (r *JobRepositoryImpl) MarkFailed(jobID uint, attempts int, errMsg string) error {
+	err := r.DB.Model(&models.Job{}).Where("id = ?", jobID).Updates(map[string]interface{}{
+		"status": models.JobStatusFailed,
+		"error":  errMsg,
+	}).Error
+	if err != nil {
+		logrus.Errorf("Error marking job %d failed after %d attempts: %v", jobID, attempts, err)
+	}
+	return err
+}
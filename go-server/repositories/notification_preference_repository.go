@@ -0,0 +1,60 @@
+package repositories
+
+import (
+	"go-server/models"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// NotificationPreferenceRepository backs services.NotificationService's
+// per-user, per-notification-type channel opt-ins.
+type NotificationPreferenceRepository interface {
+	// ListByUser returns every preference row userID has set, across all
+	// notification types and channels.
+	ListByUser(userID uint) ([]models.NotificationPreference, error)
+	// Set upserts whether channel is enabled for userID's notifType.
+	Set(userID uint, notifType, channel string, enabled bool) error
+}
+
+type NotificationPreferenceRepositoryImpl struct {
+	*BaseRepositoryImpl
+}
+
+func NewNotificationPreferenceRepository(db *gorm.DB) NotificationPreferenceRepository {
+	return &NotificationPreferenceRepositoryImpl{
+		BaseRepositoryImpl: NewBaseRepository(db),
+	}
+}
+
+// ListByUser returns every preference row userID has set.
+func (r *NotificationPreferenceRepositoryImpl) ListByUser(userID uint) ([]models.NotificationPreference, error) {
+	var prefs []models.NotificationPreference
+	err := r.DB.Where("user_id = ?", userID).Find(&prefs).Error
+	if err != nil {
+		logrus.Errorf("Error listing notification preferences: %v", err)
+		return nil, err
+	}
+	return prefs, nil
+}
+
+// Set upserts userID's (notifType, channel) preference, so repeatedly
+// opting into (or out of) the same channel is idempotent rather than a
+// duplicate-key error.
+func (r *NotificationPreferenceRepositoryImpl) Set(userID uint, notifType, channel string, enabled bool) error {
+	pref := models.NotificationPreference{
+		UserID:           userID,
+		NotificationType: notifType,
+		Channel:          channel,
+		Enabled:          enabled,
+	}
+	err := r.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "notification_type"}, {Name: "channel"}},
+		DoUpdates: clause.AssignmentColumns([]string{"enabled"}),
+	}).Create(&pref).Error
+	if err != nil {
+		logrus.Errorf("Error setting notification preference: %v", err)
+	}
+	return err
+}
@@ -0,0 +1,93 @@
+package repositories
+
+import (
+	"time"
+
+	"go-server/models"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type RefreshTokenRepository interface {
+	BaseRepository
+	GetByJTI(jti string) (*models.RefreshToken, error)
+	RevokeByJTI(jti string) error
+	RevokeSession(sessionID string) error
+	RevokeAllUserSessions(userID uint) error
+	GetActiveSessionsByUser(userID uint) ([]models.RefreshToken, error)
+}
+
+type RefreshTokenRepositoryImpl struct {
+	*BaseRepositoryImpl
+}
+
+func NewRefreshTokenRepository(db *gorm.DB) RefreshTokenRepository {
+	return &RefreshTokenRepositoryImpl{
+		BaseRepositoryImpl: NewBaseRepository(db),
+	}
+}
+
+// GetByJTI looks up a refresh token row by its jti. Returns (nil, nil) if no
+// row exists under that jti.
+func (r *RefreshTokenRepositoryImpl) GetByJTI(jti string) (*models.RefreshToken, error) {
+	var token models.RefreshToken
+	err := r.DB.Where("jti = ?", jti).First(&token).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		logrus.Errorf("Error getting refresh token by jti: %v", err)
+		return nil, err
+	}
+	return &token, nil
+}
+
+// RevokeByJTI marks a single rotation-chain link revoked.
+func (r *RefreshTokenRepositoryImpl) RevokeByJTI(jti string) error {
+	now := time.Now()
+	err := r.DB.Model(&models.RefreshToken{}).Where("jti = ? AND revoked_at IS NULL", jti).
+		Update("revoked_at", &now).Error
+	if err != nil {
+		logrus.Errorf("Error revoking refresh token %s: %v", jti, err)
+	}
+	return err
+}
+
+// RevokeSession revokes every unrevoked link in a session's rotation chain,
+// ending that session regardless of which jti in the chain is current.
+func (r *RefreshTokenRepositoryImpl) RevokeSession(sessionID string) error {
+	now := time.Now()
+	err := r.DB.Model(&models.RefreshToken{}).Where("session_id = ? AND revoked_at IS NULL", sessionID).
+		Update("revoked_at", &now).Error
+	if err != nil {
+		logrus.Errorf("Error revoking session %s: %v", sessionID, err)
+	}
+	return err
+}
+
+// RevokeAllUserSessions revokes every session belonging to a user, e.g. on a
+// password change or a user-initiated "sign out everywhere".
+func (r *RefreshTokenRepositoryImpl) RevokeAllUserSessions(userID uint) error {
+	now := time.Now()
+	err := r.DB.Model(&models.RefreshToken{}).Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", &now).Error
+	if err != nil {
+		logrus.Errorf("Error revoking sessions for user %d: %v", userID, err)
+	}
+	return err
+}
+
+// GetActiveSessionsByUser returns the current (unrevoked, unexpired) link of
+// every session chain belonging to a user, i.e. one row per active device,
+// for TokenService.ListActiveSessions.
+func (r *RefreshTokenRepositoryImpl) GetActiveSessionsByUser(userID uint) ([]models.RefreshToken, error) {
+	var tokens []models.RefreshToken
+	err := r.DB.Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, time.Now()).
+		Order("issued_at DESC").Find(&tokens).Error
+	if err != nil {
+		logrus.Errorf("Error getting active sessions for user %d: %v", userID, err)
+		return nil, err
+	}
+	return tokens, nil
+}
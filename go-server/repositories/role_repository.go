@@ -0,0 +1,77 @@
+package repositories
+
+import (
+	"go-server/models"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type RoleRepository interface {
+	BaseRepository
+	GetByName(name string) (*models.Role, error)
+	// GetRolesByUserID returns every role granted to userID, for baking
+	// into a freshly issued JWT's Roles/Scopes claims or an AuthContext.
+	GetRolesByUserID(userID uint) ([]models.Role, error)
+	GrantRole(userID, roleID uint) error
+	RevokeRole(userID, roleID uint) error
+}
+
+type RoleRepositoryImpl struct {
+	*BaseRepositoryImpl
+}
+
+func NewRoleRepository(db *gorm.DB) RoleRepository {
+	return &RoleRepositoryImpl{
+		BaseRepositoryImpl: NewBaseRepository(db),
+	}
+}
+
+// GetByName looks up a role by its unique name, e.g. "admin" during
+// bootstrapping or grant/revoke.
+func (r *RoleRepositoryImpl) GetByName(name string) (*models.Role, error) {
+	var role models.Role
+	err := r.DB.Where("name = ?", name).First(&role).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		logrus.Errorf("Error getting role by name: %v", err)
+		return nil, err
+	}
+	return &role, nil
+}
+
+// GetRolesByUserID returns every role granted to userID via user_roles.
+func (r *RoleRepositoryImpl) GetRolesByUserID(userID uint) ([]models.Role, error) {
+	var roles []models.Role
+	err := r.DB.Joins("JOIN user_roles ON user_roles.role_id = roles.id").
+		Where("user_roles.user_id = ?", userID).
+		Find(&roles).Error
+	if err != nil {
+		logrus.Errorf("Error getting roles by user ID: %v", err)
+		return nil, err
+	}
+	return roles, nil
+}
+
+// GrantRole assigns roleID to userID, idempotently (granting an
+// already-held role is a no-op rather than a duplicate-key error).
+func (r *RoleRepositoryImpl) GrantRole(userID, roleID uint) error {
+	err := r.DB.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&models.UserRole{UserID: userID, RoleID: roleID}).Error
+	if err != nil {
+		logrus.Errorf("Error granting role: %v", err)
+	}
+	return err
+}
+
+// RevokeRole removes roleID from userID.
+func (r *RoleRepositoryImpl) RevokeRole(userID, roleID uint) error {
+	err := r.DB.Where("user_id = ? AND role_id = ?", userID, roleID).Delete(&models.UserRole{}).Error
+	if err != nil {
+		logrus.Errorf("Error revoking role: %v", err)
+	}
+	return err
+}
@@ -0,0 +1,174 @@
+package repositories
+
+import (
+	"fmt"
+
+	"go-server/models"
+	"go-server/utils"
+
+	"gorm.io/gorm"
+)
+
+// SearchRepository runs a full-text search against a single entity table,
+// paginated with an opaque cursor (last row's id/created_at) instead of
+// OFFSET so deep pages stay cheap and stable under concurrent inserts. It
+// relies on the FULLTEXT indexes config.Bootstrap creates on courses.name,
+// courses.description, subjects.name, chapters.name, and topics.name.
+type SearchRepository interface {
+	Search(entityType, query string, cursor utils.Cursor, limit int) (items []map[string]interface{}, nextCursor string, total int64, err error)
+}
+
+type SearchRepositoryImpl struct {
+	DB *gorm.DB
+}
+
+func NewSearchRepository(db *gorm.DB) SearchRepository {
+	return &SearchRepositoryImpl{DB: db}
+}
+
+func (r *SearchRepositoryImpl) Search(entityType, query string, cursor utils.Cursor, limit int) ([]map[string]interface{}, string, int64, error) {
+	switch entityType {
+	case "course":
+		return r.searchCourses(query, cursor, limit)
+	case "subject":
+		return r.searchSubjects(query, cursor, limit)
+	case "chapter":
+		return r.searchChapters(query, cursor, limit)
+	case "topic":
+		return r.searchTopics(query, cursor, limit)
+	default:
+		return nil, "", 0, fmt.Errorf("unsupported search type: %s", entityType)
+	}
+}
+
+func (r *SearchRepositoryImpl) searchCourses(query string, cursor utils.Cursor, limit int) ([]map[string]interface{}, string, int64, error) {
+	base := r.DB.Model(&models.Course{}).
+		Where("MATCH(name, description) AGAINST (? IN NATURAL LANGUAGE MODE)", query)
+
+	var total int64
+	if err := base.Count(&total).Error; err != nil {
+		return nil, "", 0, err
+	}
+
+	db := base
+	if !cursor.LastCreatedAt.IsZero() {
+		db = db.Where("(created_at, id) < (?, ?)", cursor.LastCreatedAt, cursor.LastID)
+	}
+
+	var courses []models.Course
+	if err := db.Order("created_at DESC, id DESC").Limit(limit + 1).Find(&courses).Error; err != nil {
+		return nil, "", 0, err
+	}
+
+	var nextCursor string
+	if len(courses) > limit {
+		last := courses[limit-1]
+		nextCursor = utils.EncodeCursor(utils.Cursor{LastID: last.ID, LastCreatedAt: last.CreatedAt})
+		courses = courses[:limit]
+	}
+
+	items := make([]map[string]interface{}, len(courses))
+	for i, course := range courses {
+		items[i] = course.ToDict()
+	}
+	return items, nextCursor, total, nil
+}
+
+func (r *SearchRepositoryImpl) searchSubjects(query string, cursor utils.Cursor, limit int) ([]map[string]interface{}, string, int64, error) {
+	base := r.DB.Model(&models.Subject{}).
+		Where("MATCH(name) AGAINST (? IN NATURAL LANGUAGE MODE)", query)
+
+	var total int64
+	if err := base.Count(&total).Error; err != nil {
+		return nil, "", 0, err
+	}
+
+	db := base
+	if !cursor.LastCreatedAt.IsZero() {
+		db = db.Where("(created_at, id) < (?, ?)", cursor.LastCreatedAt, cursor.LastID)
+	}
+
+	var subjects []models.Subject
+	if err := db.Order("created_at DESC, id DESC").Limit(limit + 1).Find(&subjects).Error; err != nil {
+		return nil, "", 0, err
+	}
+
+	var nextCursor string
+	if len(subjects) > limit {
+		last := subjects[limit-1]
+		nextCursor = utils.EncodeCursor(utils.Cursor{LastID: last.ID, LastCreatedAt: last.CreatedAt})
+		subjects = subjects[:limit]
+	}
+
+	items := make([]map[string]interface{}, len(subjects))
+	for i, subject := range subjects {
+		items[i] = subject.ToDict()
+	}
+	return items, nextCursor, total, nil
+}
+
+func (r *SearchRepositoryImpl) searchChapters(query string, cursor utils.Cursor, limit int) ([]map[string]interface{}, string, int64, error) {
+	base := r.DB.Model(&models.Chapter{}).
+		Where("MATCH(name) AGAINST (? IN NATURAL LANGUAGE MODE)", query)
+
+	var total int64
+	if err := base.Count(&total).Error; err != nil {
+		return nil, "", 0, err
+	}
+
+	db := base
+	if !cursor.LastCreatedAt.IsZero() {
+		db = db.Where("(created_at, id) < (?, ?)", cursor.LastCreatedAt, cursor.LastID)
+	}
+
+	var chapters []models.Chapter
+	if err := db.Order("created_at DESC, id DESC").Limit(limit + 1).Find(&chapters).Error; err != nil {
+		return nil, "", 0, err
+	}
+
+	var nextCursor string
+	if len(chapters) > limit {
+		last := chapters[limit-1]
+		nextCursor = utils.EncodeCursor(utils.Cursor{LastID: last.ID, LastCreatedAt: last.CreatedAt})
+		chapters = chapters[:limit]
+	}
+
+	items := make([]map[string]interface{}, len(chapters))
+	for i, chapter := range chapters {
+		items[i] = chapter.ToDict()
+	}
+	return items, nextCursor, total, nil
+}
+
+func (r *SearchRepositoryImpl) searchTopics(query string, cursor utils.Cursor, limit int) ([]map[string]interface{}, string, int64, error) {
+	base := r.DB.Model(&models.Topic{}).
+		Where("MATCH(name) AGAINST (? IN NATURAL LANGUAGE MODE)", query)
+
+	var total int64
+	if err := base.Count(&total).Error; err != nil {
+		return nil, "", 0, err
+	}
+
+	db := base
+	if !cursor.LastCreatedAt.IsZero() {
+		db = db.Where("(created_at, id) < (?, ?)", cursor.LastCreatedAt, cursor.LastID)
+	}
+
+	var topics []models.Topic
+	if err := db.Order("created_at DESC, id DESC").Limit(limit + 1).Find(&topics).Error; err != nil {
+		return nil, "", 0, err
+	}
+
+	var nextCursor string
+	if len(topics) > limit {
+		last := topics[limit-1]
+		nextCursor = utils.EncodeCursor(utils.Cursor{LastID: last.ID, LastCreatedAt: last.CreatedAt})
+		topics = topics[:limit]
+	}
+
+	items := make([]map[string]interface{}, len(topics))
+	for i, topic := range topics {
+		items[i] = topic.ToDict()
+	}
+	return items, nextCursor, total, nil
+}
@@ -0,0 +1,91 @@
+package repositories
+
+import (
+	"go-server/models"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type SigningKeyRepository interface {
+	BaseRepository
+	GetByKid(kid string) (*models.SigningKey, error)
+	// GetActive returns the one key currently signing new tokens, or nil if
+	// none has been generated yet.
+	GetActive() (*models.SigningKey, error)
+	// GetVerifiable returns every non-retired key (active + retiring), so
+	// ValidateToken and the JWKS endpoint can accept/publish a key even
+	// mid-rotation.
+	GetVerifiable() ([]models.SigningKey, error)
+	ListAll() ([]models.SigningKey, error)
+	UpdateStatus(kid, status string) error
+}
+
+type SigningKeyRepositoryImpl struct {
+	*BaseRepositoryImpl
+}
+
+func NewSigningKeyRepository(db *gorm.DB) SigningKeyRepository {
+	return &SigningKeyRepositoryImpl{
+		BaseRepositoryImpl: NewBaseRepository(db),
+	}
+}
+
+// GetByKid looks up a signing key by its JWT "kid" header value.
+func (r *SigningKeyRepositoryImpl) GetByKid(kid string) (*models.SigningKey, error) {
+	var key models.SigningKey
+	err := r.DB.Where("kid = ?", kid).First(&key).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		logrus.Errorf("Error getting signing key by kid: %v", err)
+		return nil, err
+	}
+	return &key, nil
+}
+
+// GetActive returns the current active signing key, or nil if none exists.
+func (r *SigningKeyRepositoryImpl) GetActive() (*models.SigningKey, error) {
+	var key models.SigningKey
+	err := r.DB.Where("status = ?", models.SigningKeyActive).First(&key).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		logrus.Errorf("Error getting active signing key: %v", err)
+		return nil, err
+	}
+	return &key, nil
+}
+
+// GetVerifiable returns every key that is still accepted for verification.
+func (r *SigningKeyRepositoryImpl) GetVerifiable() ([]models.SigningKey, error) {
+	var keys []models.SigningKey
+	err := r.DB.Where("status IN ?", []string{models.SigningKeyActive, models.SigningKeyRetiring}).Find(&keys).Error
+	if err != nil {
+		logrus.Errorf("Error getting verifiable signing keys: %v", err)
+		return nil, err
+	}
+	return keys, nil
+}
+
+// ListAll returns every signing key regardless of status, for admin display.
+func (r *SigningKeyRepositoryImpl) ListAll() ([]models.SigningKey, error) {
+	var keys []models.SigningKey
+	err := r.DB.Order("created_at DESC").Find(&keys).Error
+	if err != nil {
+		logrus.Errorf("Error listing signing keys: %v", err)
+		return nil, err
+	}
+	return keys, nil
+}
+
+// UpdateStatus transitions a key between active/retiring/retired.
+func (r *SigningKeyRepositoryImpl) UpdateStatus(kid, status string) error {
+	err := r.DB.Model(&models.SigningKey{}).Where("kid = ?", kid).Update("status", status).Error
+	if err != nil {
+		logrus.Errorf("Error updating signing key status: %v", err)
+	}
+	return err
+}
@@ -1,20 +1,44 @@
 package repositories
 
 import (
+	"strings"
+
 	"go-server/models"
 
 	"github.com/sirupsen/logrus"
 	"gorm.io/gorm"
 )
 
+// SubjectUpsertResult pairs a subject with whether CreateOrGetSubjects
+// inserted it just now or it already existed under the course.
+type SubjectUpsertResult struct {
+	Subject models.Subject
+	Created bool
+}
+
 type SubjectRepository interface {
 	BaseRepository
 	GetSubjectsByCourseID(courseID uint) ([]models.Subject, error)
 	GetSubjectWithChapters(subjectID uint) (*models.Subject, error)
 	CreateSubjects(subjects []models.Subject) error
+	// CreateOrGetSubjects is the dedup-safe counterpart to CreateSubjects
+	// for caller-supplied subject names: inside one transaction, it looks
+	// up each name against courseID's existing subjects
+	// case-insensitively, inserts only the ones that don't already exist
+	// (including duplicate names within the same call), and returns every
+	// requested name tagged with whether it was just created.
+	// uq_subjects_course_name (bootstrapped by config.Bootstrap) backstops
+	// this against a concurrent racing insert of the same name.
+	CreateOrGetSubjects(courseID uint, names []string) ([]SubjectUpsertResult, error)
 	DeleteSubjectsByCourseID(courseID uint) error
+	QuerySubjectsByCourseID(courseID uint, q *models.ListQuery) ([]models.Subject, int64, error)
 }
 
+// subjectSortCols and subjectSearchCols whitelist which columns
+// QuerySubjectsByCourseID may sort and search by.
+var subjectSortCols = []string{"name", "created_at"}
+var subjectSearchCols = []string{"name"}
+
 type SubjectRepositoryImpl struct {
 	*BaseRepositoryImpl
 }
@@ -36,6 +60,49 @@ func (r *SubjectRepositoryImpl) GetSubjectsByCourseID(courseID uint) ([]models.S
 	return subjects, nil
 }
 
+// CreateOrGetSubjects inserts whichever of names don't already exist
+// (case-insensitively) under courseID, and returns every one of them —
+// pre-existing and newly-created alike, in request order — tagged with
+// whether this call created it. The lookup-then-insert runs in a single
+// transaction so a retried bulk-create request can't end up creating the
+// same subject twice.
+func (r *SubjectRepositoryImpl) CreateOrGetSubjects(courseID uint, names []string) ([]SubjectUpsertResult, error) {
+	results := make([]SubjectUpsertResult, 0, len(names))
+
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		var existing []models.Subject
+		if err := tx.Where("course_id = ?", courseID).Find(&existing).Error; err != nil {
+			return err
+		}
+
+		byName := make(map[string]models.Subject, len(existing)+len(names))
+		for _, subject := range existing {
+			byName[strings.ToLower(subject.Name)] = subject
+		}
+
+		for _, name := range names {
+			key := strings.ToLower(strings.TrimSpace(name))
+			if found, ok := byName[key]; ok {
+				results = append(results, SubjectUpsertResult{Subject: found, Created: false})
+				continue
+			}
+
+			subject := models.Subject{Name: name, CourseID: courseID}
+			if err := tx.Create(&subject).Error; err != nil {
+				return err
+			}
+			byName[key] = subject
+			results = append(results, SubjectUpsertResult{Subject: subject, Created: true})
+		}
+		return nil
+	})
+	if err != nil {
+		logrus.Errorf("Error upserting subjects for course %d: %v", courseID, err)
+		return nil, err
+	}
+	return results, nil
+}
+
 // GetSubjectWithChapters gets a subject with its chapters
 func (r *SubjectRepositoryImpl) GetSubjectWithChapters(subjectID uint) (*models.Subject, error) {
 	var subject models.Subject
@@ -47,6 +114,20 @@ func (r *SubjectRepositoryImpl) GetSubjectWithChapters(subjectID uint) (*models.
 	return &subject, nil
 }
 
+// QuerySubjectsByCourseID returns a page of a course's subjects matching q,
+// along with the total row count before pagination was applied.
+func (r *SubjectRepositoryImpl) QuerySubjectsByCourseID(courseID uint, q *models.ListQuery) ([]models.Subject, int64, error) {
+	var subjects []models.Subject
+	total, err := r.Query(&subjects, q, subjectSortCols, subjectSearchCols, func(db *gorm.DB) *gorm.DB {
+		return db.Where("course_id = ?", courseID)
+	})
+	if err != nil {
+		logrus.Errorf("Error querying subjects by course ID: %v", err)
+		return nil, 0, err
+	}
+	return subjects, total, nil
+}
+
 // CreateSubjects creates multiple subjects
 func (r *SubjectRepositoryImpl) CreateSubjects(subjects []models.Subject) error {
 	err := r.DB.CreateInBatches(&subjects, 100).Error
@@ -0,0 +1,74 @@
+package repositories
+
+import (
+	"time"
+
+	"go-server/models"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// TokenRepository backs the unified one-time token store (password reset,
+// email verification, and future invite flows) services.AuthServiceImpl
+// consumes, alongside the digest and Telegram services' own tokens.
+type TokenRepository interface {
+	CreateToken(token *models.Token) error
+	GetToken(tokenHash string) (*models.Token, error)
+	DeleteToken(tokenHash string) error
+	DeleteOlderThan(before time.Time) error
+}
+
+type TokenRepositoryImpl struct {
+	*BaseRepositoryImpl
+}
+
+func NewTokenRepository(db *gorm.DB) TokenRepository {
+	return &TokenRepositoryImpl{
+		BaseRepositoryImpl: NewBaseRepository(db),
+	}
+}
+
+// CreateToken persists a newly issued token's hash.
+func (r *TokenRepositoryImpl) CreateToken(token *models.Token) error {
+	if err := r.DB.Create(token).Error; err != nil {
+		logrus.Errorf("Error creating token: %v", err)
+		return err
+	}
+	return nil
+}
+
+// GetToken looks up an unexpired token by its hash. Returns (nil, nil) if
+// no matching, unexpired token exists.
+func (r *TokenRepositoryImpl) GetToken(tokenHash string) (*models.Token, error) {
+	var token models.Token
+	err := r.DB.Where("token_hash = ? AND expires_at > ?", tokenHash, time.Now()).First(&token).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		logrus.Errorf("Error getting token: %v", err)
+		return nil, err
+	}
+	return &token, nil
+}
+
+// DeleteToken removes a token by its hash, consuming it so it cannot be
+// redeemed a second time.
+func (r *TokenRepositoryImpl) DeleteToken(tokenHash string) error {
+	if err := r.DB.Where("token_hash = ?", tokenHash).Delete(&models.Token{}).Error; err != nil {
+		logrus.Errorf("Error deleting token: %v", err)
+		return err
+	}
+	return nil
+}
+
+// DeleteOlderThan removes tokens that expired before the given time, so the
+// table doesn't grow unbounded with abandoned reset/verification requests.
+func (r *TokenRepositoryImpl) DeleteOlderThan(before time.Time) error {
+	err := r.DB.Where("expires_at <= ?", before).Delete(&models.Token{}).Error
+	if err != nil {
+		logrus.Errorf("Error deleting expired tokens: %v", err)
+	}
+	return err
+}
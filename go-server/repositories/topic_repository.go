@@ -10,6 +10,14 @@ import (
 type TopicRepository interface {
 	BaseRepository
 	GetTopicsByChapterID(chapterID uint) ([]models.Topic, error)
+	// GetTopicsBySubjectID gets every topic across every chapter in a
+	// subject with a single join, rather than the caller looping per
+	// chapter and calling GetTopicsByChapterID N times.
+	GetTopicsBySubjectID(subjectID uint) ([]models.Topic, error)
+	// GetTopicsByChapterIDs gets every topic across all of the given
+	// chapters in a single query, for callers assembling a multi-chapter
+	// tree who would otherwise call GetTopicsByChapterID once per chapter.
+	GetTopicsByChapterIDs(chapterIDs []uint) ([]models.Topic, error)
 	GetTopicWithContents(topicID uint) (*models.Topic, error)
 	CreateTopics(topics []models.Topic) error
 	DeleteTopicsByChapterID(chapterID uint) error
@@ -36,6 +44,36 @@ func (r *TopicRepositoryImpl) GetTopicsByChapterID(chapterID uint) ([]models.Top
 	return topics, nil
 }
 
+// GetTopicsBySubjectID gets every topic belonging to any chapter in a
+// subject, via a single join against chapters rather than one query per
+// chapter.
+func (r *TopicRepositoryImpl) GetTopicsBySubjectID(subjectID uint) ([]models.Topic, error) {
+	var topics []models.Topic
+	err := r.DB.Joins("JOIN chapters ON chapters.id = topics.chapter_id").
+		Where("chapters.subject_id = ?", subjectID).
+		Find(&topics).Error
+	if err != nil {
+		logrus.Errorf("Error getting topics by subject ID: %v", err)
+		return nil, err
+	}
+	return topics, nil
+}
+
+// GetTopicsByChapterIDs gets every topic belonging to any of chapterIDs in
+// a single query.
+func (r *TopicRepositoryImpl) GetTopicsByChapterIDs(chapterIDs []uint) ([]models.Topic, error) {
+	var topics []models.Topic
+	if len(chapterIDs) == 0 {
+		return topics, nil
+	}
+	err := r.DB.Where("chapter_id IN ?", chapterIDs).Find(&topics).Error
+	if err != nil {
+		logrus.Errorf("Error getting topics by chapter IDs: %v", err)
+		return nil, err
+	}
+	return topics, nil
+}
+
 // GetTopicWithContents gets a topic with its contents
 func (r *TopicRepositoryImpl) GetTopicWithContents(topicID uint) (*models.Topic, error) {
 	var topic models.Topic
@@ -63,4 +101,4 @@ func (r *TopicRepositoryImpl) DeleteTopicsByChapterID(chapterID uint) error {
 		logrus.Errorf("Error deleting topics by chapter ID: %v", err)
 	}
 	return err
-}
\ No newline at end of file
+}
@@ -0,0 +1,72 @@
+package repositories
+
+import (
+	"go-server/models"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// TOTPRecoveryCodeRepository backs the one-time recovery codes issued
+// alongside a TOTP enrollment.
+type TOTPRecoveryCodeRepository interface {
+	CreateBatch(codes []*models.TOTPRecoveryCode) error
+	GetUnusedByHash(userID uint, codeHash string) (*models.TOTPRecoveryCode, error)
+	MarkUsed(id uint) error
+	DeleteByUserID(userID uint) error
+}
+
+type TOTPRecoveryCodeRepositoryImpl struct {
+	*BaseRepositoryImpl
+}
+
+func NewTOTPRecoveryCodeRepository(db *gorm.DB) TOTPRecoveryCodeRepository {
+	return &TOTPRecoveryCodeRepositoryImpl{
+		BaseRepositoryImpl: NewBaseRepository(db),
+	}
+}
+
+// CreateBatch persists the ten recovery codes generated at activation in a
+// single insert.
+func (r *TOTPRecoveryCodeRepositoryImpl) CreateBatch(codes []*models.TOTPRecoveryCode) error {
+	if err := r.DB.Create(&codes).Error; err != nil {
+		logrus.Errorf("Error creating TOTP recovery codes: %v", err)
+		return err
+	}
+	return nil
+}
+
+// GetUnusedByHash looks up an unused recovery code by its hash, scoped to
+// userID so one user's codes can't be redeemed against another's account.
+// Returns (nil, nil) if no matching, unused code exists.
+func (r *TOTPRecoveryCodeRepositoryImpl) GetUnusedByHash(userID uint, codeHash string) (*models.TOTPRecoveryCode, error) {
+	var code models.TOTPRecoveryCode
+	err := r.DB.Where("user_id = ? AND code_hash = ? AND used = ?", userID, codeHash, false).First(&code).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		logrus.Errorf("Error getting TOTP recovery code: %v", err)
+		return nil, err
+	}
+	return &code, nil
+}
+
+// MarkUsed consumes a recovery code so it cannot be redeemed a second time.
+func (r *TOTPRecoveryCodeRepositoryImpl) MarkUsed(id uint) error {
+	if err := r.DB.Model(&models.TOTPRecoveryCode{}).Where("id = ?", id).Update("used", true).Error; err != nil {
+		logrus.Errorf("Error marking TOTP recovery code used: %v", err)
+		return err
+	}
+	return nil
+}
+
+// DeleteByUserID removes all of userID's recovery codes, e.g. when 2FA is
+// disabled or re-enrolled.
+func (r *TOTPRecoveryCodeRepositoryImpl) DeleteByUserID(userID uint) error {
+	if err := r.DB.Where("user_id = ?", userID).Delete(&models.TOTPRecoveryCode{}).Error; err != nil {
+		logrus.Errorf("Error deleting TOTP recovery codes: %v", err)
+		return err
+	}
+	return nil
+}
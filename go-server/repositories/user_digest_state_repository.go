@@ -0,0 +1,60 @@
+package repositories
+
+import (
+	"time"
+
+	"go-server/models"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// UserDigestStateRepository backs services.DigestService's per-user
+// last-sent watermark.
+type UserDigestStateRepository interface {
+	// GetByUserID returns userID's digest state, or nil if they've never
+	// been sent one.
+	GetByUserID(userID uint) (*models.UserDigestState, error)
+	// SetLastSentAt upserts userID's watermark to sentAt.
+	SetLastSentAt(userID uint, sentAt time.Time) error
+}
+
+type UserDigestStateRepositoryImpl struct {
+	*BaseRepositoryImpl
+}
+
+func NewUserDigestStateRepository(db *gorm.DB) UserDigestStateRepository {
+	return &UserDigestStateRepositoryImpl{
+		BaseRepositoryImpl: NewBaseRepository(db),
+	}
+}
+
+// GetByUserID returns userID's digest state, or (nil, nil) if they've never
+// been sent a digest.
+func (r *UserDigestStateRepositoryImpl) GetByUserID(userID uint) (*models.UserDigestState, error) {
+	var state models.UserDigestState
+	err := r.DB.Where("user_id = ?", userID).First(&state).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		logrus.Errorf("Error getting user digest state: %v", err)
+		return nil, err
+	}
+	return &state, nil
+}
+
+// SetLastSentAt upserts userID's watermark to sentAt, so a user sent more
+// than one digest doesn't collide on the primary key.
+func (r *UserDigestStateRepositoryImpl) SetLastSentAt(userID uint, sentAt time.Time) error {
+	state := models.UserDigestState{UserID: userID, LastSentAt: &sentAt}
+	err := r.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"last_sent_at"}),
+	}).Create(&state).Error
+	if err != nil {
+		logrus.Errorf("Error setting user digest state: %v", err)
+	}
+	return err
+}
@@ -0,0 +1,49 @@
+package repositories
+
+import (
+	"go-server/models"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// UserIdentityRepository backs the provider/subject -> local user links
+// services.OAuthService uses to recognize a returning social-login user.
+type UserIdentityRepository interface {
+	GetByProviderSubject(provider, subject string) (*models.UserIdentity, error)
+	Create(identity *models.UserIdentity) error
+}
+
+type UserIdentityRepositoryImpl struct {
+	*BaseRepositoryImpl
+}
+
+func NewUserIdentityRepository(db *gorm.DB) UserIdentityRepository {
+	return &UserIdentityRepositoryImpl{
+		BaseRepositoryImpl: NewBaseRepository(db),
+	}
+}
+
+// GetByProviderSubject looks up the identity link for a provider account.
+// Returns (nil, nil) if this is the account's first login.
+func (r *UserIdentityRepositoryImpl) GetByProviderSubject(provider, subject string) (*models.UserIdentity, error) {
+	var identity models.UserIdentity
+	err := r.DB.Where("provider = ? AND subject = ?", provider, subject).First(&identity).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		logrus.Errorf("Error getting user identity: %v", err)
+		return nil, err
+	}
+	return &identity, nil
+}
+
+// Create links a provider account to a local user, on its first login.
+func (r *UserIdentityRepositoryImpl) Create(identity *models.UserIdentity) error {
+	if err := r.DB.Create(identity).Error; err != nil {
+		logrus.Errorf("Error creating user identity: %v", err)
+		return err
+	}
+	return nil
+}
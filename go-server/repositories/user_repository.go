@@ -15,6 +15,22 @@ type UserRepository interface {
 	UpdateLastLogin(userID uint) error
 	GetUserWithCourses(userID uint) (*models.User, error)
 	UpdatePassword(userID uint, hashedPassword string) error
+	// UpdateLastLoginAndPassword updates both columns in one transaction,
+	// so a rehash-on-login upgrade (e.g. legacy bcrypt to argon2id) can't
+	// be recorded as a successful login without the stronger hash actually
+	// landing, or vice versa.
+	UpdateLastLoginAndPassword(userID uint, hashedPassword string) error
+	// UpdateTelegramChatID links userID's account to a Telegram chat, once
+	// services.TelegramService confirms they control it via the PIN flow.
+	UpdateTelegramChatID(userID uint, chatID string) error
+	// ListDigestRecipients returns every active user who hasn't opted out
+	// of the periodic digest email, for services.DigestService.RunDigest to
+	// iterate.
+	ListDigestRecipients() ([]models.User, error)
+	// UpdateDigestOptOut flips userID's digest_opt_out flag to true, via
+	// the signed unsubscribe link services.DigestService embeds in every
+	// digest email.
+	UpdateDigestOptOut(userID uint) error
 }
 
 type UserRepositoryImpl struct {
@@ -78,4 +94,49 @@ func (r *UserRepositoryImpl) UpdatePassword(userID uint, hashedPassword string)
 		logrus.Errorf("Error updating password: %v", err)
 	}
 	return err
+}
+
+// UpdateLastLoginAndPassword updates last_login and password_hash together
+// in one transaction.
+func (r *UserRepositoryImpl) UpdateLastLoginAndPassword(userID uint, hashedPassword string) error {
+	now := time.Now()
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.User{}).Where("id = ?", userID).Update("last_login", &now).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.User{}).Where("id = ?", userID).Update("password_hash", hashedPassword).Error
+	})
+	if err != nil {
+		logrus.Errorf("Error updating last login and password: %v", err)
+	}
+	return err
+}
+
+// UpdateTelegramChatID links userID's account to a Telegram chat.
+func (r *UserRepositoryImpl) UpdateTelegramChatID(userID uint, chatID string) error {
+	err := r.DB.Model(&models.User{}).Where("id = ?", userID).Update("telegram_chat_id", chatID).Error
+	if err != nil {
+		logrus.Errorf("Error updating telegram chat id: %v", err)
+	}
+	return err
+}
+
+// ListDigestRecipients returns every active, non-opted-out user.
+func (r *UserRepositoryImpl) ListDigestRecipients() ([]models.User, error) {
+	var users []models.User
+	err := r.DB.Where("is_active = ? AND digest_opt_out = ?", true, false).Find(&users).Error
+	if err != nil {
+		logrus.Errorf("Error listing digest recipients: %v", err)
+		return nil, err
+	}
+	return users, nil
+}
+
+// UpdateDigestOptOut flips userID's digest_opt_out flag to true.
+func (r *UserRepositoryImpl) UpdateDigestOptOut(userID uint) error {
+	err := r.DB.Model(&models.User{}).Where("id = ?", userID).Update("digest_opt_out", true).Error
+	if err != nil {
+		logrus.Errorf("Error updating digest opt-out: %v", err)
+	}
+	return err
 }
\ No newline at end of file
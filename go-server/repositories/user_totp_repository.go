@@ -0,0 +1,68 @@
+package repositories
+
+import (
+	"go-server/models"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// UserTOTPRepository backs a user's RFC 6238 TOTP enrollment.
+type UserTOTPRepository interface {
+	GetByUserID(userID uint) (*models.UserTOTP, error)
+	Create(totp *models.UserTOTP) error
+	Update(totp *models.UserTOTP) error
+	DeleteByUserID(userID uint) error
+}
+
+type UserTOTPRepositoryImpl struct {
+	*BaseRepositoryImpl
+}
+
+func NewUserTOTPRepository(db *gorm.DB) UserTOTPRepository {
+	return &UserTOTPRepositoryImpl{
+		BaseRepositoryImpl: NewBaseRepository(db),
+	}
+}
+
+// GetByUserID returns userID's TOTP enrollment, or (nil, nil) if they've
+// never enrolled.
+func (r *UserTOTPRepositoryImpl) GetByUserID(userID uint) (*models.UserTOTP, error) {
+	var totp models.UserTOTP
+	err := r.DB.Where("user_id = ?", userID).First(&totp).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		logrus.Errorf("Error getting TOTP enrollment: %v", err)
+		return nil, err
+	}
+	return &totp, nil
+}
+
+// Create persists a new TOTP enrollment.
+func (r *UserTOTPRepositoryImpl) Create(totp *models.UserTOTP) error {
+	if err := r.DB.Create(totp).Error; err != nil {
+		logrus.Errorf("Error creating TOTP enrollment: %v", err)
+		return err
+	}
+	return nil
+}
+
+// Update persists changes to an existing TOTP enrollment (e.g. activating it).
+func (r *UserTOTPRepositoryImpl) Update(totp *models.UserTOTP) error {
+	if err := r.DB.Save(totp).Error; err != nil {
+		logrus.Errorf("Error updating TOTP enrollment: %v", err)
+		return err
+	}
+	return nil
+}
+
+// DeleteByUserID removes userID's TOTP enrollment, e.g. when 2FA is disabled.
+func (r *UserTOTPRepositoryImpl) DeleteByUserID(userID uint) error {
+	if err := r.DB.Where("user_id = ?", userID).Delete(&models.UserTOTP{}).Error; err != nil {
+		logrus.Errorf("Error deleting TOTP enrollment: %v", err)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,292 @@
+package routes
+
+import (
+	"go-server/middleware"
+	"go-server/models"
+	"go-server/services"
+	"go-server/utils"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupAdminRoutes mounts the role grant/revoke/list API admins use to
+// manage RBAC. The whole group requires the "admin" role; granting that
+// role through this API is the one way to create another admin.
+//
+// keyManager is nil when the server is running in the default HS256 mode,
+// in which case the rotate endpoint reports that key rotation isn't
+// available rather than panicking on a nil pointer.
+func SetupAdminRoutes(router *gin.RouterGroup, authService services.AuthService, contentService services.ContentService, emailService services.EmailService, keyManager *utils.KeyManager) {
+	router.Use(middleware.RequireRole("admin"))
+
+	router.GET("/users/:user_id/roles", handleListUserRoles(authService))
+	router.POST("/users/:user_id/roles", handleGrantRole(authService))
+	router.DELETE("/users/:user_id/roles/:role", handleRevokeRole(authService))
+
+	router.POST("/keys/rotate", handleRotateSigningKey(keyManager))
+
+	router.POST("/courses/:course_id/reindex", handleReindexCourseContent(contentService))
+
+	router.GET("/email-templates", handleListEmailTemplates(emailService))
+	router.GET("/email-templates/:name", handleGetEmailTemplate(emailService))
+	router.PUT("/email-templates/:name", handleSaveEmailTemplate(emailService))
+	router.POST("/email-templates/:name/preview", handlePreviewEmailTemplate(emailService))
+}
+
+// handleReindexCourseContent re-embeds every content row under a course,
+// for recovering from a model change or a corrupted embedding.
+func handleReindexCourseContent(contentService services.ContentService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		courseID, err := middleware.ParseIDParam(c, "course_id")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.APIResponse{
+				Success: false,
+				Error:   "invalid course ID",
+			})
+			return
+		}
+
+		if err := contentService.Reindex(courseID); err != nil {
+			c.Error(err)
+			return
+		}
+
+		c.JSON(http.StatusAccepted, models.APIResponse{
+			Success: true,
+			Message: "Content reindexing queued",
+		})
+	}
+}
+
+// handleRotateSigningKey promotes a freshly generated signing key to active
+// and demotes the previous one to retiring, so it keeps verifying tokens it
+// already signed until they expire.
+func handleRotateSigningKey(keyManager *utils.KeyManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if keyManager == nil {
+			c.JSON(http.StatusConflict, models.APIResponse{
+				Success: false,
+				Error:   "key rotation requires JWT_SIGNING_ALGORITHM to be RS256 or ES256",
+			})
+			return
+		}
+
+		key, err := keyManager.Rotate()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.APIResponse{
+				Success: false,
+				Error:   err.Error(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, models.APIResponse{
+			Success: true,
+			Message: "Signing key rotated successfully",
+			Data:    key.ToDict(),
+		})
+	}
+}
+
+type grantRoleRequest struct {
+	Role string `json:"role" validate:"required"`
+}
+
+func handleListUserRoles(authService services.AuthService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, err := middleware.ParseIDParam(c, "user_id")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.APIResponse{
+				Success: false,
+				Error:   "invalid user ID",
+			})
+			return
+		}
+
+		roles, err := authService.ListUserRoles(userID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.APIResponse{
+				Success: false,
+				Error:   err.Error(),
+			})
+			return
+		}
+
+		var rolesData []map[string]interface{}
+		for _, role := range roles {
+			rolesData = append(rolesData, role.ToDict())
+		}
+
+		c.JSON(http.StatusOK, models.APIResponse{
+			Success: true,
+			Data:    rolesData,
+		})
+	}
+}
+
+func handleGrantRole(authService services.AuthService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, err := middleware.ParseIDParam(c, "user_id")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.APIResponse{
+				Success: false,
+				Error:   "invalid user ID",
+			})
+			return
+		}
+
+		var req grantRoleRequest
+		if err := c.ShouldBindJSON(&req); err != nil || req.Role == "" {
+			c.JSON(http.StatusBadRequest, models.APIResponse{
+				Success: false,
+				Error:   "invalid request body",
+			})
+			return
+		}
+
+		if err := authService.GrantRole(userID, req.Role); err != nil {
+			c.JSON(http.StatusBadRequest, models.APIResponse{
+				Success: false,
+				Error:   err.Error(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, models.APIResponse{
+			Success: true,
+			Message: "Role granted successfully",
+		})
+	}
+}
+
+func handleRevokeRole(authService services.AuthService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, err := middleware.ParseIDParam(c, "user_id")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.APIResponse{
+				Success: false,
+				Error:   "invalid user ID",
+			})
+			return
+		}
+
+		role := c.Param("role")
+		if role == "" {
+			c.JSON(http.StatusBadRequest, models.APIResponse{
+				Success: false,
+				Error:   "role is required",
+			})
+			return
+		}
+
+		if err := authService.RevokeRole(userID, role); err != nil {
+			c.JSON(http.StatusBadRequest, models.APIResponse{
+				Success: false,
+				Error:   err.Error(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, models.APIResponse{
+			Success: true,
+			Message: "Role revoked successfully",
+		})
+	}
+}
+
+// handleListEmailTemplates lists every supported email template, so an
+// admin UI can show what's available to edit without fetching each body.
+func handleListEmailTemplates(emailService services.EmailService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, models.APIResponse{
+			Success: true,
+			Data:    emailService.ListTemplates(),
+		})
+	}
+}
+
+// handleGetEmailTemplate returns the current HTML and text bodies of the
+// named template for editing.
+func handleGetEmailTemplate(emailService services.EmailService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tmpl, err := emailService.GetTemplate(c.Param("name"))
+		if err != nil {
+			c.JSON(http.StatusNotFound, models.APIResponse{
+				Success: false,
+				Error:   err.Error(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, models.APIResponse{
+			Success: true,
+			Data:    tmpl,
+		})
+	}
+}
+
+type saveEmailTemplateRequest struct {
+	HTML string `json:"html" validate:"required"`
+	Text string `json:"text" validate:"required"`
+}
+
+// handleSaveEmailTemplate overwrites the named template's HTML and text
+// bodies, so an operator can rebrand a transactional email without
+// recompiling.
+func handleSaveEmailTemplate(emailService services.EmailService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req saveEmailTemplateRequest
+		if err := c.ShouldBindJSON(&req); err != nil || req.HTML == "" || req.Text == "" {
+			c.JSON(http.StatusBadRequest, models.APIResponse{
+				Success: false,
+				Error:   "invalid request body",
+			})
+			return
+		}
+
+		if err := emailService.SaveTemplate(c.Param("name"), req.HTML, req.Text); err != nil {
+			c.JSON(http.StatusBadRequest, models.APIResponse{
+				Success: false,
+				Error:   err.Error(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, models.APIResponse{
+			Success: true,
+			Message: "Email template saved successfully",
+		})
+	}
+}
+
+// handlePreviewEmailTemplate renders the named template against the
+// request's sample data without sending anything, so an admin can check an
+// edit before it reaches real recipients.
+func handlePreviewEmailTemplate(emailService services.EmailService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var sampleData map[string]string
+		if err := c.ShouldBindJSON(&sampleData); err != nil && err != io.EOF {
+			c.JSON(http.StatusBadRequest, models.APIResponse{
+				Success: false,
+				Error:   "invalid request body",
+			})
+			return
+		}
+
+		preview, err := emailService.PreviewEmail(c.Param("name"), sampleData)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.APIResponse{
+				Success: false,
+				Error:   err.Error(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, models.APIResponse{
+			Success: true,
+			Data:    preview,
+		})
+	}
+}
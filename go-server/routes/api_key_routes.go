@@ -0,0 +1,86 @@
+package routes
+
+import (
+	"go-server/middleware"
+	"go-server/models"
+	"go-server/services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupAPIKeyRoutes mounts the endpoint users register an HTTP Signatures
+// public key with, so their CI bots or server-to-server integrations can
+// authenticate via middleware.HTTPSignatureMiddleware instead of a JWT.
+func SetupAPIKeyRoutes(router *gin.RouterGroup, apiKeyService services.APIKeyService) {
+	router.POST("", handleRegisterAPIKey(apiKeyService))
+	router.GET("", handleListAPIKeys(apiKeyService))
+}
+
+func handleRegisterAPIKey(apiKeyService services.APIKeyService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, err := middleware.GetUserIDFromContext(c)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, models.APIResponse{
+				Success: false,
+				Error:   "unauthorized",
+			})
+			return
+		}
+
+		var req struct {
+			KeyID        string `json:"key_id" binding:"required"`
+			PublicKeyPEM string `json:"public_key_pem" binding:"required"`
+			Algorithm    string `json:"algorithm" binding:"required"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, models.APIResponse{
+				Success: false,
+				Error:   "invalid request format",
+			})
+			return
+		}
+
+		key, err := apiKeyService.RegisterKey(userID, req.KeyID, req.PublicKeyPEM, req.Algorithm)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+
+		c.JSON(http.StatusCreated, models.APIResponse{
+			Success: true,
+			Message: "API key registered successfully",
+			Data:    key.ToDict(),
+		})
+	}
+}
+
+func handleListAPIKeys(apiKeyService services.APIKeyService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, err := middleware.GetUserIDFromContext(c)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, models.APIResponse{
+				Success: false,
+				Error:   "unauthorized",
+			})
+			return
+		}
+
+		keys, err := apiKeyService.ListKeys(userID)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+
+		dicts := make([]map[string]interface{}, len(keys))
+		for i := range keys {
+			dicts[i] = keys[i].ToDict()
+		}
+
+		c.JSON(http.StatusOK, models.APIResponse{
+			Success: true,
+			Message: "API keys retrieved successfully",
+			Data:    dicts,
+		})
+	}
+}
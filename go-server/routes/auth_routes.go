@@ -1,6 +1,7 @@
 package routes
 
 import (
+	"go-server/config"
 	"go-server/middleware"
 	"go-server/models"
 	"go-server/services"
@@ -10,12 +11,24 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
-func SetupAuthRoutes(router *gin.RouterGroup, authService services.AuthService) {
-	router.POST("/register", handleRegister(authService))
-	router.POST("/login", handleLogin(authService))
+// SetupAuthRoutes mounts the unauthenticated auth endpoints. idempotencyMW
+// guards register and password-reset, where a client retrying a
+// timed-out request could otherwise double-register a user or re-send a
+// reset email; login/refresh/logout are left unguarded since replaying
+// them is already safe (or, for refresh, actively undesirable to cache).
+// rateLimitMW guards the endpoints an attacker could use to brute-force a
+// password or flood an inbox: login, register, and both password-reset
+// steps.
+func SetupAuthRoutes(router *gin.RouterGroup, authService services.AuthService, cfg *config.Config, idempotencyMW, rateLimitMW gin.HandlerFunc) {
+	router.POST("/register", rateLimitMW, idempotencyMW, handleRegister(authService))
+	router.POST("/login", rateLimitMW, handleLogin(authService, cfg))
 	router.POST("/refresh", handleRefreshToken(authService))
-	router.POST("/password-reset/request", handlePasswordResetRequest(authService))
-	router.POST("/password-reset/confirm", handlePasswordResetConfirm(authService))
+	router.POST("/logout", handleLogout(authService, cfg))
+	router.POST("/password-reset/request", rateLimitMW, idempotencyMW, handlePasswordResetRequest(authService))
+	router.POST("/password-reset/confirm", rateLimitMW, idempotencyMW, handlePasswordResetConfirm(authService))
+	router.POST("/verify-email", idempotencyMW, handleVerifyEmail(authService))
+	router.POST("/verify-email/resend", idempotencyMW, handleResendVerificationEmail(authService))
+	router.POST("/2fa/verify", idempotencyMW, handleVerifyMFA(authService, cfg))
 }
 
 func handleRegister(authService services.AuthService) gin.HandlerFunc {
@@ -51,7 +64,7 @@ func handleRegister(authService services.AuthService) gin.HandlerFunc {
 	}
 }
 
-func handleLogin(authService services.AuthService) gin.HandlerFunc {
+func handleLogin(authService services.AuthService, cfg *config.Config) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		var req models.UserLoginRequest
 		if err := c.ShouldBindJSON(&req); err != nil {
@@ -62,10 +75,10 @@ func handleLogin(authService services.AuthService) gin.HandlerFunc {
 			return
 		}
 
-		response, err := authService.Login(&req)
+		response, err := authService.Login(&req, c.Request.UserAgent(), c.ClientIP())
 		if err != nil {
 			statusCode := http.StatusUnauthorized
-			if err.Error() == "account is deactivated" {
+			if err.Error() == "account is not active; please verify your email" {
 				statusCode = http.StatusForbidden
 			}
 
@@ -76,6 +89,30 @@ func handleLogin(authService services.AuthService) gin.HandlerFunc {
 			return
 		}
 
+		if response.MFARequired {
+			// Password verified, but a TOTP code is still needed before any
+			// session (bearer or cookie) is established.
+			c.JSON(http.StatusOK, models.APIResponse{
+				Success: true,
+				Message: "two-factor authentication code required",
+				Data:    response,
+			})
+			return
+		}
+
+		// In session or hybrid auth mode, also establish a server-side session
+		// so first-party web clients don't need to manage the bearer token.
+		if cfg.Auth.Mode == "session" || cfg.Auth.Mode == "hybrid" {
+			userID, _ := response.User["id"].(uint)
+			email, _ := response.User["email"].(string)
+			csrfToken, err := middleware.SetSessionUser(c, cfg, userID, email)
+			if err != nil {
+				logrus.Errorf("Failed to establish session on login: %v", err)
+			} else {
+				c.Header("X-CSRF-Token", csrfToken)
+			}
+		}
+
 		c.JSON(http.StatusOK, models.APIResponse{
 			Success: true,
 			Message: "login successful",
@@ -84,6 +121,43 @@ func handleLogin(authService services.AuthService) gin.HandlerFunc {
 	}
 }
 
+// handleLogout ends the caller's session(s): it revokes the presented
+// refresh token (if any — a session-mode client may not hold one) so it
+// can't be rotated again, and, in session/hybrid auth mode, also clears the
+// server-side session cookie.
+func handleLogout(authService services.AuthService, cfg *config.Config) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req struct {
+			RefreshToken string `json:"refresh_token"`
+		}
+		_ = c.ShouldBindJSON(&req)
+
+		if req.RefreshToken != "" {
+			if err := authService.Logout(req.RefreshToken); err != nil {
+				logrus.Errorf("Error revoking session on logout: %v", err)
+				// Still proceed to clear the session cookie below; an
+				// already-invalid or unknown refresh token isn't a reason to
+				// fail logout for a client that's trying to sign out anyway.
+			}
+		}
+
+		if cfg.Auth.Mode == "session" || cfg.Auth.Mode == "hybrid" {
+			if err := middleware.ClearSession(c, cfg); err != nil {
+				c.JSON(http.StatusInternalServerError, models.APIResponse{
+					Success: false,
+					Error:   "failed to log out",
+				})
+				return
+			}
+		}
+
+		c.JSON(http.StatusOK, models.APIResponse{
+			Success: true,
+			Message: "logout successful",
+		})
+	}
+}
+
 func handleRefreshToken(authService services.AuthService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		var req struct {
@@ -98,12 +172,9 @@ func handleRefreshToken(authService services.AuthService) gin.HandlerFunc {
 			return
 		}
 
-		response, err := authService.RefreshToken(req.RefreshToken)
+		response, err := authService.RefreshToken(req.RefreshToken, c.Request.UserAgent(), c.ClientIP())
 		if err != nil {
-			c.JSON(http.StatusUnauthorized, models.APIResponse{
-				Success: false,
-				Error:   err.Error(),
-			})
+			c.Error(err)
 			return
 		}
 
@@ -166,8 +237,231 @@ func handlePasswordResetConfirm(authService services.AuthService) gin.HandlerFun
 	}
 }
 
+// handleVerifyEmail redeems a token emailed at registration (or by
+// handleResendVerificationEmail) and activates the account it belongs to.
+func handleVerifyEmail(authService services.AuthService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req struct {
+			Token string `json:"token" binding:"required"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, models.APIResponse{
+				Success: false,
+				Error:   "invalid request format",
+			})
+			return
+		}
+
+		if err := authService.VerifyEmail(req.Token); err != nil {
+			c.JSON(http.StatusBadRequest, models.APIResponse{
+				Success: false,
+				Error:   err.Error(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, models.APIResponse{
+			Success: true,
+			Message: "email verified successfully",
+		})
+	}
+}
+
+// handleResendVerificationEmail issues a fresh verification token for an
+// email that hasn't confirmed yet. Always returns success, even if the
+// email doesn't exist or is already verified, to prevent email enumeration.
+func handleResendVerificationEmail(authService services.AuthService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req struct {
+			Email string `json:"email" binding:"required,email"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, models.APIResponse{
+				Success: false,
+				Error:   "invalid request format",
+			})
+			return
+		}
+
+		if err := authService.ResendVerificationEmail(req.Email); err != nil {
+			logrus.Errorf("Resend verification email error: %v", err)
+		}
+
+		c.JSON(http.StatusOK, models.APIResponse{
+			Success: true,
+			Message: "if the email exists and is not yet verified, a verification link has been sent",
+		})
+	}
+}
+
+// handleVerifyMFA completes a login handleLogin paused for a second factor,
+// redeeming the mfa_token it returned alongside a TOTP or recovery code.
+func handleVerifyMFA(authService services.AuthService, cfg *config.Config) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req struct {
+			MFAToken string `json:"mfa_token" binding:"required"`
+			Code     string `json:"code" binding:"required"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, models.APIResponse{
+				Success: false,
+				Error:   "invalid request format",
+			})
+			return
+		}
+
+		response, err := authService.VerifyMFA(req.MFAToken, req.Code, c.Request.UserAgent(), c.ClientIP())
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, models.APIResponse{
+				Success: false,
+				Error:   err.Error(),
+			})
+			return
+		}
+
+		if cfg.Auth.Mode == "session" || cfg.Auth.Mode == "hybrid" {
+			userID, _ := response.User["id"].(uint)
+			email, _ := response.User["email"].(string)
+			csrfToken, err := middleware.SetSessionUser(c, cfg, userID, email)
+			if err != nil {
+				logrus.Errorf("Failed to establish session on MFA verification: %v", err)
+			} else {
+				c.Header("X-CSRF-Token", csrfToken)
+			}
+		}
+
+		c.JSON(http.StatusOK, models.APIResponse{
+			Success: true,
+			Message: "login successful",
+			Data:    response,
+		})
+	}
+}
+
+// SetupTOTPRoutes mounts authenticated two-factor-authentication management
+// endpoints. Unlike the rest of SetupAuthRoutes, these require a signed-in
+// session (the user is already past password login), so they're mounted on
+// the protected route group rather than authGroup.
+func SetupTOTPRoutes(router *gin.RouterGroup, authService services.AuthService, idempotencyMW gin.HandlerFunc) {
+	router.POST("/2fa/enroll", idempotencyMW, handleEnrollTOTP(authService))
+	router.POST("/2fa/activate", idempotencyMW, handleActivateTOTP(authService))
+	router.POST("/2fa/disable", idempotencyMW, handleDisableTOTP(authService))
+}
+
+func handleEnrollTOTP(authService services.AuthService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, err := middleware.GetUserIDFromContext(c)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, models.APIResponse{
+				Success: false,
+				Error:   "unauthorized",
+			})
+			return
+		}
+
+		secret, otpauthURI, err := authService.EnrollTOTP(userID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.APIResponse{
+				Success: false,
+				Error:   err.Error(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, models.APIResponse{
+			Success: true,
+			Message: "scan the QR code with your authenticator app, then confirm with /auth/2fa/activate",
+			Data: map[string]interface{}{
+				"secret":      secret,
+				"otpauth_uri": otpauthURI,
+			},
+		})
+	}
+}
+
+func handleActivateTOTP(authService services.AuthService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, err := middleware.GetUserIDFromContext(c)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, models.APIResponse{
+				Success: false,
+				Error:   "unauthorized",
+			})
+			return
+		}
+
+		var req struct {
+			Code string `json:"code" binding:"required"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, models.APIResponse{
+				Success: false,
+				Error:   "invalid request format",
+			})
+			return
+		}
+
+		recoveryCodes, err := authService.ActivateTOTP(userID, req.Code)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.APIResponse{
+				Success: false,
+				Error:   err.Error(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, models.APIResponse{
+			Success: true,
+			Message: "two-factor authentication enabled; store these recovery codes somewhere safe, they won't be shown again",
+			Data: map[string]interface{}{
+				"recovery_codes": recoveryCodes,
+			},
+		})
+	}
+}
+
+func handleDisableTOTP(authService services.AuthService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, err := middleware.GetUserIDFromContext(c)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, models.APIResponse{
+				Success: false,
+				Error:   "unauthorized",
+			})
+			return
+		}
+
+		var req struct {
+			Code string `json:"code" binding:"required"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, models.APIResponse{
+				Success: false,
+				Error:   "invalid request format",
+			})
+			return
+		}
+
+		if err := authService.DisableTOTP(userID, req.Code); err != nil {
+			c.JSON(http.StatusBadRequest, models.APIResponse{
+				Success: false,
+				Error:   err.Error(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, models.APIResponse{
+			Success: true,
+			Message: "two-factor authentication disabled",
+		})
+	}
+}
+
 func SetupUserRoutes(router *gin.RouterGroup, authService services.AuthService) {
 	router.GET("/profile", handleGetProfile(authService))
+	router.GET("/sessions", handleListSessions(authService))
+	router.DELETE("/sessions", handleRevokeAllSessions(authService))
+	router.DELETE("/sessions/:sessionID", handleRevokeSession(authService))
 }
 
 func handleGetProfile(authService services.AuthService) gin.HandlerFunc {
@@ -195,4 +489,85 @@ func handleGetProfile(authService services.AuthService) gin.HandlerFunc {
 			Data:    user.ToDict(),
 		})
 	}
-}
\ No newline at end of file
+}
+
+// handleListSessions returns the signed-in user's active refresh-token
+// sessions, e.g. for an account security page listing signed-in devices.
+func handleListSessions(authService services.AuthService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, err := middleware.GetUserIDFromContext(c)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, models.APIResponse{
+				Success: false,
+				Error:   "unauthorized",
+			})
+			return
+		}
+
+		sessions, err := authService.ListActiveSessions(userID)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+
+		dicts := make([]map[string]interface{}, len(sessions))
+		for i, session := range sessions {
+			dicts[i] = session.ToDict()
+		}
+
+		c.JSON(http.StatusOK, models.APIResponse{
+			Success: true,
+			Data:    dicts,
+		})
+	}
+}
+
+// handleRevokeSession signs the user out of one active session, identified
+// by its session_id as returned from handleListSessions.
+func handleRevokeSession(authService services.AuthService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, err := middleware.GetUserIDFromContext(c)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, models.APIResponse{
+				Success: false,
+				Error:   "unauthorized",
+			})
+			return
+		}
+
+		if err := authService.RevokeSession(userID, c.Param("sessionID")); err != nil {
+			c.Error(err)
+			return
+		}
+
+		c.JSON(http.StatusOK, models.APIResponse{
+			Success: true,
+			Message: "session revoked",
+		})
+	}
+}
+
+// handleRevokeAllSessions signs the user out of every active session, e.g.
+// a "sign out everywhere" action.
+func handleRevokeAllSessions(authService services.AuthService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, err := middleware.GetUserIDFromContext(c)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, models.APIResponse{
+				Success: false,
+				Error:   "unauthorized",
+			})
+			return
+		}
+
+		if err := authService.RevokeAllUserSessions(userID); err != nil {
+			c.Error(err)
+			return
+		}
+
+		c.JSON(http.StatusOK, models.APIResponse{
+			Success: true,
+			Message: "all sessions revoked",
+		})
+	}
+}
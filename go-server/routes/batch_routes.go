@@ -0,0 +1,136 @@
+package routes
+
+import (
+	"encoding/json"
+	"fmt"
+	"go-server/middleware"
+	"go-server/models"
+	"go-server/services"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// batchWatchPollInterval is how often handleWatchBatch re-checks a batch's
+// aggregate progress while streaming it to the client, the same interval
+// job_routes.go's handleWatchJob polls at.
+const batchWatchPollInterval = 1 * time.Second
+
+// SetupBatchRoutes mounts the endpoints clients use to track a batch
+// content-generation request queued through the content routes'
+// /generate/chapter/:chapter_id and /generate/subject/:subject_id
+// endpoints: GET /:id for a single poll, GET /:id/watch for an SSE stream
+// of aggregate progress, mirroring job_routes.go's per-job /:id/watch.
+func SetupBatchRoutes(router *gin.RouterGroup, contentService services.ContentService) {
+	router.GET("/:id", handleGetBatch(contentService))
+	router.GET("/:id/watch", handleWatchBatch(contentService))
+}
+
+func handleGetBatch(contentService services.ContentService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, err := middleware.GetUserIDFromContext(c)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, models.APIResponse{
+				Success: false,
+				Error:   "unauthorized",
+			})
+			return
+		}
+
+		batchID, err := middleware.ParseIDParam(c, "id")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.APIResponse{
+				Success: false,
+				Error:   "invalid batch ID",
+			})
+			return
+		}
+
+		batch, err := contentService.GetBatch(batchID, userID)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+
+		c.JSON(http.StatusOK, models.APIResponse{
+			Success: true,
+			Message: "Batch retrieved successfully",
+			Data:    batch.ToDictWithItems(),
+		})
+	}
+}
+
+// handleWatchBatch streams a batch's aggregate progress (total/completed/
+// failed counts and overall status, derived the same way ToDict does from
+// its items' live job statuses) as server-sent events, emitting a frame
+// whenever that progress changes until the batch reaches a terminal status
+// or the client disconnects. Spares a client polling a long-running
+// "generate content for every topic in this chapter/subject" batch.
+func handleWatchBatch(contentService services.ContentService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, err := middleware.GetUserIDFromContext(c)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, models.APIResponse{
+				Success: false,
+				Error:   "unauthorized",
+			})
+			return
+		}
+
+		batchID, err := middleware.ParseIDParam(c, "id")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.APIResponse{
+				Success: false,
+				Error:   "invalid batch ID",
+			})
+			return
+		}
+
+		c.Header("Content-Type", "text/event-stream")
+		c.Header("Cache-Control", "no-cache")
+		c.Header("Connection", "keep-alive")
+		c.Status(http.StatusOK)
+
+		ticker := time.NewTicker(batchWatchPollInterval)
+		defer ticker.Stop()
+
+		var lastStatus string
+		var lastCompleted, lastFailed int
+		for {
+			batch, err := contentService.GetBatch(batchID, userID)
+			if err != nil {
+				fmt.Fprintf(c.Writer, "event: error\ndata: %s\n\n", err.Error())
+				c.Writer.Flush()
+				return
+			}
+
+			dict := batch.ToDict()
+			status := dict["status"].(string)
+			completed := dict["completed"].(int)
+			failed := dict["failed"].(int)
+
+			if status != lastStatus || completed != lastCompleted || failed != lastFailed {
+				data, err := json.Marshal(dict)
+				if err != nil {
+					fmt.Fprintf(c.Writer, "event: error\ndata: %s\n\n", err.Error())
+					c.Writer.Flush()
+					return
+				}
+				fmt.Fprintf(c.Writer, "event: status\ndata: %s\n\n", data)
+				c.Writer.Flush()
+				lastStatus, lastCompleted, lastFailed = status, completed, failed
+			}
+
+			if status == models.JobStatusCompleted || status == models.JobStatusFailed {
+				return
+			}
+
+			select {
+			case <-c.Request.Context().Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}
+}
@@ -1,12 +1,318 @@
 package routes
 
 import (
-	"go-server/repositories"
+	"encoding/json"
+	"fmt"
+	"go-server/middleware"
+	"go-server/models"
+	"go-server/services"
+	"net/http"
 
 	"github.com/gin-gonic/gin"
 )
 
-func SetupChapterRoutes(router *gin.RouterGroup, chapterRepo repositories.ChapterRepository, topicRepo repositories.TopicRepository, subjectRepo repositories.SubjectRepository) {
-	// Chapter routes can be added here as needed
-	// For now, most chapter operations are handled through course hierarchy
-}
\ No newline at end of file
+// generateRateLimitMW applies a stricter per-caller quota to the
+// topic-generation endpoints, which call the LLM provider chain.
+func SetupChapterRoutes(router *gin.RouterGroup, chapterService services.ChapterService, topicService services.TopicService, generateRateLimitMW gin.HandlerFunc) {
+	router.GET("/:id", handleGetChapter(chapterService))
+	router.PUT("/:id", handleUpdateChapter(chapterService))
+	router.DELETE("/:id", handleDeleteChapter(chapterService))
+	router.GET("/:id/topics", handleGetChapterTopics(topicService))
+	router.POST("/:id/topics", generateRateLimitMW, handleGenerateTopics(topicService))
+	router.POST("/:id/topics/stream", generateRateLimitMW, handleStreamGenerateTopics(topicService))
+	router.POST("/:id/topics/async", generateRateLimitMW, handleGenerateTopicsAsync(topicService))
+}
+
+func handleGetChapter(chapterService services.ChapterService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		chapterID, err := middleware.ParseIDParam(c, "id")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.APIResponse{
+				Success: false,
+				Error:   "invalid chapter ID",
+			})
+			return
+		}
+
+		chapter, err := chapterService.GetChapterByID(chapterID)
+		if err != nil {
+			statusCode := http.StatusInternalServerError
+			if err.Error() == "chapter not found" {
+				statusCode = http.StatusNotFound
+			}
+
+			c.JSON(statusCode, models.APIResponse{
+				Success: false,
+				Error:   err.Error(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, models.APIResponse{
+			Success: true,
+			Data:    chapter.ToDict(),
+		})
+	}
+}
+
+func handleUpdateChapter(chapterService services.ChapterService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		chapterID, err := middleware.ParseIDParam(c, "id")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.APIResponse{
+				Success: false,
+				Error:   "invalid chapter ID",
+			})
+			return
+		}
+
+		var req struct {
+			Name string `json:"name" binding:"required"`
+		}
+
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, models.APIResponse{
+				Success: false,
+				Error:   "invalid request format",
+			})
+			return
+		}
+
+		chapter, err := chapterService.UpdateChapter(chapterID, req.Name)
+		if err != nil {
+			statusCode := http.StatusInternalServerError
+			if err.Error() == "chapter not found" {
+				statusCode = http.StatusNotFound
+			}
+
+			c.JSON(statusCode, models.APIResponse{
+				Success: false,
+				Error:   err.Error(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, models.APIResponse{
+			Success: true,
+			Message: "chapter updated successfully",
+			Data:    chapter.ToDict(),
+		})
+	}
+}
+
+func handleDeleteChapter(chapterService services.ChapterService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		chapterID, err := middleware.ParseIDParam(c, "id")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.APIResponse{
+				Success: false,
+				Error:   "invalid chapter ID",
+			})
+			return
+		}
+
+		if err := chapterService.DeleteChapter(chapterID); err != nil {
+			statusCode := http.StatusInternalServerError
+			if err.Error() == "chapter not found" {
+				statusCode = http.StatusNotFound
+			}
+
+			c.JSON(statusCode, models.APIResponse{
+				Success: false,
+				Error:   err.Error(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, models.APIResponse{
+			Success: true,
+			Message: "chapter deleted successfully",
+		})
+	}
+}
+
+func handleGetChapterTopics(topicService services.TopicService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		chapterID, err := middleware.ParseIDParam(c, "id")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.APIResponse{
+				Success: false,
+				Error:   "invalid chapter ID",
+			})
+			return
+		}
+
+		topics, err := topicService.GetTopicsByChapterID(chapterID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.APIResponse{
+				Success: false,
+				Error:   err.Error(),
+			})
+			return
+		}
+
+		var topicDicts []map[string]interface{}
+		for _, topic := range topics {
+			topicDicts = append(topicDicts, topic.ToDict())
+		}
+
+		c.JSON(http.StatusOK, models.APIResponse{
+			Success: true,
+			Data:    topicDicts,
+		})
+	}
+}
+
+// generateTopicsRequest is the body handleGenerateTopics, handleStreamGenerateTopics,
+// and handleGenerateTopicsAsync bind: the chapter's ancestry isn't derivable
+// from the chapter ID alone, so the caller supplies it the same way
+// handleGenerateChapters requires course_id.
+type generateTopicsRequest struct {
+	CourseID  uint `json:"course_id" binding:"required"`
+	SubjectID uint `json:"subject_id" binding:"required"`
+}
+
+func handleGenerateTopics(topicService services.TopicService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		chapterID, err := middleware.ParseIDParam(c, "id")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.APIResponse{
+				Success: false,
+				Error:   "invalid chapter ID",
+			})
+			return
+		}
+
+		var req generateTopicsRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, models.APIResponse{
+				Success: false,
+				Error:   "invalid request format",
+			})
+			return
+		}
+
+		topics, err := topicService.GenerateTopics(req.CourseID, req.SubjectID, chapterID)
+		if err != nil {
+			statusCode := http.StatusInternalServerError
+			if err.Error() == "chapter not found" || err.Error() == "subject not found" || err.Error() == "course not found" {
+				statusCode = http.StatusNotFound
+			}
+
+			c.JSON(statusCode, models.APIResponse{
+				Success: false,
+				Error:   err.Error(),
+			})
+			return
+		}
+
+		var topicDicts []map[string]interface{}
+		for _, topic := range topics {
+			topicDicts = append(topicDicts, topic.ToDict())
+		}
+
+		c.JSON(http.StatusOK, models.APIResponse{
+			Success: true,
+			Message: "topics generated successfully",
+			Data:    topicDicts,
+		})
+	}
+}
+
+// handleStreamGenerateTopics is handleGenerateTopics' SSE counterpart,
+// following the same progress/item/done framing as
+// handleStreamGenerateChapters in routes/subject_routes.go.
+func handleStreamGenerateTopics(topicService services.TopicService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		chapterID, err := middleware.ParseIDParam(c, "id")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.APIResponse{
+				Success: false,
+				Error:   "invalid chapter ID",
+			})
+			return
+		}
+
+		var req generateTopicsRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, models.APIResponse{
+				Success: false,
+				Error:   "invalid request format",
+			})
+			return
+		}
+
+		c.Header("Content-Type", "text/event-stream")
+		c.Header("Cache-Control", "no-cache")
+		c.Header("Connection", "keep-alive")
+		c.Status(http.StatusOK)
+
+		fmt.Fprintf(c.Writer, "event: progress\ndata: {\"status\":\"generating\"}\n\n")
+		c.Writer.Flush()
+
+		topics, err := topicService.GenerateTopics(req.CourseID, req.SubjectID, chapterID)
+		if err != nil {
+			fmt.Fprintf(c.Writer, "event: error\ndata: %s\n\n", err.Error())
+			c.Writer.Flush()
+			return
+		}
+
+		ids := make([]uint, 0, len(topics))
+		for _, topic := range topics {
+			data, err := json.Marshal(topic.ToDict())
+			if err != nil {
+				fmt.Fprintf(c.Writer, "event: error\ndata: %s\n\n", err.Error())
+				c.Writer.Flush()
+				return
+			}
+			fmt.Fprintf(c.Writer, "event: item\ndata: %s\n\n", data)
+			c.Writer.Flush()
+			ids = append(ids, topic.ID)
+		}
+
+		done, _ := json.Marshal(map[string]interface{}{"topic_ids": ids})
+		fmt.Fprintf(c.Writer, "event: done\ndata: %s\n\n", done)
+		c.Writer.Flush()
+	}
+}
+
+// handleGenerateTopicsAsync queues the same generation handleGenerateTopics
+// performs synchronously and returns the queued job for the caller to poll
+// or watch via the job routes. An Idempotency-Key header makes a retried
+// submission return the original job instead of double-spending on LLM calls.
+func handleGenerateTopicsAsync(topicService services.TopicService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		chapterID, err := middleware.ParseIDParam(c, "id")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.APIResponse{
+				Success: false,
+				Error:   "invalid chapter ID",
+			})
+			return
+		}
+
+		var req generateTopicsRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, models.APIResponse{
+				Success: false,
+				Error:   "invalid request format",
+			})
+			return
+		}
+
+		job, err := topicService.EnqueueGenerateTopicsJob(req.CourseID, req.SubjectID, chapterID, c.GetHeader("Idempotency-Key"))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.APIResponse{
+				Success: false,
+				Error:   err.Error(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusAccepted, models.APIResponse{
+			Success: true,
+			Message: "Topic generation queued",
+			Data:    job.ToDict(),
+		})
+	}
+}
@@ -1,17 +1,36 @@
 package routes
 
 import (
+	"fmt"
 	"go-server/middleware"
 	"go-server/models"
 	"go-server/services"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
-func SetupContentRoutes(router *gin.RouterGroup, contentService services.ContentService) {
-	router.POST("/generate", handleGenerateContent(contentService))
+// contentStreamKeepAlive is how often handleGenerateContentStream sends an
+// SSE comment line while waiting on the next chunk, so intermediaries that
+// time out idle connections (and the frontend's EventSource, which treats a
+// long silence as a dropped connection) see steady traffic during a slow
+// generation.
+const contentStreamKeepAlive = 15 * time.Second
+
+// generateRateLimitMW applies a stricter per-caller quota than the
+// default protected-route limit to every endpoint that calls the LLM
+// provider chain, since each such call is far more expensive than an
+// ordinary CRUD request.
+func SetupContentRoutes(router *gin.RouterGroup, contentService services.ContentService, generateRateLimitMW gin.HandlerFunc) {
+	router.POST("/generate", generateRateLimitMW, handleGenerateContent(contentService))
+	router.POST("/generate/async", generateRateLimitMW, handleGenerateContentAsync(contentService))
+	router.GET("/generate/stream", generateRateLimitMW, handleGenerateContentStream(contentService))
+	router.POST("/generate/chapter/:chapter_id", generateRateLimitMW, handleGenerateContentForChapter(contentService))
+	router.POST("/generate/subject/:subject_id", generateRateLimitMW, handleGenerateContentForSubject(contentService))
 	router.GET("/topic/:topic_id", handleGetContentByTopic(contentService))
+	router.GET("/search", handleSearchContent(contentService))
 	router.PUT("/:id", handleUpdateContent(contentService))
 	router.DELETE("/:id", handleDeleteContent(contentService))
 }
@@ -38,18 +57,7 @@ func handleGenerateContent(contentService services.ContentService) gin.HandlerFu
 
 		content, err := contentService.GenerateContent(userID, &req)
 		if err != nil {
-			statusCode := http.StatusInternalServerError
-			if err.Error() == "course not found" || err.Error() == "topic not found" ||
-				err.Error() == "chapter not found" || err.Error() == "subject not found" {
-				statusCode = http.StatusNotFound
-			} else if err.Error() == "unauthorized access to course" {
-				statusCode = http.StatusForbidden
-			}
-
-			c.JSON(statusCode, models.APIResponse{
-				Success: false,
-				Error:   err.Error(),
-			})
+			c.Error(err)
 			return
 		}
 
@@ -61,6 +69,222 @@ func handleGenerateContent(contentService services.ContentService) gin.HandlerFu
 	}
 }
 
+// handleGenerateContentAsync queues the same generation GenerateContent
+// performs synchronously and returns the queued job for the caller to poll
+// (or watch, via the job routes' /:id/watch SSE endpoint) instead of
+// blocking on a slow LLM call. An Idempotency-Key header makes a retried
+// submission return the original job instead of double-spending on LLM
+// calls.
+func handleGenerateContentAsync(contentService services.ContentService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, err := middleware.GetUserIDFromContext(c)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, models.APIResponse{
+				Success: false,
+				Error:   "unauthorized",
+			})
+			return
+		}
+
+		var req models.ContentGenerateRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, models.APIResponse{
+				Success: false,
+				Error:   "invalid request format",
+			})
+			return
+		}
+
+		job, err := contentService.EnqueueGenerateContentJob(userID, &req, c.GetHeader("Idempotency-Key"))
+		if err != nil {
+			c.Error(err)
+			return
+		}
+
+		c.JSON(http.StatusAccepted, models.APIResponse{
+			Success: true,
+			Message: "Content generation queued",
+			Data:    job.ToDict(),
+		})
+	}
+}
+
+// handleGenerateContentForChapter queues content generation for every topic
+// in a chapter that doesn't already have content, returning a batch handle
+// to poll via GET /batches/:id for aggregate and per-topic progress.
+func handleGenerateContentForChapter(contentService services.ContentService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, err := middleware.GetUserIDFromContext(c)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, models.APIResponse{
+				Success: false,
+				Error:   "unauthorized",
+			})
+			return
+		}
+
+		chapterID, err := middleware.ParseIDParam(c, "chapter_id")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.APIResponse{
+				Success: false,
+				Error:   "invalid chapter ID",
+			})
+			return
+		}
+
+		batch, err := contentService.GenerateContentForChapter(chapterID, userID)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+
+		c.JSON(http.StatusAccepted, models.APIResponse{
+			Success: true,
+			Message: "Content generation queued for chapter",
+			Data:    batch.ToDict(),
+		})
+	}
+}
+
+// handleGenerateContentForSubject is handleGenerateContentForChapter's
+// subject-wide counterpart.
+func handleGenerateContentForSubject(contentService services.ContentService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, err := middleware.GetUserIDFromContext(c)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, models.APIResponse{
+				Success: false,
+				Error:   "unauthorized",
+			})
+			return
+		}
+
+		subjectID, err := middleware.ParseIDParam(c, "subject_id")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.APIResponse{
+				Success: false,
+				Error:   "invalid subject ID",
+			})
+			return
+		}
+
+		batch, err := contentService.GenerateContentForSubject(subjectID, userID)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+
+		c.JSON(http.StatusAccepted, models.APIResponse{
+			Success: true,
+			Message: "Content generation queued for subject",
+			Data:    batch.ToDict(),
+		})
+	}
+}
+
+// handleGenerateContentStream is GenerateContent's streaming equivalent: it
+// streams generated content to the browser as server-sent events so the
+// frontend can render tokens as they arrive instead of waiting on a single
+// blocking response. It takes a GET with query parameters, rather than the
+// POST body handleGenerateContent uses, because the EventSource API the
+// frontend uses to consume SSE only supports GET requests without a body.
+// c.Request.Context() is passed through to ContentService.GenerateContentStream
+// so that if the client disconnects, the in-flight LLM call is canceled
+// instead of running to completion with no one reading its output; while
+// waiting on the next chunk, a keep-alive comment is sent every
+// contentStreamKeepAlive so the connection doesn't look idle to the client
+// or any intermediary proxy during a slow generation.
+func handleGenerateContentStream(contentService services.ContentService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, err := middleware.GetUserIDFromContext(c)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, models.APIResponse{
+				Success: false,
+				Error:   "unauthorized",
+			})
+			return
+		}
+
+		req, err := parseContentGenerateQuery(c)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.APIResponse{
+				Success: false,
+				Error:   "invalid request parameters",
+			})
+			return
+		}
+
+		chunks, errs := contentService.GenerateContentStream(c.Request.Context(), userID, req)
+
+		c.Header("Content-Type", "text/event-stream")
+		c.Header("Cache-Control", "no-cache")
+		c.Header("Connection", "keep-alive")
+		c.Status(http.StatusOK)
+
+		keepAlive := time.NewTicker(contentStreamKeepAlive)
+		defer keepAlive.Stop()
+
+	streamLoop:
+		for {
+			select {
+			case chunk, ok := <-chunks:
+				if !ok {
+					break streamLoop
+				}
+				fmt.Fprintf(c.Writer, "event: chunk\ndata: %s\n\n", chunk)
+				c.Writer.Flush()
+			case <-keepAlive.C:
+				fmt.Fprint(c.Writer, ": keep-alive\n\n")
+				c.Writer.Flush()
+			case <-c.Request.Context().Done():
+				return
+			}
+		}
+
+		if err := <-errs; err != nil {
+			fmt.Fprintf(c.Writer, "event: error\ndata: %s\n\n", err.Error())
+			c.Writer.Flush()
+			return
+		}
+
+		fmt.Fprint(c.Writer, "event: done\ndata: {}\n\n")
+		c.Writer.Flush()
+	}
+}
+
+// parseContentGenerateQuery builds a models.ContentGenerateRequest from
+// query parameters, since handleGenerateContentStream's GET can't carry a
+// JSON body the way handleGenerateContent's POST does.
+func parseContentGenerateQuery(c *gin.Context) (*models.ContentGenerateRequest, error) {
+	courseID, err := strconv.ParseUint(c.Query("course_id"), 10, 32)
+	if err != nil {
+		return nil, err
+	}
+	subjectID, err := strconv.ParseUint(c.Query("subject_id"), 10, 32)
+	if err != nil {
+		return nil, err
+	}
+	chapterID, err := strconv.ParseUint(c.Query("chapter_id"), 10, 32)
+	if err != nil {
+		return nil, err
+	}
+	topicID, err := strconv.ParseUint(c.Query("topic_id"), 10, 32)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.ContentGenerateRequest{
+		CourseID:  uint(courseID),
+		SubjectID: uint(subjectID),
+		ChapterID: uint(chapterID),
+		TopicID:   uint(topicID),
+	}, nil
+}
+
+// handleGetContentByTopic returns the single Content row for a topic (the
+// schema enforces one content per topic), so it is left as a direct fetch
+// rather than wired to models.ListQuery/writePaginationHeaders, which exist
+// to page over collections.
 func handleGetContentByTopic(contentService services.ContentService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		userID, err := middleware.GetUserIDFromContext(c)
@@ -83,21 +307,57 @@ func handleGetContentByTopic(contentService services.ContentService) gin.Handler
 
 		content, err := contentService.GetContentByTopicID(topicID, userID)
 		if err != nil {
-			statusCode := http.StatusNotFound
-			if err.Error() == "unauthorized access to content" {
-				statusCode = http.StatusForbidden
-			}
+			c.Error(err)
+			return
+		}
+
+		c.JSON(http.StatusOK, models.APIResponse{
+			Success: true,
+			Data:    content.ToDict(),
+		})
+	}
+}
 
-			c.JSON(statusCode, models.APIResponse{
+// handleSearchContent embeds the "q" query parameter and returns the
+// top-k topics (with their content) across courses the caller owns, by
+// cosine similarity. "k" defaults to ContentService.SearchContent's own
+// default when absent or unparseable.
+func handleSearchContent(contentService services.ContentService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, err := middleware.GetUserIDFromContext(c)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, models.APIResponse{
 				Success: false,
-				Error:   err.Error(),
+				Error:   "unauthorized",
 			})
 			return
 		}
 
+		query := c.Query("q")
+		if query == "" {
+			c.JSON(http.StatusBadRequest, models.APIResponse{
+				Success: false,
+				Error:   "q is required",
+			})
+			return
+		}
+
+		k, _ := strconv.Atoi(c.Query("k"))
+
+		results, err := contentService.SearchContent(userID, query, k)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+
+		matches := make([]map[string]interface{}, len(results))
+		for i, content := range results {
+			matches[i] = content.ToDict()
+		}
+
 		c.JSON(http.StatusOK, models.APIResponse{
 			Success: true,
-			Data:    content.ToDict(),
+			Data:    matches,
 		})
 	}
 }
@@ -136,17 +396,7 @@ func handleUpdateContent(contentService services.ContentService) gin.HandlerFunc
 
 		content, err := contentService.UpdateContent(contentID, userID, req.Content)
 		if err != nil {
-			statusCode := http.StatusBadRequest
-			if err.Error() == "content not found" {
-				statusCode = http.StatusNotFound
-			} else if err.Error() == "unauthorized access to content" {
-				statusCode = http.StatusForbidden
-			}
-
-			c.JSON(statusCode, models.APIResponse{
-				Success: false,
-				Error:   err.Error(),
-			})
+			c.Error(err)
 			return
 		}
 
@@ -180,17 +430,7 @@ func handleDeleteContent(contentService services.ContentService) gin.HandlerFunc
 
 		err = contentService.DeleteContent(contentID, userID)
 		if err != nil {
-			statusCode := http.StatusBadRequest
-			if err.Error() == "content not found" {
-				statusCode = http.StatusNotFound
-			} else if err.Error() == "unauthorized access to content" {
-				statusCode = http.StatusForbidden
-			}
-
-			c.JSON(statusCode, models.APIResponse{
-				Success: false,
-				Error:   err.Error(),
-			})
+			c.Error(err)
 			return
 		}
 
@@ -199,4 +439,4 @@ func handleDeleteContent(contentService services.ContentService) gin.HandlerFunc
 			Message: "content deleted successfully",
 		})
 	}
-}
\ No newline at end of file
+}
@@ -1,26 +1,44 @@
 package routes
 
 import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
 	"go-server/middleware"
 	"go-server/models"
 	"go-server/repositories"
 	"go-server/services"
+	"io"
 	"net/http"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
 )
 
-func SetupCourseRoutes(router *gin.RouterGroup, courseService services.CourseService, subjectRepo repositories.SubjectRepository, chapterRepo repositories.ChapterRepository, topicRepo repositories.TopicRepository) {
-	router.POST("", handleCreateCourse(courseService))
+// SetupCourseRoutes mounts course CRUD and generation endpoints.
+// idempotencyMW is applied to the mutating (POST/PUT/DELETE) routes so a
+// retried request with the same Idempotency-Key header replays the
+// original response instead of re-running it; it's skipped on the
+// streaming and already-queue-idempotent async endpoints, which have their
+// own replay semantics. generateRateLimitMW applies a stricter per-caller
+// quota to the subject-generation endpoints, which call the LLM provider
+// chain.
+func SetupCourseRoutes(router *gin.RouterGroup, courseService services.CourseService, subjectRepo repositories.SubjectRepository, imageService services.ImageService, idempotencyMW, generateRateLimitMW gin.HandlerFunc) {
+	router.POST("", idempotencyMW, handleCreateCourse(courseService))
 	router.GET("", handleGetUserCourses(courseService))
 	router.GET("/:id", handleGetCourse(courseService))
-	router.PUT("/:id", handleUpdateCourse(courseService))
-	router.DELETE("/:id", handleDeleteCourse(courseService))
-	router.POST("/:id/subjects", handleGenerateSubjects(courseService))
+	router.PUT("/:id", idempotencyMW, handleUpdateCourse(courseService))
+	router.DELETE("/:id", idempotencyMW, handleDeleteCourse(courseService))
+	router.POST("/:id/subjects", idempotencyMW, generateRateLimitMW, handleGenerateSubjects(courseService))
+	router.POST("/:id/subjects/async", generateRateLimitMW, handleGenerateSubjectsAsync(courseService))
+	router.GET("/:id/subjects/stream", generateRateLimitMW, handleStreamGenerateSubjects(courseService))
 	router.GET("/:id/subjects", handleGetCourseSubjects(subjectRepo))
-	router.POST("/:id/subjects/bulk", handleCreateSubjects(subjectRepo, courseService))
-	router.GET("/:id/hierarchy", handleGetCourseHierarchy(courseService, subjectRepo, chapterRepo, topicRepo))
+	router.POST("/:id/subjects/bulk", idempotencyMW, handleCreateSubjects(subjectRepo, courseService))
+	router.GET("/:id/hierarchy", handleGetCourseHierarchy(courseService))
+	router.GET("/:id/export", handleExportCourse(courseService))
+	router.POST("/import", idempotencyMW, handleImportCourse(courseService))
+	router.GET("/:id/image/stream", handleStreamCourseImage(imageService))
 }
 
 func handleCreateCourse(courseService services.CourseService) gin.HandlerFunc {
@@ -71,15 +89,23 @@ func handleGetUserCourses(courseService services.CourseService) gin.HandlerFunc
 			return
 		}
 
-		courses, err := courseService.GetUserCourses(userID)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, models.APIResponse{
+		var q models.ListQuery
+		if err := c.ShouldBindQuery(&q); err != nil {
+			c.JSON(http.StatusBadRequest, models.APIResponse{
 				Success: false,
-				Error:   err.Error(),
+				Error:   "invalid query parameters",
 			})
 			return
 		}
 
+		courses, total, err := courseService.GetUserCoursesPaginated(userID, &q)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+
+		writePaginationHeaders(c, &q, total)
+
 		// Convert to dict format
 		var coursesData []map[string]interface{}
 		for _, course := range courses {
@@ -280,6 +306,109 @@ func handleGenerateSubjects(courseService services.CourseService) gin.HandlerFun
 	}
 }
 
+// handleStreamGenerateSubjects is handleGenerateSubjects' SSE counterpart:
+// it emits an "event: progress" frame while CourseService.GenerateSubjects
+// is running, one "event: item" frame per generated subject, and a final
+// "event: done" frame carrying the persisted subjects' IDs. CourseService's
+// LLM call still returns the whole list at once (utils.LLMProvider streams
+// only topic content, not subject lists), so "item" frames are emitted as soon as generation
+// returns rather than as each name is produced, but the client still gets
+// incremental progress instead of a single request blocking for the full
+// duration of a large course's generation.
+func handleStreamGenerateSubjects(courseService services.CourseService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, err := middleware.GetUserIDFromContext(c)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, models.APIResponse{
+				Success: false,
+				Error:   "unauthorized",
+			})
+			return
+		}
+
+		courseID, err := middleware.ParseIDParam(c, "id")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.APIResponse{
+				Success: false,
+				Error:   "invalid course ID",
+			})
+			return
+		}
+
+		c.Header("Content-Type", "text/event-stream")
+		c.Header("Cache-Control", "no-cache")
+		c.Header("Connection", "keep-alive")
+		c.Status(http.StatusOK)
+
+		fmt.Fprintf(c.Writer, "event: progress\ndata: {\"status\":\"generating\"}\n\n")
+		c.Writer.Flush()
+
+		subjects, err := courseService.GenerateSubjects(courseID, userID)
+		if err != nil {
+			fmt.Fprintf(c.Writer, "event: error\ndata: %s\n\n", err.Error())
+			c.Writer.Flush()
+			return
+		}
+
+		ids := make([]uint, 0, len(subjects))
+		for _, subject := range subjects {
+			data, err := json.Marshal(subject.ToDict())
+			if err != nil {
+				fmt.Fprintf(c.Writer, "event: error\ndata: %s\n\n", err.Error())
+				c.Writer.Flush()
+				return
+			}
+			fmt.Fprintf(c.Writer, "event: item\ndata: %s\n\n", data)
+			c.Writer.Flush()
+			ids = append(ids, subject.ID)
+		}
+
+		done, _ := json.Marshal(map[string]interface{}{"subject_ids": ids})
+		fmt.Fprintf(c.Writer, "event: done\ndata: %s\n\n", done)
+		c.Writer.Flush()
+	}
+}
+
+// handleGenerateSubjectsAsync queues the same generation handleGenerateSubjects
+// performs synchronously and returns the queued job for the caller to poll
+// or watch via the job routes, the same pattern content_routes.go's
+// handleGenerateContentAsync uses. An Idempotency-Key header makes a
+// retried submission return the original job instead of double-spending on
+// LLM calls.
+func handleGenerateSubjectsAsync(courseService services.CourseService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, err := middleware.GetUserIDFromContext(c)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, models.APIResponse{
+				Success: false,
+				Error:   "unauthorized",
+			})
+			return
+		}
+
+		courseID, err := middleware.ParseIDParam(c, "id")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.APIResponse{
+				Success: false,
+				Error:   "invalid course ID",
+			})
+			return
+		}
+
+		job, err := courseService.EnqueueGenerateSubjectsJob(courseID, userID, c.GetHeader("Idempotency-Key"))
+		if err != nil {
+			c.Error(err)
+			return
+		}
+
+		c.JSON(http.StatusAccepted, models.APIResponse{
+			Success: true,
+			Message: "Subject generation queued",
+			Data:    job.ToDict(),
+		})
+	}
+}
+
 func handleGetCourseSubjects(subjectRepo repositories.SubjectRepository) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		courseID, err := middleware.ParseIDParam(c, "id")
@@ -291,7 +420,16 @@ func handleGetCourseSubjects(subjectRepo repositories.SubjectRepository) gin.Han
 			return
 		}
 
-		subjects, err := subjectRepo.GetSubjectsByCourseID(courseID)
+		var q models.ListQuery
+		if err := c.ShouldBindQuery(&q); err != nil {
+			c.JSON(http.StatusBadRequest, models.APIResponse{
+				Success: false,
+				Error:   "invalid query parameters",
+			})
+			return
+		}
+
+		subjects, total, err := subjectRepo.QuerySubjectsByCourseID(courseID, &q)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, models.APIResponse{
 				Success: false,
@@ -300,6 +438,8 @@ func handleGetCourseSubjects(subjectRepo repositories.SubjectRepository) gin.Han
 			return
 		}
 
+		writePaginationHeaders(c, &q, total)
+
 		// Convert to dict format
 		var subjectsData []map[string]interface{}
 		for _, subject := range subjects {
@@ -357,16 +497,10 @@ func handleCreateSubjects(subjectRepo repositories.SubjectRepository, courseServ
 			return
 		}
 
-		// Create subjects
-		var subjects []models.Subject
-		for _, name := range req.Subjects {
-			subjects = append(subjects, models.Subject{
-				Name:     name,
-				CourseID: courseID,
-			})
-		}
-
-		err = subjectRepo.CreateSubjects(subjects)
+		// Create only the subjects that don't already exist for this
+		// course (case-insensitively); a retried submission of the same
+		// names is a no-op rather than a duplicate batch.
+		results, err := subjectRepo.CreateOrGetSubjects(courseID, req.Subjects)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, models.APIResponse{
 				Success: false,
@@ -375,10 +509,14 @@ func handleCreateSubjects(subjectRepo repositories.SubjectRepository, courseServ
 			return
 		}
 
-		// Convert to dict format
-		var subjectsData []map[string]interface{}
-		for _, subject := range subjects {
-			subjectsData = append(subjectsData, subject.ToDict())
+		// Convert to dict format, tagging each with whether it was just
+		// created so the frontend can distinguish new subjects from ones
+		// that already existed under this course.
+		subjectsData := make([]map[string]interface{}, 0, len(results))
+		for _, result := range results {
+			data := result.Subject.ToDict()
+			data["created"] = result.Created
+			subjectsData = append(subjectsData, data)
 		}
 
 		c.JSON(http.StatusCreated, models.APIResponse{
@@ -389,7 +527,11 @@ func handleCreateSubjects(subjectRepo repositories.SubjectRepository, courseServ
 	}
 }
 
-func handleGetCourseHierarchy(courseService services.CourseService, subjectRepo repositories.SubjectRepository, chapterRepo repositories.ChapterRepository, topicRepo repositories.TopicRepository) gin.HandlerFunc {
+// handleGetCourseHierarchy serves the course's full subject/chapter/topic
+// tree from courseService.GetHierarchy's batched queries, and honors
+// If-None-Match against the computed ETag so an unchanged client request
+// costs a 304 instead of re-querying and re-serializing the whole tree.
+func handleGetCourseHierarchy(courseService services.CourseService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		userID, err := middleware.GetUserIDFromContext(c)
 		if err != nil {
@@ -409,8 +551,7 @@ func handleGetCourseHierarchy(courseService services.CourseService, subjectRepo
 			return
 		}
 
-		// Verify course ownership
-		course, err := courseService.GetCourseByID(courseID, userID)
+		hierarchy, err := courseService.GetHierarchy(courseID, userID)
 		if err != nil {
 			statusCode := http.StatusNotFound
 			if err.Error() == "unauthorized access to course" {
@@ -424,55 +565,128 @@ func handleGetCourseHierarchy(courseService services.CourseService, subjectRepo
 			return
 		}
 
-		// Get subjects
-		subjects, err := subjectRepo.GetSubjectsByCourseID(courseID)
+		c.Header("Cache-Control", "private, max-age=0, must-revalidate")
+		c.Header("ETag", hierarchy.ETag)
+
+		if c.GetHeader("If-None-Match") == hierarchy.ETag {
+			c.Status(http.StatusNotModified)
+			return
+		}
+
+		c.JSON(http.StatusOK, models.APIResponse{
+			Success: true,
+			Data:    hierarchy.Data,
+		})
+	}
+}
+
+// handleExportCourse streams the course tree as a ZIP archive directly to the
+// response, the way photoprism streams album ZIPs, so no temp file is needed.
+func handleExportCourse(courseService services.CourseService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, err := middleware.GetUserIDFromContext(c)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, models.APIResponse{
+			c.JSON(http.StatusUnauthorized, models.APIResponse{
 				Success: false,
-				Error:   "failed to get subjects",
+				Error:   "unauthorized",
 			})
 			return
 		}
 
-		// Build hierarchy
-		hierarchy := make(map[string]interface{})
-		hierarchy["course"] = course.ToDict()
-		hierarchy["subjects"] = make([]map[string]interface{}, 0)
+		courseID, err := middleware.ParseIDParam(c, "id")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.APIResponse{
+				Success: false,
+				Error:   "invalid course ID",
+			})
+			return
+		}
 
-		for _, subject := range subjects {
-			subjectData := subject.ToDict()
+		c.Header("Content-Type", "application/zip")
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="course-%d.zip"`, courseID))
 
-			// Get chapters for this subject
-			chapters, err := chapterRepo.GetChaptersBySubjectID(subject.ID)
-			if err != nil {
-				continue
-			}
+		if err := courseService.ExportCourse(courseID, userID, c.Writer); err != nil {
+			c.Error(err)
+			return
+		}
+	}
+}
 
-			subjectData["chapters"] = make([]map[string]interface{}, 0)
-			for _, chapter := range chapters {
-				chapterData := chapter.ToDict()
+// handleImportCourse accepts a ZIP archive in the same layout produced by
+// handleExportCourse for cross-instance course transfer.
+func handleImportCourse(courseService services.CourseService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, err := middleware.GetUserIDFromContext(c)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, models.APIResponse{
+				Success: false,
+				Error:   "unauthorized",
+			})
+			return
+		}
 
-				// Get topics for this chapter
-				topics, err := topicRepo.GetTopicsByChapterID(chapter.ID)
-				if err != nil {
-					continue
-				}
+		file, _, err := c.Request.FormFile("archive")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.APIResponse{
+				Success: false,
+				Error:   "archive file is required",
+			})
+			return
+		}
+		defer file.Close()
 
-				var topicsData []map[string]interface{}
-				for _, topic := range topics {
-					topicsData = append(topicsData, topic.ToDict())
-				}
-				chapterData["topics"] = topicsData
+		data, err := io.ReadAll(file)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.APIResponse{
+				Success: false,
+				Error:   "failed to read archive",
+			})
+			return
+		}
 
-				subjectData["chapters"] = append(subjectData["chapters"].([]map[string]interface{}), chapterData)
-			}
+		zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.APIResponse{
+				Success: false,
+				Error:   "invalid zip archive",
+			})
+			return
+		}
 
-			hierarchy["subjects"] = append(hierarchy["subjects"].([]map[string]interface{}), subjectData)
+		course, err := courseService.ImportCourse(userID, zr)
+		if err != nil {
+			c.Error(err)
+			return
 		}
 
-		c.JSON(http.StatusOK, models.APIResponse{
+		c.JSON(http.StatusCreated, models.APIResponse{
 			Success: true,
-			Data:    hierarchy,
+			Message: "course imported successfully",
+			Data:    course.ToDict(),
 		})
 	}
-}
\ No newline at end of file
+}
+
+// handleStreamCourseImage is GenerateCourseImage's streaming equivalent: it
+// writes newline-delimited JSON progress frames (see utils.JSONStreamWriter)
+// as generation proceeds instead of blocking until a single JSON response is
+// ready, so the frontend can render a live progress bar.
+func handleStreamCourseImage(imageService services.ImageService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		courseID, err := middleware.ParseIDParam(c, "id")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.APIResponse{
+				Success: false,
+				Error:   "invalid course ID",
+			})
+			return
+		}
+
+		c.Header("Content-Type", "application/x-ndjson")
+		c.Status(http.StatusOK)
+
+		// Errors are already reported to the client as an errorDetail frame
+		// by GenerateCourseImageStream, so there's nothing left to do here.
+		_ = imageService.GenerateCourseImageStream(courseID, c.Writer)
+	}
+}
@@ -0,0 +1,45 @@
+package routes
+
+import (
+	"net/http"
+
+	"go-server/models"
+	"go-server/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupDigestRoutes mounts the unauthenticated unsubscribe link embedded in
+// every digest email. It's on authGroup alongside the OAuth callbacks: the
+// token in the query string, not a session or JWT, authorizes the request.
+func SetupDigestRoutes(router *gin.RouterGroup, digestService services.DigestService) {
+	router.GET("/digest/unsubscribe", handleDigestUnsubscribe(digestService))
+}
+
+// handleDigestUnsubscribe redeems the token query param and opts its owning
+// user out of all future digests.
+func handleDigestUnsubscribe(digestService services.DigestService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := c.Query("token")
+		if token == "" {
+			c.JSON(http.StatusBadRequest, models.APIResponse{
+				Success: false,
+				Error:   "missing token",
+			})
+			return
+		}
+
+		if err := digestService.Unsubscribe(token); err != nil {
+			c.JSON(http.StatusBadRequest, models.APIResponse{
+				Success: false,
+				Error:   "invalid or expired unsubscribe link",
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, models.APIResponse{
+			Success: true,
+			Message: "you have been unsubscribed from digest emails",
+		})
+	}
+}
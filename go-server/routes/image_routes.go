@@ -9,10 +9,20 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-func SetupImageRoutes(router *gin.RouterGroup, imageService services.ImageService) {
-	router.POST("/courses/:id/generate", handleGenerateCourseImage(imageService))
-	router.POST("/subjects/:id/generate", handleGenerateSubjectImage(imageService))
-	router.POST("/custom", handleGenerateCustomImage(imageService))
+// generateRateLimitMW applies a stricter per-caller quota to every
+// image-generation endpoint, since each call burns an image provider quota.
+func SetupImageRoutes(router *gin.RouterGroup, imageService services.ImageService, generateRateLimitMW gin.HandlerFunc) {
+	router.POST("/courses/:id/generate", generateRateLimitMW, handleGenerateCourseImage(imageService))
+	router.POST("/subjects/:id/generate", generateRateLimitMW, handleGenerateSubjectImage(imageService))
+	router.POST("/custom", generateRateLimitMW, handleGenerateCustomImage(imageService))
+
+	// Async counterparts: same generation work, but queued onto the job
+	// worker pool and returning immediately with a job ID to poll instead of
+	// blocking until the image is ready.
+	router.POST("/courses/:id/generate/async", generateRateLimitMW, handleGenerateCourseImageAsync(imageService))
+	router.POST("/subjects/:id/generate/async", generateRateLimitMW, handleGenerateSubjectImageAsync(imageService))
+	router.POST("/custom/async", generateRateLimitMW, handleGenerateCustomImageAsync(imageService))
+
 	router.GET("/list/:prefix", handleListImages(imageService))
 	router.DELETE("/:path", handleDeleteImage(imageService))
 }
@@ -30,16 +40,7 @@ func handleGenerateCourseImage(imageService services.ImageService) gin.HandlerFu
 
 		imageURL, err := imageService.GenerateCourseImage(courseID)
 		if err != nil {
-			statusCode := http.StatusInternalServerError
-			if err.Error() == "course not found" {
-				statusCode = http.StatusNotFound
-			}
-
-			c.JSON(statusCode, models.APIResponse{
-				Success: false,
-				Message: "Failed to generate course image",
-				Error:   err.Error(),
-			})
+			c.Error(err)
 			return
 		}
 
@@ -67,16 +68,7 @@ func handleGenerateSubjectImage(imageService services.ImageService) gin.HandlerF
 
 		imageURL, err := imageService.GenerateSubjectImage(subjectID)
 		if err != nil {
-			statusCode := http.StatusInternalServerError
-			if err.Error() == "subject not found" {
-				statusCode = http.StatusNotFound
-			}
-
-			c.JSON(statusCode, models.APIResponse{
-				Success: false,
-				Message: "Failed to generate subject image",
-				Error:   err.Error(),
-			})
+			c.Error(err)
 			return
 		}
 
@@ -107,11 +99,7 @@ func handleGenerateCustomImage(imageService services.ImageService) gin.HandlerFu
 
 		imageURL, err := imageService.GenerateCustomImage(req.Prompt)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, models.APIResponse{
-				Success: false,
-				Message: "Failed to generate custom image",
-				Error:   err.Error(),
-			})
+			c.Error(err)
 			return
 		}
 
@@ -126,26 +114,131 @@ func handleGenerateCustomImage(imageService services.ImageService) gin.HandlerFu
 	}
 }
 
+func handleGenerateCourseImageAsync(imageService services.ImageService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		courseID, err := middleware.ParseIDParam(c, "id")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.APIResponse{
+				Success: false,
+				Error:   "invalid course ID",
+			})
+			return
+		}
+
+		job, err := imageService.EnqueueCourseImageJob(courseID, c.GetHeader("Idempotency-Key"))
+		if err != nil {
+			c.Error(err)
+			return
+		}
+
+		c.JSON(http.StatusAccepted, models.APIResponse{
+			Success: true,
+			Message: "Course image generation queued",
+			Data:    job.ToDict(),
+		})
+	}
+}
+
+func handleGenerateSubjectImageAsync(imageService services.ImageService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		subjectID, err := middleware.ParseIDParam(c, "id")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.APIResponse{
+				Success: false,
+				Error:   "invalid subject ID",
+			})
+			return
+		}
+
+		job, err := imageService.EnqueueSubjectImageJob(subjectID, c.GetHeader("Idempotency-Key"))
+		if err != nil {
+			c.Error(err)
+			return
+		}
+
+		c.JSON(http.StatusAccepted, models.APIResponse{
+			Success: true,
+			Message: "Subject image generation queued",
+			Data:    job.ToDict(),
+		})
+	}
+}
+
+func handleGenerateCustomImageAsync(imageService services.ImageService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req struct {
+			Prompt string `json:"prompt" binding:"required"`
+		}
+
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, models.APIResponse{
+				Success: false,
+				Error:   "invalid request format",
+			})
+			return
+		}
+
+		job, err := imageService.EnqueueCustomImageJob(req.Prompt, c.GetHeader("Idempotency-Key"))
+		if err != nil {
+			c.Error(err)
+			return
+		}
+
+		c.JSON(http.StatusAccepted, models.APIResponse{
+			Success: true,
+			Message: "Custom image generation queued",
+			Data:    job.ToDict(),
+		})
+	}
+}
+
+// handleListImages lists images under a prefix. Azure Blob Storage's listing
+// API used by ImageService.ListImages has no server-side offset/limit, so
+// pagination is applied in memory over the full listing; X-Total-Count still
+// reflects the true total before that slice.
 func handleListImages(imageService services.ImageService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		prefix := c.Param("prefix")
 
-		images, err := imageService.ListImages(prefix)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, models.APIResponse{
+		var q models.ListQuery
+		if err := c.ShouldBindQuery(&q); err != nil {
+			c.JSON(http.StatusBadRequest, models.APIResponse{
 				Success: false,
-				Message: "Failed to list images",
-				Error:   err.Error(),
+				Error:   "invalid query parameters",
 			})
 			return
 		}
 
+		images, err := imageService.ListImages(prefix)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+
+		total := int64(len(images))
+		limit := q.Count
+		if limit <= 0 {
+			limit = defaultPageLimit
+		}
+		page := images
+		if q.Offset < len(images) {
+			end := q.Offset + limit
+			if end > len(images) {
+				end = len(images)
+			}
+			page = images[q.Offset:end]
+		} else {
+			page = []string{}
+		}
+
+		writePaginationHeaders(c, &q, total)
+
 		c.JSON(http.StatusOK, models.APIResponse{
 			Success: true,
 			Message: "Images retrieved successfully",
 			Data: map[string]interface{}{
-				"images": images,
-				"count":  len(images),
+				"images": page,
+				"count":  len(page),
 				"prefix": prefix,
 			},
 		})
@@ -157,11 +250,7 @@ func handleDeleteImage(imageService services.ImageService) gin.HandlerFunc {
 		imagePath := c.Param("path")
 
 		if err := imageService.DeleteImage(imagePath); err != nil {
-			c.JSON(http.StatusInternalServerError, models.APIResponse{
-				Success: false,
-				Message: "Failed to delete image",
-				Error:   err.Error(),
-			})
+			c.Error(err)
 			return
 		}
 
@@ -170,4 +259,4 @@ func handleDeleteImage(imageService services.ImageService) gin.HandlerFunc {
 			Message: "Image deleted successfully",
 		})
 	}
-}
\ No newline at end of file
+}
@@ -0,0 +1,111 @@
+package routes
+
+import (
+	"encoding/json"
+	"fmt"
+	"go-server/middleware"
+	"go-server/models"
+	"go-server/repositories"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// jobWatchPollInterval is how often handleWatchJob re-checks a job's status
+// while streaming it to the client.
+const jobWatchPollInterval = 1 * time.Second
+
+// SetupJobRoutes mounts the read-only endpoints clients use to learn the
+// outcome of work they queued through an async endpoint, e.g. the image and
+// content routes' /generate/async variants: GET /:id for a single poll, GET
+// /:id/watch for an SSE stream of status changes.
+func SetupJobRoutes(router *gin.RouterGroup, jobRepo repositories.JobRepository) {
+	router.GET("/:id", handleGetJob(jobRepo))
+	router.GET("/:id/watch", handleWatchJob(jobRepo))
+}
+
+func handleGetJob(jobRepo repositories.JobRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		jobID, err := middleware.ParseIDParam(c, "id")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.APIResponse{
+				Success: false,
+				Error:   "invalid job ID",
+			})
+			return
+		}
+
+		var job models.Job
+		if err := jobRepo.GetByID(jobID, &job); err != nil {
+			c.Error(err)
+			return
+		}
+
+		c.JSON(http.StatusOK, models.APIResponse{
+			Success: true,
+			Message: "Job retrieved successfully",
+			Data:    job.ToDict(),
+		})
+	}
+}
+
+// handleWatchJob streams a job's status as server-sent events, re-checking
+// it every jobWatchPollInterval and emitting a frame only when its status or
+// progress changes, until the job reaches a terminal status or the client
+// disconnects. This
+// spares a client the choice between slow polling and a single blocking
+// request for long-running work like content or image generation.
+func handleWatchJob(jobRepo repositories.JobRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		jobID, err := middleware.ParseIDParam(c, "id")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.APIResponse{
+				Success: false,
+				Error:   "invalid job ID",
+			})
+			return
+		}
+
+		c.Header("Content-Type", "text/event-stream")
+		c.Header("Cache-Control", "no-cache")
+		c.Header("Connection", "keep-alive")
+		c.Status(http.StatusOK)
+
+		ticker := time.NewTicker(jobWatchPollInterval)
+		defer ticker.Stop()
+
+		var lastStatus, lastProgress string
+		for {
+			var job models.Job
+			if err := jobRepo.GetByID(jobID, &job); err != nil {
+				fmt.Fprintf(c.Writer, "event: error\ndata: %s\n\n", err.Error())
+				c.Writer.Flush()
+				return
+			}
+
+			if job.Status != lastStatus || job.Progress != lastProgress {
+				data, err := json.Marshal(job.ToDict())
+				if err != nil {
+					fmt.Fprintf(c.Writer, "event: error\ndata: %s\n\n", err.Error())
+					c.Writer.Flush()
+					return
+				}
+				fmt.Fprintf(c.Writer, "event: status\ndata: %s\n\n", data)
+				c.Writer.Flush()
+				lastStatus = job.Status
+				lastProgress = job.Progress
+			}
+
+			if job.Status == models.JobStatusCompleted || job.Status == models.JobStatusFailed {
+				return
+			}
+
+			select {
+			case <-c.Request.Context().Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}
+}
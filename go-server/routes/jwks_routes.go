@@ -0,0 +1,27 @@
+package routes
+
+import (
+	"net/http"
+
+	"go-server/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupJWKSRoutes mounts the public JWKS endpoint directly on router, outside
+// the /api group, so other services can fetch it the same way they would
+// fetch any other well-known URI.
+func SetupJWKSRoutes(router *gin.Engine, keyManager *utils.KeyManager) {
+	router.GET("/.well-known/jwks.json", handleJWKS(keyManager))
+}
+
+func handleJWKS(keyManager *utils.KeyManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		jwks, err := keyManager.JWKS()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load signing keys"})
+			return
+		}
+		c.JSON(http.StatusOK, jwks)
+	}
+}
@@ -0,0 +1,169 @@
+package routes
+
+import (
+	"crypto/hmac"
+	"go-server/config"
+	"go-server/models"
+	"go-server/services"
+	"go-server/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// pkceVerifierCookieName holds the PKCE code_verifier handleOAuthAuthorize
+// generated, for handleOAuthCallback to read back on the redirect; it's
+// short-lived and httpOnly since only the backend ever needs it.
+const pkceVerifierCookieName = "cw_oauth_pkce_verifier"
+
+// pkceVerifierCookieMaxAge bounds how long a pending authorization-code
+// flow stays redeemable, matching the state value's own TTL.
+const pkceVerifierCookieMaxAge = 10 * 60
+
+// oauthStateCookieName holds the state value handleOAuthAuthorize issued,
+// for handleOAuthCallback to compare against req.State as a double-submit
+// check. The state is also returned in handleOAuthAuthorize's JSON
+// response (so the frontend can pass it back as req.State), which on its
+// own proves nothing about which browser is redeeming it; binding it to an
+// httpOnly cookie the way the PKCE verifier already is ties the flow to
+// the browser that started it, closing the login-CSRF gap a bare signed
+// state leaves open — including for GitHub, which has no PKCE verifier to
+// provide that binding otherwise.
+const oauthStateCookieName = "cw_oauth_state"
+
+// oauthStateCookieMaxAge matches the state value's own TTL.
+const oauthStateCookieMaxAge = 10 * 60
+
+// SetupOAuthRoutes mounts the social login endpoints under /auth/oauth,
+// alongside SetupAuthRoutes' password-based ones.
+func SetupOAuthRoutes(router *gin.RouterGroup, oauthService services.OAuthService, cfg *config.Config) {
+	router.GET("/oauth/:provider/authorize", handleOAuthAuthorize(oauthService, cfg))
+	router.POST("/oauth/:provider/login", handleOAuthLogin(oauthService))
+	router.POST("/oauth/:provider/callback", handleOAuthCallback(oauthService, cfg))
+}
+
+// handleOAuthAuthorize issues a signed state value (and, for PKCE-capable
+// providers, a code_verifier/code_challenge pair) and returns provider's
+// authorization endpoint URL for the frontend to redirect the browser to.
+func handleOAuthAuthorize(oauthService services.OAuthService, cfg *config.Config) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		provider := c.Param("provider")
+
+		state, err := utils.GenerateOAuthState(cfg.SecretKey)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.APIResponse{
+				Success: false,
+				Error:   "failed to start oauth flow",
+			})
+			return
+		}
+		c.SetCookie(oauthStateCookieName, state, oauthStateCookieMaxAge, "/", "", cfg.Environment == "production", true)
+
+		var codeChallenge string
+		if provider != "github" {
+			verifier, challenge, err := utils.GeneratePKCEVerifier()
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, models.APIResponse{
+					Success: false,
+					Error:   "failed to start oauth flow",
+				})
+				return
+			}
+			codeChallenge = challenge
+			c.SetCookie(pkceVerifierCookieName, verifier, pkceVerifierCookieMaxAge, "/", "", cfg.Environment == "production", true)
+		}
+
+		authorizationURL, err := oauthService.AuthorizeURL(provider, state, codeChallenge)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.APIResponse{
+				Success: false,
+				Error:   err.Error(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, models.APIResponse{
+			Success: true,
+			Data: gin.H{
+				"authorization_url": authorizationURL,
+				"state":             state,
+			},
+		})
+	}
+}
+
+func handleOAuthLogin(oauthService services.OAuthService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req models.OAuthLoginRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, models.APIResponse{
+				Success: false,
+				Error:   "invalid request format",
+			})
+			return
+		}
+
+		response, err := oauthService.Login(c.Param("provider"), &req, c.Request.UserAgent(), c.ClientIP())
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, models.APIResponse{
+				Success: false,
+				Error:   err.Error(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, models.APIResponse{
+			Success: true,
+			Message: "login successful",
+			Data:    response,
+		})
+	}
+}
+
+// handleOAuthCallback validates req.State against the one
+// handleOAuthAuthorize issued (both its signature/expiry and, via the
+// state cookie, that it's being redeemed by the same browser that started
+// the flow), then exchanges req.Code (plus the PKCE verifier stashed in a
+// cookie by handleOAuthAuthorize, if any) for a session.
+func handleOAuthCallback(oauthService services.OAuthService, cfg *config.Config) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req models.OAuthCallbackRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, models.APIResponse{
+				Success: false,
+				Error:   "invalid request format",
+			})
+			return
+		}
+
+		stateCookie, cookieErr := c.Cookie(oauthStateCookieName)
+		c.SetCookie(oauthStateCookieName, "", -1, "/", "", cfg.Environment == "production", true)
+
+		stateMatches := stateCookie != "" && hmac.Equal([]byte(stateCookie), []byte(req.State))
+		if cookieErr != nil || !stateMatches || !utils.ValidateOAuthState(cfg.SecretKey, req.State) {
+			c.JSON(http.StatusUnauthorized, models.APIResponse{
+				Success: false,
+				Error:   "invalid or expired oauth state",
+			})
+			return
+		}
+
+		codeVerifier, _ := c.Cookie(pkceVerifierCookieName)
+		c.SetCookie(pkceVerifierCookieName, "", -1, "/", "", cfg.Environment == "production", true)
+
+		response, err := oauthService.Callback(c.Param("provider"), &req, codeVerifier, c.Request.UserAgent(), c.ClientIP())
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, models.APIResponse{
+				Success: false,
+				Error:   err.Error(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, models.APIResponse{
+			Success: true,
+			Message: "login successful",
+			Data:    response,
+		})
+	}
+}
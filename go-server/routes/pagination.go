@@ -0,0 +1,60 @@
+package routes
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"go-server/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+const defaultPageLimit = 20
+
+// writePaginationHeaders sets X-Total-Count, X-Limit, X-Offset, and an RFC
+// 5988 Link header (rel="next"/rel="prev") describing the page of total rows
+// q selected. Every paginated list handler calls this after a successful
+// BaseRepositoryImpl.Query.
+func writePaginationHeaders(c *gin.Context, q *models.ListQuery, total int64) {
+	limit := q.Count
+	if limit <= 0 {
+		limit = defaultPageLimit
+	}
+
+	c.Header("X-Total-Count", strconv.FormatInt(total, 10))
+	c.Header("X-Limit", strconv.Itoa(limit))
+	c.Header("X-Offset", strconv.Itoa(q.Offset))
+
+	var links []string
+	if nextOffset := q.Offset + limit; int64(nextOffset) < total {
+		links = append(links, fmt.Sprintf(`<%s>; rel="next"`, pageURL(c, limit, nextOffset)))
+	}
+	if q.Offset > 0 {
+		prevOffset := q.Offset - limit
+		if prevOffset < 0 {
+			prevOffset = 0
+		}
+		links = append(links, fmt.Sprintf(`<%s>; rel="prev"`, pageURL(c, limit, prevOffset)))
+	}
+	if len(links) > 0 {
+		c.Header("Link", strings.Join(links, ", "))
+	}
+}
+
+// pageURL rebuilds the current request URL with count/offset replaced,
+// preserving every other query parameter (sort, order, q, filters).
+func pageURL(c *gin.Context, limit, offset int) string {
+	query := c.Request.URL.Query()
+	query.Set("count", strconv.Itoa(limit))
+	query.Set("offset", strconv.Itoa(offset))
+
+	u := *c.Request.URL
+	u.RawQuery = query.Encode()
+
+	scheme := "http"
+	if c.Request.TLS != nil {
+		scheme = "https"
+	}
+	return fmt.Sprintf("%s://%s%s", scheme, c.Request.Host, u.String())
+}
@@ -6,15 +6,24 @@ import (
 	"go-server/services"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 )
 
-func SetupPublicRoutes(router *gin.RouterGroup, courseService services.CourseService, testimonialRepo repositories.TestimonialRepository) {
+func SetupPublicRoutes(router *gin.RouterGroup, courseService services.CourseService, testimonialRepo repositories.TestimonialRepository, searchService services.SearchService) {
 	router.GET("/courses/search", handleSearchCourses(courseService))
 	router.GET("/testimonials", handleGetApprovedTestimonials(testimonialRepo))
+	router.GET("/search", handleSearch(searchService))
 }
 
+// handleSearchCourses optionally narrows the search to one user's courses
+// (user_id) and/or by whether subjects have been generated yet
+// (has_subjects), in addition to the required full-text query q. fields, a
+// comma-separated subset of "name,description", restricts which columns
+// snippets are drawn from; facet, currently only "has_subjects", adds a
+// facet_counts breakdown of the full (unpaginated) match set to the
+// response.
 func handleSearchCourses(courseService services.CourseService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		query := c.Query("q")
@@ -32,7 +41,32 @@ func handleSearchCourses(courseService services.CourseService) gin.HandlerFunc {
 			limit = 10
 		}
 
-		courses, err := courseService.SearchCourses(query, limit)
+		offset, err := strconv.Atoi(c.Query("offset"))
+		if err != nil || offset < 0 {
+			offset = 0
+		}
+
+		var filter repositories.CourseSearchFilter
+		if userIDStr := c.Query("user_id"); userIDStr != "" {
+			if userID, err := strconv.ParseUint(userIDStr, 10, 32); err == nil {
+				uid := uint(userID)
+				filter.UserID = &uid
+			}
+		}
+		if hasSubjectsStr := c.Query("has_subjects"); hasSubjectsStr != "" {
+			if hasSubjects, err := strconv.ParseBool(hasSubjectsStr); err == nil {
+				filter.HasSubjects = &hasSubjects
+			}
+		}
+		if fieldsStr := c.Query("fields"); fieldsStr != "" {
+			for _, field := range strings.Split(fieldsStr, ",") {
+				if field = strings.TrimSpace(field); field != "" {
+					filter.Fields = append(filter.Fields, field)
+				}
+			}
+		}
+
+		results, total, err := courseService.SearchCourses(query, filter, offset, limit)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, models.APIResponse{
 				Success: false,
@@ -41,15 +75,70 @@ func handleSearchCourses(courseService services.CourseService) gin.HandlerFunc {
 			return
 		}
 
-		// Convert to dict format
-		var coursesData []map[string]interface{}
-		for _, course := range courses {
-			coursesData = append(coursesData, course.ToDict())
+		coursesData := make([]map[string]interface{}, len(results))
+		for i, result := range results {
+			data := result.Course.ToDict()
+			data["snippet"] = result.Snippet
+			coursesData[i] = data
+		}
+
+		var meta map[string]interface{}
+		if facet := c.Query("facet"); facet != "" {
+			facetCounts, err := courseService.SearchCourseFacets(query, filter, facet)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, models.APIResponse{
+					Success: false,
+					Error:   err.Error(),
+				})
+				return
+			}
+			meta = map[string]interface{}{"facet_counts": facetCounts}
 		}
 
+		writePaginationHeaders(c, &models.ListQuery{Offset: offset, Count: limit}, total)
 		c.JSON(http.StatusOK, models.APIResponse{
 			Success: true,
 			Data:    coursesData,
+			Meta:    meta,
+		})
+	}
+}
+
+// handleSearch is the cross-entity counterpart to handleSearchCourses: it
+// full-text searches a single entity table named by the required type
+// query parameter (course, subject, chapter, or topic) and paginates with
+// an opaque cursor instead of offset/limit, since deep offset pagination
+// over a FULLTEXT-ranked result set degrades with page depth.
+func handleSearch(searchService services.SearchService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		entityType := c.Query("type")
+		query := c.Query("q")
+		if entityType == "" || query == "" {
+			c.JSON(http.StatusBadRequest, models.APIResponse{
+				Success: false,
+				Error:   "type and q are required",
+			})
+			return
+		}
+
+		limit, err := strconv.Atoi(c.Query("limit"))
+		if err != nil {
+			limit = 0
+		}
+
+		items, nextCursor, total, err := searchService.Search(entityType, query, c.Query("cursor"), limit)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.APIResponse{
+				Success: false,
+				Error:   err.Error(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, models.PaginatedResponse{
+			Items:      items,
+			Total:      total,
+			NextCursor: nextCursor,
 		})
 	}
 }
@@ -76,4 +165,4 @@ func handleGetApprovedTestimonials(testimonialRepo repositories.TestimonialRepos
 			Data:    testimonialsData,
 		})
 	}
-}
\ No newline at end of file
+}
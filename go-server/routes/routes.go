@@ -1,17 +1,54 @@
 package routes
 
 import (
+	"context"
 	"go-server/config"
+	"go-server/jobs"
 	"go-server/middleware"
+	"go-server/ratelimit"
 	"go-server/repositories"
 	"go-server/services"
+	"go-server/storage"
+	"go-server/utils"
 
+	"github.com/gin-contrib/sessions"
 	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
 	"gorm.io/gorm"
 )
 
-// SetupRoutes initializes all routes
-func SetupRoutes(router *gin.RouterGroup, db *gorm.DB, cfg *config.Config) {
+// backgroundJobWorkers is the number of goroutines draining the shared
+// background job queue (image and content generation); both are I/O-bound
+// against external providers, so a small pool is enough to keep several
+// requests in flight without hammering provider rate limits.
+const backgroundJobWorkers = 4
+
+// SetupRoutes initializes all routes. It returns the KeyManager signing
+// tokens in RS256/ES256 mode (nil when JWT_SIGNING_ALGORITHM is the default
+// HS256), so main.go can mount the public JWKS endpoint against the same
+// instance used here to sign and verify tokens, and the DigestService so
+// main.go can drive services.DigestService.RunDigest on a ticker.
+func SetupRoutes(router *gin.RouterGroup, db *gorm.DB, cfg *config.Config) (*utils.KeyManager, services.DigestService) {
+	// Tags every request with a correlation ID before anything else runs, so
+	// ErrorHandler/apierror.Abort can include it in error bodies.
+	router.Use(middleware.RequestIDMiddleware())
+
+	// Renders any error handlers push with c.Error(err) as a models.APIResponse
+	router.Use(middleware.ErrorHandler())
+
+	// In session or hybrid auth mode, mount the session store and CSRF check
+	// ahead of everything else so auth/login can start a session and every
+	// protected route can read it.
+	sessionBacked := cfg.Auth.Mode == "session" || cfg.Auth.Mode == "hybrid"
+	if sessionBacked {
+		store, err := middleware.NewSessionStore(cfg)
+		if err != nil {
+			logrus.Fatalf("Failed to initialize session store: %v", err)
+		}
+		router.Use(sessions.Sessions("cw_session", store))
+		router.Use(middleware.CSRFMiddleware(cfg))
+	}
+
 	// Initialize repositories
 	userRepo := repositories.NewUserRepository(db)
 	courseRepo := repositories.NewCourseRepository(db)
@@ -20,37 +57,111 @@ func SetupRoutes(router *gin.RouterGroup, db *gorm.DB, cfg *config.Config) {
 	topicRepo := repositories.NewTopicRepository(db)
 	contentRepo := repositories.NewContentRepository(db)
 	testimonialRepo := repositories.NewTestimonialRepository(db)
+	jobRepo := repositories.NewJobRepository(db)
+	batchRepo := repositories.NewBatchRepository(db)
+	apiKeyRepo := repositories.NewAPIKeyRepository(db)
+	refreshTokenRepo := repositories.NewRefreshTokenRepository(db)
+	roleRepo := repositories.NewRoleRepository(db)
+	signingKeyRepo := repositories.NewSigningKeyRepository(db)
+	contentEmbeddingRepo := repositories.NewContentEmbeddingRepository(db)
+	searchRepo := repositories.NewSearchRepository(db)
+	idempotencyKeyRepo := repositories.NewIdempotencyKeyRepository(db)
+	tokenRepo := repositories.NewTokenRepository(db)
+	userTOTPRepo := repositories.NewUserTOTPRepository(db)
+	totpRecoveryCodeRepo := repositories.NewTOTPRecoveryCodeRepository(db)
+	identityRepo := repositories.NewUserIdentityRepository(db)
+	notificationPreferenceRepo := repositories.NewNotificationPreferenceRepository(db)
+	digestEventRepo := repositories.NewDigestEventRepository(db)
+	digestStateRepo := repositories.NewUserDigestStateRepository(db)
+	imageAssetRepo := repositories.NewImageAssetRepository(db)
 
 	// Initialize services
 	emailService := services.NewEmailService()
-	authService := services.NewAuthService(userRepo, cfg, emailService)
-	courseService := services.NewCourseService(courseRepo, subjectRepo)
-	subjectService := services.NewSubjectService(subjectRepo, courseRepo)
-	chapterService := services.NewChapterService(chapterRepo, subjectRepo, courseRepo)
-	topicService := services.NewTopicService(topicRepo, chapterRepo, subjectRepo, courseRepo)
-	contentService := services.NewContentService(contentRepo, topicRepo, chapterRepo, subjectRepo, courseRepo)
+	telegramService := services.NewTelegramService(tokenRepo, userRepo, cfg)
+	notificationService := services.NewNotificationService(
+		[]services.NotificationTransport{
+			services.NewMailgunTransport(emailService),
+			services.NewTelegramTransport(cfg),
+		},
+		notificationPreferenceRepo,
+	)
+	digestService := services.NewDigestService(userRepo, digestEventRepo, digestStateRepo, tokenRepo, emailService, cfg)
+
+	var jwtUtil *utils.JWTUtil
+	var keyManager *utils.KeyManager
+	if cfg.JWTSigningAlgorithm == "RS256" || cfg.JWTSigningAlgorithm == "ES256" {
+		keyManager = utils.NewKeyManager(signingKeyRepo, cfg.JWTSigningAlgorithm)
+		if err := keyManager.EnsureActiveKey(); err != nil {
+			logrus.Fatalf("Failed to provision signing key: %v", err)
+		}
+		jwtUtil = utils.NewJWTUtilWithKeyManager(keyManager, cfg.JWTAccessTokenExpires, cfg.JWTRefreshTokenExpires)
+	} else {
+		jwtUtil = utils.NewJWTUtil(cfg.JWTSecretKey, cfg.JWTAccessTokenExpires, cfg.JWTRefreshTokenExpires)
+	}
+
+	sessionCache := utils.NewSessionRevocationCache()
+	rateLimitStore := ratelimit.NewStore(cfg)
+	tokenService := services.NewTokenService(refreshTokenRepo, userRepo, roleRepo, userTOTPRepo, jwtUtil, sessionCache)
+	authService := services.NewAuthService(userRepo, roleRepo, tokenRepo, userTOTPRepo, totpRecoveryCodeRepo, tokenService, cfg, emailService, notificationService, rateLimitStore)
+	oauthService := services.NewOAuthService(userRepo, identityRepo, tokenService, cfg)
+	apiKeyService := services.NewAPIKeyService(apiKeyRepo)
+	storageBackend, err := storage.NewBackend(cfg)
+	if err != nil {
+		logrus.Fatalf("Failed to initialize storage backend: %v", err)
+	}
+	backgroundJobQueue := jobs.NewQueue(jobRepo, backgroundJobWorkers)
+	imageService := services.NewImageService(courseRepo, subjectRepo, imageAssetRepo, storageBackend, backgroundJobQueue, cfg)
+	llmProvider := services.NewLLMProviderChain(cfg)
+	contentService := services.NewContentService(contentRepo, topicRepo, chapterRepo, subjectRepo, courseRepo, batchRepo, contentEmbeddingRepo, digestEventRepo, llmProvider, backgroundJobQueue, cfg)
+	backgroundJobQueue.Start(context.Background())
+	courseService := services.NewCourseService(courseRepo, subjectRepo, chapterRepo, topicRepo, contentRepo, imageService, llmProvider, backgroundJobQueue, cfg.MaxConcurrentContentGenerationsPerUser)
+	subjectService := services.NewSubjectService(subjectRepo, courseRepo, llmProvider, digestEventRepo)
+	chapterService := services.NewChapterService(chapterRepo, subjectRepo, courseRepo, llmProvider, backgroundJobQueue, digestEventRepo)
+	topicService := services.NewTopicService(topicRepo, chapterRepo, subjectRepo, courseRepo, llmProvider, backgroundJobQueue)
 	testimonialService := services.NewTestimonialService(testimonialRepo, userRepo)
-	imageService := services.NewImageService(courseRepo, subjectRepo)
+	searchService := services.NewSearchService(searchRepo)
+	idempotencyMW := middleware.IdempotencyMiddleware(idempotencyKeyRepo)
+	rateLimitMW := middleware.RateLimitMiddleware(rateLimitStore)
+
+	apiLimiterStore := ratelimit.NewLimiterStore(cfg)
+	defaultRateLimitMW := middleware.APIRateLimitMiddleware(apiLimiterStore, middleware.DefaultAPIRateLimitPolicy, "default")
+	generateRateLimitMW := middleware.APIRateLimitMiddleware(apiLimiterStore, middleware.GenerateRateLimitPolicy, "generate")
 
 	// Auth routes (no auth required)
 	authGroup := router.Group("/auth")
-	SetupAuthRoutes(authGroup, authService)
+	SetupAuthRoutes(authGroup, authService, cfg, idempotencyMW, rateLimitMW)
+	SetupOAuthRoutes(authGroup, oauthService, cfg)
+	SetupTelegramWebhookRoutes(authGroup, telegramService, cfg)
+	SetupDigestRoutes(authGroup, digestService)
+
+	// Protected routes (auth required). The auth mode picks which middleware
+	// enforces it: jwt (default) requires a bearer token or a signed
+	// request, session requires only the cw_session cookie, hybrid accepts
+	// either of those.
+	var protectedAuth gin.HandlerFunc
+	switch cfg.Auth.Mode {
+	case "session":
+		protectedAuth = middleware.SessionAuthMiddleware(cfg, roleRepo)
+	case "hybrid":
+		protectedAuth = middleware.HybridAuthMiddleware(cfg, roleRepo, sessionCache)
+	default:
+		protectedAuth = middleware.AuthOrHTTPSignatureMiddleware(cfg, apiKeyRepo, userRepo, roleRepo, sessionCache)
+	}
 
-	// Protected routes (auth required)
 	protected := router.Group("/")
-	protected.Use(middleware.AuthMiddleware(cfg))
+	protected.Use(protectedAuth, defaultRateLimitMW)
 	{
 		// Course routes
-		SetupCourseRoutes(protected.Group("/courses"), courseService, subjectService, chapterService, topicService)
+		SetupCourseRoutes(protected.Group("/courses"), courseService, subjectRepo, imageService, idempotencyMW, generateRateLimitMW)
 
-		// Content routes  
-		SetupContentRoutes(protected.Group("/content"), contentService)
+		// Content routes
+		SetupContentRoutes(protected.Group("/content"), contentService, generateRateLimitMW)
 
 		// Subject routes
-		SetupSubjectRoutes(protected.Group("/subjects"), subjectService, chapterService)
+		SetupSubjectRoutes(protected.Group("/subjects"), subjectService, chapterService, idempotencyMW, generateRateLimitMW)
 
 		// Chapter routes
-		SetupChapterRoutes(protected.Group("/chapters"), chapterService, topicService)
+		SetupChapterRoutes(protected.Group("/chapters"), chapterService, topicService, generateRateLimitMW)
 
 		// Topic routes
 		SetupTopicRoutes(protected.Group("/topics"), topicService)
@@ -58,11 +169,34 @@ func SetupRoutes(router *gin.RouterGroup, db *gorm.DB, cfg *config.Config) {
 		// User profile routes
 		SetupUserRoutes(protected.Group("/users"), authService)
 
+		// Two-factor authentication management (enroll/activate/disable)
+		SetupTOTPRoutes(protected.Group("/auth"), authService, idempotencyMW)
+
+		// Telegram account-linking PIN issuance
+		SetupTelegramRoutes(protected.Group("/auth"), telegramService)
+
 		// Testimonial routes
-		SetupTestimonialRoutes(protected.Group("/testimonials"), testimonialService)
+		SetupTestimonialRoutes(protected.Group("/testimonials"), testimonialService, idempotencyMW)
 
 		// Image routes
-		SetupImageRoutes(protected.Group("/images"), imageService)
+		SetupImageRoutes(protected.Group("/images"), imageService, generateRateLimitMW)
+
+		// Job status routes, for polling or watching work queued through the
+		// image and content routes' async endpoints
+		SetupJobRoutes(protected.Group("/jobs"), jobRepo)
+
+		// Batch status routes, for polling the aggregate and per-topic
+		// progress of a "generate all content" request queued through the
+		// content routes' /generate/chapter and /generate/subject endpoints
+		SetupBatchRoutes(protected.Group("/batches"), contentService)
+
+		// API key registration, for clients that authenticate via
+		// middleware.HTTPSignatureMiddleware instead of a JWT
+		SetupAPIKeyRoutes(protected.Group("/keys"), apiKeyService)
+
+		// Admin-only role grant/revoke/list and signing key rotation, gated by
+		// middleware.RequireRole("admin")
+		SetupAdminRoutes(protected.Group("/admin"), authService, contentService, emailService, keyManager)
 	}
 
 	// Public routes (optional auth)
@@ -70,6 +204,8 @@ func SetupRoutes(router *gin.RouterGroup, db *gorm.DB, cfg *config.Config) {
 	public.Use(middleware.OptionalAuthMiddleware(cfg))
 	{
 		// Public course search
-		SetupPublicRoutes(public, courseService, testimonialRepo)
+		SetupPublicRoutes(public, courseService, testimonialRepo, searchService)
 	}
-}
\ No newline at end of file
+
+	return keyManager, digestService
+}
@@ -1,6 +1,8 @@
 package routes
 
 import (
+	"encoding/json"
+	"fmt"
 	"go-server/middleware"
 	"go-server/models"
 	"go-server/services"
@@ -9,12 +11,19 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-func SetupSubjectRoutes(router *gin.RouterGroup, subjectService services.SubjectService, chapterService services.ChapterService) {
+// SetupSubjectRoutes mounts subject CRUD and chapter-generation endpoints.
+// idempotencyMW guards the mutating routes, mirroring SetupCourseRoutes;
+// it's skipped on the streaming and queue-idempotent async endpoints.
+// generateRateLimitMW applies a stricter per-caller quota to the
+// chapter-generation endpoints, which call the LLM provider chain.
+func SetupSubjectRoutes(router *gin.RouterGroup, subjectService services.SubjectService, chapterService services.ChapterService, idempotencyMW, generateRateLimitMW gin.HandlerFunc) {
 	router.GET("/:id", handleGetSubject(subjectService))
-	router.PUT("/:id", handleUpdateSubject(subjectService))
-	router.DELETE("/:id", handleDeleteSubject(subjectService))
+	router.PUT("/:id", idempotencyMW, handleUpdateSubject(subjectService))
+	router.DELETE("/:id", idempotencyMW, handleDeleteSubject(subjectService))
 	router.GET("/:id/chapters", handleGetSubjectChapters(chapterService))
-	router.POST("/:id/chapters", handleGenerateChapters(chapterService))
+	router.POST("/:id/chapters", idempotencyMW, generateRateLimitMW, handleGenerateChapters(chapterService))
+	router.POST("/:id/chapters/stream", generateRateLimitMW, handleStreamGenerateChapters(chapterService))
+	router.POST("/:id/chapters/async", generateRateLimitMW, handleGenerateChaptersAsync(chapterService))
 }
 
 func handleGetSubject(subjectService services.SubjectService) gin.HandlerFunc {
@@ -205,4 +214,108 @@ func handleGenerateChapters(chapterService services.ChapterService) gin.HandlerF
 			Data:    chapterDicts,
 		})
 	}
-}
\ No newline at end of file
+}
+
+// handleStreamGenerateChapters is handleGenerateChapters' SSE counterpart,
+// following the same progress/item/done framing as
+// handleStreamGenerateSubjects in routes/course_routes.go.
+func handleStreamGenerateChapters(chapterService services.ChapterService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		subjectID, err := middleware.ParseIDParam(c, "id")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.APIResponse{
+				Success: false,
+				Error:   "invalid subject ID",
+			})
+			return
+		}
+
+		var req struct {
+			CourseID uint `json:"course_id" binding:"required"`
+		}
+
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, models.APIResponse{
+				Success: false,
+				Error:   "invalid request format",
+			})
+			return
+		}
+
+		c.Header("Content-Type", "text/event-stream")
+		c.Header("Cache-Control", "no-cache")
+		c.Header("Connection", "keep-alive")
+		c.Status(http.StatusOK)
+
+		fmt.Fprintf(c.Writer, "event: progress\ndata: {\"status\":\"generating\"}\n\n")
+		c.Writer.Flush()
+
+		chapters, err := chapterService.GenerateChapters(req.CourseID, subjectID)
+		if err != nil {
+			fmt.Fprintf(c.Writer, "event: error\ndata: %s\n\n", err.Error())
+			c.Writer.Flush()
+			return
+		}
+
+		ids := make([]uint, 0, len(chapters))
+		for _, chapter := range chapters {
+			data, err := json.Marshal(chapter.ToDict())
+			if err != nil {
+				fmt.Fprintf(c.Writer, "event: error\ndata: %s\n\n", err.Error())
+				c.Writer.Flush()
+				return
+			}
+			fmt.Fprintf(c.Writer, "event: item\ndata: %s\n\n", data)
+			c.Writer.Flush()
+			ids = append(ids, chapter.ID)
+		}
+
+		done, _ := json.Marshal(map[string]interface{}{"chapter_ids": ids})
+		fmt.Fprintf(c.Writer, "event: done\ndata: %s\n\n", done)
+		c.Writer.Flush()
+	}
+}
+
+// handleGenerateChaptersAsync queues the same generation handleGenerateChapters
+// performs synchronously and returns the queued job for the caller to poll
+// or watch via the job routes. An Idempotency-Key header makes a retried
+// submission return the original job instead of double-spending on LLM calls.
+func handleGenerateChaptersAsync(chapterService services.ChapterService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		subjectID, err := middleware.ParseIDParam(c, "id")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.APIResponse{
+				Success: false,
+				Error:   "invalid subject ID",
+			})
+			return
+		}
+
+		var req struct {
+			CourseID uint `json:"course_id" binding:"required"`
+		}
+
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, models.APIResponse{
+				Success: false,
+				Error:   "invalid request format",
+			})
+			return
+		}
+
+		job, err := chapterService.EnqueueGenerateChaptersJob(req.CourseID, subjectID, c.GetHeader("Idempotency-Key"))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.APIResponse{
+				Success: false,
+				Error:   err.Error(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusAccepted, models.APIResponse{
+			Success: true,
+			Message: "Chapter generation queued",
+			Data:    job.ToDict(),
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package routes
+
+import (
+	"io"
+	"net/http"
+
+	"go-server/config"
+	"go-server/middleware"
+	"go-server/models"
+	"go-server/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// SetupTelegramRoutes mounts the authenticated endpoint a signed-in user
+// hits to start linking their account to Telegram.
+func SetupTelegramRoutes(router *gin.RouterGroup, telegramService services.TelegramService) {
+	router.POST("/link-telegram", handleLinkTelegram(telegramService))
+}
+
+// handleLinkTelegram issues a short-lived PIN the user sends to the bot to
+// finish linking; the actual link happens in handleTelegramWebhook once the
+// bot relays that message back to us.
+func handleLinkTelegram(telegramService services.TelegramService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, err := middleware.GetUserIDFromContext(c)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, models.APIResponse{
+				Success: false,
+				Error:   "unauthorized",
+			})
+			return
+		}
+
+		pin, err := telegramService.GenerateLinkPIN(userID)
+		if err != nil {
+			logrus.Errorf("Error generating telegram link PIN: %v", err)
+			c.JSON(http.StatusInternalServerError, models.APIResponse{
+				Success: false,
+				Error:   "failed to start telegram linking",
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, models.APIResponse{
+			Success: true,
+			Message: "send this code to the bot from the Telegram chat you want linked",
+			Data:    map[string]interface{}{"pin": pin},
+		})
+	}
+}
+
+// SetupTelegramWebhookRoutes mounts the unauthenticated endpoint Telegram
+// posts updates to. It's on authGroup alongside the OAuth callbacks: no JWT
+// is possible here, and authenticity is instead checked against
+// cfg.Telegram.WebhookSecret.
+func SetupTelegramWebhookRoutes(router *gin.RouterGroup, telegramService services.TelegramService, cfg *config.Config) {
+	router.POST("/telegram/webhook", handleTelegramWebhook(telegramService, cfg))
+}
+
+// handleTelegramWebhook verifies the request came from Telegram (when
+// WebhookSecret is configured) and hands its body to TelegramService. It
+// always returns 200 once parsed so Telegram doesn't retry a message whose
+// PIN simply didn't match.
+func handleTelegramWebhook(telegramService services.TelegramService, cfg *config.Config) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if cfg.Telegram.WebhookSecret != "" && c.GetHeader("X-Telegram-Bot-Api-Secret-Token") != cfg.Telegram.WebhookSecret {
+			c.JSON(http.StatusUnauthorized, models.APIResponse{
+				Success: false,
+				Error:   "invalid webhook secret",
+			})
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.APIResponse{
+				Success: false,
+				Error:   "failed to read request body",
+			})
+			return
+		}
+
+		if err := telegramService.HandleUpdate(body); err != nil {
+			logrus.Errorf("Error handling telegram update: %v", err)
+		}
+
+		c.JSON(http.StatusOK, models.APIResponse{Success: true})
+	}
+}
@@ -0,0 +1,282 @@
+package routes
+
+import (
+	"go-server/middleware"
+	"go-server/models"
+	"go-server/services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupTestimonialRoutes mounts testimonial CRUD plus the approval
+// endpoint. TestimonialServiceImpl enforces the testimonial:approve scope
+// itself, but RequireScope aborts the request earlier, before any
+// read/parse work happens. idempotencyMW guards the mutating routes,
+// mirroring SetupCourseRoutes.
+func SetupTestimonialRoutes(router *gin.RouterGroup, testimonialService services.TestimonialService, idempotencyMW gin.HandlerFunc) {
+	router.POST("", idempotencyMW, handleCreateTestimonial(testimonialService))
+	router.GET("/mine", handleGetUserTestimonials(testimonialService))
+	router.GET("/all", handleGetAllTestimonials(testimonialService))
+	router.GET("/:id", handleGetTestimonial(testimonialService))
+	router.PUT("/:id", idempotencyMW, handleUpdateTestimonial(testimonialService))
+	router.DELETE("/:id", idempotencyMW, handleDeleteTestimonial(testimonialService))
+	router.PUT("/:id/approve", middleware.RequireScope("testimonial:approve"), idempotencyMW, handleApproveTestimonial(testimonialService))
+}
+
+func handleCreateTestimonial(testimonialService services.TestimonialService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, err := middleware.GetUserIDFromContext(c)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, models.APIResponse{
+				Success: false,
+				Error:   "unauthorized",
+			})
+			return
+		}
+
+		var req models.TestimonialCreateRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, models.APIResponse{
+				Success: false,
+				Error:   "invalid request body",
+			})
+			return
+		}
+
+		testimonial, err := testimonialService.CreateTestimonial(userID, &req)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.APIResponse{
+				Success: false,
+				Error:   err.Error(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusCreated, models.APIResponse{
+			Success: true,
+			Message: "Testimonial submitted for approval",
+			Data:    testimonial.ToDict(),
+		})
+	}
+}
+
+func handleGetUserTestimonials(testimonialService services.TestimonialService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, err := middleware.GetUserIDFromContext(c)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, models.APIResponse{
+				Success: false,
+				Error:   "unauthorized",
+			})
+			return
+		}
+
+		testimonials, err := testimonialService.GetUserTestimonials(userID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.APIResponse{
+				Success: false,
+				Error:   err.Error(),
+			})
+			return
+		}
+
+		var testimonialsData []map[string]interface{}
+		for _, testimonial := range testimonials {
+			testimonialsData = append(testimonialsData, testimonial.ToDict())
+		}
+
+		c.JSON(http.StatusOK, models.APIResponse{
+			Success: true,
+			Data:    testimonialsData,
+		})
+	}
+}
+
+func handleGetAllTestimonials(testimonialService services.TestimonialService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		testimonials, err := testimonialService.GetAllTestimonials()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.APIResponse{
+				Success: false,
+				Error:   err.Error(),
+			})
+			return
+		}
+
+		var testimonialsData []map[string]interface{}
+		for _, testimonial := range testimonials {
+			testimonialsData = append(testimonialsData, testimonial.ToDict())
+		}
+
+		c.JSON(http.StatusOK, models.APIResponse{
+			Success: true,
+			Data:    testimonialsData,
+		})
+	}
+}
+
+func handleGetTestimonial(testimonialService services.TestimonialService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		testimonialID, err := middleware.ParseIDParam(c, "id")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.APIResponse{
+				Success: false,
+				Error:   "invalid testimonial ID",
+			})
+			return
+		}
+
+		testimonial, err := testimonialService.GetTestimonialByID(testimonialID)
+		if err != nil {
+			c.JSON(http.StatusNotFound, models.APIResponse{
+				Success: false,
+				Error:   err.Error(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, models.APIResponse{
+			Success: true,
+			Data:    testimonial.ToDict(),
+		})
+	}
+}
+
+func handleUpdateTestimonial(testimonialService services.TestimonialService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, err := middleware.GetUserIDFromContext(c)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, models.APIResponse{
+				Success: false,
+				Error:   "unauthorized",
+			})
+			return
+		}
+
+		testimonialID, err := middleware.ParseIDParam(c, "id")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.APIResponse{
+				Success: false,
+				Error:   "invalid testimonial ID",
+			})
+			return
+		}
+
+		var req models.TestimonialUpdateRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, models.APIResponse{
+				Success: false,
+				Error:   "invalid request body",
+			})
+			return
+		}
+
+		authCtx, err := middleware.GetAuthContext(c)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, models.APIResponse{
+				Success: false,
+				Error:   "unauthorized",
+			})
+			return
+		}
+
+		testimonial, err := testimonialService.UpdateTestimonial(testimonialID, userID, &req, authCtx)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.APIResponse{
+				Success: false,
+				Error:   err.Error(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, models.APIResponse{
+			Success: true,
+			Message: "Testimonial updated successfully",
+			Data:    testimonial.ToDict(),
+		})
+	}
+}
+
+func handleDeleteTestimonial(testimonialService services.TestimonialService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, err := middleware.GetUserIDFromContext(c)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, models.APIResponse{
+				Success: false,
+				Error:   "unauthorized",
+			})
+			return
+		}
+
+		testimonialID, err := middleware.ParseIDParam(c, "id")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.APIResponse{
+				Success: false,
+				Error:   "invalid testimonial ID",
+			})
+			return
+		}
+
+		if err := testimonialService.DeleteTestimonial(testimonialID, userID); err != nil {
+			c.JSON(http.StatusBadRequest, models.APIResponse{
+				Success: false,
+				Error:   err.Error(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, models.APIResponse{
+			Success: true,
+			Message: "Testimonial deleted successfully",
+		})
+	}
+}
+
+func handleApproveTestimonial(testimonialService services.TestimonialService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		testimonialID, err := middleware.ParseIDParam(c, "id")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.APIResponse{
+				Success: false,
+				Error:   "invalid testimonial ID",
+			})
+			return
+		}
+
+		var req struct {
+			IsApproved bool `json:"is_approved"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, models.APIResponse{
+				Success: false,
+				Error:   "invalid request body",
+			})
+			return
+		}
+
+		authCtx, err := middleware.GetAuthContext(c)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, models.APIResponse{
+				Success: false,
+				Error:   "unauthorized",
+			})
+			return
+		}
+
+		testimonial, err := testimonialService.ApproveTestimonial(testimonialID, req.IsApproved, authCtx)
+		if err != nil {
+			c.JSON(http.StatusForbidden, models.APIResponse{
+				Success: false,
+				Error:   err.Error(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, models.APIResponse{
+			Success: true,
+			Message: "Testimonial approval updated",
+			Data:    testimonial.ToDict(),
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package services
+
+import (
+	"fmt"
+
+	"go-server/apierror"
+	"go-server/models"
+	"go-server/repositories"
+	"go-server/utils"
+)
+
+type APIKeyService interface {
+	// RegisterKey validates and stores publicKeyPEM under keyID as userID's
+	// HTTP Signature credentials, for HTTPSignatureMiddleware to verify
+	// future signed requests against.
+	RegisterKey(userID uint, keyID, publicKeyPEM, algorithm string) (*models.APIKey, error)
+	ListKeys(userID uint) ([]models.APIKey, error)
+}
+
+type APIKeyServiceImpl struct {
+	apiKeyRepo repositories.APIKeyRepository
+}
+
+func NewAPIKeyService(apiKeyRepo repositories.APIKeyRepository) APIKeyService {
+	return &APIKeyServiceImpl{apiKeyRepo: apiKeyRepo}
+}
+
+func (s *APIKeyServiceImpl) RegisterKey(userID uint, keyID, publicKeyPEM, algorithm string) (*models.APIKey, error) {
+	if algorithm != utils.SignatureAlgoRSASHA256 && algorithm != utils.SignatureAlgoEd25519 {
+		return nil, fmt.Errorf("%w", apierror.Validation(fmt.Sprintf("unsupported algorithm %q", algorithm)))
+	}
+	if _, err := utils.ParsePublicKeyPEM(publicKeyPEM); err != nil {
+		return nil, fmt.Errorf("%w", apierror.Validation("invalid public key: "+err.Error()))
+	}
+
+	existing, err := s.apiKeyRepo.GetByKeyID(keyID)
+	if err != nil {
+		return nil, fmt.Errorf("%w", apierror.Internal("failed to check existing key", err))
+	}
+	if existing != nil {
+		return nil, fmt.Errorf("%w", apierror.Conflict("a key with this keyId is already registered"))
+	}
+
+	key := &models.APIKey{
+		UserID:       userID,
+		KeyID:        keyID,
+		PublicKeyPEM: publicKeyPEM,
+		Algorithm:    algorithm,
+	}
+	if err := s.apiKeyRepo.CreateAPIKey(key); err != nil {
+		return nil, fmt.Errorf("%w", apierror.Internal("failed to register key", err))
+	}
+
+	return key, nil
+}
+
+func (s *APIKeyServiceImpl) ListKeys(userID uint) ([]models.APIKey, error) {
+	keys, err := s.apiKeyRepo.GetByUserID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("%w", apierror.Internal("failed to list keys", err))
+	}
+	return keys, nil
+}
@@ -4,31 +4,78 @@ import (
 	"errors"
 	"fmt"
 	"go-server/config"
+	"go-server/metrics"
 	"go-server/models"
+	"go-server/ratelimit"
 	"go-server/repositories"
 	"go-server/utils"
+	"time"
 
 	"github.com/sirupsen/logrus"
 	"gorm.io/gorm"
 )
 
+// totpRecoveryCodeCount is how many one-time recovery codes ActivateTOTP
+// generates, a conventional count (Google/GitHub 2FA both use ten).
+const totpRecoveryCodeCount = 10
+
 type AuthService interface {
 	Register(req *models.UserRegisterRequest) (*models.User, error)
-	Login(req *models.UserLoginRequest) (*models.UserLoginResponse, error)
-	RefreshToken(refreshToken string) (*models.UserLoginResponse, error)
+	Login(req *models.UserLoginRequest, userAgent, ip string) (*models.UserLoginResponse, error)
+	RefreshToken(refreshToken, userAgent, ip string) (*models.UserLoginResponse, error)
+	// Logout revokes refreshToken's session so it can no longer be rotated
+	// or used to mint access tokens, rather than just relying on the
+	// client to discard it.
+	Logout(refreshToken string) error
 	RequestPasswordReset(email string) error
 	ResetPassword(token, newPassword string) error
+	// VerifyEmail redeems a token issued at registration (or by
+	// ResendVerificationEmail) and activates the account it belongs to.
+	VerifyEmail(token string) error
+	// ResendVerificationEmail issues a fresh email-verification token for
+	// email, silently no-oping if the address doesn't exist or is already
+	// verified so callers can't use it to probe which emails are registered.
+	ResendVerificationEmail(email string) error
+	// EnrollTOTP generates a new (not-yet-active) TOTP secret for userID and
+	// returns it alongside an otpauth:// URI for QR rendering. A prior
+	// unconfirmed enrollment is replaced; an already-activated one must be
+	// disabled first.
+	EnrollTOTP(userID uint) (secret, otpauthURI string, err error)
+	// ActivateTOTP confirms a pending enrollment with a code from the
+	// user's authenticator app, turns it on, and returns ten recovery codes
+	// shown to the user exactly once.
+	ActivateTOTP(userID uint, code string) (recoveryCodes []string, err error)
+	// DisableTOTP turns off userID's 2FA after confirming code (a current
+	// TOTP code or an unused recovery code), and deletes the recovery codes.
+	DisableTOTP(userID uint, code string) error
+	// VerifyMFA completes a login that Login paused for a TOTP code: it
+	// redeems mfaToken (issued by Login) and code (a TOTP or recovery code)
+	// and, on success, issues the real token pair.
+	VerifyMFA(mfaToken, code string, userAgent, ip string) (*models.UserLoginResponse, error)
 	GetUserProfile(userID uint) (*models.User, error)
+	RevokeSession(userID uint, sessionID string) error
+	RevokeAllUserSessions(userID uint) error
+	ListActiveSessions(userID uint) ([]models.RefreshToken, error)
+	GrantRole(userID uint, roleName string) error
+	RevokeRole(userID uint, roleName string) error
+	ListUserRoles(userID uint) ([]models.Role, error)
 }
 
 type AuthServiceImpl struct {
-	userRepo    repositories.UserRepository
-	jwtUtil     *utils.JWTUtil
-	config      *config.Config
-	emailService EmailService // We'll create this later
+	userRepo             repositories.UserRepository
+	roleRepo             repositories.RoleRepository
+	tokenRepo            repositories.TokenRepository
+	userTOTPRepo         repositories.UserTOTPRepository
+	totpRecoveryCodeRepo repositories.TOTPRecoveryCodeRepository
+	jwtUtil              *utils.JWTUtil
+	tokenService         TokenService
+	config               *config.Config
+	emailService         EmailService // We'll create this later
+	notificationService  NotificationService
+	rateLimitStore       ratelimit.RateLimitStore
 }
 
-func NewAuthService(userRepo repositories.UserRepository, config *config.Config, emailService EmailService) AuthService {
+func NewAuthService(userRepo repositories.UserRepository, roleRepo repositories.RoleRepository, tokenRepo repositories.TokenRepository, userTOTPRepo repositories.UserTOTPRepository, totpRecoveryCodeRepo repositories.TOTPRecoveryCodeRepository, tokenService TokenService, config *config.Config, emailService EmailService, notificationService NotificationService, rateLimitStore ratelimit.RateLimitStore) AuthService {
 	jwtUtil := utils.NewJWTUtil(
 		config.JWTSecretKey,
 		config.JWTAccessTokenExpires,
@@ -36,10 +83,17 @@ func NewAuthService(userRepo repositories.UserRepository, config *config.Config,
 	)
 
 	return &AuthServiceImpl{
-		userRepo:     userRepo,
-		jwtUtil:      jwtUtil,
-		config:       config,
-		emailService: emailService,
+		userRepo:             userRepo,
+		roleRepo:             roleRepo,
+		tokenRepo:            tokenRepo,
+		userTOTPRepo:         userTOTPRepo,
+		totpRecoveryCodeRepo: totpRecoveryCodeRepo,
+		jwtUtil:              jwtUtil,
+		tokenService:         tokenService,
+		config:               config,
+		emailService:         emailService,
+		notificationService:  notificationService,
+		rateLimitStore:       rateLimitStore,
 	}
 }
 
@@ -72,24 +126,25 @@ func (s *AuthServiceImpl) Register(req *models.UserRegisterRequest) (*models.Use
 	}
 
 	// Validate password strength
-	if !utils.IsValidPassword(req.Password) {
-		return nil, errors.New("password must be at least 6 characters and contain both letters and numbers")
+	if reason := utils.IsValidPassword(req.Password, s.config); reason != "" {
+		return nil, errors.New(reason)
 	}
 
-	// Create new user
+	// Create new user, inactive until they verify their email
 	user := &models.User{
 		Email:     req.Email,
 		FirstName: req.FirstName,
 		LastName:  req.LastName,
-		IsActive:  true,
+		IsActive:  false,
 		IsAdmin:   false,
 	}
 
-	// Set password
-	if err := user.SetPassword(req.Password); err != nil {
+	hash, err := utils.HashPassword(req.Password, s.config.Password.Pepper, utils.Argon2ParamsFromConfig(s.config))
+	if err != nil {
 		logrus.Errorf("Error setting password: %v", err)
 		return nil, errors.New("failed to process password")
 	}
+	user.PasswordHash = hash
 
 	// Save user
 	if err := s.userRepo.CreateUser(user); err != nil {
@@ -97,12 +152,40 @@ func (s *AuthServiceImpl) Register(req *models.UserRegisterRequest) (*models.Use
 		return nil, errors.New("failed to create user")
 	}
 
+	if err := s.issueEmailVerificationToken(user); err != nil {
+		// The account was created; a failed verification email shouldn't
+		// fail registration itself, since ResendVerificationEmail lets the
+		// user retry.
+		logrus.Errorf("Error sending verification email to %s: %v", user.Email, err)
+	}
+
 	logrus.Infof("User registered successfully: %s", user.Email)
 	return user, nil
 }
 
+// issueEmailVerificationToken creates a fresh email_verification token for
+// user and emails it, used by both Register and ResendVerificationEmail.
+func (s *AuthServiceImpl) issueEmailVerificationToken(user *models.User) error {
+	rawToken, err := utils.GenerateSecureToken()
+	if err != nil {
+		return fmt.Errorf("failed to generate verification token: %w", err)
+	}
+
+	tokenRecord := &models.Token{
+		TokenHash: utils.HashToken(rawToken),
+		Type:      models.TokenTypeEmailVerification,
+		UserID:    user.ID,
+		ExpiresAt: time.Now().Add(s.config.EmailVerificationTokenExpires),
+	}
+	if err := s.tokenRepo.CreateToken(tokenRecord); err != nil {
+		return fmt.Errorf("failed to store verification token: %w", err)
+	}
+
+	return s.notificationService.Notify(user, NotificationTypeVerification, map[string]string{"Token": rawToken})
+}
+
 // Login authenticates a user and returns tokens
-func (s *AuthServiceImpl) Login(req *models.UserLoginRequest) (*models.UserLoginResponse, error) {
+func (s *AuthServiceImpl) Login(req *models.UserLoginRequest, userAgent, ip string) (*models.UserLoginResponse, error) {
 	// Validate request
 	if err := utils.ValidateStruct(req); err != nil {
 		return nil, fmt.Errorf("validation error: %s", utils.FormatValidationErrors(err))
@@ -118,31 +201,72 @@ func (s *AuthServiceImpl) Login(req *models.UserLoginRequest) (*models.UserLogin
 		return nil, errors.New("invalid email or password")
 	}
 
-	// Check if user is active
+	// Check if user is active (either deactivated by an admin, or still
+	// pending email verification from registration)
 	if !user.IsActive {
-		return nil, errors.New("account is deactivated")
+		return nil, errors.New("account is not active; please verify your email")
 	}
 
-	// Verify password
-	if !user.CheckPassword(req.Password) {
+	// Verify password. Only a bad password counts against the rate limiter;
+	// an unknown email returns above before reaching here, so an attacker
+	// can't amplify enumeration by tripping lockouts on accounts that don't
+	// exist.
+	if !utils.CheckPasswordHash(req.Password, s.config.Password.Pepper, user.PasswordHash) {
+		metrics.AuthLoginFailuresTotal.Inc()
+		s.rateLimitStore.RecordFailure(ratelimit.Key(ip, req.Email), s.config.Auth.MaxFailures, s.config.Auth.LockoutWindow)
 		return nil, errors.New("invalid email or password")
 	}
+	s.rateLimitStore.Reset(ratelimit.Key(ip, req.Email))
+
+	// A legacy bcrypt hash, or an argon2id one hashed under weaker
+	// parameters than currently configured, is upgraded now that the
+	// plaintext is in hand; rehashNeeded is folded into the UpdateLastLogin
+	// write below so both land in the same transaction.
+	rehashNeeded := utils.NeedsRehash(user.PasswordHash, utils.Argon2ParamsFromConfig(s.config))
+	var rehashed string
+	if rehashNeeded {
+		hash, err := utils.HashPassword(req.Password, s.config.Password.Pepper, utils.Argon2ParamsFromConfig(s.config))
+		if err != nil {
+			logrus.Errorf("Error rehashing password on login: %v", err)
+			rehashNeeded = false
+		} else {
+			rehashed = hash
+		}
+	}
 
-	// Generate tokens
-	accessToken, err := s.jwtUtil.GenerateAccessToken(user.ID, user.Email)
+	totp, err := s.userTOTPRepo.GetByUserID(user.ID)
 	if err != nil {
-		logrus.Errorf("Error generating access token: %v", err)
-		return nil, errors.New("failed to generate access token")
+		logrus.Errorf("Error checking TOTP enrollment: %v", err)
+		return nil, errors.New("authentication failed")
+	}
+	if totp != nil && totp.Enabled {
+		// Password verified, but a second factor is still required: hand
+		// back a short-lived mfa_pending token instead of real tokens, for
+		// the client to resubmit with a TOTP code to VerifyMFA.
+		mfaToken, err := s.jwtUtil.GenerateMFAPendingToken(user.ID, user.Email)
+		if err != nil {
+			logrus.Errorf("Error generating MFA pending token: %v", err)
+			return nil, errors.New("failed to generate tokens")
+		}
+		return &models.UserLoginResponse{MFARequired: true, MFAToken: mfaToken}, nil
 	}
 
-	refreshToken, err := s.jwtUtil.GenerateRefreshToken(user.ID, user.Email)
+	// Start a new refresh-token session for this login, so it can be rotated
+	// and revoked independently of any other device the user is signed into.
+	accessToken, refreshToken, err := s.tokenService.IssueSession(user.ID, user.Email, userAgent, ip)
 	if err != nil {
-		logrus.Errorf("Error generating refresh token: %v", err)
-		return nil, errors.New("failed to generate refresh token")
+		logrus.Errorf("Error issuing session: %v", err)
+		return nil, errors.New("failed to generate tokens")
 	}
 
-	// Update last login
-	if err := s.userRepo.UpdateLastLogin(user.ID); err != nil {
+	// Update last login, folding in the argon2id upgrade from above (if
+	// any) so it can't be recorded as a successful login without the
+	// stronger hash actually landing.
+	if rehashNeeded {
+		if err := s.userRepo.UpdateLastLoginAndPassword(user.ID, rehashed); err != nil {
+			logrus.Errorf("Error updating last login and password hash: %v", err)
+		}
+	} else if err := s.userRepo.UpdateLastLogin(user.ID); err != nil {
 		logrus.Errorf("Error updating last login: %v", err)
 		// Don't fail the login for this
 	}
@@ -157,47 +281,30 @@ func (s *AuthServiceImpl) Login(req *models.UserLoginRequest) (*models.UserLogin
 	return response, nil
 }
 
-// RefreshToken generates new tokens using refresh token
-func (s *AuthServiceImpl) RefreshToken(refreshToken string) (*models.UserLoginResponse, error) {
-	// Validate refresh token
+// RefreshToken redeems a refresh token for a new token pair, rotating it
+// through s.tokenService so the presented jti can never be redeemed again;
+// presenting an already-rotated jti revokes the whole session (reuse
+// detection) and this returns an error instead of new tokens.
+func (s *AuthServiceImpl) RefreshToken(refreshToken, userAgent, ip string) (*models.UserLoginResponse, error) {
 	claims, err := s.jwtUtil.ValidateToken(refreshToken)
 	if err != nil {
 		return nil, errors.New("invalid or expired refresh token")
 	}
-
-	// Check if it's a refresh token
 	if claims.Subject != "refresh_token" {
 		return nil, errors.New("invalid token type")
 	}
 
-	// Get user
+	newAccessToken, newRefreshToken, err := s.tokenService.Rotate(refreshToken, userAgent, ip)
+	if err != nil {
+		return nil, err
+	}
+
 	user := &models.User{}
 	if err := s.userRepo.GetByID(claims.UserID, user); err != nil {
-		if err == gorm.ErrRecordNotFound {
-			return nil, errors.New("user not found")
-		}
 		logrus.Errorf("Error finding user: %v", err)
 		return nil, errors.New("failed to find user")
 	}
 
-	// Check if user is active
-	if !user.IsActive {
-		return nil, errors.New("account is deactivated")
-	}
-
-	// Generate new tokens
-	newAccessToken, err := s.jwtUtil.GenerateAccessToken(user.ID, user.Email)
-	if err != nil {
-		logrus.Errorf("Error generating access token: %v", err)
-		return nil, errors.New("failed to generate access token")
-	}
-
-	newRefreshToken, err := s.jwtUtil.GenerateRefreshToken(user.ID, user.Email)
-	if err != nil {
-		logrus.Errorf("Error generating refresh token: %v", err)
-		return nil, errors.New("failed to generate refresh token")
-	}
-
 	response := &models.UserLoginResponse{
 		User:         user.ToDict(),
 		AccessToken:  newAccessToken,
@@ -207,7 +314,24 @@ func (s *AuthServiceImpl) RefreshToken(refreshToken string) (*models.UserLoginRe
 	return response, nil
 }
 
-// RequestPasswordReset initiates password reset process
+// Logout revokes refreshToken's session immediately, rather than leaving it
+// valid until its own expiry, so a shared or stolen token can't be rotated
+// after the legitimate client has signed out.
+func (s *AuthServiceImpl) Logout(refreshToken string) error {
+	claims, err := s.jwtUtil.ValidateToken(refreshToken)
+	if err != nil {
+		return errors.New("invalid or expired refresh token")
+	}
+	if claims.Subject != "refresh_token" {
+		return errors.New("invalid token type")
+	}
+
+	return s.tokenService.RevokeSession(claims.UserID, claims.SessionID)
+}
+
+// RequestPasswordReset issues a password_recovery token and emails the
+// user a reset link. It always returns nil for an unknown email so the
+// caller can't use this endpoint to enumerate registered addresses.
 func (s *AuthServiceImpl) RequestPasswordReset(email string) error {
 	// Find user by email
 	user, err := s.userRepo.GetByEmail(email)
@@ -221,29 +345,350 @@ func (s *AuthServiceImpl) RequestPasswordReset(email string) error {
 		return nil
 	}
 
-	// Create password reset token
-	// This would need a password reset repository
-	// For now, we'll just log it
-	logrus.Infof("Password reset requested for user: %s", user.Email)
+	rawToken, err := utils.GenerateSecureToken()
+	if err != nil {
+		logrus.Errorf("Error generating password reset token: %v", err)
+		return errors.New("failed to process request")
+	}
 
-	// TODO: Send password reset email
-	// return s.emailService.SendPasswordResetEmail(user.Email, resetToken)
+	tokenRecord := &models.Token{
+		TokenHash: utils.HashToken(rawToken),
+		Type:      models.TokenTypePasswordRecovery,
+		UserID:    user.ID,
+		ExpiresAt: time.Now().Add(s.config.PasswordResetTokenExpires),
+	}
+	if err := s.tokenRepo.CreateToken(tokenRecord); err != nil {
+		logrus.Errorf("Error storing password reset token: %v", err)
+		return errors.New("failed to process request")
+	}
+
+	if err := s.notificationService.Notify(user, NotificationTypePasswordReset, map[string]string{"Token": rawToken}); err != nil {
+		logrus.Errorf("Error sending password reset notification: %v", err)
+		return errors.New("failed to send password reset email")
+	}
 
+	logrus.Infof("Password reset requested for user: %s", user.Email)
 	return nil
 }
 
-// ResetPassword resets user password using token
+// ResetPassword redeems a password_recovery token, sets newPassword, and
+// consumes the token so it cannot be replayed.
 func (s *AuthServiceImpl) ResetPassword(token, newPassword string) error {
 	// Validate password strength
-	if !utils.IsValidPassword(newPassword) {
-		return errors.New("password must be at least 6 characters and contain both letters and numbers")
+	if reason := utils.IsValidPassword(newPassword, s.config); reason != "" {
+		return errors.New(reason)
+	}
+
+	tokenHash := utils.HashToken(token)
+	tokenRecord, err := s.tokenRepo.GetToken(tokenHash)
+	if err != nil {
+		logrus.Errorf("Error looking up password reset token: %v", err)
+		return errors.New("failed to reset password")
+	}
+	if tokenRecord == nil || tokenRecord.Type != models.TokenTypePasswordRecovery {
+		return errors.New("invalid or expired password reset token")
+	}
+
+	user := &models.User{}
+	if err := s.userRepo.GetByID(tokenRecord.UserID, user); err != nil {
+		logrus.Errorf("Error finding user for password reset: %v", err)
+		return errors.New("failed to reset password")
+	}
+
+	hash, err := utils.HashPassword(newPassword, s.config.Password.Pepper, utils.Argon2ParamsFromConfig(s.config))
+	if err != nil {
+		logrus.Errorf("Error setting new password: %v", err)
+		return errors.New("failed to process password")
+	}
+
+	if err := s.userRepo.UpdatePassword(user.ID, hash); err != nil {
+		logrus.Errorf("Error saving new password: %v", err)
+		return errors.New("failed to reset password")
+	}
+
+	if err := s.tokenRepo.DeleteToken(tokenHash); err != nil {
+		// The password is already changed; log but don't fail the request
+		// over a cleanup error.
+		logrus.Errorf("Error deleting consumed password reset token: %v", err)
+	}
+
+	logrus.Infof("Password reset completed for user %d", user.ID)
+	return nil
+}
+
+// VerifyEmail redeems an email_verification token and activates the
+// account it belongs to.
+func (s *AuthServiceImpl) VerifyEmail(token string) error {
+	tokenHash := utils.HashToken(token)
+	tokenRecord, err := s.tokenRepo.GetToken(tokenHash)
+	if err != nil {
+		logrus.Errorf("Error looking up email verification token: %v", err)
+		return errors.New("failed to verify email")
+	}
+	if tokenRecord == nil || tokenRecord.Type != models.TokenTypeEmailVerification {
+		return errors.New("invalid or expired verification token")
+	}
+
+	user := &models.User{}
+	if err := s.userRepo.GetByID(tokenRecord.UserID, user); err != nil {
+		logrus.Errorf("Error finding user for email verification: %v", err)
+		return errors.New("failed to verify email")
+	}
+
+	user.IsActive = true
+	if err := s.userRepo.Update(user); err != nil {
+		logrus.Errorf("Error activating user: %v", err)
+		return errors.New("failed to verify email")
+	}
+
+	if err := s.tokenRepo.DeleteToken(tokenHash); err != nil {
+		logrus.Errorf("Error deleting consumed verification token: %v", err)
+	}
+
+	logrus.Infof("Email verified for user %d", user.ID)
+	return nil
+}
+
+// ResendVerificationEmail issues a fresh email_verification token for
+// email. It always returns nil for an unknown or already-verified address
+// so the caller can't use this endpoint to enumerate registered accounts.
+func (s *AuthServiceImpl) ResendVerificationEmail(email string) error {
+	user, err := s.userRepo.GetByEmail(email)
+	if err != nil {
+		logrus.Errorf("Error finding user: %v", err)
+		return errors.New("failed to process request")
+	}
+	if user == nil || user.IsActive {
+		return nil
+	}
+
+	if err := s.issueEmailVerificationToken(user); err != nil {
+		logrus.Errorf("Error resending verification email to %s: %v", user.Email, err)
+		return errors.New("failed to send verification email")
+	}
+
+	return nil
+}
+
+// EnrollTOTP generates a fresh TOTP secret for userID and stores it
+// encrypted, pending confirmation via ActivateTOTP. Calling this again
+// before activating simply replaces the pending secret.
+func (s *AuthServiceImpl) EnrollTOTP(userID uint) (string, string, error) {
+	user := &models.User{}
+	if err := s.userRepo.GetByID(userID, user); err != nil {
+		logrus.Errorf("Error finding user for TOTP enrollment: %v", err)
+		return "", "", errors.New("failed to enroll two-factor authentication")
+	}
+
+	existing, err := s.userTOTPRepo.GetByUserID(userID)
+	if err != nil {
+		logrus.Errorf("Error checking existing TOTP enrollment: %v", err)
+		return "", "", errors.New("failed to enroll two-factor authentication")
+	}
+	if existing != nil && existing.Enabled {
+		return "", "", errors.New("two-factor authentication is already enabled")
+	}
+
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		logrus.Errorf("Error generating TOTP secret: %v", err)
+		return "", "", errors.New("failed to enroll two-factor authentication")
+	}
+
+	encryptedSecret, err := utils.EncryptWithSecret(s.config.SecretKey, secret)
+	if err != nil {
+		logrus.Errorf("Error encrypting TOTP secret: %v", err)
+		return "", "", errors.New("failed to enroll two-factor authentication")
+	}
+
+	if existing != nil {
+		existing.SecretEncrypted = encryptedSecret
+		if err := s.userTOTPRepo.Update(existing); err != nil {
+			logrus.Errorf("Error updating pending TOTP enrollment: %v", err)
+			return "", "", errors.New("failed to enroll two-factor authentication")
+		}
+	} else {
+		if err := s.userTOTPRepo.Create(&models.UserTOTP{UserID: userID, SecretEncrypted: encryptedSecret}); err != nil {
+			logrus.Errorf("Error creating TOTP enrollment: %v", err)
+			return "", "", errors.New("failed to enroll two-factor authentication")
+		}
+	}
+
+	otpauthURI := utils.TOTPAuthURI(s.config.AppName, user.Email, secret)
+	return secret, otpauthURI, nil
+}
+
+// ActivateTOTP confirms a pending TOTP enrollment and generates the ten
+// recovery codes that can substitute for a TOTP code later.
+func (s *AuthServiceImpl) ActivateTOTP(userID uint, code string) ([]string, error) {
+	totp, err := s.userTOTPRepo.GetByUserID(userID)
+	if err != nil {
+		logrus.Errorf("Error looking up TOTP enrollment: %v", err)
+		return nil, errors.New("failed to activate two-factor authentication")
+	}
+	if totp == nil {
+		return nil, errors.New("no pending two-factor authentication enrollment")
+	}
+	if totp.Enabled {
+		return nil, errors.New("two-factor authentication is already enabled")
+	}
+
+	secret, err := utils.DecryptWithSecret(s.config.SecretKey, totp.SecretEncrypted)
+	if err != nil {
+		logrus.Errorf("Error decrypting TOTP secret: %v", err)
+		return nil, errors.New("failed to activate two-factor authentication")
+	}
+	if !utils.ValidateTOTP(secret, code) {
+		return nil, errors.New("invalid verification code")
+	}
+
+	totp.Enabled = true
+	if err := s.userTOTPRepo.Update(totp); err != nil {
+		logrus.Errorf("Error activating TOTP enrollment: %v", err)
+		return nil, errors.New("failed to activate two-factor authentication")
+	}
+
+	recoveryCodes := make([]string, 0, totpRecoveryCodeCount)
+	rows := make([]*models.TOTPRecoveryCode, 0, totpRecoveryCodeCount)
+	for i := 0; i < totpRecoveryCodeCount; i++ {
+		code, err := utils.GenerateRecoveryCode()
+		if err != nil {
+			logrus.Errorf("Error generating recovery code: %v", err)
+			return nil, errors.New("failed to activate two-factor authentication")
+		}
+		recoveryCodes = append(recoveryCodes, code)
+		rows = append(rows, &models.TOTPRecoveryCode{UserID: userID, CodeHash: utils.HashToken(code)})
+	}
+	if err := s.totpRecoveryCodeRepo.CreateBatch(rows); err != nil {
+		logrus.Errorf("Error storing recovery codes: %v", err)
+		return nil, errors.New("failed to activate two-factor authentication")
+	}
+
+	logrus.Infof("TOTP enabled for user %d", userID)
+	return recoveryCodes, nil
+}
+
+// DisableTOTP turns off userID's 2FA after confirming a current TOTP or
+// recovery code, and discards the recovery codes.
+func (s *AuthServiceImpl) DisableTOTP(userID uint, code string) error {
+	totp, err := s.userTOTPRepo.GetByUserID(userID)
+	if err != nil {
+		logrus.Errorf("Error looking up TOTP enrollment: %v", err)
+		return errors.New("failed to disable two-factor authentication")
+	}
+	if totp == nil || !totp.Enabled {
+		return errors.New("two-factor authentication is not enabled")
+	}
+
+	if err := s.verifyTOTPOrRecoveryCode(userID, totp, code); err != nil {
+		return err
+	}
+
+	if err := s.userTOTPRepo.DeleteByUserID(userID); err != nil {
+		logrus.Errorf("Error deleting TOTP enrollment: %v", err)
+		return errors.New("failed to disable two-factor authentication")
+	}
+	if err := s.totpRecoveryCodeRepo.DeleteByUserID(userID); err != nil {
+		logrus.Errorf("Error deleting recovery codes: %v", err)
 	}
 
-	// TODO: Implement password reset token validation
-	// This would need a password reset repository
-	
-	logrus.Info("Password reset attempted")
-	return errors.New("password reset not implemented yet")
+	logrus.Infof("TOTP disabled for user %d", userID)
+	return nil
+}
+
+// VerifyMFA completes a login Login paused for a second factor: it redeems
+// mfaToken, checks code against the user's TOTP secret or recovery codes,
+// and on success issues the real token pair.
+func (s *AuthServiceImpl) VerifyMFA(mfaToken, code, userAgent, ip string) (*models.UserLoginResponse, error) {
+	claims, err := s.jwtUtil.ValidateToken(mfaToken)
+	if err != nil {
+		return nil, errors.New("invalid or expired MFA token")
+	}
+	if claims.Subject != "mfa_pending" {
+		return nil, errors.New("invalid token type")
+	}
+
+	user := &models.User{}
+	if err := s.userRepo.GetByID(claims.UserID, user); err != nil {
+		logrus.Errorf("Error finding user for MFA verification: %v", err)
+		return nil, errors.New("authentication failed")
+	}
+	if !user.IsActive {
+		return nil, errors.New("account is not active; please verify your email")
+	}
+
+	totp, err := s.userTOTPRepo.GetByUserID(user.ID)
+	if err != nil || totp == nil || !totp.Enabled {
+		logrus.Errorf("Error looking up TOTP enrollment during MFA verification: %v", err)
+		return nil, errors.New("two-factor authentication is not enabled")
+	}
+
+	if err := s.verifyTOTPOrRecoveryCode(user.ID, totp, code); err != nil {
+		return nil, err
+	}
+
+	accessToken, refreshToken, err := s.tokenService.IssueSession(user.ID, user.Email, userAgent, ip)
+	if err != nil {
+		logrus.Errorf("Error issuing session after MFA verification: %v", err)
+		return nil, errors.New("failed to generate tokens")
+	}
+
+	if err := s.userRepo.UpdateLastLogin(user.ID); err != nil {
+		logrus.Errorf("Error updating last login: %v", err)
+	}
+
+	logrus.Infof("User completed MFA login: %s", user.Email)
+	return &models.UserLoginResponse{
+		User:         user.ToDict(),
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+	}, nil
+}
+
+// verifyTOTPOrRecoveryCode accepts either a current TOTP code or one of
+// userID's unused recovery codes, consuming the recovery code if that's
+// what matched.
+func (s *AuthServiceImpl) verifyTOTPOrRecoveryCode(userID uint, totp *models.UserTOTP, code string) error {
+	secret, err := utils.DecryptWithSecret(s.config.SecretKey, totp.SecretEncrypted)
+	if err != nil {
+		logrus.Errorf("Error decrypting TOTP secret: %v", err)
+		return errors.New("failed to verify two-factor authentication code")
+	}
+	if utils.ValidateTOTP(secret, code) {
+		return nil
+	}
+
+	recoveryCode, err := s.totpRecoveryCodeRepo.GetUnusedByHash(userID, utils.HashToken(code))
+	if err != nil {
+		logrus.Errorf("Error looking up recovery code: %v", err)
+		return errors.New("failed to verify two-factor authentication code")
+	}
+	if recoveryCode == nil {
+		return errors.New("invalid two-factor authentication code")
+	}
+
+	if err := s.totpRecoveryCodeRepo.MarkUsed(recoveryCode.ID); err != nil {
+		logrus.Errorf("Error marking recovery code used: %v", err)
+	}
+	return nil
+}
+
+// RevokeSession ends one of userID's active sessions, e.g. a "sign out this
+// device" action.
+func (s *AuthServiceImpl) RevokeSession(userID uint, sessionID string) error {
+	return s.tokenService.RevokeSession(userID, sessionID)
+}
+
+// RevokeAllUserSessions ends every session belonging to userID, e.g. a
+// "sign out everywhere" action or a password change.
+func (s *AuthServiceImpl) RevokeAllUserSessions(userID uint) error {
+	return s.tokenService.RevokeAllUserSessions(userID)
+}
+
+// ListActiveSessions returns userID's active sessions for display in an
+// account security page.
+func (s *AuthServiceImpl) ListActiveSessions(userID uint) ([]models.RefreshToken, error) {
+	return s.tokenService.ListActiveSessions(userID)
 }
 
 // GetUserProfile returns user profile information
@@ -258,4 +703,57 @@ func (s *AuthServiceImpl) GetUserProfile(userID uint) (*models.User, error) {
 	}
 
 	return user, nil
-}
\ No newline at end of file
+}
+
+// GrantRole assigns roleName to userID, e.g. via the admin-management API.
+// A previously-issued access token won't carry the new role until the user
+// next logs in or refreshes, since roles are baked into the JWT at
+// issuance time.
+func (s *AuthServiceImpl) GrantRole(userID uint, roleName string) error {
+	role, err := s.roleRepo.GetByName(roleName)
+	if err != nil {
+		logrus.Errorf("Error looking up role: %v", err)
+		return errors.New("failed to look up role")
+	}
+	if role == nil {
+		return errors.New("role not found")
+	}
+
+	if err := s.roleRepo.GrantRole(userID, role.ID); err != nil {
+		logrus.Errorf("Error granting role: %v", err)
+		return errors.New("failed to grant role")
+	}
+
+	logrus.Infof("Role %s granted to user %d", roleName, userID)
+	return nil
+}
+
+// RevokeRole removes roleName from userID.
+func (s *AuthServiceImpl) RevokeRole(userID uint, roleName string) error {
+	role, err := s.roleRepo.GetByName(roleName)
+	if err != nil {
+		logrus.Errorf("Error looking up role: %v", err)
+		return errors.New("failed to look up role")
+	}
+	if role == nil {
+		return errors.New("role not found")
+	}
+
+	if err := s.roleRepo.RevokeRole(userID, role.ID); err != nil {
+		logrus.Errorf("Error revoking role: %v", err)
+		return errors.New("failed to revoke role")
+	}
+
+	logrus.Infof("Role %s revoked from user %d", roleName, userID)
+	return nil
+}
+
+// ListUserRoles returns userID's currently granted roles.
+func (s *AuthServiceImpl) ListUserRoles(userID uint) ([]models.Role, error) {
+	roles, err := s.roleRepo.GetRolesByUserID(userID)
+	if err != nil {
+		logrus.Errorf("Error listing user roles: %v", err)
+		return nil, errors.New("failed to list user roles")
+	}
+	return roles, nil
+}
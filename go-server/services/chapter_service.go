@@ -2,8 +2,10 @@ package services
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"go-server/jobs"
 	"go-server/models"
 	"go-server/repositories"
 	"go-server/utils"
@@ -12,8 +14,16 @@ import (
 	"gorm.io/gorm"
 )
 
+// jobKindGenerateChapters is the kind registered with queue by
+// NewChapterService.
+const jobKindGenerateChapters = "generate_chapters"
+
 type ChapterService interface {
 	GenerateChapters(courseID, subjectID uint) ([]models.Chapter, error)
+	// EnqueueGenerateChaptersJob queues the same work GenerateChapters does
+	// synchronously, returning a *models.Job a client can poll instead of
+	// holding the request open for the LLM call.
+	EnqueueGenerateChaptersJob(courseID, subjectID uint, idempotencyKey string) (*models.Job, error)
 	GetChaptersBySubjectID(subjectID uint) ([]models.Chapter, error)
 	GetChapterByID(chapterID uint) (*models.Chapter, error)
 	CreateChapters(chapters []models.Chapter) error
@@ -23,23 +33,51 @@ type ChapterService interface {
 }
 
 type ChapterServiceImpl struct {
-	chapterRepo  repositories.ChapterRepository
-	subjectRepo  repositories.SubjectRepository
-	courseRepo   repositories.CourseRepository
-	geminiHelper *utils.GeminiHelper
+	chapterRepo     repositories.ChapterRepository
+	subjectRepo     repositories.SubjectRepository
+	courseRepo      repositories.CourseRepository
+	llm             utils.LLMProvider
+	queue           *jobs.Queue
+	digestEventRepo repositories.DigestEventRepository
 }
 
-func NewChapterService(chapterRepo repositories.ChapterRepository, subjectRepo repositories.SubjectRepository, courseRepo repositories.CourseRepository) ChapterService {
-	return &ChapterServiceImpl{
-		chapterRepo:  chapterRepo,
-		subjectRepo:  subjectRepo,
-		courseRepo:   courseRepo,
-		geminiHelper: utils.NewGeminiHelper(),
+func NewChapterService(chapterRepo repositories.ChapterRepository, subjectRepo repositories.SubjectRepository, courseRepo repositories.CourseRepository, llm utils.LLMProvider, queue *jobs.Queue, digestEventRepo repositories.DigestEventRepository) ChapterService {
+	s := &ChapterServiceImpl{
+		chapterRepo:     chapterRepo,
+		subjectRepo:     subjectRepo,
+		courseRepo:      courseRepo,
+		llm:             llm,
+		queue:           queue,
+		digestEventRepo: digestEventRepo,
 	}
+
+	queue.Register(jobKindGenerateChapters, s.handleGenerateChaptersJob)
+
+	return s
 }
 
-// GenerateChapters generates chapters for a subject using Gemini AI
+// chapterJobProgressDrafted and chapterJobProgressPersisted are the
+// models.Job.Progress labels handleGenerateChaptersJob reports mid-run, for
+// a GET /jobs/:id/watch client to see more than a single "running" frame
+// while chapter generation is in flight.
+const (
+	chapterJobProgressDrafted   = "chapters_drafted"
+	chapterJobProgressPersisted = "persisted"
+)
+
+// chapterProgressFunc reports a sub-stage reached during chapter
+// generation; noopChapterProgress is used when there's no job to report
+// against (e.g. a synchronous, non-queued call).
+type chapterProgressFunc func(stage string)
+
+func noopChapterProgress(string) {}
+
+// GenerateChapters generates chapters for a subject using the configured LLM provider
 func (s *ChapterServiceImpl) GenerateChapters(courseID, subjectID uint) ([]models.Chapter, error) {
+	return s.generateChapters(courseID, subjectID, noopChapterProgress)
+}
+
+func (s *ChapterServiceImpl) generateChapters(courseID, subjectID uint, onProgress chapterProgressFunc) ([]models.Chapter, error) {
 	logrus.Infof("Starting chapter generation for subject_id: %d, course_id: %d", subjectID, courseID)
 
 	// Get subject
@@ -79,23 +117,16 @@ func (s *ChapterServiceImpl) GenerateChapters(courseID, subjectID uint) ([]model
 		return existingChapters, nil
 	}
 
-	// Generate chapters using Gemini
+	// Generate chapters using the configured LLM provider
 	ctx := context.Background()
-	prompt := fmt.Sprintf(`Generate a comprehensive list of chapters for the subject '%s' under course '%s'.
-	Consider the following:
-	1. Include chapters from basic to advanced level
-	2. Each chapter should be a distinct topic within the subject
-	3. Chapters should follow a logical learning progression
-	4. Generate maximum 8 chapters for the subject
-	5. Keep chapter names concise and clear`, subject.Name, course.Name)
-
-	chapterNames, err := s.geminiHelper.GenerateChapters(ctx, subject.Name, course.Name)
+	chapterNames, err := s.llm.GenerateChapters(ctx, subject.Name, course.Name)
 	if err != nil {
-		logrus.Errorf("Error generating chapters with Gemini: %v", err)
+		logrus.Errorf("Error generating chapters: %v", err)
 		return nil, errors.New("failed to generate chapters")
 	}
 
 	logrus.Infof("Generated %d chapters for subject %s", len(chapterNames), subject.Name)
+	onProgress(chapterJobProgressDrafted)
 
 	// Create chapter models
 	var chapters []models.Chapter
@@ -111,11 +142,76 @@ func (s *ChapterServiceImpl) GenerateChapters(courseID, subjectID uint) ([]model
 		logrus.Errorf("Error saving chapters: %v", err)
 		return nil, errors.New("failed to save chapters")
 	}
+	onProgress(chapterJobProgressPersisted)
+
+	s.logDigestEvent(course, subject, chapters)
 
 	logrus.Infof("Successfully created %d chapters for subject: %s", len(chapters), subject.Name)
 	return chapters, nil
 }
 
+// logDigestEvent records a digest_events row for course.UserID summarizing
+// the chapters just generated, for services.DigestService.RunDigest to pick
+// up on their next digest. A course with no owner (UserID nil) has no one
+// to digest to, so this is a no-op for those.
+func (s *ChapterServiceImpl) logDigestEvent(course *models.Course, subject *models.Subject, chapters []models.Chapter) {
+	if course.UserID == nil || len(chapters) == 0 {
+		return
+	}
+
+	event := &models.DigestEvent{
+		UserID:    *course.UserID,
+		CourseID:  course.ID,
+		EventType: models.DigestEventChapterGenerated,
+		Message:   fmt.Sprintf("%d new chapters added to %q in %q", len(chapters), subject.Name, course.Name),
+	}
+	if err := s.digestEventRepo.Create(event); err != nil {
+		logrus.Errorf("Error logging digest event for chapter generation: %v", err)
+	}
+}
+
+// generateChaptersJobPayload is EnqueueGenerateChaptersJob's JSON payload.
+type generateChaptersJobPayload struct {
+	CourseID  uint `json:"course_id"`
+	SubjectID uint `json:"subject_id"`
+}
+
+// EnqueueGenerateChaptersJob queues GenerateChapters to run on the worker
+// pool.
+func (s *ChapterServiceImpl) EnqueueGenerateChaptersJob(courseID, subjectID uint, idempotencyKey string) (*models.Job, error) {
+	payload, err := json.Marshal(generateChaptersJobPayload{CourseID: courseID, SubjectID: subjectID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode job payload: %w", err)
+	}
+	return s.queue.Enqueue(jobKindGenerateChapters, string(payload), idempotencyKey)
+}
+
+func (s *ChapterServiceImpl) handleGenerateChaptersJob(jobID uint, payload string) (string, error) {
+	var p generateChaptersJobPayload
+	if err := json.Unmarshal([]byte(payload), &p); err != nil {
+		return "", fmt.Errorf("failed to decode job payload: %w", err)
+	}
+
+	chapters, err := s.generateChapters(p.CourseID, p.SubjectID, func(stage string) {
+		if err := s.queue.UpdateProgress(jobID, stage); err != nil {
+			logrus.Errorf("Error updating progress for job %d: %v", jobID, err)
+		}
+	})
+	if err != nil {
+		return "", err
+	}
+
+	chapterDicts := make([]map[string]interface{}, len(chapters))
+	for i, chapter := range chapters {
+		chapterDicts[i] = chapter.ToDict()
+	}
+	result, err := json.Marshal(chapterDicts)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode job result: %w", err)
+	}
+	return string(result), nil
+}
+
 // GetChaptersBySubjectID returns all chapters for a subject
 func (s *ChapterServiceImpl) GetChaptersBySubjectID(subjectID uint) ([]models.Chapter, error) {
 	chapters, err := s.chapterRepo.GetChaptersBySubjectID(subjectID)
@@ -183,4 +279,4 @@ func (s *ChapterServiceImpl) DeleteChaptersBySubjectID(subjectID uint) error {
 		return errors.New("failed to delete chapters")
 	}
 	return nil
-}
\ No newline at end of file
+}
@@ -2,30 +2,96 @@ package services
 
 import (
 	"context"
-	"errors"
+	"encoding/json"
 	"fmt"
+	"go-server/apierror"
+	"go-server/config"
+	"go-server/jobs"
 	"go-server/models"
+	"go-server/prompts"
 	"go-server/repositories"
 	"go-server/utils"
+	"sort"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/sirupsen/logrus"
 	"gorm.io/gorm"
 )
 
+// jobKindGenerateContent is the kind registered with queue by
+// NewContentService.
+const jobKindGenerateContent = "generate_content"
+
+// jobKindEmbedContent is the kind registered with queue for the embedding
+// work SearchContent's index depends on.
+const jobKindEmbedContent = "embed_content"
+
+// embeddingModel names the vector space embeddings are stored in, recorded
+// on each stored row. The reconciler only looks for content with no stored
+// embedding at all, whatever its model, so changing this does not re-embed
+// rows that already have one; Reindex re-embeds a course's rows.
+const embeddingModel = "default"
+
+// embeddingReconcileInterval is how often the background reconciler scans
+// for Content rows missing an embedding (e.g. ones generated before the
+// embed_content job existed, or that permanently failed embedding) and
+// re-enqueues them.
+const embeddingReconcileInterval = 10 * time.Minute
+
+// embeddingReconcileBatchSize caps how many missing embeddings one
+// reconciler pass enqueues, so a large backlog doesn't flood the job queue
+// in a single tick.
+const embeddingReconcileBatchSize = 50
+
 type ContentService interface {
 	GenerateContent(userID uint, req *models.ContentGenerateRequest) (*models.Content, error)
+	// GenerateContentStream generates content the same way GenerateContent
+	// does, but returns a channel of content chunks as they arrive instead
+	// of blocking until the full response is ready, so a caller can stream
+	// it to the browser (e.g. over SSE) as tokens arrive. ctx is the
+	// originating HTTP request's context, so a client disconnect cancels
+	// the underlying LLM call instead of letting it run unread to
+	// completion.
+	GenerateContentStream(ctx context.Context, userID uint, req *models.ContentGenerateRequest) (<-chan string, <-chan error)
+	// EnqueueGenerateContentJob queues the same work GenerateContent does
+	// synchronously, returning a *models.Job a client can poll instead of
+	// blocking on the request. idempotencyKey, when non-empty, makes a
+	// retried submission return the original job instead of enqueueing a
+	// duplicate generation (and a duplicate LLM spend).
+	EnqueueGenerateContentJob(userID uint, req *models.ContentGenerateRequest, idempotencyKey string) (*models.Job, error)
+	// GenerateContentForChapter and GenerateContentForSubject walk their
+	// topic tree in a single query, enqueue one content-generation job per
+	// topic that doesn't already have content, and return a *models.Batch
+	// handle a client polls via GetBatch for aggregate and per-topic
+	// progress.
+	GenerateContentForChapter(chapterID, userID uint) (*models.Batch, error)
+	GenerateContentForSubject(subjectID, userID uint) (*models.Batch, error)
+	GetBatch(batchID, userID uint) (*models.Batch, error)
 	GetContentByTopicID(topicID, userID uint) (*models.Content, error)
 	UpdateContent(contentID, userID uint, content string) (*models.Content, error)
 	DeleteContent(contentID, userID uint) error
+	// SearchContent embeds query and returns the top-k topics with content
+	// scoped to courses userID owns, ranked by cosine similarity.
+	SearchContent(userID uint, query string, k int) ([]models.Content, error)
+	// Reindex re-embeds every content row under courseID, overwriting any
+	// existing embedding. It's an admin operation, not scoped by user.
+	Reindex(courseID uint) error
 }
 
 type ContentServiceImpl struct {
-	contentRepo repositories.ContentRepository
-	topicRepo   repositories.TopicRepository
-	chapterRepo repositories.ChapterRepository
-	subjectRepo repositories.SubjectRepository
-	courseRepo  repositories.CourseRepository
-	geminiHelper *utils.GeminiHelper
+	contentRepo     repositories.ContentRepository
+	topicRepo       repositories.TopicRepository
+	chapterRepo     repositories.ChapterRepository
+	subjectRepo     repositories.SubjectRepository
+	courseRepo      repositories.CourseRepository
+	batchRepo       repositories.BatchRepository
+	embeddingRepo   repositories.ContentEmbeddingRepository
+	digestEventRepo repositories.DigestEventRepository
+	llm             utils.LLMProvider
+	queue           *jobs.Queue
+	userLimiter     *userGenerationLimiter
 }
 
 func NewContentService(
@@ -34,113 +100,627 @@ func NewContentService(
 	chapterRepo repositories.ChapterRepository,
 	subjectRepo repositories.SubjectRepository,
 	courseRepo repositories.CourseRepository,
+	batchRepo repositories.BatchRepository,
+	embeddingRepo repositories.ContentEmbeddingRepository,
+	digestEventRepo repositories.DigestEventRepository,
+	llm utils.LLMProvider,
+	queue *jobs.Queue,
+	cfg *config.Config,
 ) ContentService {
-	return &ContentServiceImpl{
-		contentRepo:  contentRepo,
-		topicRepo:    topicRepo,
-		chapterRepo:  chapterRepo,
-		subjectRepo:  subjectRepo,
-		courseRepo:   courseRepo,
-		geminiHelper: utils.NewGeminiHelper(),
+	s := &ContentServiceImpl{
+		contentRepo:     contentRepo,
+		topicRepo:       topicRepo,
+		chapterRepo:     chapterRepo,
+		subjectRepo:     subjectRepo,
+		courseRepo:      courseRepo,
+		batchRepo:       batchRepo,
+		embeddingRepo:   embeddingRepo,
+		digestEventRepo: digestEventRepo,
+		llm:             llm,
+		queue:           queue,
+		userLimiter:     newUserGenerationLimiter(cfg.MaxConcurrentContentGenerationsPerUser),
 	}
+
+	queue.Register(jobKindGenerateContent, s.handleGenerateContentJob)
+	queue.Register(jobKindEmbedContent, s.handleEmbedContentJob)
+
+	go s.runEmbeddingReconciler()
+
+	return s
 }
 
-// GenerateContent generates detailed content for a topic using Gemini AI
+// userGenerationLimiter caps how many of one user's content-generation jobs
+// run at once, keyed by user ID, so a single user's "generate all content"
+// batch can't crowd out every other user's share of the job queue's worker
+// pool.
+type userGenerationLimiter struct {
+	mu    sync.Mutex
+	sems  map[uint]chan struct{}
+	limit int
+}
+
+// newUserGenerationLimiter builds a userGenerationLimiter allowing limit
+// concurrent generations per user. limit <= 0 is treated as 1.
+func newUserGenerationLimiter(limit int) *userGenerationLimiter {
+	if limit <= 0 {
+		limit = 1
+	}
+	return &userGenerationLimiter{sems: make(map[uint]chan struct{}), limit: limit}
+}
+
+func (l *userGenerationLimiter) acquire(userID uint) {
+	l.mu.Lock()
+	sem, ok := l.sems[userID]
+	if !ok {
+		sem = make(chan struct{}, l.limit)
+		l.sems[userID] = sem
+	}
+	l.mu.Unlock()
+	sem <- struct{}{}
+}
+
+func (l *userGenerationLimiter) release(userID uint) {
+	l.mu.Lock()
+	sem := l.sems[userID]
+	l.mu.Unlock()
+	<-sem
+}
+
+// GenerateContent generates detailed content for a topic using the
+// configured LLM provider chain.
 func (s *ContentServiceImpl) GenerateContent(userID uint, req *models.ContentGenerateRequest) (*models.Content, error) {
-	// Validate request
+	course, subject, chapter, topic, existingContent, err := s.resolveAndCheckExisting(userID, req)
+	if err != nil {
+		return nil, err
+	}
+	if existingContent != nil {
+		return existingContent, nil
+	}
+
+	ctx := prompts.WithUserID(context.Background(), userID)
+
+	generatedContent, err := s.llm.GenerateTopicContent(ctx, topic.Name, chapter.Name, subject.Name, course.Name)
+	if err != nil {
+		logrus.Errorf("Error generating content: %v", err)
+		return nil, fmt.Errorf("%w", apierror.Internal("failed to generate content", err))
+	}
+
+	if err := utils.ValidateContentSafety(generatedContent); err != nil {
+		logrus.Errorf("Generated content for topic %d failed safety validation: %v", topic.ID, err)
+		return nil, fmt.Errorf("%w", apierror.ContentBlocked(err.Error()))
+	}
+
+	promptVersion, err := prompts.Default.VersionFor("topic_content", userID)
+	if err != nil {
+		logrus.Errorf("Error resolving topic_content prompt version: %v", err)
+	}
+
+	content := &models.Content{
+		TopicID:       req.TopicID,
+		Content:       generatedContent,
+		PromptVersion: promptVersion,
+	}
+
+	if err := s.contentRepo.CreateContent(content); err != nil {
+		logrus.Errorf("Error saving content: %v", err)
+		return nil, fmt.Errorf("%w", apierror.Internal("failed to save content", err))
+	}
+	s.enqueueEmbedContentJob(content.ID)
+	s.logDigestEvent(course, topic)
+
+	logrus.Infof("Generated content for topic: %s", topic.Name)
+	return content, nil
+}
+
+// logDigestEvent records a digest_events row for course.UserID summarizing
+// the content just generated, for services.DigestService.RunDigest to pick
+// up on their next digest. A course with no owner (UserID nil) has no one
+// to digest to, so this is a no-op for those.
+func (s *ContentServiceImpl) logDigestEvent(course *models.Course, topic *models.Topic) {
+	if course.UserID == nil {
+		return
+	}
+
+	event := &models.DigestEvent{
+		UserID:    *course.UserID,
+		CourseID:  course.ID,
+		EventType: models.DigestEventContentGenerated,
+		Message:   fmt.Sprintf("New content generated for %q in %q", topic.Name, course.Name),
+	}
+	if err := s.digestEventRepo.Create(event); err != nil {
+		logrus.Errorf("Error logging digest event for content generation: %v", err)
+	}
+}
+
+// GenerateContentStream is GenerateContent's streaming counterpart: it
+// resolves and validates ownership synchronously, then returns a channel of
+// content chunks as the LLM provider produces them. The generated content
+// is persisted once streaming completes successfully, same as
+// GenerateContent. ctx is the originating HTTP request's context; if the
+// client disconnects mid-stream, ctx is canceled and the in-flight LLM call
+// is aborted instead of running to completion with nothing reading it.
+func (s *ContentServiceImpl) GenerateContentStream(ctx context.Context, userID uint, req *models.ContentGenerateRequest) (<-chan string, <-chan error) {
+	chunks := make(chan string)
+	errs := make(chan error, 1)
+
+	ctx = prompts.WithUserID(ctx, userID)
+
+	course, subject, chapter, topic, existingContent, err := s.resolveAndCheckExisting(userID, req)
+	if err != nil {
+		close(chunks)
+		errs <- err
+		close(errs)
+		return chunks, errs
+	}
+	if existingContent != nil {
+		go func() {
+			defer close(chunks)
+			defer close(errs)
+			chunks <- existingContent.Content
+		}()
+		return chunks, errs
+	}
+
+	providerChunks, providerErrs := s.llm.StreamTopicContent(ctx, topic.Name, chapter.Name, subject.Name, course.Name)
+
+	go func() {
+		defer close(chunks)
+		defer close(errs)
+
+		var generated strings.Builder
+		for chunk := range providerChunks {
+			generated.WriteString(chunk)
+			chunks <- chunk
+		}
+
+		if err := <-providerErrs; err != nil {
+			logrus.Errorf("Error streaming content: %v", err)
+			errs <- fmt.Errorf("%w", apierror.Internal("failed to generate content", err))
+			return
+		}
+
+		// The stream has already reached the client chunk by chunk, so a
+		// safety failure here can't un-send what was shown; it can only
+		// stop the result from being persisted and reused for this topic.
+		if err := utils.ValidateContentSafety(generated.String()); err != nil {
+			logrus.Errorf("Streamed content for topic %d failed safety validation: %v", req.TopicID, err)
+			errs <- fmt.Errorf("%w", apierror.ContentBlocked(err.Error()))
+			return
+		}
+
+		promptVersion, err := prompts.Default.VersionFor("topic_content", userID)
+		if err != nil {
+			logrus.Errorf("Error resolving topic_content prompt version: %v", err)
+		}
+
+		content := &models.Content{
+			TopicID:       req.TopicID,
+			Content:       generated.String(),
+			PromptVersion: promptVersion,
+		}
+		if err := s.contentRepo.CreateContent(content); err != nil {
+			logrus.Errorf("Error saving streamed content: %v", err)
+			errs <- fmt.Errorf("%w", apierror.Internal("failed to save content", err))
+			return
+		}
+		s.enqueueEmbedContentJob(content.ID)
+
+		logrus.Infof("Generated content for topic: %s", topic.Name)
+	}()
+
+	return chunks, errs
+}
+
+// contentJobPayload is the JSON payload EnqueueGenerateContentJob queues and
+// handleGenerateContentJob decodes; it carries everything GenerateContent
+// needs since the job runs on a worker, not the original request's
+// goroutine.
+type contentJobPayload struct {
+	UserID    uint `json:"user_id"`
+	CourseID  uint `json:"course_id"`
+	SubjectID uint `json:"subject_id"`
+	ChapterID uint `json:"chapter_id"`
+	TopicID   uint `json:"topic_id"`
+}
+
+// EnqueueGenerateContentJob queues GenerateContent to run on the worker
+// pool.
+func (s *ContentServiceImpl) EnqueueGenerateContentJob(userID uint, req *models.ContentGenerateRequest, idempotencyKey string) (*models.Job, error) {
+	payload, err := json.Marshal(contentJobPayload{
+		UserID:    userID,
+		CourseID:  req.CourseID,
+		SubjectID: req.SubjectID,
+		ChapterID: req.ChapterID,
+		TopicID:   req.TopicID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode job payload: %w", err)
+	}
+	return s.queue.Enqueue(jobKindGenerateContent, string(payload), idempotencyKey)
+}
+
+func (s *ContentServiceImpl) handleGenerateContentJob(jobID uint, payload string) (string, error) {
+	var p contentJobPayload
+	if err := json.Unmarshal([]byte(payload), &p); err != nil {
+		return "", fmt.Errorf("failed to decode job payload: %w", err)
+	}
+
+	s.userLimiter.acquire(p.UserID)
+	defer s.userLimiter.release(p.UserID)
+
+	content, err := s.GenerateContent(p.UserID, &models.ContentGenerateRequest{
+		CourseID:  p.CourseID,
+		SubjectID: p.SubjectID,
+		ChapterID: p.ChapterID,
+		TopicID:   p.TopicID,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	result, err := json.Marshal(content.ToDict())
+	if err != nil {
+		return "", fmt.Errorf("failed to encode job result: %w", err)
+	}
+	return string(result), nil
+}
+
+// embedContentPayload is handleEmbedContentJob's JSON payload.
+type embedContentPayload struct {
+	ContentID uint `json:"content_id"`
+}
+
+// enqueueEmbedContentJob queues the embedding work for contentID on the
+// worker pool. It logs and swallows a queueing failure rather than
+// returning an error: a missing embedding only degrades SearchContent's
+// recall, and runEmbeddingReconciler will pick the row up on its next pass.
+func (s *ContentServiceImpl) enqueueEmbedContentJob(contentID uint) {
+	payload, err := json.Marshal(embedContentPayload{ContentID: contentID})
+	if err != nil {
+		logrus.Errorf("Error encoding embed job payload for content %d: %v", contentID, err)
+		return
+	}
+	if _, err := s.queue.Enqueue(jobKindEmbedContent, string(payload), ""); err != nil {
+		logrus.Errorf("Error enqueueing embed job for content %d: %v", contentID, err)
+	}
+}
+
+// handleEmbedContentJob embeds one Content row's text and upserts its
+// vector into content_embeddings.
+func (s *ContentServiceImpl) handleEmbedContentJob(jobID uint, payload string) (string, error) {
+	var p embedContentPayload
+	if err := json.Unmarshal([]byte(payload), &p); err != nil {
+		return "", fmt.Errorf("failed to decode job payload: %w", err)
+	}
+
+	content := &models.Content{}
+	if err := s.contentRepo.GetByID(p.ContentID, content); err != nil {
+		return "", fmt.Errorf("failed to get content %d: %w", p.ContentID, err)
+	}
+
+	vector, err := s.llm.EmbedText(context.Background(), content.Content)
+	if err != nil {
+		return "", fmt.Errorf("failed to embed content %d: %w", p.ContentID, err)
+	}
+
+	embedding := &models.ContentEmbedding{
+		ContentID: content.ID,
+		Model:     embeddingModel,
+		Embedding: utils.EncodeEmbedding(vector),
+	}
+	if err := s.embeddingRepo.Upsert(embedding); err != nil {
+		return "", fmt.Errorf("failed to store embedding for content %d: %w", p.ContentID, err)
+	}
+
+	return "", nil
+}
+
+// runEmbeddingReconciler periodically enqueues embed_content jobs for any
+// Content row missing an embedding — e.g. one generated before this job
+// kind existed, or whose embedding permanently failed — so SearchContent's
+// index stays complete without a client having to notice and retry.
+func (s *ContentServiceImpl) runEmbeddingReconciler() {
+	ticker := time.NewTicker(embeddingReconcileInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		contentIDs, err := s.contentRepo.GetAllContentIDs()
+		if err != nil {
+			logrus.Errorf("Embedding reconciler: failed to list content IDs: %v", err)
+			continue
+		}
+
+		missing, err := s.embeddingRepo.GetMissingContentIDs(contentIDs, embeddingReconcileBatchSize)
+		if err != nil {
+			logrus.Errorf("Embedding reconciler: failed to find missing embeddings: %v", err)
+			continue
+		}
+
+		for _, contentID := range missing {
+			s.enqueueEmbedContentJob(contentID)
+		}
+		if len(missing) > 0 {
+			logrus.Infof("Embedding reconciler: enqueued %d missing embeddings", len(missing))
+		}
+	}
+}
+
+// SearchContent embeds query, scores it against the embeddings of every
+// content row across courses userID owns, and returns the top-k by cosine
+// similarity. Content rows without a stored embedding yet (still awaiting
+// their embed_content job) are skipped rather than ranked arbitrarily.
+func (s *ContentServiceImpl) SearchContent(userID uint, query string, k int) ([]models.Content, error) {
+	if k <= 0 {
+		k = 5
+	}
+
+	contents, err := s.contentRepo.GetContentsByUserID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("%w", apierror.Internal("failed to get user's content", err))
+	}
+	if len(contents) == 0 {
+		return nil, nil
+	}
+
+	contentIDs := make([]uint, len(contents))
+	byContentID := make(map[uint]models.Content, len(contents))
+	for i, content := range contents {
+		contentIDs[i] = content.ID
+		byContentID[content.ID] = content
+	}
+
+	embeddings, err := s.embeddingRepo.GetByContentIDs(contentIDs)
+	if err != nil {
+		return nil, fmt.Errorf("%w", apierror.Internal("failed to get content embeddings", err))
+	}
+	if len(embeddings) == 0 {
+		return nil, nil
+	}
+
+	queryVector, err := s.llm.EmbedText(context.Background(), query)
+	if err != nil {
+		return nil, fmt.Errorf("%w", apierror.Internal("failed to embed search query", err))
+	}
+
+	type scored struct {
+		content    models.Content
+		similarity float64
+	}
+	results := make([]scored, 0, len(embeddings))
+	for _, embedding := range embeddings {
+		content, ok := byContentID[embedding.ContentID]
+		if !ok {
+			continue
+		}
+		similarity := utils.CosineSimilarity(queryVector, utils.DecodeEmbedding(embedding.Embedding))
+		results = append(results, scored{content: content, similarity: similarity})
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].similarity > results[j].similarity
+	})
+	if len(results) > k {
+		results = results[:k]
+	}
+
+	matches := make([]models.Content, len(results))
+	for i, result := range results {
+		matches[i] = result.content
+	}
+	return matches, nil
+}
+
+// Reindex re-embeds every content row under courseID, overwriting any
+// existing embedding rather than skipping rows that already have one, so
+// an operator can use it to recover from a model change or a corrupted
+// vector, not just to catch up rows embedding missed.
+func (s *ContentServiceImpl) Reindex(courseID uint) error {
+	contents, err := s.contentRepo.GetContentsByCourseID(courseID)
+	if err != nil {
+		return fmt.Errorf("%w", apierror.Internal("failed to get course content", err))
+	}
+
+	for _, content := range contents {
+		s.enqueueEmbedContentJob(content.ID)
+	}
+
+	logrus.Infof("Reindex: enqueued %d content rows for course %d", len(contents), courseID)
+	return nil
+}
+
+// GenerateContentForChapter walks every topic in chapterID with the same
+// single query GetTopicsByChapterID already used, enqueues one
+// content-generation job per topic that doesn't already have content, and
+// returns a *models.Batch handle for GetBatch to report progress on.
+func (s *ContentServiceImpl) GenerateContentForChapter(chapterID, userID uint) (*models.Batch, error) {
+	chapter := &models.Chapter{}
+	if err := s.chapterRepo.GetByID(chapterID, chapter); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("%w", apierror.NotFound("chapter"))
+		}
+		return nil, fmt.Errorf("%w", apierror.Internal("failed to get chapter", err))
+	}
+
+	subject := &models.Subject{}
+	if err := s.subjectRepo.GetByID(chapter.SubjectID, subject); err != nil {
+		return nil, fmt.Errorf("%w", apierror.Internal("failed to get subject", err))
+	}
+
+	course := &models.Course{}
+	if err := s.courseRepo.GetByID(subject.CourseID, course); err != nil {
+		return nil, fmt.Errorf("%w", apierror.Internal("failed to get course", err))
+	}
+	if course.UserID == nil || *course.UserID != userID {
+		return nil, fmt.Errorf("%w", apierror.Forbidden("unauthorized access to chapter"))
+	}
+
+	topics, err := s.topicRepo.GetTopicsByChapterID(chapterID)
+	if err != nil {
+		return nil, fmt.Errorf("%w", apierror.Internal("failed to get topics", err))
+	}
+
+	return s.enqueueBatch(userID, course.ID, subject.ID, models.BatchScopeChapter, chapterID, topics)
+}
+
+// GenerateContentForSubject is GenerateContentForChapter's subject-wide
+// counterpart: it walks every topic across every chapter in subjectID with
+// TopicRepository.GetTopicsBySubjectID's single join, instead of looping
+// GetTopicsByChapterID once per chapter.
+func (s *ContentServiceImpl) GenerateContentForSubject(subjectID, userID uint) (*models.Batch, error) {
+	subject := &models.Subject{}
+	if err := s.subjectRepo.GetByID(subjectID, subject); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("%w", apierror.NotFound("subject"))
+		}
+		return nil, fmt.Errorf("%w", apierror.Internal("failed to get subject", err))
+	}
+
+	course := &models.Course{}
+	if err := s.courseRepo.GetByID(subject.CourseID, course); err != nil {
+		return nil, fmt.Errorf("%w", apierror.Internal("failed to get course", err))
+	}
+	if course.UserID == nil || *course.UserID != userID {
+		return nil, fmt.Errorf("%w", apierror.Forbidden("unauthorized access to subject"))
+	}
+
+	topics, err := s.topicRepo.GetTopicsBySubjectID(subjectID)
+	if err != nil {
+		return nil, fmt.Errorf("%w", apierror.Internal("failed to get topics", err))
+	}
+
+	return s.enqueueBatch(userID, course.ID, subject.ID, models.BatchScopeSubject, subjectID, topics)
+}
+
+// enqueueBatch records a Batch scoped to scope/scopeID, then enqueues one
+// content-generation job per topic in topics that doesn't already have
+// content (checked with a single GetContentsByTopicIDs query rather than
+// one GetContentByTopicID call per topic), recording each as a BatchItem.
+func (s *ContentServiceImpl) enqueueBatch(userID, courseID, subjectID uint, scope string, scopeID uint, topics []models.Topic) (*models.Batch, error) {
+	topicIDs := make([]uint, len(topics))
+	for i, topic := range topics {
+		topicIDs[i] = topic.ID
+	}
+
+	existing, err := s.contentRepo.GetContentsByTopicIDs(topicIDs)
+	if err != nil {
+		return nil, fmt.Errorf("%w", apierror.Internal("failed to check existing content", err))
+	}
+	hasContent := make(map[uint]bool, len(existing))
+	for _, content := range existing {
+		hasContent[content.TopicID] = true
+	}
+
+	batch := &models.Batch{UserID: userID, Scope: scope, ScopeID: scopeID}
+	if err := s.batchRepo.Create(batch); err != nil {
+		return nil, fmt.Errorf("%w", apierror.Internal("failed to create batch", err))
+	}
+
+	var items []models.BatchItem
+	for _, topic := range topics {
+		if hasContent[topic.ID] {
+			continue
+		}
+
+		job, err := s.EnqueueGenerateContentJob(userID, &models.ContentGenerateRequest{
+			CourseID:  courseID,
+			SubjectID: subjectID,
+			ChapterID: topic.ChapterID,
+			TopicID:   topic.ID,
+		}, "")
+		if err != nil {
+			return nil, fmt.Errorf("%w", apierror.Internal("failed to enqueue content job", err))
+		}
+
+		items = append(items, models.BatchItem{BatchID: batch.ID, TopicID: topic.ID, JobID: job.ID})
+	}
+
+	if len(items) > 0 {
+		if err := s.batchRepo.CreateItems(items); err != nil {
+			return nil, fmt.Errorf("%w", apierror.Internal("failed to record batch items", err))
+		}
+	}
+	batch.Items = items
+
+	return batch, nil
+}
+
+// GetBatch returns a batch's aggregate and per-topic progress, enforcing
+// that only the user who started it can see it.
+func (s *ContentServiceImpl) GetBatch(batchID, userID uint) (*models.Batch, error) {
+	batch, err := s.batchRepo.GetWithItems(batchID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("%w", apierror.NotFound("batch"))
+		}
+		return nil, fmt.Errorf("%w", apierror.Internal("failed to get batch", err))
+	}
+	if batch.UserID != userID {
+		return nil, fmt.Errorf("%w", apierror.Forbidden("unauthorized access to batch"))
+	}
+	return batch, nil
+}
+
+// resolveAndCheckExisting validates req and the course/subject/chapter/topic
+// ownership chain shared by GenerateContent and GenerateContentStream,
+// then reports any content already generated for the topic so callers can
+// skip regeneration.
+func (s *ContentServiceImpl) resolveAndCheckExisting(userID uint, req *models.ContentGenerateRequest) (*models.Course, *models.Subject, *models.Chapter, *models.Topic, *models.Content, error) {
 	if err := utils.ValidateStruct(req); err != nil {
-		return nil, fmt.Errorf("validation error: %s", utils.FormatValidationErrors(err))
+		return nil, nil, nil, nil, nil, fmt.Errorf("%w", apierror.Validation(utils.FormatValidationErrors(err)))
 	}
 
-	// Verify ownership and get entities
 	course := &models.Course{}
 	if err := s.courseRepo.GetByID(req.CourseID, course); err != nil {
 		if err == gorm.ErrRecordNotFound {
-			return nil, errors.New("course not found")
+			return nil, nil, nil, nil, nil, fmt.Errorf("%w", apierror.NotFound("course"))
 		}
-		return nil, errors.New("failed to get course")
+		return nil, nil, nil, nil, nil, fmt.Errorf("%w", apierror.Internal("failed to get course", err))
 	}
 
-	// Check course ownership
 	if course.UserID == nil || *course.UserID != userID {
-		return nil, errors.New("unauthorized access to course")
+		return nil, nil, nil, nil, nil, fmt.Errorf("%w", apierror.Forbidden("unauthorized access to course"))
 	}
 
-	// Get topic
 	topic := &models.Topic{}
 	if err := s.topicRepo.GetByID(req.TopicID, topic); err != nil {
 		if err == gorm.ErrRecordNotFound {
-			return nil, errors.New("topic not found")
+			return nil, nil, nil, nil, nil, fmt.Errorf("%w", apierror.NotFound("topic"))
 		}
-		return nil, errors.New("failed to get topic")
+		return nil, nil, nil, nil, nil, fmt.Errorf("%w", apierror.Internal("failed to get topic", err))
 	}
 
-	// Get chapter
 	chapter := &models.Chapter{}
 	if err := s.chapterRepo.GetByID(req.ChapterID, chapter); err != nil {
 		if err == gorm.ErrRecordNotFound {
-			return nil, errors.New("chapter not found")
+			return nil, nil, nil, nil, nil, fmt.Errorf("%w", apierror.NotFound("chapter"))
 		}
-		return nil, errors.New("failed to get chapter")
+		return nil, nil, nil, nil, nil, fmt.Errorf("%w", apierror.Internal("failed to get chapter", err))
 	}
 
-	// Get subject
 	subject := &models.Subject{}
 	if err := s.subjectRepo.GetByID(req.SubjectID, subject); err != nil {
 		if err == gorm.ErrRecordNotFound {
-			return nil, errors.New("subject not found")
+			return nil, nil, nil, nil, nil, fmt.Errorf("%w", apierror.NotFound("subject"))
 		}
-		return nil, errors.New("failed to get subject")
+		return nil, nil, nil, nil, nil, fmt.Errorf("%w", apierror.Internal("failed to get subject", err))
 	}
 
-	// Verify relationships
 	if subject.CourseID != req.CourseID {
-		return nil, errors.New("subject does not belong to the specified course")
+		return nil, nil, nil, nil, nil, fmt.Errorf("%w", apierror.Validation("subject does not belong to the specified course"))
 	}
 	if chapter.SubjectID != req.SubjectID {
-		return nil, errors.New("chapter does not belong to the specified subject")
+		return nil, nil, nil, nil, nil, fmt.Errorf("%w", apierror.Validation("chapter does not belong to the specified subject"))
 	}
 	if topic.ChapterID != req.ChapterID {
-		return nil, errors.New("topic does not belong to the specified chapter")
+		return nil, nil, nil, nil, nil, fmt.Errorf("%w", apierror.Validation("topic does not belong to the specified chapter"))
 	}
 
-	// Check if content already exists
 	existingContent, err := s.contentRepo.GetContentByTopicID(req.TopicID)
 	if err != nil {
-		return nil, errors.New("failed to check existing content")
-	}
-	if existingContent != nil {
-		return existingContent, nil
-	}
-
-	// Generate content using Gemini
-	ctx := context.Background()
-	generatedContent, err := s.geminiHelper.GenerateTopicContent(
-		ctx,
-		topic.Name,
-		chapter.Name,
-		subject.Name,
-		course.Name,
-	)
-	if err != nil {
-		logrus.Errorf("Error generating content with Gemini: %v", err)
-		return nil, errors.New("failed to generate content")
-	}
-
-	// Create content model
-	content := &models.Content{
-		TopicID: req.TopicID,
-		Content: generatedContent,
+		return nil, nil, nil, nil, nil, fmt.Errorf("%w", apierror.Internal("failed to check existing content", err))
 	}
 
-	// Save content
-	if err := s.contentRepo.CreateContent(content); err != nil {
-		logrus.Errorf("Error saving content: %v", err)
-		return nil, errors.New("failed to save content")
-	}
-
-	logrus.Infof("Generated content for topic: %s", topic.Name)
-	return content, nil
+	return course, subject, chapter, topic, existingContent, nil
 }
 
 // GetContentByTopicID returns content for a specific topic
@@ -149,41 +729,41 @@ func (s *ContentServiceImpl) GetContentByTopicID(topicID, userID uint) (*models.
 	topic := &models.Topic{}
 	if err := s.topicRepo.GetByID(topicID, topic); err != nil {
 		if err == gorm.ErrRecordNotFound {
-			return nil, errors.New("topic not found")
+			return nil, fmt.Errorf("%w", apierror.NotFound("topic"))
 		}
-		return nil, errors.New("failed to get topic")
+		return nil, fmt.Errorf("%w", apierror.Internal("failed to get topic", err))
 	}
 
 	// Get chapter to verify ownership chain
 	chapter := &models.Chapter{}
 	if err := s.chapterRepo.GetByID(topic.ChapterID, chapter); err != nil {
-		return nil, errors.New("failed to get chapter")
+		return nil, fmt.Errorf("%w", apierror.Internal("failed to get chapter", err))
 	}
 
 	// Get subject to verify ownership chain
 	subject := &models.Subject{}
 	if err := s.subjectRepo.GetByID(chapter.SubjectID, subject); err != nil {
-		return nil, errors.New("failed to get subject")
+		return nil, fmt.Errorf("%w", apierror.Internal("failed to get subject", err))
 	}
 
 	// Get course to verify ownership
 	course := &models.Course{}
 	if err := s.courseRepo.GetByID(subject.CourseID, course); err != nil {
-		return nil, errors.New("failed to get course")
+		return nil, fmt.Errorf("%w", apierror.Internal("failed to get course", err))
 	}
 
 	// Check ownership
 	if course.UserID == nil || *course.UserID != userID {
-		return nil, errors.New("unauthorized access to content")
+		return nil, fmt.Errorf("%w", apierror.Forbidden("unauthorized access to content"))
 	}
 
 	// Get content
 	content, err := s.contentRepo.GetContentByTopicID(topicID)
 	if err != nil {
-		return nil, errors.New("failed to get content")
+		return nil, fmt.Errorf("%w", apierror.Internal("failed to get content", err))
 	}
 	if content == nil {
-		return nil, errors.New("content not found")
+		return nil, fmt.Errorf("%w", apierror.NotFound("content"))
 	}
 
 	return content, nil
@@ -195,9 +775,9 @@ func (s *ContentServiceImpl) UpdateContent(contentID, userID uint, newContent st
 	content := &models.Content{}
 	if err := s.contentRepo.GetByID(contentID, content); err != nil {
 		if err == gorm.ErrRecordNotFound {
-			return nil, errors.New("content not found")
+			return nil, fmt.Errorf("%w", apierror.NotFound("content"))
 		}
-		return nil, errors.New("failed to get content")
+		return nil, fmt.Errorf("%w", apierror.Internal("failed to get content", err))
 	}
 
 	// Verify ownership through the content chain
@@ -211,7 +791,7 @@ func (s *ContentServiceImpl) UpdateContent(contentID, userID uint, newContent st
 
 	if err := s.contentRepo.UpdateContent(content); err != nil {
 		logrus.Errorf("Error updating content: %v", err)
-		return nil, errors.New("failed to update content")
+		return nil, fmt.Errorf("%w", apierror.Internal("failed to update content", err))
 	}
 
 	logrus.Infof("Content updated successfully: %d", contentID)
@@ -224,9 +804,9 @@ func (s *ContentServiceImpl) DeleteContent(contentID, userID uint) error {
 	content := &models.Content{}
 	if err := s.contentRepo.GetByID(contentID, content); err != nil {
 		if err == gorm.ErrRecordNotFound {
-			return errors.New("content not found")
+			return fmt.Errorf("%w", apierror.NotFound("content"))
 		}
-		return errors.New("failed to get content")
+		return fmt.Errorf("%w", apierror.Internal("failed to get content", err))
 	}
 
 	// Verify ownership through the content chain
@@ -238,9 +818,9 @@ func (s *ContentServiceImpl) DeleteContent(contentID, userID uint) error {
 	// Delete content
 	if err := s.contentRepo.Delete(contentID, &models.Content{}); err != nil {
 		logrus.Errorf("Error deleting content: %v", err)
-		return errors.New("failed to delete content")
+		return fmt.Errorf("%w", apierror.Internal("failed to delete content", err))
 	}
 
 	logrus.Infof("Content deleted successfully: %d", contentID)
 	return nil
-}
\ No newline at end of file
+}
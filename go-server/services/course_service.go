@@ -1,39 +1,92 @@
 package services
 
 import (
+	"archive/zip"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"go-server/apierror"
+	"go-server/jobs"
 	"go-server/models"
 	"go-server/repositories"
 	"go-server/utils"
+	"io"
+	"regexp"
 
 	"github.com/sirupsen/logrus"
 	"gorm.io/gorm"
 )
 
+// jobKindGenerateSubjects is the kind registered with queue by
+// NewCourseService.
+const jobKindGenerateSubjects = "generate_subjects"
+
 type CourseService interface {
 	CreateCourse(userID uint, req *models.CourseCreateRequest) (*models.Course, error)
-	GetUserCourses(userID uint) ([]models.Course, error)
+	GetUserCoursesPaginated(userID uint, q *models.ListQuery) ([]models.Course, int64, error)
 	GetCourseByID(courseID, userID uint) (*models.Course, error)
+	// GetHierarchy assembles a course's full subjects/chapters/topics tree
+	// from three batched queries (one per level, each scoped with an IN
+	// across the previous level's IDs) instead of one query per subject and
+	// one per chapter, and returns a strong ETag the caller can use to
+	// answer an unchanged request with 304 Not Modified.
+	GetHierarchy(courseID, userID uint) (*CourseHierarchy, error)
 	UpdateCourse(courseID, userID uint, req *models.CourseUpdateRequest) (*models.Course, error)
 	DeleteCourse(courseID, userID uint) error
 	GenerateSubjects(courseID, userID uint) ([]models.Subject, error)
-	SearchCourses(query string, limit int) ([]models.Course, error)
+	// EnqueueGenerateSubjectsJob queues the same work GenerateSubjects does
+	// synchronously, returning a *models.Job a client can poll instead of
+	// holding the request open for the LLM call. idempotencyKey, when
+	// non-empty, makes a retried submission return the original job instead
+	// of enqueueing (and re-billing) a duplicate generation.
+	EnqueueGenerateSubjectsJob(courseID, userID uint, idempotencyKey string) (*models.Job, error)
+	SearchCourses(query string, filter repositories.CourseSearchFilter, offset, limit int) ([]repositories.CourseSearchResult, int64, error)
+	SearchCourseFacets(query string, filter repositories.CourseSearchFilter, facet string) (repositories.CourseFacetCounts, error)
+	ExportCourse(courseID, userID uint, w io.Writer) error
+	ImportCourse(userID uint, archive *zip.Reader) (*models.Course, error)
 }
 
 type CourseServiceImpl struct {
-	courseRepo  repositories.CourseRepository
-	subjectRepo repositories.SubjectRepository
-	geminiHelper *utils.GeminiHelper
+	courseRepo   repositories.CourseRepository
+	subjectRepo  repositories.SubjectRepository
+	chapterRepo  repositories.ChapterRepository
+	topicRepo    repositories.TopicRepository
+	contentRepo  repositories.ContentRepository
+	imageService ImageService
+	llm          utils.LLMProvider
+	queue        *jobs.Queue
+	userLimiter  *userGenerationLimiter
 }
 
-func NewCourseService(courseRepo repositories.CourseRepository, subjectRepo repositories.SubjectRepository) CourseService {
-	return &CourseServiceImpl{
+func NewCourseService(
+	courseRepo repositories.CourseRepository,
+	subjectRepo repositories.SubjectRepository,
+	chapterRepo repositories.ChapterRepository,
+	topicRepo repositories.TopicRepository,
+	contentRepo repositories.ContentRepository,
+	imageService ImageService,
+	llm utils.LLMProvider,
+	queue *jobs.Queue,
+	maxConcurrentGenerationsPerUser int,
+) CourseService {
+	s := &CourseServiceImpl{
 		courseRepo:   courseRepo,
 		subjectRepo:  subjectRepo,
-		geminiHelper: utils.NewGeminiHelper(),
+		chapterRepo:  chapterRepo,
+		topicRepo:    topicRepo,
+		contentRepo:  contentRepo,
+		imageService: imageService,
+		llm:          llm,
+		queue:        queue,
+		userLimiter:  newUserGenerationLimiter(maxConcurrentGenerationsPerUser),
 	}
+
+	queue.Register(jobKindGenerateSubjects, s.handleGenerateSubjectsJob)
+
+	return s
 }
 
 // CreateCourse creates a new course
@@ -64,15 +117,16 @@ func (s *CourseServiceImpl) CreateCourse(userID uint, req *models.CourseCreateRe
 	return course, nil
 }
 
-// GetUserCourses returns all courses for a user
-func (s *CourseServiceImpl) GetUserCourses(userID uint) ([]models.Course, error) {
-	courses, err := s.courseRepo.GetUserCourses(userID)
+// GetUserCoursesPaginated returns a page of a user's courses matching q,
+// along with the total count before pagination was applied.
+func (s *CourseServiceImpl) GetUserCoursesPaginated(userID uint, q *models.ListQuery) ([]models.Course, int64, error) {
+	courses, total, err := s.courseRepo.GetUserCoursesPaginated(userID, q)
 	if err != nil {
 		logrus.Errorf("Error getting user courses: %v", err)
-		return nil, errors.New("failed to get courses")
+		return nil, 0, fmt.Errorf("%w", apierror.Internal("failed to get courses", err))
 	}
 
-	return courses, nil
+	return courses, total, nil
 }
 
 // GetCourseByID returns a specific course if user owns it
@@ -94,6 +148,109 @@ func (s *CourseServiceImpl) GetCourseByID(courseID, userID uint) (*models.Course
 	return course, nil
 }
 
+// CourseHierarchy is the assembled tree GetHierarchy returns: the nested
+// course/subjects/chapters/topics data in the same shape the course
+// hierarchy endpoint has always served, plus a strong ETag computed over
+// every row involved so the caller can answer a matching If-None-Match
+// with 304 Not Modified.
+type CourseHierarchy struct {
+	Data map[string]interface{}
+	ETag string
+}
+
+// GetHierarchy fetches a course's subjects, chapters and topics with one
+// batched query per level (GetSubjectsByCourseID, then
+// GetChaptersBySubjectIDs across every subject ID, then
+// GetTopicsByChapterIDs across every chapter ID) instead of the N+1+M
+// pattern of querying chapters per subject and topics per chapter, and
+// assembles the nested tree in memory. The ETag is a SHA-256 hash of the
+// course ID, the latest UpdatedAt across every row in the tree, and the
+// total row count, so it changes whenever anything in the tree does.
+func (s *CourseServiceImpl) GetHierarchy(courseID, userID uint) (*CourseHierarchy, error) {
+	course, err := s.GetCourseByID(courseID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	subjects, err := s.subjectRepo.GetSubjectsByCourseID(courseID)
+	if err != nil {
+		logrus.Errorf("Error getting subjects for hierarchy: %v", err)
+		return nil, errors.New("failed to get subjects")
+	}
+
+	subjectIDs := make([]uint, len(subjects))
+	for i, subject := range subjects {
+		subjectIDs[i] = subject.ID
+	}
+
+	chapters, err := s.chapterRepo.GetChaptersBySubjectIDs(subjectIDs)
+	if err != nil {
+		logrus.Errorf("Error getting chapters for hierarchy: %v", err)
+		return nil, errors.New("failed to get chapters")
+	}
+	chaptersBySubject := make(map[uint][]models.Chapter, len(subjects))
+	chapterIDs := make([]uint, len(chapters))
+	for i, chapter := range chapters {
+		chaptersBySubject[chapter.SubjectID] = append(chaptersBySubject[chapter.SubjectID], chapter)
+		chapterIDs[i] = chapter.ID
+	}
+
+	topics, err := s.topicRepo.GetTopicsByChapterIDs(chapterIDs)
+	if err != nil {
+		logrus.Errorf("Error getting topics for hierarchy: %v", err)
+		return nil, errors.New("failed to get topics")
+	}
+	topicsByChapter := make(map[uint][]models.Topic, len(chapters))
+	for _, topic := range topics {
+		topicsByChapter[topic.ChapterID] = append(topicsByChapter[topic.ChapterID], topic)
+	}
+
+	latest := course.UpdatedAt
+	rowCount := 1 // the course itself
+	subjectsData := make([]map[string]interface{}, 0, len(subjects))
+	for _, subject := range subjects {
+		if subject.UpdatedAt.After(latest) {
+			latest = subject.UpdatedAt
+		}
+		rowCount++
+
+		subjectData := subject.ToDict()
+		subjectChapters := chaptersBySubject[subject.ID]
+		chaptersData := make([]map[string]interface{}, 0, len(subjectChapters))
+		for _, chapter := range subjectChapters {
+			if chapter.UpdatedAt.After(latest) {
+				latest = chapter.UpdatedAt
+			}
+			rowCount++
+
+			chapterData := chapter.ToDict()
+			chapterTopics := topicsByChapter[chapter.ID]
+			topicsData := make([]map[string]interface{}, 0, len(chapterTopics))
+			for _, topic := range chapterTopics {
+				if topic.UpdatedAt.After(latest) {
+					latest = topic.UpdatedAt
+				}
+				rowCount++
+				topicsData = append(topicsData, topic.ToDict())
+			}
+			chapterData["topics"] = topicsData
+			chaptersData = append(chaptersData, chapterData)
+		}
+		subjectData["chapters"] = chaptersData
+		subjectsData = append(subjectsData, subjectData)
+	}
+
+	data := map[string]interface{}{
+		"course":   course.ToDict(),
+		"subjects": subjectsData,
+	}
+
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%d:%d:%d", courseID, latest.UnixNano(), rowCount)))
+	etag := `"` + hex.EncodeToString(sum[:]) + `"`
+
+	return &CourseHierarchy{Data: data, ETag: etag}, nil
+}
+
 // UpdateCourse updates a course
 func (s *CourseServiceImpl) UpdateCourse(courseID, userID uint, req *models.CourseUpdateRequest) (*models.Course, error) {
 	// Get existing course
@@ -146,7 +303,7 @@ func (s *CourseServiceImpl) DeleteCourse(courseID, userID uint) error {
 	return nil
 }
 
-// GenerateSubjects generates subjects for a course using Gemini AI
+// GenerateSubjects generates subjects for a course using the configured LLM provider
 func (s *CourseServiceImpl) GenerateSubjects(courseID, userID uint) ([]models.Subject, error) {
 	// Get course
 	course, err := s.GetCourseByID(courseID, userID)
@@ -165,11 +322,11 @@ func (s *CourseServiceImpl) GenerateSubjects(courseID, userID uint) ([]models.Su
 		return existingSubjects, nil
 	}
 
-	// Generate subjects using Gemini
+	// Generate subjects using the configured LLM provider
 	ctx := context.Background()
-	subjectNames, err := s.geminiHelper.GenerateSubjects(ctx, course.Name, course.Description)
+	subjectNames, err := s.llm.GenerateSubjects(ctx, course.Name, course.Description)
 	if err != nil {
-		logrus.Errorf("Error generating subjects with Gemini: %v", err)
+		logrus.Errorf("Error generating subjects: %v", err)
 		return nil, errors.New("failed to generate subjects")
 	}
 
@@ -198,18 +355,255 @@ func (s *CourseServiceImpl) GenerateSubjects(courseID, userID uint) ([]models.Su
 	return subjects, nil
 }
 
+// generateSubjectsJobPayload is EnqueueGenerateSubjectsJob's JSON payload.
+type generateSubjectsJobPayload struct {
+	CourseID uint `json:"course_id"`
+	UserID   uint `json:"user_id"`
+}
+
+// EnqueueGenerateSubjectsJob queues GenerateSubjects to run on the worker
+// pool.
+func (s *CourseServiceImpl) EnqueueGenerateSubjectsJob(courseID, userID uint, idempotencyKey string) (*models.Job, error) {
+	payload, err := json.Marshal(generateSubjectsJobPayload{CourseID: courseID, UserID: userID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode job payload: %w", err)
+	}
+	return s.queue.Enqueue(jobKindGenerateSubjects, string(payload), idempotencyKey)
+}
+
+func (s *CourseServiceImpl) handleGenerateSubjectsJob(jobID uint, payload string) (string, error) {
+	var p generateSubjectsJobPayload
+	if err := json.Unmarshal([]byte(payload), &p); err != nil {
+		return "", fmt.Errorf("failed to decode job payload: %w", err)
+	}
+
+	s.userLimiter.acquire(p.UserID)
+	defer s.userLimiter.release(p.UserID)
+
+	subjects, err := s.GenerateSubjects(p.CourseID, p.UserID)
+	if err != nil {
+		return "", err
+	}
+
+	subjectDicts := make([]map[string]interface{}, len(subjects))
+	for i, subject := range subjects {
+		subjectDicts[i] = subject.ToDict()
+	}
+	result, err := json.Marshal(subjectDicts)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode job result: %w", err)
+	}
+	return string(result), nil
+}
+
 // SearchCourses searches for courses
-func (s *CourseServiceImpl) SearchCourses(query string, limit int) ([]models.Course, error) {
+func (s *CourseServiceImpl) SearchCourses(query string, filter repositories.CourseSearchFilter, offset, limit int) ([]repositories.CourseSearchResult, int64, error) {
 	if query == "" {
-		return []models.Course{}, nil
+		return []repositories.CourseSearchResult{}, 0, nil
 	}
 
 	query = utils.SanitizeString(query)
-	courses, err := s.courseRepo.SearchCourses(query, limit)
+	results, total, err := s.courseRepo.SearchCourses(query, filter, offset, limit)
 	if err != nil {
 		logrus.Errorf("Error searching courses: %v", err)
-		return nil, errors.New("failed to search courses")
+		return nil, 0, errors.New("failed to search courses")
+	}
+
+	return results, total, nil
+}
+
+// SearchCourseFacets returns facet counts over the same match set
+// SearchCourses(query, filter, ...) would page through.
+func (s *CourseServiceImpl) SearchCourseFacets(query string, filter repositories.CourseSearchFilter, facet string) (repositories.CourseFacetCounts, error) {
+	if query == "" {
+		return repositories.CourseFacetCounts{}, nil
+	}
+
+	query = utils.SanitizeString(query)
+	counts, err := s.courseRepo.FacetCounts(query, filter, facet)
+	if err != nil {
+		logrus.Errorf("Error computing course search facets: %v", err)
+		return nil, errors.New("failed to compute search facets")
 	}
 
-	return courses, nil
-}
\ No newline at end of file
+	return counts, nil
+}
+
+var topicExportPathPattern = regexp.MustCompile(`^subjects/([^/]+)/chapters/([^/]+)/topics/([^/]+)\.md$`)
+
+// ExportCourse streams the full course tree as a ZIP archive to w: course.json
+// metadata, subjects/<slug>/chapters/<slug>/topics/<slug>.md per Content.Content,
+// and an images/ folder with the course and subject cover images.
+func (s *CourseServiceImpl) ExportCourse(courseID, userID uint, w io.Writer) error {
+	course, err := s.GetCourseByID(courseID, userID)
+	if err != nil {
+		return err
+	}
+
+	tree, err := s.courseRepo.GetFullCourseTree(courseID)
+	if err != nil {
+		return fmt.Errorf("%w", apierror.Internal("failed to load course tree", err))
+	}
+
+	zw := zip.NewWriter(w)
+
+	meta, err := json.MarshalIndent(course.ToDict(), "", "  ")
+	if err != nil {
+		return fmt.Errorf("%w", apierror.Internal("failed to encode course metadata", err))
+	}
+	if err := writeZipEntry(zw, "course.json", meta); err != nil {
+		return err
+	}
+
+	if course.ImageURL != nil {
+		if imageBytes, downloadErr := s.imageService.DownloadImage(*course.ImageURL); downloadErr == nil {
+			if err := writeZipEntry(zw, "images/course.png", imageBytes); err != nil {
+				return err
+			}
+		} else {
+			logrus.Warnf("Skipping course cover image in export: %v", downloadErr)
+		}
+	}
+
+	for _, subject := range tree.Subjects {
+		subjectSlug := utils.Slugify(subject.Name)
+
+		if subject.ImageURL != nil {
+			if imageBytes, downloadErr := s.imageService.DownloadImage(*subject.ImageURL); downloadErr == nil {
+				path := fmt.Sprintf("images/subjects/%s.png", subjectSlug)
+				if err := writeZipEntry(zw, path, imageBytes); err != nil {
+					return err
+				}
+			} else {
+				logrus.Warnf("Skipping subject %d cover image in export: %v", subject.ID, downloadErr)
+			}
+		}
+
+		for _, chapter := range subject.Chapters {
+			chapterSlug := utils.Slugify(chapter.Name)
+
+			for _, topic := range chapter.Topics {
+				topicSlug := utils.Slugify(topic.Name)
+				for _, content := range topic.Contents {
+					path := fmt.Sprintf("subjects/%s/chapters/%s/topics/%s.md", subjectSlug, chapterSlug, topicSlug)
+					if err := writeZipEntry(zw, path, []byte(content.Content)); err != nil {
+						return err
+					}
+				}
+			}
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("%w", apierror.Internal("failed to finalize export archive", err))
+	}
+	return nil
+}
+
+// writeZipEntry writes a single file entry into an open zip.Writer.
+func writeZipEntry(zw *zip.Writer, name string, data []byte) error {
+	fw, err := zw.Create(name)
+	if err != nil {
+		return fmt.Errorf("%w", apierror.Internal("failed to write export entry "+name, err))
+	}
+	if _, err := fw.Write(data); err != nil {
+		return fmt.Errorf("%w", apierror.Internal("failed to write export entry "+name, err))
+	}
+	return nil
+}
+
+// ImportCourse recreates a course and its subject/chapter/topic/content tree
+// from a ZIP archive produced by ExportCourse, for cross-instance transfer.
+// Cover images are not re-uploaded; only the text content is restored.
+func (s *CourseServiceImpl) ImportCourse(userID uint, archive *zip.Reader) (*models.Course, error) {
+	metaFile, err := archive.Open("course.json")
+	if err != nil {
+		return nil, fmt.Errorf("%w", apierror.Validation("archive is missing course.json"))
+	}
+	metaBytes, err := io.ReadAll(metaFile)
+	metaFile.Close()
+	if err != nil {
+		return nil, fmt.Errorf("%w", apierror.Validation("failed to read course.json"))
+	}
+
+	var meta map[string]interface{}
+	if err := json.Unmarshal(metaBytes, &meta); err != nil {
+		return nil, fmt.Errorf("%w", apierror.Validation("course.json is not valid JSON"))
+	}
+
+	name, _ := meta["name"].(string)
+	description, _ := meta["description"].(string)
+	if name == "" || description == "" {
+		return nil, fmt.Errorf("%w", apierror.Validation("course.json is missing name or description"))
+	}
+
+	course := &models.Course{
+		Name:        utils.SanitizeString(name),
+		Description: utils.SanitizeString(description),
+		UserID:      &userID,
+	}
+	if err := s.courseRepo.Create(course); err != nil {
+		return nil, fmt.Errorf("%w", apierror.Internal("failed to create imported course", err))
+	}
+
+	subjectIDs := map[string]uint{}
+	chapterIDs := map[string]uint{}
+
+	for _, f := range archive.File {
+		matches := topicExportPathPattern.FindStringSubmatch(f.Name)
+		if matches == nil {
+			continue
+		}
+		subjectSlug, chapterSlug, topicSlug := matches[1], matches[2], matches[3]
+
+		subjectID, ok := subjectIDs[subjectSlug]
+		if !ok {
+			subject := models.Subject{Name: subjectSlug, CourseID: course.ID}
+			if err := s.subjectRepo.Create(&subject); err != nil {
+				return nil, fmt.Errorf("%w", apierror.Internal("failed to import subject", err))
+			}
+			subjectID = subject.ID
+			subjectIDs[subjectSlug] = subjectID
+		}
+
+		chapterKey := subjectSlug + "/" + chapterSlug
+		chapterID, ok := chapterIDs[chapterKey]
+		if !ok {
+			chapter := models.Chapter{Name: chapterSlug, SubjectID: subjectID}
+			if err := s.chapterRepo.Create(&chapter); err != nil {
+				return nil, fmt.Errorf("%w", apierror.Internal("failed to import chapter", err))
+			}
+			chapterID = chapter.ID
+			chapterIDs[chapterKey] = chapterID
+		}
+
+		topic := models.Topic{Name: topicSlug, ChapterID: chapterID}
+		if err := s.topicRepo.Create(&topic); err != nil {
+			return nil, fmt.Errorf("%w", apierror.Internal("failed to import topic", err))
+		}
+
+		rc, err := f.Open()
+		if err != nil {
+			return nil, fmt.Errorf("%w", apierror.Internal("failed to read "+f.Name, err))
+		}
+		contentBytes, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			return nil, fmt.Errorf("%w", apierror.Internal("failed to read "+f.Name, err))
+		}
+
+		content := &models.Content{TopicID: topic.ID, Content: string(contentBytes)}
+		if err := s.contentRepo.CreateContent(content); err != nil {
+			return nil, fmt.Errorf("%w", apierror.Internal("failed to import content", err))
+		}
+	}
+
+	if len(subjectIDs) > 0 {
+		if err := s.courseRepo.UpdateHasSubjects(course.ID, true); err != nil {
+			logrus.Errorf("Error updating has_subjects flag after import: %v", err)
+		}
+	}
+
+	logrus.Infof("Imported course '%s' (ID: %d) for user %d", course.Name, course.ID, userID)
+	return course, nil
+}
@@ -0,0 +1,187 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"strings"
+	"time"
+
+	"go-server/config"
+	"go-server/models"
+	"go-server/repositories"
+	"go-server/utils"
+
+	"github.com/sirupsen/logrus"
+)
+
+// digestUnsubscribeTokenExpiry bounds how long the unsubscribe link embedded
+// in one digest email stays valid. A fresh token is issued on every send, so
+// this only needs to outlive how long a user might leave the email unread,
+// not the digest cadence itself.
+const digestUnsubscribeTokenExpiry = 90 * 24 * time.Hour
+
+// DigestService periodically emails each opted-in user a summary of
+// digest-worthy activity (newly generated chapters, subjects, and content)
+// on courses they own, since their last digest.
+type DigestService interface {
+	// RunDigest summarizes and sends one digest to every eligible user.
+	// It's meant to be invoked on a fixed cadence by a scheduler goroutine
+	// (see cmd/main.go), not per-request.
+	RunDigest() error
+	// Unsubscribe redeems a token from the unsubscribe link embedded in a
+	// digest email and opts the owning user out of all future digests.
+	Unsubscribe(token string) error
+}
+
+type DigestServiceImpl struct {
+	userRepo        repositories.UserRepository
+	digestEventRepo repositories.DigestEventRepository
+	digestStateRepo repositories.UserDigestStateRepository
+	tokenRepo       repositories.TokenRepository
+	emailService    EmailService
+	appName         string
+	frontendURL     string
+}
+
+func NewDigestService(
+	userRepo repositories.UserRepository,
+	digestEventRepo repositories.DigestEventRepository,
+	digestStateRepo repositories.UserDigestStateRepository,
+	tokenRepo repositories.TokenRepository,
+	emailService EmailService,
+	cfg *config.Config,
+) DigestService {
+	return &DigestServiceImpl{
+		userRepo:        userRepo,
+		digestEventRepo: digestEventRepo,
+		digestStateRepo: digestStateRepo,
+		tokenRepo:       tokenRepo,
+		emailService:    emailService,
+		appName:         cfg.AppName,
+		frontendURL:     cfg.FrontendURL,
+	}
+}
+
+// RunDigest sends a digest to every active, non-opted-out user who has new
+// digest events since their last one; a user with nothing new is skipped
+// entirely rather than sent an empty email.
+func (s *DigestServiceImpl) RunDigest() error {
+	users, err := s.userRepo.ListDigestRecipients()
+	if err != nil {
+		return fmt.Errorf("failed to list digest recipients: %w", err)
+	}
+
+	var lastErr error
+	for _, user := range users {
+		if err := s.sendUserDigest(&user); err != nil {
+			logrus.Errorf("Error sending digest to user %d: %v", user.ID, err)
+			lastErr = err
+		}
+	}
+	return lastErr
+}
+
+func (s *DigestServiceImpl) sendUserDigest(user *models.User) error {
+	state, err := s.digestStateRepo.GetByUserID(user.ID)
+	if err != nil {
+		return fmt.Errorf("failed to load digest state: %w", err)
+	}
+
+	since := user.CreatedAt
+	if state != nil && state.LastSentAt != nil {
+		since = *state.LastSentAt
+	}
+
+	events, err := s.digestEventRepo.ListSince(user.ID, since)
+	if err != nil {
+		return fmt.Errorf("failed to list digest events: %w", err)
+	}
+	if len(events) == 0 {
+		return nil
+	}
+
+	unsubscribeURL, err := s.unsubscribeURL(user.ID)
+	if err != nil {
+		return fmt.Errorf("failed to build unsubscribe link: %w", err)
+	}
+
+	subject := fmt.Sprintf("Your %s learning digest", s.appName)
+	if err := s.emailService.SendEmail(user.Email, subject, s.renderHTML(events, unsubscribeURL), s.renderText(events, unsubscribeURL)); err != nil {
+		return fmt.Errorf("failed to send digest email: %w", err)
+	}
+
+	if err := s.digestStateRepo.SetLastSentAt(user.ID, time.Now()); err != nil {
+		logrus.Errorf("Error recording digest state for user %d: %v", user.ID, err)
+	}
+	return nil
+}
+
+// unsubscribeURL issues a fresh digest_unsubscribe token for userID, the
+// same unified-token-store pattern password reset and email verification
+// use (see models.Token), and returns the link to embed in the email.
+func (s *DigestServiceImpl) unsubscribeURL(userID uint) (string, error) {
+	rawToken, err := utils.GenerateSecureToken()
+	if err != nil {
+		return "", fmt.Errorf("failed to generate unsubscribe token: %w", err)
+	}
+
+	tokenRecord := &models.Token{
+		TokenHash: utils.HashToken(rawToken),
+		Type:      models.TokenTypeDigestUnsubscribe,
+		UserID:    userID,
+		ExpiresAt: time.Now().Add(digestUnsubscribeTokenExpiry),
+	}
+	if err := s.tokenRepo.CreateToken(tokenRecord); err != nil {
+		return "", fmt.Errorf("failed to store unsubscribe token: %w", err)
+	}
+
+	return fmt.Sprintf("%s/digest/unsubscribe?token=%s", s.frontendURL, rawToken), nil
+}
+
+// Unsubscribe redeems a digest_unsubscribe token and opts its user out of
+// all future digests.
+func (s *DigestServiceImpl) Unsubscribe(token string) error {
+	tokenHash := utils.HashToken(token)
+	tokenRecord, err := s.tokenRepo.GetToken(tokenHash)
+	if err != nil {
+		return fmt.Errorf("failed to look up unsubscribe token: %w", err)
+	}
+	if tokenRecord == nil || tokenRecord.Type != models.TokenTypeDigestUnsubscribe {
+		return errors.New("invalid or expired unsubscribe link")
+	}
+
+	if err := s.userRepo.UpdateDigestOptOut(tokenRecord.UserID); err != nil {
+		return fmt.Errorf("failed to record digest opt-out: %w", err)
+	}
+	if err := s.tokenRepo.DeleteToken(tokenHash); err != nil {
+		logrus.Errorf("Error deleting consumed unsubscribe token: %v", err)
+	}
+
+	logrus.Infof("User %d unsubscribed from the learning digest", tokenRecord.UserID)
+	return nil
+}
+
+// renderHTML builds the digest email's HTML body as a flat list of events,
+// escaping each message since it's user/LLM-derived content.
+func (s *DigestServiceImpl) renderHTML(events []models.DigestEvent, unsubscribeURL string) string {
+	var items strings.Builder
+	for _, event := range events {
+		items.WriteString(fmt.Sprintf("<li>%s</li>", html.EscapeString(event.Message)))
+	}
+
+	return fmt.Sprintf(
+		`<html><body><h2>Your %s learning digest</h2><ul>%s</ul><p><a href="%s">Unsubscribe from digest emails</a></p></body></html>`,
+		html.EscapeString(s.appName), items.String(), unsubscribeURL,
+	)
+}
+
+// renderText is renderHTML's plain-text counterpart.
+func (s *DigestServiceImpl) renderText(events []models.DigestEvent, unsubscribeURL string) string {
+	lines := make([]string, len(events))
+	for i, event := range events {
+		lines[i] = "- " + event.Message
+	}
+
+	return fmt.Sprintf("Your %s learning digest\n\n%s\n\nUnsubscribe: %s", s.appName, strings.Join(lines, "\n"), unsubscribeURL)
+}
@@ -1,9 +1,7 @@
 package services
 
 import (
-	"bytes"
 	"fmt"
-	"html/template"
 	"net/http"
 	"net/url"
 	"os"
@@ -14,8 +12,18 @@ import (
 
 type EmailService interface {
 	SendPasswordResetEmail(email, token string) error
+	SendVerificationEmail(email, token string) error
 	SendWelcomeEmail(email, name string) error
 	SendEmail(to, subject, htmlBody, textBody string) error
+
+	// ListTemplates, GetTemplate, SaveTemplate, and PreviewEmail back an
+	// admin template-editing API, letting operators rebrand transactional
+	// emails by editing the files under EMAIL_TEMPLATE_DIR instead of
+	// recompiling.
+	ListTemplates() []EmailTemplateInfo
+	GetTemplate(name string) (EmailTemplate, error)
+	SaveTemplate(name, htmlBody, textBody string) error
+	PreviewEmail(name string, sampleData map[string]string) (EmailPreview, error)
 }
 
 type EmailServiceImpl struct {
@@ -26,6 +34,7 @@ type EmailServiceImpl struct {
 	appName       string
 	frontendURL   string
 	isConfigured  bool
+	templates     *templateStore
 }
 
 func NewEmailService() EmailService {
@@ -64,6 +73,15 @@ func NewEmailService() EmailService {
 		logrus.Infof("Email service configured successfully using Mailgun domain: %s", service.mailgunDomain)
 	}
 
+	templateDir := os.Getenv("EMAIL_TEMPLATE_DIR")
+	if templateDir == "" {
+		templateDir = "./email_templates"
+	}
+	service.templates = newTemplateStore(templateDir)
+	if err := service.templates.ensureDefaults(); err != nil {
+		logrus.Errorf("Failed to write default email templates to %s: %v", templateDir, err)
+	}
+
 	return service
 }
 
@@ -74,71 +92,33 @@ func (s *EmailServiceImpl) SendPasswordResetEmail(email, token string) error {
 		return nil
 	}
 
-	subject := fmt.Sprintf("Password Reset - %s", s.appName)
-	resetURL := fmt.Sprintf("%s/reset-password?token=%s", s.frontendURL, token)
-
-	// HTML template
-	htmlTemplate := `
-<!DOCTYPE html>
-<html>
-<head>
-    <meta charset="utf-8">
-    <title>{{.Subject}}</title>
-</head>
-<body style="font-family: Arial, sans-serif; line-height: 1.6; color: #333;">
-    <div style="max-width: 600px; margin: 0 auto; padding: 20px;">
-        <h2 style="color: #2c3e50;">Password Reset Request</h2>
-        <p>Hello,</p>
-        <p>We received a request to reset your password for your {{.AppName}} account.</p>
-        <p>Click the button below to reset your password:</p>
-        <div style="text-align: center; margin: 30px 0;">
-            <a href="{{.ResetURL}}" style="background-color: #3498db; color: white; padding: 12px 30px; text-decoration: none; border-radius: 5px; display: inline-block;">Reset Password</a>
-        </div>
-        <p>Or copy and paste this link into your browser:</p>
-        <p style="word-break: break-all; background-color: #f8f9fa; padding: 10px; border-radius: 3px;">{{.ResetURL}}</p>
-        <p><strong>This link will expire in 1 hour.</strong></p>
-        <p>If you didn't request this password reset, please ignore this email or contact support if you have concerns.</p>
-        <hr style="border: none; border-top: 1px solid #eee; margin: 30px 0;">
-        <p style="font-size: 12px; color: #7f8c8d;">
-            Best regards,<br>
-            The {{.AppName}} Team
-        </p>
-    </div>
-</body>
-</html>`
-
-	// Text template
-	textTemplate := `Password Reset Request
-
-Hello,
-
-We received a request to reset your password for your {{.AppName}} account.
-
-Please click the following link to reset your password:
-{{.ResetURL}}
-
-This link will expire in 1 hour.
-
-If you didn't request this password reset, please ignore this email or contact support if you have concerns.
-
-Best regards,
-The {{.AppName}} Team`
-
-	data := map[string]string{
-		"Subject":   subject,
-		"AppName":   s.appName,
-		"ResetURL":  resetURL,
-		"Email":     email,
-	}
+	data := s.baseContext()
+	data["ResetURL"] = fmt.Sprintf("%s/reset-password?token=%s", s.frontendURL, token)
+	data["Email"] = email
 
-	htmlBody, err := s.renderTemplate(htmlTemplate, data)
+	subject, htmlBody, textBody, err := s.renderEmail(templateNamePasswordReset, data)
 	if err != nil {
-		return fmt.Errorf("failed to render HTML template: %w", err)
+		return err
+	}
+
+	return s.SendEmail(email, subject, htmlBody, textBody)
+}
+
+// SendVerificationEmail sends the link a newly-registered user follows to
+// confirm their email address and activate their account.
+func (s *EmailServiceImpl) SendVerificationEmail(email, token string) error {
+	if !s.isConfigured {
+		logrus.Warning("Email service not configured, skipping verification email")
+		return nil
 	}
 
-	textBody, err := s.renderTemplate(textTemplate, data)
+	data := s.baseContext()
+	data["VerifyURL"] = fmt.Sprintf("%s/verify-email?token=%s", s.frontendURL, token)
+	data["Email"] = email
+
+	subject, htmlBody, textBody, err := s.renderEmail(templateNameVerification, data)
 	if err != nil {
-		return fmt.Errorf("failed to render text template: %w", err)
+		return err
 	}
 
 	return s.SendEmail(email, subject, htmlBody, textBody)
@@ -151,75 +131,12 @@ func (s *EmailServiceImpl) SendWelcomeEmail(email, name string) error {
 		return nil
 	}
 
-	subject := fmt.Sprintf("Welcome to %s!", s.appName)
-
-	htmlTemplate := `
-<!DOCTYPE html>
-<html>
-<head>
-    <meta charset="utf-8">
-    <title>{{.Subject}}</title>
-</head>
-<body style="font-family: Arial, sans-serif; line-height: 1.6; color: #333;">
-    <div style="max-width: 600px; margin: 0 auto; padding: 20px;">
-        <h2 style="color: #2c3e50;">Welcome to {{.AppName}}!</h2>
-        <p>Hello {{.Name}},</p>
-        <p>Welcome to {{.AppName}}! We're excited to have you on board.</p>
-        <p>You can now access all our features:</p>
-        <ul>
-            <li>Create and manage courses</li>
-            <li>Generate AI-powered educational content</li>
-            <li>Access comprehensive learning materials</li>
-            <li>Track your progress</li>
-        </ul>
-        <div style="text-align: center; margin: 30px 0;">
-            <a href="{{.FrontendURL}}" style="background-color: #27ae60; color: white; padding: 12px 30px; text-decoration: none; border-radius: 5px; display: inline-block;">Get Started</a>
-        </div>
-        <p>If you have any questions, feel free to contact us at {{.ContactEmail}}.</p>
-        <hr style="border: none; border-top: 1px solid #eee; margin: 30px 0;">
-        <p style="font-size: 12px; color: #7f8c8d;">
-            Best regards,<br>
-            The {{.AppName}} Team
-        </p>
-    </div>
-</body>
-</html>`
-
-	textTemplate := `Welcome to {{.AppName}}!
-
-Hello {{.Name}},
-
-Welcome to {{.AppName}}! We're excited to have you on board.
-
-You can now access all our features:
-- Create and manage courses
-- Generate AI-powered educational content
-- Access comprehensive learning materials
-- Track your progress
-
-Visit our platform: {{.FrontendURL}}
-
-If you have any questions, feel free to contact us at {{.ContactEmail}}.
-
-Best regards,
-The {{.AppName}} Team`
-
-	data := map[string]string{
-		"Subject":     subject,
-		"AppName":     s.appName,
-		"Name":        name,
-		"FrontendURL": s.frontendURL,
-		"ContactEmail": s.contactEmail,
-	}
-
-	htmlBody, err := s.renderTemplate(htmlTemplate, data)
-	if err != nil {
-		return fmt.Errorf("failed to render HTML template: %w", err)
-	}
+	data := s.baseContext()
+	data["Name"] = name
 
-	textBody, err := s.renderTemplate(textTemplate, data)
+	subject, htmlBody, textBody, err := s.renderEmail(templateNameWelcome, data)
 	if err != nil {
-		return fmt.Errorf("failed to render text template: %w", err)
+		return err
 	}
 
 	return s.SendEmail(email, subject, htmlBody, textBody)
@@ -266,17 +183,66 @@ func (s *EmailServiceImpl) SendEmail(to, subject, htmlBody, textBody string) err
 	return nil
 }
 
-// renderTemplate renders a template string with data
-func (s *EmailServiceImpl) renderTemplate(templateStr string, data map[string]string) (string, error) {
-	tmpl, err := template.New("email").Parse(templateStr)
+// baseContext returns the placeholder values shared by every email: AppName,
+// FrontendURL, and ContactEmail. Callers add whatever email-specific fields
+// the template needs (e.g. ResetURL, Name) on top of the returned map.
+func (s *EmailServiceImpl) baseContext() map[string]string {
+	return map[string]string{
+		"AppName":      s.appName,
+		"FrontendURL":  s.frontendURL,
+		"ContactEmail": s.contactEmail,
+	}
+}
+
+// renderEmail loads template name's current HTML/text pair and renders its
+// subject and bodies against data.
+func (s *EmailServiceImpl) renderEmail(name string, data map[string]string) (subject, htmlBody, textBody string, err error) {
+	pair, err := s.templates.load(name)
 	if err != nil {
-		return "", err
+		return "", "", "", fmt.Errorf("failed to load %s template: %w", name, err)
 	}
 
-	var buf bytes.Buffer
-	if err := tmpl.Execute(&buf, data); err != nil {
-		return "", err
+	subject = renderPlaceholders(defaultSubjects[name], data)
+	htmlBody = renderPlaceholdersHTML(pair.HTML, data)
+	textBody = renderPlaceholders(pair.Text, data)
+	return subject, htmlBody, textBody, nil
+}
+
+// ListTemplates summarizes every supported email template for an admin UI,
+// without its (potentially large) HTML/text bodies.
+func (s *EmailServiceImpl) ListTemplates() []EmailTemplateInfo {
+	return s.templates.list()
+}
+
+// GetTemplate returns name's current editable HTML and text bodies.
+func (s *EmailServiceImpl) GetTemplate(name string) (EmailTemplate, error) {
+	pair, err := s.templates.load(name)
+	if err != nil {
+		return EmailTemplate{}, err
+	}
+	return EmailTemplate{Name: name, HTML: pair.HTML, Text: pair.Text}, nil
+}
+
+// SaveTemplate overwrites name's HTML and text bodies on disk. The next
+// SendXEmail or PreviewEmail call picks up the change, since templateStore's
+// cache is keyed on file mtime.
+func (s *EmailServiceImpl) SaveTemplate(name, htmlBody, textBody string) error {
+	return s.templates.save(name, htmlBody, textBody)
+}
+
+// PreviewEmail renders name against sampleData without sending, so an admin
+// can check a template edit before it reaches real recipients. sampleData is
+// layered over the service's own AppName/FrontendURL/ContactEmail, so a
+// caller only needs to supply the email-specific fields (e.g. ResetURL).
+func (s *EmailServiceImpl) PreviewEmail(name string, sampleData map[string]string) (EmailPreview, error) {
+	data := s.baseContext()
+	for key, value := range sampleData {
+		data[key] = value
 	}
 
-	return buf.String(), nil
-}
\ No newline at end of file
+	subject, htmlBody, textBody, err := s.renderEmail(name, data)
+	if err != nil {
+		return EmailPreview{}, err
+	}
+	return EmailPreview{Subject: subject, HTMLBody: htmlBody, TextBody: textBody}, nil
+}
@@ -0,0 +1,371 @@
+package services
+
+import (
+	"fmt"
+	"html"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Names of the supported email templates. Each is backed by a <name>.html
+// and <name>.txt file pair under EmailServiceImpl's template directory.
+const (
+	templateNamePasswordReset = "password_reset"
+	templateNameVerification  = "verification"
+	templateNameWelcome       = "welcome"
+)
+
+// templateNames lists the supported templates in a stable order, for
+// ensureDefaults and ListTemplates.
+var templateNames = []string{templateNamePasswordReset, templateNameVerification, templateNameWelcome}
+
+// templatePair is one email type's editable HTML and plain-text bodies.
+type templatePair struct {
+	HTML string
+	Text string
+}
+
+// defaultSubjects are each template's subject line. Unlike the bodies,
+// subjects aren't file-backed or editable via SaveTemplate - they're short
+// enough that rebranding them is a recompile, same as AppName itself. They
+// use the same {VariableName} placeholder syntax as the bodies.
+var defaultSubjects = map[string]string{
+	templateNamePasswordReset: "Password Reset - {AppName}",
+	templateNameVerification:  "Verify your email - {AppName}",
+	templateNameWelcome:       "Welcome to {AppName}!",
+}
+
+// defaultTemplates are the compiled-in HTML/text bodies, written to disk by
+// ensureDefaults on first run so operators can rebrand transactional emails
+// by editing the files in place instead of recompiling.
+var defaultTemplates = map[string]templatePair{
+	templateNamePasswordReset: {HTML: passwordResetHTMLDefault, Text: passwordResetTextDefault},
+	templateNameVerification:  {HTML: verificationHTMLDefault, Text: verificationTextDefault},
+	templateNameWelcome:       {HTML: welcomeHTMLDefault, Text: welcomeTextDefault},
+}
+
+// EmailTemplateInfo summarizes a template for admin listing, without its
+// (potentially large) HTML/text bodies.
+type EmailTemplateInfo struct {
+	Name      string    `json:"name"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// EmailTemplate is a template's current editable content.
+type EmailTemplate struct {
+	Name string `json:"name"`
+	HTML string `json:"html"`
+	Text string `json:"text"`
+}
+
+// EmailPreview is the rendered result of EmailService.PreviewEmail, returned
+// instead of sent so an admin can check a template edit before it reaches
+// real recipients.
+type EmailPreview struct {
+	Subject  string `json:"subject"`
+	HTMLBody string `json:"html_body"`
+	TextBody string `json:"text_body"`
+}
+
+// cachedTemplate is a templatePair loaded from disk, alongside the file
+// mtimes it was loaded at so templateStore.load can detect an operator edit
+// and reload without restarting the process.
+type cachedTemplate struct {
+	pair      templatePair
+	htmlMTime time.Time
+	textMTime time.Time
+}
+
+// templateStore loads email templates from a directory of <name>.html /
+// <name>.txt file pairs, falling back to the compiled-in default for any
+// template that hasn't been customized yet. Loaded templates are cached and
+// only reloaded once their file's mtime advances, so sending an email isn't
+// doing disk I/O every time.
+type templateStore struct {
+	dir string
+
+	mu    sync.Mutex
+	cache map[string]*cachedTemplate
+}
+
+func newTemplateStore(dir string) *templateStore {
+	return &templateStore{
+		dir:   dir,
+		cache: make(map[string]*cachedTemplate),
+	}
+}
+
+// ensureDefaults writes the compiled-in template for any name whose files
+// don't exist yet under dir, so a fresh deployment gets editable copies of
+// every template without an operator needing to seed them by hand.
+func (t *templateStore) ensureDefaults() error {
+	if err := os.MkdirAll(t.dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create email template directory: %w", err)
+	}
+	for _, name := range templateNames {
+		def := defaultTemplates[name]
+		if err := t.writeIfMissing(name+".html", def.HTML); err != nil {
+			return err
+		}
+		if err := t.writeIfMissing(name+".txt", def.Text); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *templateStore) writeIfMissing(filename, content string) error {
+	path := filepath.Join(t.dir, filename)
+	if _, err := os.Stat(path); err == nil {
+		return nil
+	} else if !os.IsNotExist(err) {
+		return fmt.Errorf("failed to stat %s: %w", path, err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
+// load returns name's current HTML/text pair, reloading from disk if either
+// file's mtime has advanced since it was last cached, and falling back to
+// the compiled-in default if the files haven't been written yet.
+func (t *templateStore) load(name string) (templatePair, error) {
+	def, ok := defaultTemplates[name]
+	if !ok {
+		return templatePair{}, fmt.Errorf("unknown email template: %s", name)
+	}
+
+	htmlPath := filepath.Join(t.dir, name+".html")
+	textPath := filepath.Join(t.dir, name+".txt")
+
+	htmlInfo, err := os.Stat(htmlPath)
+	if err != nil {
+		return def, nil
+	}
+	textInfo, err := os.Stat(textPath)
+	if err != nil {
+		return def, nil
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if cached, ok := t.cache[name]; ok &&
+		cached.htmlMTime.Equal(htmlInfo.ModTime()) && cached.textMTime.Equal(textInfo.ModTime()) {
+		return cached.pair, nil
+	}
+
+	htmlBytes, err := os.ReadFile(htmlPath)
+	if err != nil {
+		return templatePair{}, fmt.Errorf("failed to read %s: %w", htmlPath, err)
+	}
+	textBytes, err := os.ReadFile(textPath)
+	if err != nil {
+		return templatePair{}, fmt.Errorf("failed to read %s: %w", textPath, err)
+	}
+
+	pair := templatePair{HTML: string(htmlBytes), Text: string(textBytes)}
+	t.cache[name] = &cachedTemplate{pair: pair, htmlMTime: htmlInfo.ModTime(), textMTime: textInfo.ModTime()}
+	return pair, nil
+}
+
+// save writes name's HTML/text pair to disk and evicts it from the cache,
+// so the next load picks up the new content and mtime.
+func (t *templateStore) save(name, htmlBody, textBody string) error {
+	if _, ok := defaultTemplates[name]; !ok {
+		return fmt.Errorf("unknown email template: %s", name)
+	}
+	if err := os.MkdirAll(t.dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create email template directory: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(t.dir, name+".html"), []byte(htmlBody), 0o644); err != nil {
+		return fmt.Errorf("failed to write %s.html: %w", name, err)
+	}
+	if err := os.WriteFile(filepath.Join(t.dir, name+".txt"), []byte(textBody), 0o644); err != nil {
+		return fmt.Errorf("failed to write %s.txt: %w", name, err)
+	}
+
+	t.mu.Lock()
+	delete(t.cache, name)
+	t.mu.Unlock()
+	return nil
+}
+
+// list returns every supported template name in a stable order, each with
+// the mtime of its HTML file (the zero time if it hasn't been written yet).
+func (t *templateStore) list() []EmailTemplateInfo {
+	infos := make([]EmailTemplateInfo, 0, len(templateNames))
+	for _, name := range templateNames {
+		var updatedAt time.Time
+		if info, err := os.Stat(filepath.Join(t.dir, name+".html")); err == nil {
+			updatedAt = info.ModTime()
+		}
+		infos = append(infos, EmailTemplateInfo{Name: name, UpdatedAt: updatedAt})
+	}
+	return infos
+}
+
+// renderPlaceholders substitutes each "{Key}" in tmpl with data["Key"],
+// leaving any placeholder without a matching key untouched.
+func renderPlaceholders(tmpl string, data map[string]string) string {
+	result := tmpl
+	for key, value := range data {
+		result = strings.ReplaceAll(result, "{"+key+"}", value)
+	}
+	return result
+}
+
+// renderPlaceholdersHTML is renderPlaceholders with every value HTML-escaped
+// first, so a user-controlled field like Name can't inject markup into the
+// HTML body.
+func renderPlaceholdersHTML(tmpl string, data map[string]string) string {
+	escaped := make(map[string]string, len(data))
+	for key, value := range data {
+		escaped[key] = html.EscapeString(value)
+	}
+	return renderPlaceholders(tmpl, escaped)
+}
+
+const passwordResetHTMLDefault = `
+<!DOCTYPE html>
+<html>
+<head>
+    <meta charset="utf-8">
+    <title>Password Reset - {AppName}</title>
+</head>
+<body style="font-family: Arial, sans-serif; line-height: 1.6; color: #333;">
+    <div style="max-width: 600px; margin: 0 auto; padding: 20px;">
+        <h2 style="color: #2c3e50;">Password Reset Request</h2>
+        <p>Hello,</p>
+        <p>We received a request to reset your password for your {AppName} account.</p>
+        <p>Click the button below to reset your password:</p>
+        <div style="text-align: center; margin: 30px 0;">
+            <a href="{ResetURL}" style="background-color: #3498db; color: white; padding: 12px 30px; text-decoration: none; border-radius: 5px; display: inline-block;">Reset Password</a>
+        </div>
+        <p>Or copy and paste this link into your browser:</p>
+        <p style="word-break: break-all; background-color: #f8f9fa; padding: 10px; border-radius: 3px;">{ResetURL}</p>
+        <p><strong>This link will expire in 1 hour.</strong></p>
+        <p>If you didn't request this password reset, please ignore this email or contact support if you have concerns.</p>
+        <hr style="border: none; border-top: 1px solid #eee; margin: 30px 0;">
+        <p style="font-size: 12px; color: #7f8c8d;">
+            Best regards,<br>
+            The {AppName} Team
+        </p>
+    </div>
+</body>
+</html>`
+
+const passwordResetTextDefault = `Password Reset Request
+
+Hello,
+
+We received a request to reset your password for your {AppName} account.
+
+Please click the following link to reset your password:
+{ResetURL}
+
+This link will expire in 1 hour.
+
+If you didn't request this password reset, please ignore this email or contact support if you have concerns.
+
+Best regards,
+The {AppName} Team`
+
+const verificationHTMLDefault = `
+<!DOCTYPE html>
+<html>
+<head>
+    <meta charset="utf-8">
+    <title>Verify your email - {AppName}</title>
+</head>
+<body style="font-family: Arial, sans-serif; line-height: 1.6; color: #333;">
+    <div style="max-width: 600px; margin: 0 auto; padding: 20px;">
+        <h2 style="color: #2c3e50;">Verify your email</h2>
+        <p>Hello,</p>
+        <p>Thanks for signing up for {AppName}. Please confirm your email address to activate your account.</p>
+        <div style="text-align: center; margin: 30px 0;">
+            <a href="{VerifyURL}" style="background-color: #3498db; color: white; padding: 12px 30px; text-decoration: none; border-radius: 5px; display: inline-block;">Verify Email</a>
+        </div>
+        <p>Or copy and paste this link into your browser:</p>
+        <p style="word-break: break-all; background-color: #f8f9fa; padding: 10px; border-radius: 3px;">{VerifyURL}</p>
+        <p><strong>This link will expire in 24 hours.</strong></p>
+        <p>If you didn't create this account, you can safely ignore this email.</p>
+        <hr style="border: none; border-top: 1px solid #eee; margin: 30px 0;">
+        <p style="font-size: 12px; color: #7f8c8d;">
+            Best regards,<br>
+            The {AppName} Team
+        </p>
+    </div>
+</body>
+</html>`
+
+const verificationTextDefault = `Verify your email
+
+Hello,
+
+Thanks for signing up for {AppName}. Please confirm your email address to activate your account.
+
+Please click the following link to verify your email:
+{VerifyURL}
+
+This link will expire in 24 hours.
+
+If you didn't create this account, you can safely ignore this email.
+
+Best regards,
+The {AppName} Team`
+
+const welcomeHTMLDefault = `
+<!DOCTYPE html>
+<html>
+<head>
+    <meta charset="utf-8">
+    <title>Welcome to {AppName}!</title>
+</head>
+<body style="font-family: Arial, sans-serif; line-height: 1.6; color: #333;">
+    <div style="max-width: 600px; margin: 0 auto; padding: 20px;">
+        <h2 style="color: #2c3e50;">Welcome to {AppName}!</h2>
+        <p>Hello {Name},</p>
+        <p>Welcome to {AppName}! We're excited to have you on board.</p>
+        <p>You can now access all our features:</p>
+        <ul>
+            <li>Create and manage courses</li>
+            <li>Generate AI-powered educational content</li>
+            <li>Access comprehensive learning materials</li>
+            <li>Track your progress</li>
+        </ul>
+        <div style="text-align: center; margin: 30px 0;">
+            <a href="{FrontendURL}" style="background-color: #27ae60; color: white; padding: 12px 30px; text-decoration: none; border-radius: 5px; display: inline-block;">Get Started</a>
+        </div>
+        <p>If you have any questions, feel free to contact us at {ContactEmail}.</p>
+        <hr style="border: none; border-top: 1px solid #eee; margin: 30px 0;">
+        <p style="font-size: 12px; color: #7f8c8d;">
+            Best regards,<br>
+            The {AppName} Team
+        </p>
+    </div>
+</body>
+</html>`
+
+const welcomeTextDefault = `Welcome to {AppName}!
+
+Hello {Name},
+
+Welcome to {AppName}! We're excited to have you on board.
+
+You can now access all our features:
+- Create and manage courses
+- Generate AI-powered educational content
+- Access comprehensive learning materials
+- Track your progress
+
+Visit our platform: {FrontendURL}
+
+If you have any questions, feel free to contact us at {ContactEmail}.
+
+Best regards,
+The {AppName} Team`
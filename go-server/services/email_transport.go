@@ -0,0 +1,34 @@
+package services
+
+import (
+	"fmt"
+
+	"go-server/models"
+)
+
+// MailgunTransport adapts EmailService to NotificationTransport, so
+// NotificationService can fan out to email alongside other channels without
+// knowing EmailServiceImpl's per-type method names.
+type MailgunTransport struct {
+	emailService EmailService
+}
+
+// NewMailgunTransport wraps emailService as a "email" NotificationTransport.
+func NewMailgunTransport(emailService EmailService) *MailgunTransport {
+	return &MailgunTransport{emailService: emailService}
+}
+
+func (t *MailgunTransport) Channel() string { return "email" }
+
+func (t *MailgunTransport) Send(user *models.User, notifType NotificationType, data map[string]string) error {
+	switch notifType {
+	case NotificationTypePasswordReset:
+		return t.emailService.SendPasswordResetEmail(user.Email, data["Token"])
+	case NotificationTypeVerification:
+		return t.emailService.SendVerificationEmail(user.Email, data["Token"])
+	case NotificationTypeWelcome:
+		return t.emailService.SendWelcomeEmail(user.Email, data["Name"])
+	default:
+		return fmt.Errorf("mailgun transport: unknown notification type %q", notifType)
+	}
+}
@@ -2,13 +2,22 @@ package services
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"go-server/apierror"
+	"go-server/config"
+	"go-server/jobs"
 	"go-server/models"
 	"go-server/repositories"
+	"go-server/storage"
 	"go-server/utils"
-	"os"
+	"io"
+	"net/http"
 	"path/filepath"
+	"time"
 
 	"github.com/sirupsen/logrus"
 	"gorm.io/gorm"
@@ -16,222 +25,387 @@ import (
 
 type ImageService interface {
 	GenerateCourseImage(courseID uint) (string, error)
-	GenerateSubjectImage(subjectID uint) (string, error) 
+	GenerateSubjectImage(subjectID uint) (string, error)
 	GenerateCustomImage(prompt string) (string, error)
+	// GenerateCourseImageStream generates a course's cover image the same
+	// way GenerateCourseImage does, but reports progress to w as
+	// newline-delimited JSON frames instead of blocking until completion.
+	GenerateCourseImageStream(courseID uint, w io.Writer) error
 	UploadImage(imageData []byte, imagePath string) (string, error)
 	DeleteImage(imagePath string) error
 	ListImages(pathPrefix string) ([]string, error)
+	// GetSignedURL and GetSignedURLs return time-limited signed URLs for
+	// already-uploaded blobs via the process-wide storage.SignedURLCache,
+	// so repeated requests for the same path within its refresh-ahead
+	// window don't re-sign it against the backend every time.
+	GetSignedURL(path string, ttl time.Duration) (string, error)
+	GetSignedURLs(paths []string, ttl time.Duration) (map[string]string, error)
+	DownloadImage(imageURL string) ([]byte, error)
+	// EnqueueCourseImageJob, EnqueueSubjectImageJob and EnqueueCustomImageJob
+	// queue the same generation work the Generate* methods do synchronously,
+	// returning a *models.Job a client can poll instead of blocking on the
+	// request. idempotencyKey, when non-empty, makes a retried submission
+	// return the original job instead of enqueueing a duplicate.
+	EnqueueCourseImageJob(courseID uint, idempotencyKey string) (*models.Job, error)
+	EnqueueSubjectImageJob(subjectID uint, idempotencyKey string) (*models.Job, error)
+	EnqueueCustomImageJob(prompt string, idempotencyKey string) (*models.Job, error)
 }
 
 type ImageServiceImpl struct {
-	courseRepo      repositories.CourseRepository
-	subjectRepo     repositories.SubjectRepository
-	azureStorage    *utils.AzureStorageHelper
-	imageGenerator  *utils.GeminiImageGenerator
+	courseRepo     repositories.CourseRepository
+	subjectRepo    repositories.SubjectRepository
+	imageRepo      repositories.ImageAssetRepository
+	storage        storage.Backend
+	signedURLCache *storage.SignedURLCache
+	providers      []utils.ImageProvider
+	queue          *jobs.Queue
 }
 
-func NewImageService(courseRepo repositories.CourseRepository, subjectRepo repositories.SubjectRepository) ImageService {
-	return &ImageServiceImpl{
+// cacheModelTag versions the cache key derivation; bump it when the provider
+// chain changes in a way that should invalidate previously cached images.
+const cacheModelTag = "chain-v1"
+
+// Job kinds registered with queue by NewImageService.
+const (
+	jobKindGenerateCourseImage  = "generate_course_image"
+	jobKindGenerateSubjectImage = "generate_subject_image"
+	jobKindGenerateCustomImage  = "generate_custom_image"
+)
+
+func NewImageService(courseRepo repositories.CourseRepository, subjectRepo repositories.SubjectRepository, imageRepo repositories.ImageAssetRepository, backend storage.Backend, queue *jobs.Queue, cfg *config.Config) ImageService {
+	defaultOrder := []utils.ImageProvider{
+		utils.NewGeminiImageGenerator(),
+		utils.NewOpenAIImageProvider(cfg.OpenAIImageAPIKey),
+		utils.NewStabilityImageProvider(cfg.StabilityAIAPIKey),
+		utils.NewStableDiffusionImageProvider(cfg.StableDiffusionEndpoint),
+		utils.NewPlaceholderProvider(),
+	}
+
+	s := &ImageServiceImpl{
 		courseRepo:     courseRepo,
 		subjectRepo:    subjectRepo,
-		azureStorage:   utils.GetAzureStorageHelper(),
-		imageGenerator: utils.NewGeminiImageGenerator(),
+		imageRepo:      imageRepo,
+		storage:        backend,
+		signedURLCache: storage.NewSignedURLCache(backend, cfg.SignedURLCacheSize),
+		providers:      prioritizeProviders(defaultOrder, cfg.ImageProviderPriority),
+		queue:          queue,
+	}
+
+	queue.Register(jobKindGenerateCourseImage, s.handleGenerateCourseImageJob)
+	queue.Register(jobKindGenerateSubjectImage, s.handleGenerateSubjectImageJob)
+	queue.Register(jobKindGenerateCustomImage, s.handleGenerateCustomImageJob)
+
+	return s
+}
+
+// prioritizeProviders reorders providers so any name listed in priority runs
+// first, in the order given; providers priority doesn't mention keep running
+// afterward in their original relative order.
+func prioritizeProviders(providers []utils.ImageProvider, priority []string) []utils.ImageProvider {
+	if len(priority) == 0 {
+		return providers
+	}
+
+	byName := make(map[string]utils.ImageProvider, len(providers))
+	for _, p := range providers {
+		byName[p.Name()] = p
+	}
+
+	ordered := make([]utils.ImageProvider, 0, len(providers))
+	seen := make(map[string]bool, len(providers))
+	for _, name := range priority {
+		if p, ok := byName[name]; ok && !seen[name] {
+			ordered = append(ordered, p)
+			seen[name] = true
+		}
+	}
+	for _, p := range providers {
+		if !seen[p.Name()] {
+			ordered = append(ordered, p)
+		}
 	}
+	return ordered
 }
 
 // GenerateCourseImage generates and stores a cover image for a course
 func (s *ImageServiceImpl) GenerateCourseImage(courseID uint) (string, error) {
-	// Get course details
 	course := &models.Course{}
 	if err := s.courseRepo.GetByID(courseID, course); err != nil {
 		if err == gorm.ErrRecordNotFound {
-			return "", errors.New("course not found")
+			return "", fmt.Errorf("%w", apierror.NotFound("course"))
 		}
-		return "", fmt.Errorf("failed to get course: %w", err)
+		return "", fmt.Errorf("%w", apierror.Internal("failed to get course", err))
 	}
 
-	// Check if image generator is available
-	if !s.imageGenerator.IsAvailable() {
-		return "", errors.New("image generation not available - no API key configured")
+	prompt := fmt.Sprintf("course-cover:%s:%s", course.Name, course.Description)
+	imageURL, err := s.generateAndCache(prompt, func(ctx context.Context, p utils.ImageProvider) ([]byte, error) {
+		return p.GenerateCourseImage(ctx, course.Name, course.Description)
+	})
+	if err != nil {
+		return "", err
 	}
 
-	// Check if Azure storage is available
-	if !s.azureStorage.IsInitialized() {
-		return "", errors.New("Azure storage not initialized - check configuration")
+	course.ImageURL = &imageURL
+	if err := s.courseRepo.Update(course); err != nil {
+		logrus.Errorf("Failed to update course with image URL: %v", err)
+		// Don't fail the operation, just log the error
 	}
 
-	// Generate the image
-	ctx := context.Background()
-	logrus.Infof("Generating image for course '%s' (ID: %d)", course.Name, courseID)
-	
-	imageBytes, err := s.imageGenerator.GenerateCourseImage(ctx, course.Name, course.Description)
-	if err != nil {
-		return "", fmt.Errorf("failed to generate image: %w", err)
-	}
+	return imageURL, nil
+}
 
-	if len(imageBytes) == 0 {
-		return "", errors.New("failed to generate image - no data received")
-	}
+// GenerateCourseImageStream is GenerateCourseImage's streaming counterpart:
+// instead of blocking until the image is ready, it writes newline-delimited
+// JSON progress frames to w as it goes, so a client behind a proxy sees live
+// progress instead of a single delayed response.
+func (s *ImageServiceImpl) GenerateCourseImageStream(courseID uint, w io.Writer) error {
+	stream := utils.NewJSONStreamWriter(w, fmt.Sprintf("course-%d", courseID))
 
-	logrus.Infof("Image generated successfully, size: %d bytes", len(imageBytes))
+	stream.WriteProgress("generating", 0)
 
-	// Save a local debug copy if in debug mode
-	if os.Getenv("DEBUG") == "true" {
-		debugPath := fmt.Sprintf("/tmp/course_%d_image_debug.png", courseID)
-		if err := os.WriteFile(debugPath, imageBytes, 0644); err != nil {
-			logrus.Warnf("Could not save debug image: %v", err)
+	course := &models.Course{}
+	if err := s.courseRepo.GetByID(courseID, course); err != nil {
+		var wrapped error
+		if err == gorm.ErrRecordNotFound {
+			wrapped = apierror.NotFound("course")
 		} else {
-			logrus.Infof("Debug image saved to %s", debugPath)
+			wrapped = apierror.Internal("failed to get course", err)
 		}
+		stream.WriteError(wrapped)
+		return fmt.Errorf("%w", wrapped)
 	}
 
-	// Upload the image to Azure Storage
-	imagePath := fmt.Sprintf("courses/%d/cover", courseID)
-	logrus.Infof("Uploading image to Azure Storage path: %s", imagePath)
-	
-	imageURL, err := s.azureStorage.UploadImage(imageBytes, imagePath)
+	stream.WriteProgress("generating", 50)
+
+	prompt := fmt.Sprintf("course-cover:%s:%s", course.Name, course.Description)
+	imageURL, err := s.generateAndCache(prompt, func(ctx context.Context, p utils.ImageProvider) ([]byte, error) {
+		return p.GenerateCourseImage(ctx, course.Name, course.Description)
+	})
 	if err != nil {
-		return "", fmt.Errorf("failed to upload image to Azure Storage: %w", err)
+		stream.WriteError(err)
+		return err
 	}
 
-	logrus.Infof("Image uploaded successfully, URL: %s", imageURL)
+	stream.WriteStatus("uploading")
 
-	// Update the course with the image URL
 	course.ImageURL = &imageURL
 	if err := s.courseRepo.Update(course); err != nil {
 		logrus.Errorf("Failed to update course with image URL: %v", err)
 		// Don't fail the operation, just log the error
 	}
 
-	return imageURL, nil
+	stream.WriteComplete(imageURL)
+	return nil
 }
 
 // GenerateSubjectImage generates and stores a cover image for a subject
 func (s *ImageServiceImpl) GenerateSubjectImage(subjectID uint) (string, error) {
-	// Get subject details
 	subject := &models.Subject{}
 	if err := s.subjectRepo.GetByID(subjectID, subject); err != nil {
 		if err == gorm.ErrRecordNotFound {
-			return "", errors.New("subject not found")
+			return "", fmt.Errorf("%w", apierror.NotFound("subject"))
 		}
-		return "", fmt.Errorf("failed to get subject: %w", err)
+		return "", fmt.Errorf("%w", apierror.Internal("failed to get subject", err))
 	}
 
-	// Get course details for context
 	course := &models.Course{}
 	if err := s.courseRepo.GetByID(subject.CourseID, course); err != nil {
 		return "", fmt.Errorf("failed to get course for subject: %w", err)
 	}
 
-	// Check if image generator is available
-	if !s.imageGenerator.IsAvailable() {
-		return "", errors.New("image generation not available - no API key configured")
+	prompt := fmt.Sprintf("subject-cover:%s:%s", subject.Name, course.Name)
+	imageURL, err := s.generateAndCache(prompt, func(ctx context.Context, p utils.ImageProvider) ([]byte, error) {
+		return p.GenerateSubjectImage(ctx, subject.Name, course.Name)
+	})
+	if err != nil {
+		return "", err
 	}
 
-	// Check if Azure storage is available
-	if !s.azureStorage.IsInitialized() {
-		return "", errors.New("Azure storage not initialized - check configuration")
-	}
+	return imageURL, nil
+}
 
-	// Generate the image
+// GenerateCustomImage generates an image based on a custom prompt
+func (s *ImageServiceImpl) GenerateCustomImage(prompt string) (string, error) {
+	return s.generateAndCache("custom:"+prompt, func(ctx context.Context, p utils.ImageProvider) ([]byte, error) {
+		return p.GenerateCustom(ctx, prompt)
+	})
+}
+
+// generateAndCache checks the content-addressed cache for an image generated
+// from prompt before running call through the provider chain in order,
+// recording each provider's latency and success via logrus fields so that one
+// bad provider (e.g. an expired key) can't take down image generation. On a
+// cache miss, the first successful provider's result is uploaded under the
+// cache key and that becomes the returned URL.
+func (s *ImageServiceImpl) generateAndCache(prompt string, call func(context.Context, utils.ImageProvider) ([]byte, error)) (string, error) {
 	ctx := context.Background()
-	logrus.Infof("Generating image for subject '%s' (ID: %d)", subject.Name, subjectID)
-	
-	imageBytes, err := s.imageGenerator.GenerateSubjectImage(ctx, subject.Name, course.Name)
-	if err != nil {
-		return "", fmt.Errorf("failed to generate subject image: %w", err)
-	}
 
-	if len(imageBytes) == 0 {
-		return "", errors.New("failed to generate subject image - no data received")
+	cachePath := fmt.Sprintf("cache/%s.png", imageCacheKey(prompt))
+	if exists, err := s.storage.Exists(ctx, cachePath); err == nil && exists {
+		logrus.WithField("cache_key", cachePath).Info("Image cache hit, skipping provider chain")
+		return s.storage.PublicURL(cachePath), nil
 	}
 
-	logrus.Infof("Subject image generated successfully, size: %d bytes", len(imageBytes))
+	var lastErr error
+	for _, provider := range s.providers {
+		if !provider.Available() {
+			continue
+		}
 
-	// Upload the image to Azure Storage
-	imagePath := fmt.Sprintf("subjects/%d/cover", subjectID)
-	logrus.Infof("Uploading subject image to Azure Storage path: %s", imagePath)
-	
-	imageURL, err := s.azureStorage.UploadImage(imageBytes, imagePath)
-	if err != nil {
-		return "", fmt.Errorf("failed to upload subject image to Azure Storage: %w", err)
-	}
+		start := time.Now()
+		imageBytes, err := call(ctx, provider)
+		fields := logrus.Fields{
+			"provider":   provider.Name(),
+			"latency_ms": time.Since(start).Milliseconds(),
+			"cost_usd":   provider.EstimatedCostUSD(),
+		}
 
-	logrus.Infof("Subject image uploaded successfully, URL: %s", imageURL)
+		if err != nil {
+			logrus.WithFields(fields).Warnf("Image provider failed: %v", err)
+			lastErr = err
+			continue
+		}
+		if len(imageBytes) == 0 {
+			logrus.WithFields(fields).Warn("Image provider returned no data")
+			lastErr = fmt.Errorf("%s returned no image data", provider.Name())
+			continue
+		}
 
-	return imageURL, nil
-}
+		logrus.WithFields(fields).Info("Image provider succeeded")
 
-// GenerateCustomImage generates an image based on a custom prompt
-func (s *ImageServiceImpl) GenerateCustomImage(prompt string) (string, error) {
-	// Check if image generator is available
-	if !s.imageGenerator.IsAvailable() {
-		return "", errors.New("image generation not available - no API key configured")
+		imageURL, uploadErr := s.storage.Upload(ctx, imageBytes, cachePath)
+		if uploadErr != nil {
+			return "", fmt.Errorf("failed to cache generated image: %w", uploadErr)
+		}
+		return imageURL, nil
 	}
 
-	// Check if Azure storage is available
-	if !s.azureStorage.IsInitialized() {
-		return "", errors.New("Azure storage not initialized - check configuration")
+	if lastErr == nil {
+		lastErr = errors.New("no image provider is available")
 	}
+	return "", fmt.Errorf("all image providers failed: %w", lastErr)
+}
 
-	// Generate the image
-	ctx := context.Background()
-	logrus.Infof("Generating custom image with prompt: %s", prompt)
-	
-	imageBytes, err := s.imageGenerator.GenerateCustomImage(ctx, prompt)
+// courseImageJobPayload, subjectImageJobPayload and customImageJobPayload are
+// the opaque JSON payloads jobs.Queue stores and hands back to the matching
+// handleGenerate*Job below.
+type courseImageJobPayload struct {
+	CourseID uint `json:"course_id"`
+}
+
+type subjectImageJobPayload struct {
+	SubjectID uint `json:"subject_id"`
+}
+
+type customImageJobPayload struct {
+	Prompt string `json:"prompt"`
+}
+
+// EnqueueCourseImageJob queues GenerateCourseImage to run on the worker pool.
+func (s *ImageServiceImpl) EnqueueCourseImageJob(courseID uint, idempotencyKey string) (*models.Job, error) {
+	payload, err := json.Marshal(courseImageJobPayload{CourseID: courseID})
 	if err != nil {
-		return "", fmt.Errorf("failed to generate custom image: %w", err)
+		return nil, fmt.Errorf("failed to encode job payload: %w", err)
 	}
+	return s.queue.Enqueue(jobKindGenerateCourseImage, string(payload), idempotencyKey)
+}
 
-	if len(imageBytes) == 0 {
-		return "", errors.New("failed to generate custom image - no data received")
+// EnqueueSubjectImageJob queues GenerateSubjectImage to run on the worker pool.
+func (s *ImageServiceImpl) EnqueueSubjectImageJob(subjectID uint, idempotencyKey string) (*models.Job, error) {
+	payload, err := json.Marshal(subjectImageJobPayload{SubjectID: subjectID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode job payload: %w", err)
 	}
+	return s.queue.Enqueue(jobKindGenerateSubjectImage, string(payload), idempotencyKey)
+}
 
-	logrus.Infof("Custom image generated successfully, size: %d bytes", len(imageBytes))
-
-	// Upload the image to Azure Storage
-	imagePath := fmt.Sprintf("custom/%d", utils.GenerateUniqueID())
-	logrus.Infof("Uploading custom image to Azure Storage path: %s", imagePath)
-	
-	imageURL, err := s.azureStorage.UploadImage(imageBytes, imagePath)
+// EnqueueCustomImageJob queues GenerateCustomImage to run on the worker pool.
+func (s *ImageServiceImpl) EnqueueCustomImageJob(prompt string, idempotencyKey string) (*models.Job, error) {
+	payload, err := json.Marshal(customImageJobPayload{Prompt: prompt})
 	if err != nil {
-		return "", fmt.Errorf("failed to upload custom image to Azure Storage: %w", err)
+		return nil, fmt.Errorf("failed to encode job payload: %w", err)
 	}
+	return s.queue.Enqueue(jobKindGenerateCustomImage, string(payload), idempotencyKey)
+}
 
-	logrus.Infof("Custom image uploaded successfully, URL: %s", imageURL)
+func (s *ImageServiceImpl) handleGenerateCourseImageJob(jobID uint, payload string) (string, error) {
+	var p courseImageJobPayload
+	if err := json.Unmarshal([]byte(payload), &p); err != nil {
+		return "", fmt.Errorf("failed to decode job payload: %w", err)
+	}
+	return s.GenerateCourseImage(p.CourseID)
+}
 
-	return imageURL, nil
+func (s *ImageServiceImpl) handleGenerateSubjectImageJob(jobID uint, payload string) (string, error) {
+	var p subjectImageJobPayload
+	if err := json.Unmarshal([]byte(payload), &p); err != nil {
+		return "", fmt.Errorf("failed to decode job payload: %w", err)
+	}
+	return s.GenerateSubjectImage(p.SubjectID)
 }
 
-// UploadImage uploads an image directly to Azure Storage
-func (s *ImageServiceImpl) UploadImage(imageData []byte, imagePath string) (string, error) {
-	// Check if Azure storage is available
-	if !s.azureStorage.IsInitialized() {
-		return "", errors.New("Azure storage not initialized - check configuration")
+func (s *ImageServiceImpl) handleGenerateCustomImageJob(jobID uint, payload string) (string, error) {
+	var p customImageJobPayload
+	if err := json.Unmarshal([]byte(payload), &p); err != nil {
+		return "", fmt.Errorf("failed to decode job payload: %w", err)
 	}
+	return s.GenerateCustomImage(p.Prompt)
+}
 
+// imageCacheKey derives the cache object name from the prompt and the
+// current provider-chain version, so a chain change can invalidate old
+// cached results by bumping cacheModelTag.
+func imageCacheKey(prompt string) string {
+	sum := sha256.Sum256([]byte(cacheModelTag + ":" + prompt))
+	return hex.EncodeToString(sum[:])
+}
+
+// UploadImage uploads an image directly to the configured storage backend.
+// The blob itself is stored content-addressed, under
+// sha256/<hex digest><ext> (ext detected from the bytes, not assumed to be
+// .png), so uploading byte-identical content a second time — e.g. the same
+// AI-generated asset produced twice — skips the upload and reuses the
+// existing blob. imagePath is the logical name callers address the image
+// by; s.imageRepo's metadata sidecar records which content hash it
+// currently points at. DownloadImage, ListImages and DeleteImage do not
+// consult that record: they work on the URL or storage path they are given.
+func (s *ImageServiceImpl) UploadImage(imageData []byte, imagePath string) (string, error) {
 	if len(imageData) == 0 {
 		return "", errors.New("no image data provided")
 	}
 
-	logrus.Infof("Uploading image to path: %s, size: %d bytes", imagePath, len(imageData))
-	
-	imageURL, err := s.azureStorage.UploadImage(imageData, imagePath)
-	if err != nil {
-		return "", fmt.Errorf("failed to upload image: %w", err)
+	ctx := context.Background()
+	sum := sha256.Sum256(imageData)
+	contentHash := hex.EncodeToString(sum[:])
+	contentType, ext := utils.DetectImageContentType(imageData)
+	hashPath := fmt.Sprintf("sha256/%s%s", contentHash, ext)
+
+	imageURL := s.storage.PublicURL(hashPath)
+	if exists, err := s.storage.Exists(ctx, hashPath); err == nil && exists {
+		logrus.WithField("content_hash", contentHash).Info("Image content already stored, skipping re-upload")
+	} else {
+		logrus.Infof("Uploading image to path: %s (logical path %s), size: %d bytes", hashPath, imagePath, len(imageData))
+		imageURL, err = s.storage.Upload(ctx, imageData, hashPath)
+		if err != nil {
+			return "", fmt.Errorf("failed to upload image: %w", err)
+		}
+	}
+
+	if err := s.imageRepo.Upsert(&models.ImageAsset{
+		LogicalPath: imagePath,
+		ContentHash: contentHash,
+		ContentType: contentType,
+		URL:         imageURL,
+	}); err != nil {
+		return "", fmt.Errorf("failed to record image asset metadata: %w", err)
 	}
 
 	logrus.Infof("Image uploaded successfully, URL: %s", imageURL)
 	return imageURL, nil
 }
 
-// DeleteImage deletes an image from Azure Storage
+// DeleteImage deletes an image from the configured storage backend
 func (s *ImageServiceImpl) DeleteImage(imagePath string) error {
-	// Check if Azure storage is available
-	if !s.azureStorage.IsInitialized() {
-		return errors.New("Azure storage not initialized - check configuration")
-	}
-
 	// Extract just the path from the full URL if needed
 	if filepath.IsAbs(imagePath) {
 		// Extract the blob path from the full URL
@@ -241,29 +415,58 @@ func (s *ImageServiceImpl) DeleteImage(imagePath string) error {
 	}
 
 	logrus.Infof("Deleting image: %s", imagePath)
-	
-	if err := s.azureStorage.DeleteImage(imagePath); err != nil {
+
+	if err := s.storage.Delete(context.Background(), imagePath); err != nil {
 		return fmt.Errorf("failed to delete image: %w", err)
 	}
+	s.signedURLCache.Purge(imagePath)
 
 	logrus.Infof("Image deleted successfully: %s", imagePath)
 	return nil
 }
 
-// ListImages lists all images in a specific path
-func (s *ImageServiceImpl) ListImages(pathPrefix string) ([]string, error) {
-	// Check if Azure storage is available
-	if !s.azureStorage.IsInitialized() {
-		return nil, errors.New("Azure storage not initialized - check configuration")
+// GetSignedURL returns a signed URL for path valid for ttl, reusing a
+// cached one if it's still fresh enough (see storage.SignedURLCache).
+func (s *ImageServiceImpl) GetSignedURL(path string, ttl time.Duration) (string, error) {
+	return s.signedURLCache.GenerateSASURL(context.Background(), path, ttl)
+}
+
+// GetSignedURLs batch-signs paths in one pass, e.g. for a hierarchy
+// endpoint rendering many images at once.
+func (s *ImageServiceImpl) GetSignedURLs(paths []string, ttl time.Duration) (map[string]string, error) {
+	return s.signedURLCache.GenerateSASURLs(context.Background(), paths, ttl)
+}
+
+// DownloadImage fetches the raw bytes of a previously uploaded image by its
+// public URL, e.g. for bundling cover images into a course export archive.
+func (s *ImageServiceImpl) DownloadImage(imageURL string) ([]byte, error) {
+	resp, err := http.Get(imageURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download image: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download image: unexpected status %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read downloaded image: %w", err)
 	}
 
+	return data, nil
+}
+
+// ListImages lists all images in a specific path
+func (s *ImageServiceImpl) ListImages(pathPrefix string) ([]string, error) {
 	logrus.Infof("Listing images with prefix: %s", pathPrefix)
-	
-	imageURLs, err := s.azureStorage.ListImages(pathPrefix)
+
+	imageURLs, err := s.storage.List(context.Background(), pathPrefix)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list images: %w", err)
 	}
 
 	logrus.Infof("Found %d images with prefix: %s", len(imageURLs), pathPrefix)
 	return imageURLs, nil
-}
\ No newline at end of file
+}
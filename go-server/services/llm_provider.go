@@ -0,0 +1,66 @@
+package services
+
+import (
+	"time"
+
+	"go-server/config"
+	"go-server/utils"
+)
+
+// llmProviderTimeout bounds a single provider's attempt within the chain;
+// llmMaxRetries is the number of additional attempts on a retryable error
+// before moving on; llmCircuitCooldown is how long a provider whose topic
+// content call failed (after any retries) is skipped on subsequent requests.
+const (
+	llmProviderTimeout = 30 * time.Second
+	llmMaxRetries      = 2
+	llmCircuitCooldown = 2 * time.Minute
+)
+
+// NewLLMProviderChain builds the utils.LLMProvider every generation service
+// (course/subject/chapter/topic list generation, and content generation)
+// runs against: Gemini, an OpenAI-compatible endpoint, Anthropic, and a
+// local Ollama server, tried in cfg.LLMProviderPriority order and falling
+// back to the next on a rate-limit/5xx error. Callers share a single chain
+// so its circuit-breaker state reflects a provider's real health across
+// every kind of generation, not just one.
+func NewLLMProviderChain(cfg *config.Config) utils.LLMProvider {
+	providers := []utils.LLMProvider{
+		utils.NewGeminiHelper(),
+		utils.NewOpenAICompatLLMProvider(cfg.OpenAICompatAPIKey, cfg.OpenAICompatBaseURL, cfg.OpenAICompatModel),
+		utils.NewAnthropicLLMProvider(cfg.AnthropicAPIKey, cfg.AnthropicModel),
+		utils.NewOllamaLLMProvider(cfg.OllamaBaseURL, cfg.OllamaModel),
+	}
+	return utils.NewProviderChain(prioritizeLLMProviders(providers, cfg.LLMProviderPriority), llmProviderTimeout, llmMaxRetries, llmCircuitCooldown, cfg.LLMRateLimitPerMinute)
+}
+
+// prioritizeLLMProviders reorders providers so any name listed in priority
+// runs first, in the order given; providers priority doesn't mention keep
+// running afterward in their original relative order. Mirrors
+// ImageService's prioritizeProviders for the same reason: operators pick a
+// try-order via config without the code needing to know about it.
+func prioritizeLLMProviders(providers []utils.LLMProvider, priority []string) []utils.LLMProvider {
+	if len(priority) == 0 {
+		return providers
+	}
+
+	byName := make(map[string]utils.LLMProvider, len(providers))
+	for _, p := range providers {
+		byName[p.Name()] = p
+	}
+
+	ordered := make([]utils.LLMProvider, 0, len(providers))
+	seen := make(map[string]bool, len(providers))
+	for _, name := range priority {
+		if p, ok := byName[name]; ok && !seen[name] {
+			ordered = append(ordered, p)
+			seen[name] = true
+		}
+	}
+	for _, p := range providers {
+		if !seen[p.Name()] {
+			ordered = append(ordered, p)
+		}
+	}
+	return ordered
+}
@@ -0,0 +1,107 @@
+package services
+
+import (
+	"fmt"
+
+	"go-server/models"
+	"go-server/repositories"
+
+	"github.com/sirupsen/logrus"
+)
+
+// NotificationType identifies what's being sent, used both to resolve the
+// recipient's per-type channel preferences and to pick which content a
+// NotificationTransport renders.
+type NotificationType string
+
+const (
+	NotificationTypePasswordReset NotificationType = "password_reset"
+	NotificationTypeVerification  NotificationType = "verification"
+	NotificationTypeWelcome       NotificationType = "welcome"
+)
+
+// defaultChannels is the channel set a notification type is delivered on
+// when the user has no NotificationPreference rows at all, so every
+// existing account keeps receiving email exactly as before this feature
+// was added.
+var defaultChannels = map[string]bool{"email": true}
+
+// NotificationTransport delivers one notification to a user over a single
+// channel (e.g. email, Telegram). Each transport is responsible for its own
+// rendering - HTML for email, Markdown for Telegram - from the same data.
+type NotificationTransport interface {
+	// Channel identifies this transport for NotificationPreferences lookups
+	// and must match the "channel" value preferences are stored under
+	// (e.g. "email", "telegram").
+	Channel() string
+	// Send renders and delivers notifType to user using data as the
+	// placeholder context (e.g. "Token", "Name"). A transport that can't
+	// reach user (not configured, not linked) should return nil rather than
+	// an error, the same way EmailServiceImpl skips silently when unconfigured.
+	Send(user *models.User, notifType NotificationType, data map[string]string) error
+}
+
+// NotificationService fans a single logical notification out to every
+// channel a user has opted into, so callers like AuthServiceImpl don't need
+// to know which transports exist.
+type NotificationService interface {
+	// Notify delivers notifType to user on every channel they've enabled
+	// for it (email-only by default). A delivery failure on one channel is
+	// logged rather than aborting the others; the returned error, if any, is
+	// the last transport's failure.
+	Notify(user *models.User, notifType NotificationType, data map[string]string) error
+}
+
+type NotificationServiceImpl struct {
+	transports []NotificationTransport
+	prefsRepo  repositories.NotificationPreferenceRepository
+}
+
+// NewNotificationService builds a NotificationService that fans out to
+// transports, in the order given.
+func NewNotificationService(transports []NotificationTransport, prefsRepo repositories.NotificationPreferenceRepository) NotificationService {
+	return &NotificationServiceImpl{
+		transports: transports,
+		prefsRepo:  prefsRepo,
+	}
+}
+
+// enabledChannels returns the set of channels userID has opted into for
+// notifType, falling back to defaultChannels if they have no preferences
+// recorded for it at all.
+func (s *NotificationServiceImpl) enabledChannels(userID uint, notifType NotificationType) (map[string]bool, error) {
+	prefs, err := s.prefsRepo.ListByUser(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load notification preferences: %w", err)
+	}
+
+	enabled := make(map[string]bool)
+	for _, pref := range prefs {
+		if pref.NotificationType == string(notifType) {
+			enabled[pref.Channel] = pref.Enabled
+		}
+	}
+	if len(enabled) == 0 {
+		return defaultChannels, nil
+	}
+	return enabled, nil
+}
+
+func (s *NotificationServiceImpl) Notify(user *models.User, notifType NotificationType, data map[string]string) error {
+	enabled, err := s.enabledChannels(user.ID, notifType)
+	if err != nil {
+		return err
+	}
+
+	var lastErr error
+	for _, transport := range s.transports {
+		if !enabled[transport.Channel()] {
+			continue
+		}
+		if err := transport.Send(user, notifType, data); err != nil {
+			logrus.Errorf("Notification delivery failed on channel %q for user %d: %v", transport.Channel(), user.ID, err)
+			lastErr = err
+		}
+	}
+	return lastErr
+}
@@ -0,0 +1,516 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-server/config"
+	"go-server/models"
+	"go-server/repositories"
+	"go-server/utils"
+
+	"github.com/sirupsen/logrus"
+)
+
+// githubUserAPIURL is GitHub's user-info endpoint, used to verify a GitHub
+// access token in place of the JWKS signature check OIDC providers get,
+// since GitHub's classic OAuth apps don't issue OIDC ID tokens.
+const githubUserAPIURL = "https://api.github.com/user"
+
+// githubUserEmailsAPIURL lists the account's emails with their
+// primary/verified flags, which the profile endpoint above doesn't expose.
+const githubUserEmailsAPIURL = "https://api.github.com/user/emails"
+
+// githubTokenURL and githubAuthURL are GitHub's fixed authorization-code
+// endpoints; unlike Google/generic OIDC they aren't configurable per
+// deployment.
+const githubTokenURL = "https://github.com/login/oauth/access_token"
+const githubAuthURL = "https://github.com/login/oauth/authorize"
+
+// oidcScopes and githubScopes are the permissions requested on the
+// authorization redirect: openid+email+profile are the standard OIDC
+// claims this service reads back from the ID token; GitHub has no OIDC
+// scope and instead needs read:user/user:email to call githubUserAPIURL.
+const oidcScopes = "openid email profile"
+const githubScopes = "read:user user:email"
+
+// OAuthService authenticates a user via a social login provider and issues
+// a session the same way AuthService.Login does, auto-creating a local
+// user account linked by email on first login from that provider.
+type OAuthService interface {
+	// Login verifies req's credential against provider and returns a
+	// session for the matching (or newly created) local user. Used when the
+	// client already completed the provider-side OAuth dance and holds an
+	// id_token/access_token directly (SDK or implicit-style flow).
+	Login(provider string, req *models.OAuthLoginRequest, userAgent, ip string) (*models.UserLoginResponse, error)
+
+	// Callback exchanges an authorization code for a provider credential,
+	// then proceeds exactly like Login. Used when the client only holds the
+	// code a provider's redirect-based flow handed back to it. codeVerifier
+	// is the PKCE verifier generated alongside the state AuthorizeURL
+	// returned, empty for providers (GitHub) that don't support PKCE.
+	Callback(provider string, req *models.OAuthCallbackRequest, codeVerifier, userAgent, ip string) (*models.UserLoginResponse, error)
+
+	// AuthorizeURL builds provider's authorization endpoint URL for the
+	// frontend to redirect the browser to, embedding state for CSRF
+	// protection and, when codeChallenge is non-empty, a PKCE
+	// code_challenge so the authorization code can only be redeemed by
+	// whoever holds the matching code_verifier.
+	AuthorizeURL(provider, state, codeChallenge string) (string, error)
+}
+
+type OAuthServiceImpl struct {
+	userRepo       repositories.UserRepository
+	identityRepo   repositories.UserIdentityRepository
+	tokenService   TokenService
+	config         *config.Config
+	googleVerifier *utils.OIDCVerifier
+	oidcVerifier   *utils.OIDCVerifier
+	httpClient     *http.Client
+}
+
+func NewOAuthService(userRepo repositories.UserRepository, identityRepo repositories.UserIdentityRepository, tokenService TokenService, cfg *config.Config) OAuthService {
+	svc := &OAuthServiceImpl{
+		userRepo:     userRepo,
+		identityRepo: identityRepo,
+		tokenService: tokenService,
+		config:       cfg,
+		httpClient:   &http.Client{Timeout: 10 * time.Second},
+	}
+
+	if cfg.OAuth.Google.ClientID != "" {
+		svc.googleVerifier = utils.NewOIDCVerifier(cfg.OAuth.Google.Issuer, cfg.OAuth.Google.JWKSURL, cfg.OAuth.Google.ClientID)
+	}
+	if cfg.OAuth.OIDC.ClientID != "" {
+		svc.oidcVerifier = utils.NewOIDCVerifier(cfg.OAuth.OIDC.Issuer, cfg.OAuth.OIDC.JWKSURL, cfg.OAuth.OIDC.ClientID)
+	}
+
+	return svc
+}
+
+// Login dispatches to the provider-specific verification, then finds or
+// creates the local user it identifies and issues a session for them.
+func (s *OAuthServiceImpl) Login(provider string, req *models.OAuthLoginRequest, userAgent, ip string) (*models.UserLoginResponse, error) {
+	var subject, email string
+	var emailVerified bool
+	var err error
+
+	switch provider {
+	case "google":
+		subject, email, emailVerified, err = s.verifyOIDC(s.googleVerifier, req.IDToken)
+	case "oidc":
+		subject, email, emailVerified, err = s.verifyOIDC(s.oidcVerifier, req.IDToken)
+	case "github":
+		subject, email, emailVerified, err = s.verifyGitHub(req.AccessToken)
+	default:
+		return nil, errors.New("unsupported oauth provider")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if email == "" {
+		return nil, errors.New("provider did not return a verifiable email")
+	}
+
+	user, err := s.findOrLinkUser(provider, subject, email, emailVerified)
+	if err != nil {
+		return nil, err
+	}
+
+	if !user.IsActive {
+		return nil, errors.New("account is deactivated")
+	}
+
+	accessToken, refreshToken, err := s.tokenService.IssueSession(user.ID, user.Email, userAgent, ip)
+	if err != nil {
+		logrus.Errorf("Error issuing session: %v", err)
+		return nil, errors.New("failed to generate tokens")
+	}
+
+	if err := s.userRepo.UpdateLastLogin(user.ID); err != nil {
+		logrus.Errorf("Error updating last login: %v", err)
+		// Don't fail the login for this
+	}
+
+	logrus.Infof("User logged in via %s oauth: %s", provider, user.Email)
+	return &models.UserLoginResponse{
+		User:         user.ToDict(),
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+	}, nil
+}
+
+// Callback exchanges req's authorization code (and, for PKCE-capable
+// providers, codeVerifier) for a provider credential at the provider's
+// token endpoint, then delegates to Login with it.
+func (s *OAuthServiceImpl) Callback(provider string, req *models.OAuthCallbackRequest, codeVerifier, userAgent, ip string) (*models.UserLoginResponse, error) {
+	var loginReq *models.OAuthLoginRequest
+	var err error
+
+	switch provider {
+	case "google":
+		loginReq, err = s.exchangeCodeForIDToken(s.config.OAuth.Google, req.Code, codeVerifier)
+	case "oidc":
+		loginReq, err = s.exchangeCodeForIDToken(s.config.OAuth.OIDC, req.Code, codeVerifier)
+	case "github":
+		loginReq, err = s.exchangeGitHubCode(req.Code)
+	default:
+		return nil, errors.New("unsupported oauth provider")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return s.Login(provider, loginReq, userAgent, ip)
+}
+
+// AuthorizeURL builds provider's authorization endpoint URL, embedding state
+// and, when codeChallenge is non-empty, the PKCE challenge. GitHub doesn't
+// support PKCE, so codeChallenge is ignored for it.
+func (s *OAuthServiceImpl) AuthorizeURL(provider, state, codeChallenge string) (string, error) {
+	switch provider {
+	case "google":
+		return s.buildAuthorizeURL(s.config.OAuth.Google, oidcScopes, state, codeChallenge)
+	case "oidc":
+		return s.buildAuthorizeURL(s.config.OAuth.OIDC, oidcScopes, state, codeChallenge)
+	case "github":
+		cfg := s.config.OAuth.GitHub
+		if cfg.ClientID == "" {
+			return "", errors.New("oauth provider is not configured")
+		}
+		params := url.Values{
+			"client_id":    {cfg.ClientID},
+			"redirect_uri": {cfg.RedirectURL},
+			"scope":        {githubScopes},
+			"state":        {state},
+		}
+		return githubAuthURL + "?" + params.Encode(), nil
+	default:
+		return "", errors.New("unsupported oauth provider")
+	}
+}
+
+// buildAuthorizeURL builds an OIDC provider's authorization URL for
+// AuthorizeURL, adding a PKCE code_challenge when one is given.
+func (s *OAuthServiceImpl) buildAuthorizeURL(cfg config.OIDCProviderConfig, scopes, state, codeChallenge string) (string, error) {
+	if cfg.ClientID == "" {
+		return "", errors.New("oauth provider is not configured")
+	}
+
+	params := url.Values{
+		"client_id":     {cfg.ClientID},
+		"redirect_uri":  {cfg.RedirectURL},
+		"response_type": {"code"},
+		"scope":         {scopes},
+		"state":         {state},
+	}
+	if codeChallenge != "" {
+		params.Set("code_challenge", codeChallenge)
+		params.Set("code_challenge_method", "S256")
+	}
+
+	return cfg.AuthURL + "?" + params.Encode(), nil
+}
+
+// exchangeCodeForIDToken redeems code (and, if the authorization request
+// used PKCE, codeVerifier) at cfg's token endpoint for an ID token, for OIDC
+// providers (Google and generic OIDC).
+func (s *OAuthServiceImpl) exchangeCodeForIDToken(cfg config.OIDCProviderConfig, code, codeVerifier string) (*models.OAuthLoginRequest, error) {
+	if cfg.ClientID == "" {
+		return nil, errors.New("oauth provider is not configured")
+	}
+	if code == "" {
+		return nil, errors.New("code is required")
+	}
+
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"client_id":     {cfg.ClientID},
+		"client_secret": {cfg.ClientSecret},
+		"redirect_uri":  {cfg.RedirectURL},
+	}
+	if codeVerifier != "" {
+		form.Set("code_verifier", codeVerifier)
+	}
+
+	body, err := s.postForm(cfg.TokenURL, form)
+	if err != nil {
+		return nil, err
+	}
+
+	var tokenResp struct {
+		IDToken string `json:"id_token"`
+	}
+	if err := json.Unmarshal(body, &tokenResp); err != nil {
+		return nil, errors.New("failed to parse token response")
+	}
+	if tokenResp.IDToken == "" {
+		return nil, errors.New("token endpoint did not return an id_token")
+	}
+
+	return &models.OAuthLoginRequest{IDToken: tokenResp.IDToken}, nil
+}
+
+// exchangeGitHubCode redeems code at GitHub's fixed token endpoint for an
+// access token.
+func (s *OAuthServiceImpl) exchangeGitHubCode(code string) (*models.OAuthLoginRequest, error) {
+	cfg := s.config.OAuth.GitHub
+	if cfg.ClientID == "" {
+		return nil, errors.New("oauth provider is not configured")
+	}
+	if code == "" {
+		return nil, errors.New("code is required")
+	}
+
+	form := url.Values{
+		"code":          {code},
+		"client_id":     {cfg.ClientID},
+		"client_secret": {cfg.ClientSecret},
+		"redirect_uri":  {cfg.RedirectURL},
+	}
+
+	body, err := s.postForm(githubTokenURL, form)
+	if err != nil {
+		return nil, err
+	}
+
+	var tokenResp struct {
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.Unmarshal(body, &tokenResp); err != nil {
+		return nil, errors.New("failed to parse token response")
+	}
+	if tokenResp.AccessToken == "" {
+		return nil, errors.New("token endpoint did not return an access_token")
+	}
+
+	return &models.OAuthLoginRequest{AccessToken: tokenResp.AccessToken}, nil
+}
+
+// postForm POSTs form to tokenURL and returns the response body, requesting
+// a JSON response since GitHub's token endpoint defaults to
+// form-urlencoded otherwise.
+func (s *OAuthServiceImpl) postForm(tokenURL string, form url.Values) ([]byte, error) {
+	req, err := http.NewRequest(http.MethodPost, tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		logrus.Errorf("Error calling oauth token endpoint: %v", err)
+		return nil, errors.New("failed to reach oauth provider")
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.New("failed to read token response")
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.New("oauth provider rejected the authorization code")
+	}
+
+	return body, nil
+}
+
+// verifyOIDC checks idToken against verifier, returning an error if the
+// provider isn't configured (empty ClientID in config.Config.OAuth) rather
+// than panicking on a nil verifier. subject is the OIDC "sub" claim,
+// used to key the models.UserIdentity link since it's stable even if the
+// user's email later changes at the provider. emailVerified reflects the
+// token's "email_verified" claim; findOrLinkUser refuses to link or
+// auto-provision by email unless it's true.
+func (s *OAuthServiceImpl) verifyOIDC(verifier *utils.OIDCVerifier, idToken string) (subject, email string, emailVerified bool, err error) {
+	if verifier == nil {
+		return "", "", false, errors.New("oauth provider is not configured")
+	}
+	if idToken == "" {
+		return "", "", false, errors.New("id_token is required")
+	}
+
+	subject, email, emailVerified, err = verifier.VerifyIDToken(idToken)
+	if err != nil {
+		logrus.Errorf("Error verifying oidc id token: %v", err)
+		return "", "", false, errors.New("invalid or expired id token")
+	}
+	return subject, email, emailVerified, nil
+}
+
+// verifyGitHub confirms accessToken is valid by calling GitHub's user API
+// with it, returning the account's numeric id (GitHub has no OIDC "sub"
+// claim, so this substitutes as the stable identity subject) and the
+// account's primary email, plus whether GitHub reports that email as
+// verified. The profile email /user returns carries no verification
+// assertion, so this instead calls /user/emails (which the user:email
+// scope in githubScopes grants access to) and only returns an email that's
+// both primary and verified.
+func (s *OAuthServiceImpl) verifyGitHub(accessToken string) (subject, email string, emailVerified bool, err error) {
+	if s.config.OAuth.GitHub.ClientID == "" {
+		return "", "", false, errors.New("oauth provider is not configured")
+	}
+	if accessToken == "" {
+		return "", "", false, errors.New("access_token is required")
+	}
+
+	userBody, err := s.githubGet(githubUserAPIURL, accessToken)
+	if err != nil {
+		return "", "", false, err
+	}
+	var githubUser struct {
+		ID int64 `json:"id"`
+	}
+	if err := json.Unmarshal(userBody, &githubUser); err != nil {
+		return "", "", false, errors.New("failed to parse github response")
+	}
+
+	emailsBody, err := s.githubGet(githubUserEmailsAPIURL, accessToken)
+	if err != nil {
+		return "", "", false, err
+	}
+	var githubEmails []struct {
+		Email    string `json:"email"`
+		Primary  bool   `json:"primary"`
+		Verified bool   `json:"verified"`
+	}
+	if err := json.Unmarshal(emailsBody, &githubEmails); err != nil {
+		return "", "", false, errors.New("failed to parse github email response")
+	}
+
+	for _, e := range githubEmails {
+		if e.Primary && e.Verified {
+			return strconv.FormatInt(githubUser.ID, 10), e.Email, true, nil
+		}
+	}
+
+	return "", "", false, errors.New("github account has no verified primary email; verify an email with github or use a different provider")
+}
+
+// githubGet issues an authenticated GET against one of GitHub's REST API
+// endpoints, shared by verifyGitHub's profile and email lookups.
+func (s *OAuthServiceImpl) githubGet(apiURL, accessToken string) ([]byte, error) {
+	req, err := http.NewRequest(http.MethodGet, apiURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build github request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	req.Header.Set("Accept", "application/vnd.github+json")
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		logrus.Errorf("Error calling github api %s: %v", apiURL, err)
+		return nil, errors.New("failed to verify github access token")
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.New("failed to read github response")
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.New("invalid or expired github access token")
+	}
+
+	return body, nil
+}
+
+// findOrLinkUser resolves provider/subject to a local user: a returning
+// social-login user is recognized by their existing models.UserIdentity
+// link; a user who already has a password/other-provider account with this
+// verified email gets this provider linked to it; otherwise a brand new
+// account is auto-provisioned and linked. Linking or auto-provisioning by
+// email is only done when emailVerified is true — an email the provider
+// doesn't itself vouch for can't be trusted to look up, let alone link
+// into, an existing account, since that would let anyone who gets a
+// provider to assert a victim's email take over the victim's account.
+func (s *OAuthServiceImpl) findOrLinkUser(provider, subject, email string, emailVerified bool) (*models.User, error) {
+	identity, err := s.identityRepo.GetByProviderSubject(provider, subject)
+	if err != nil {
+		logrus.Errorf("Error looking up oauth identity: %v", err)
+		return nil, errors.New("failed to check user existence")
+	}
+	if identity != nil {
+		user := &models.User{}
+		if err := s.userRepo.GetByID(identity.UserID, user); err != nil {
+			logrus.Errorf("Error loading user for oauth identity: %v", err)
+			return nil, errors.New("failed to load user")
+		}
+		return user, nil
+	}
+
+	if !emailVerified {
+		return nil, errors.New("oauth provider did not confirm ownership of the account's email")
+	}
+
+	user, err := s.userRepo.GetByEmail(email)
+	if err != nil {
+		logrus.Errorf("Error looking up oauth user: %v", err)
+		return nil, errors.New("failed to check user existence")
+	}
+	if user == nil {
+		user, err = s.createUser(email)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.identityRepo.Create(&models.UserIdentity{UserID: user.ID, Provider: provider, Subject: subject}); err != nil {
+		logrus.Errorf("Error linking oauth identity: %v", err)
+		return nil, errors.New("failed to link provider account")
+	}
+
+	return user, nil
+}
+
+// createUser auto-provisions a local account for a first-time oauth login,
+// with a random password the user can never type (PasswordHash/PasswordSalt
+// are gorm:"not null", so something still needs to be hashed into them).
+func (s *OAuthServiceImpl) createUser(email string) (*models.User, error) {
+	user := &models.User{
+		Email:    email,
+		IsActive: true,
+		IsAdmin:  false,
+	}
+
+	password, err := randomPassword()
+	if err != nil {
+		logrus.Errorf("Error generating oauth user password: %v", err)
+		return nil, errors.New("failed to process account creation")
+	}
+	hash, err := utils.HashPassword(password, s.config.Password.Pepper, utils.Argon2ParamsFromConfig(s.config))
+	if err != nil {
+		logrus.Errorf("Error setting oauth user password: %v", err)
+		return nil, errors.New("failed to process account creation")
+	}
+	user.PasswordHash = hash
+
+	if err := s.userRepo.CreateUser(user); err != nil {
+		logrus.Errorf("Error creating oauth user: %v", err)
+		return nil, errors.New("failed to create user")
+	}
+
+	logrus.Infof("User auto-created via oauth: %s", user.Email)
+	return user, nil
+}
+
+// randomPassword returns a hex-encoded 32-byte random string, used as an
+// unusable password for accounts created via oauth login.
+func randomPassword() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,46 @@
+package services
+
+import (
+	"go-server/repositories"
+	"go-server/utils"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	defaultSearchLimit = 20
+	maxSearchLimit     = 100
+)
+
+// SearchService answers cross-entity, cursor-paginated full-text search
+// across courses, subjects, chapters, and topics.
+type SearchService interface {
+	Search(entityType, query, cursorToken string, limit int) (items []map[string]interface{}, nextCursor string, total int64, err error)
+}
+
+type SearchServiceImpl struct {
+	searchRepo repositories.SearchRepository
+}
+
+func NewSearchService(searchRepo repositories.SearchRepository) SearchService {
+	return &SearchServiceImpl{searchRepo: searchRepo}
+}
+
+// Search decodes cursorToken, clamps limit, and delegates to searchRepo.
+func (s *SearchServiceImpl) Search(entityType, query, cursorToken string, limit int) ([]map[string]interface{}, string, int64, error) {
+	if limit <= 0 || limit > maxSearchLimit {
+		limit = defaultSearchLimit
+	}
+
+	cursor, err := utils.DecodeCursor(cursorToken)
+	if err != nil {
+		return nil, "", 0, err
+	}
+
+	items, nextCursor, total, err := s.searchRepo.Search(entityType, query, cursor, limit)
+	if err != nil {
+		logrus.Errorf("Error searching %s for %q: %v", entityType, query, err)
+		return nil, "", 0, err
+	}
+	return items, nextCursor, total, nil
+}
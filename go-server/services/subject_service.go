@@ -23,20 +23,22 @@ type SubjectService interface {
 }
 
 type SubjectServiceImpl struct {
-	subjectRepo  repositories.SubjectRepository
-	courseRepo   repositories.CourseRepository
-	geminiHelper *utils.GeminiHelper
+	subjectRepo     repositories.SubjectRepository
+	courseRepo      repositories.CourseRepository
+	llm             utils.LLMProvider
+	digestEventRepo repositories.DigestEventRepository
 }
 
-func NewSubjectService(subjectRepo repositories.SubjectRepository, courseRepo repositories.CourseRepository) SubjectService {
+func NewSubjectService(subjectRepo repositories.SubjectRepository, courseRepo repositories.CourseRepository, llm utils.LLMProvider, digestEventRepo repositories.DigestEventRepository) SubjectService {
 	return &SubjectServiceImpl{
-		subjectRepo:  subjectRepo,
-		courseRepo:   courseRepo,
-		geminiHelper: utils.NewGeminiHelper(),
+		subjectRepo:     subjectRepo,
+		courseRepo:      courseRepo,
+		llm:             llm,
+		digestEventRepo: digestEventRepo,
 	}
 }
 
-// GenerateSubjects generates subjects for a course using Gemini AI
+// GenerateSubjects generates subjects for a course using the configured LLM provider
 func (s *SubjectServiceImpl) GenerateSubjects(courseID uint) ([]models.Subject, error) {
 	// Get course details
 	course := &models.Course{}
@@ -60,19 +62,11 @@ func (s *SubjectServiceImpl) GenerateSubjects(courseID uint) ([]models.Subject,
 		}
 	}
 
-	// Generate subjects using Gemini
+	// Generate subjects using the configured LLM provider
 	ctx := context.Background()
-	prompt := fmt.Sprintf(`Based on the course '%s' with description '%s', 
-	generate a list of relevant subjects that should be included in this course.
-	Consider the following:
-	1. If it's a school/college/university course, align with their typical curriculum
-	2. Don't include the course name as a subject
-	3. Keep subjects relevant and practical
-	4. Generate maximum 5 core subjects for the course`, course.Name, course.Description)
-
-	subjectNames, err := s.geminiHelper.GenerateSubjects(ctx, course.Name, course.Description)
+	subjectNames, err := s.llm.GenerateSubjects(ctx, course.Name, course.Description)
 	if err != nil {
-		logrus.Errorf("Error generating subjects with Gemini: %v", err)
+		logrus.Errorf("Error generating subjects: %v", err)
 		return nil, errors.New("failed to generate subjects")
 	}
 
@@ -97,10 +91,32 @@ func (s *SubjectServiceImpl) GenerateSubjects(courseID uint) ([]models.Subject,
 		// Don't fail the operation for this
 	}
 
+	s.logDigestEvent(course, subjects)
+
 	logrus.Infof("Generated %d subjects for course: %s", len(subjects), course.Name)
 	return subjects, nil
 }
 
+// logDigestEvent records a digest_events row for course.UserID summarizing
+// the subjects just generated, for services.DigestService.RunDigest to pick
+// up on their next digest. A course with no owner (UserID nil) has no one
+// to digest to, so this is a no-op for those.
+func (s *SubjectServiceImpl) logDigestEvent(course *models.Course, subjects []models.Subject) {
+	if course.UserID == nil || len(subjects) == 0 {
+		return
+	}
+
+	event := &models.DigestEvent{
+		UserID:    *course.UserID,
+		CourseID:  course.ID,
+		EventType: models.DigestEventSubjectAdded,
+		Message:   fmt.Sprintf("%d new subjects added to %q", len(subjects), course.Name),
+	}
+	if err := s.digestEventRepo.Create(event); err != nil {
+		logrus.Errorf("Error logging digest event for subject generation: %v", err)
+	}
+}
+
 // GetSubjectsByCourseID returns all subjects for a course
 func (s *SubjectServiceImpl) GetSubjectsByCourseID(courseID uint) ([]models.Subject, error) {
 	subjects, err := s.subjectRepo.GetSubjectsByCourseID(courseID)
@@ -168,4 +184,4 @@ func (s *SubjectServiceImpl) DeleteSubjectsByCourseID(courseID uint) error {
 		return errors.New("failed to delete subjects")
 	}
 	return nil
-}
\ No newline at end of file
+}
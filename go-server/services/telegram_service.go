@@ -0,0 +1,220 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"go-server/config"
+	"go-server/models"
+	"go-server/repositories"
+	"go-server/utils"
+
+	"github.com/sirupsen/logrus"
+)
+
+// telegramLinkPINLength and telegramLinkPINExpiry bound the PIN
+// TelegramService.GenerateLinkPIN issues for /link-telegram: short enough to
+// type into a chat, expiring quickly since a leaked PIN lets anyone who
+// guesses it link their own chat to the issuing account.
+const (
+	telegramLinkPINLength = 6
+	telegramLinkPINExpiry = 10 * time.Minute
+)
+
+// TelegramService links a user's account to a Telegram chat and processes
+// updates from that bot, so services.NotificationService's TelegramTransport
+// has a chat ID to deliver to.
+type TelegramService interface {
+	// GenerateLinkPIN issues a short-lived PIN for userID; the user sends it
+	// to the bot to prove they control both the account and the chat.
+	GenerateLinkPIN(userID uint) (pin string, err error)
+	// HandleUpdate processes one Telegram Bot API update (a webhook POST
+	// body, or one item from a getUpdates poll): if its message text is an
+	// unexpired link PIN, it links the sending chat to that PIN's user and
+	// consumes it.
+	HandleUpdate(body []byte) error
+}
+
+type TelegramServiceImpl struct {
+	tokenRepo repositories.TokenRepository
+	userRepo  repositories.UserRepository
+	botToken  string
+	client    *http.Client
+}
+
+// NewTelegramService builds a TelegramService backed by cfg.Telegram.BotToken.
+// An empty BotToken means HandleUpdate can still link accounts, but any
+// reply to the user is skipped (see telegramSendMessage).
+func NewTelegramService(tokenRepo repositories.TokenRepository, userRepo repositories.UserRepository, cfg *config.Config) TelegramService {
+	return &TelegramServiceImpl{
+		tokenRepo: tokenRepo,
+		userRepo:  userRepo,
+		botToken:  cfg.Telegram.BotToken,
+		client:    &http.Client{},
+	}
+}
+
+// GenerateLinkPIN issues and stores a fresh telegram_link_pin token for
+// userID, the same unified-token-store pattern password reset and email
+// verification use (see models.Token).
+func (s *TelegramServiceImpl) GenerateLinkPIN(userID uint) (string, error) {
+	pin, err := utils.GenerateNumericPIN(telegramLinkPINLength)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate telegram link PIN: %w", err)
+	}
+
+	tokenRecord := &models.Token{
+		TokenHash: utils.HashToken(pin),
+		Type:      models.TokenTypeTelegramLink,
+		UserID:    userID,
+		ExpiresAt: time.Now().Add(telegramLinkPINExpiry),
+	}
+	if err := s.tokenRepo.CreateToken(tokenRecord); err != nil {
+		return "", fmt.Errorf("failed to store telegram link PIN: %w", err)
+	}
+
+	return pin, nil
+}
+
+// telegramUpdate is the subset of the Telegram Bot API's Update object
+// HandleUpdate needs: the chat a message arrived on and its text.
+type telegramUpdate struct {
+	Message *struct {
+		Chat struct {
+			ID int64 `json:"id"`
+		} `json:"chat"`
+		Text string `json:"text"`
+	} `json:"message"`
+}
+
+// HandleUpdate treats the update's message text as a link PIN: if it
+// matches an unexpired telegram_link_pin token, the sending chat is linked
+// to that token's user and the token is consumed. Anything else (a command,
+// an invalid or expired PIN) gets a reply explaining so rather than an error,
+// since a malformed or unrelated message isn't a server-side failure.
+func (s *TelegramServiceImpl) HandleUpdate(body []byte) error {
+	var update telegramUpdate
+	if err := json.Unmarshal(body, &update); err != nil {
+		return fmt.Errorf("failed to parse telegram update: %w", err)
+	}
+	if update.Message == nil {
+		return nil
+	}
+
+	chatID := fmt.Sprintf("%d", update.Message.Chat.ID)
+	pin := strings.TrimSpace(update.Message.Text)
+
+	tokenRecord, err := s.tokenRepo.GetToken(utils.HashToken(pin))
+	if err != nil {
+		return fmt.Errorf("failed to look up telegram link PIN: %w", err)
+	}
+	if tokenRecord == nil || tokenRecord.Type != models.TokenTypeTelegramLink {
+		return s.reply(chatID, "That code is invalid or has expired. Generate a new one from your account settings.")
+	}
+
+	if err := s.userRepo.UpdateTelegramChatID(tokenRecord.UserID, chatID); err != nil {
+		return fmt.Errorf("failed to link telegram chat: %w", err)
+	}
+	if err := s.tokenRepo.DeleteToken(tokenRecord.TokenHash); err != nil {
+		logrus.Errorf("Error deleting consumed telegram link PIN: %v", err)
+	}
+
+	return s.reply(chatID, "Your Telegram account is now linked. You'll receive notifications here.")
+}
+
+func (s *TelegramServiceImpl) reply(chatID, text string) error {
+	return telegramSendMessage(s.client, s.botToken, chatID, text)
+}
+
+// telegramSendMessage calls the Bot API's sendMessage, shared by
+// TelegramServiceImpl's linking replies and TelegramTransport's
+// notifications. An empty botToken (Telegram not configured) is a silent
+// no-op, the same way EmailServiceImpl skips sending without Mailgun
+// credentials.
+func telegramSendMessage(client *http.Client, botToken, chatID, text string) error {
+	if botToken == "" {
+		logrus.Warning("Telegram bot not configured, skipping message")
+		return nil
+	}
+
+	payload := url.Values{}
+	payload.Set("chat_id", chatID)
+	payload.Set("text", text)
+	payload.Set("parse_mode", "Markdown")
+
+	apiURL := fmt.Sprintf("https://api.telegram.org/bot%s/sendMessage", botToken)
+	resp, err := client.PostForm(apiURL, payload)
+	if err != nil {
+		return fmt.Errorf("failed to call telegram sendMessage: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("telegram sendMessage returned status: %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// telegramTemplates are the Markdown bodies TelegramTransport renders per
+// notification type. Unlike email, these aren't file-backed: a chat message
+// is short enough that rebranding it is a recompile, same as the email
+// subjects in defaultSubjects.
+var telegramTemplates = map[NotificationType]string{
+	NotificationTypePasswordReset: "*Password Reset*\n\nWe received a request to reset your password for your {AppName} account.\n\n[Reset your password]({ResetURL})\n\nThis link expires in 1 hour. If you didn't request this, you can ignore this message.",
+	NotificationTypeVerification:  "*Verify your email*\n\nThanks for signing up for {AppName}. Confirm your email to activate your account:\n\n[Verify email]({VerifyURL})\n\nThis link expires in 24 hours.",
+	NotificationTypeWelcome:       "*Welcome to {AppName}!*\n\nHi {Name}, we're excited to have you on board. Get started: {FrontendURL}\n\nQuestions? Contact {ContactEmail}.",
+}
+
+// TelegramTransport adapts TelegramService's underlying bot to
+// NotificationTransport, rendering each notification as Markdown instead of
+// email's HTML.
+type TelegramTransport struct {
+	botToken     string
+	client       *http.Client
+	appName      string
+	frontendURL  string
+	contactEmail string
+}
+
+// NewTelegramTransport builds a "telegram" NotificationTransport from cfg.
+func NewTelegramTransport(cfg *config.Config) *TelegramTransport {
+	return &TelegramTransport{
+		botToken:     cfg.Telegram.BotToken,
+		client:       &http.Client{},
+		appName:      cfg.AppName,
+		frontendURL:  cfg.FrontendURL,
+		contactEmail: cfg.MailContactEmail,
+	}
+}
+
+func (t *TelegramTransport) Channel() string { return "telegram" }
+
+func (t *TelegramTransport) Send(user *models.User, notifType NotificationType, data map[string]string) error {
+	if user.TelegramChatID == nil || *user.TelegramChatID == "" {
+		return nil
+	}
+
+	tmpl, ok := telegramTemplates[notifType]
+	if !ok {
+		return fmt.Errorf("telegram transport: unknown notification type %q", notifType)
+	}
+
+	ctx := map[string]string{
+		"AppName":      t.appName,
+		"FrontendURL":  t.frontendURL,
+		"ContactEmail": t.contactEmail,
+		"Name":         data["Name"],
+	}
+	switch notifType {
+	case NotificationTypePasswordReset:
+		ctx["ResetURL"] = fmt.Sprintf("%s/reset-password?token=%s", t.frontendURL, data["Token"])
+	case NotificationTypeVerification:
+		ctx["VerifyURL"] = fmt.Sprintf("%s/verify-email?token=%s", t.frontendURL, data["Token"])
+	}
+
+	return telegramSendMessage(t.client, t.botToken, *user.TelegramChatID, renderPlaceholders(tmpl, ctx))
+}
@@ -10,14 +10,19 @@ import (
 	"gorm.io/gorm"
 )
 
+// testimonialApproveScope gates changing a testimonial's approval status.
+// Before this, UpdateTestimonial's IsApproved branch was reachable by any
+// owner, not just an admin.
+const testimonialApproveScope = "testimonial:approve"
+
 type TestimonialService interface {
 	CreateTestimonial(userID uint, req *models.TestimonialCreateRequest) (*models.Testimonial, error)
 	GetUserTestimonials(userID uint) ([]models.Testimonial, error)
 	GetApprovedTestimonials() ([]models.Testimonial, error)
 	GetAllTestimonials() ([]models.Testimonial, error)
-	UpdateTestimonial(testimonialID, userID uint, req *models.TestimonialUpdateRequest) (*models.Testimonial, error)
+	UpdateTestimonial(testimonialID, userID uint, req *models.TestimonialUpdateRequest, authCtx models.AuthContext) (*models.Testimonial, error)
 	DeleteTestimonial(testimonialID, userID uint) error
-	ApproveTestimonial(testimonialID uint, isApproved bool) (*models.Testimonial, error)
+	ApproveTestimonial(testimonialID uint, isApproved bool, authCtx models.AuthContext) (*models.Testimonial, error)
 	GetTestimonialByID(testimonialID uint) (*models.Testimonial, error)
 }
 
@@ -99,8 +104,9 @@ func (s *TestimonialServiceImpl) GetAllTestimonials() ([]models.Testimonial, err
 	return testimonials, nil
 }
 
-// UpdateTestimonial updates a testimonial (user can only update their own)
-func (s *TestimonialServiceImpl) UpdateTestimonial(testimonialID, userID uint, req *models.TestimonialUpdateRequest) (*models.Testimonial, error) {
+// UpdateTestimonial updates a testimonial (user can only update their own).
+// Changing IsApproved additionally requires the testimonial:approve scope.
+func (s *TestimonialServiceImpl) UpdateTestimonial(testimonialID, userID uint, req *models.TestimonialUpdateRequest, authCtx models.AuthContext) (*models.Testimonial, error) {
 	// Get existing testimonial
 	testimonial := &models.Testimonial{}
 	if err := s.testimonialRepo.GetByID(testimonialID, testimonial); err != nil {
@@ -126,7 +132,9 @@ func (s *TestimonialServiceImpl) UpdateTestimonial(testimonialID, userID uint, r
 		testimonial.Rating = *req.Rating
 	}
 	if req.IsApproved != nil {
-		// Only admin can update approval status, this should be handled in admin routes
+		if !authCtx.HasScope(testimonialApproveScope) {
+			return nil, errors.New("forbidden: requires testimonial:approve scope")
+		}
 		testimonial.IsApproved = *req.IsApproved
 	}
 
@@ -164,8 +172,13 @@ func (s *TestimonialServiceImpl) DeleteTestimonial(testimonialID, userID uint) e
 	return nil
 }
 
-// ApproveTestimonial approves or disapproves a testimonial (admin only)
-func (s *TestimonialServiceImpl) ApproveTestimonial(testimonialID uint, isApproved bool) (*models.Testimonial, error) {
+// ApproveTestimonial approves or disapproves a testimonial; requires the
+// testimonial:approve scope regardless of which route called it.
+func (s *TestimonialServiceImpl) ApproveTestimonial(testimonialID uint, isApproved bool, authCtx models.AuthContext) (*models.Testimonial, error) {
+	if !authCtx.HasScope(testimonialApproveScope) {
+		return nil, errors.New("forbidden: requires testimonial:approve scope")
+	}
+
 	if err := s.testimonialRepo.UpdateApprovalStatus(testimonialID, isApproved); err != nil {
 		logrus.Errorf("Error updating testimonial approval: %v", err)
 		return nil, errors.New("failed to update testimonial approval")
@@ -193,4 +206,4 @@ func (s *TestimonialServiceImpl) GetTestimonialByID(testimonialID uint) (*models
 		return nil, errors.New("failed to get testimonial")
 	}
 	return testimonial, nil
-}
\ No newline at end of file
+}
@@ -0,0 +1,224 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"go-server/apierror"
+	"go-server/models"
+	"go-server/repositories"
+	"go-server/utils"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// TokenService implements refresh-token rotation with server-side
+// revocation: every issued refresh token is a single-use link in a
+// session's chain, persisted in refresh_tokens so it can be revoked before
+// its natural JWT expiry, and reused only once before Rotate detects it and
+// kills the whole session.
+type TokenService interface {
+	// IssueSession starts a new rotation chain for userID and returns its
+	// first refresh token alongside a fresh access token.
+	IssueSession(userID uint, email, userAgent, ip string) (accessToken, refreshToken string, err error)
+	// Rotate redeems refreshToken: if it is the current, unrevoked head of
+	// its chain, the chain advances to a new jti and a new token pair is
+	// returned. If refreshToken's jti was already revoked, that's reuse of a
+	// stolen or replayed token, so the entire session chain is revoked and
+	// Rotate returns an error forcing the caller to log in again.
+	Rotate(refreshToken, userAgent, ip string) (accessToken, newRefreshToken string, err error)
+	RevokeSession(userID uint, sessionID string) error
+	RevokeAllUserSessions(userID uint) error
+	ListActiveSessions(userID uint) ([]models.RefreshToken, error)
+}
+
+type TokenServiceImpl struct {
+	refreshTokenRepo repositories.RefreshTokenRepository
+	userRepo         repositories.UserRepository
+	roleRepo         repositories.RoleRepository
+	userTOTPRepo     repositories.UserTOTPRepository
+	jwtUtil          *utils.JWTUtil
+	sessionCache     *utils.SessionRevocationCache
+}
+
+func NewTokenService(refreshTokenRepo repositories.RefreshTokenRepository, userRepo repositories.UserRepository, roleRepo repositories.RoleRepository, userTOTPRepo repositories.UserTOTPRepository, jwtUtil *utils.JWTUtil, sessionCache *utils.SessionRevocationCache) TokenService {
+	return &TokenServiceImpl{
+		refreshTokenRepo: refreshTokenRepo,
+		userRepo:         userRepo,
+		roleRepo:         roleRepo,
+		userTOTPRepo:     userTOTPRepo,
+		jwtUtil:          jwtUtil,
+		sessionCache:     sessionCache,
+	}
+}
+
+// revokeSessionEverywhere ends sessionID both durably (refresh_tokens, so no
+// further rotation is possible) and immediately (the in-memory cache
+// AuthMiddleware consults, so an access token already issued under it stops
+// working before its own expiry too).
+func (s *TokenServiceImpl) revokeSessionEverywhere(sessionID string) error {
+	s.sessionCache.Revoke(sessionID, s.jwtUtil.AccessTokenDuration())
+	return s.refreshTokenRepo.RevokeSession(sessionID)
+}
+
+// isMFAEnrolled reports whether userID currently has an activated TOTP
+// enrollment.
+func (s *TokenServiceImpl) isMFAEnrolled(userID uint) (bool, error) {
+	totp, err := s.userTOTPRepo.GetByUserID(userID)
+	if err != nil {
+		return false, err
+	}
+	return totp != nil && totp.Enabled, nil
+}
+
+func (s *TokenServiceImpl) IssueSession(userID uint, email, userAgent, ip string) (string, string, error) {
+	return s.issueLink(userID, email, uuid.NewString(), nil, userAgent, ip)
+}
+
+func (s *TokenServiceImpl) Rotate(refreshToken, userAgent, ip string) (string, string, error) {
+	claims, err := s.jwtUtil.ValidateToken(refreshToken)
+	if err != nil {
+		return "", "", fmt.Errorf("%w", apierror.Unauthorized("invalid or expired refresh token"))
+	}
+	if claims.Subject != "refresh_token" {
+		return "", "", fmt.Errorf("%w", apierror.Unauthorized("invalid token type"))
+	}
+
+	current, err := s.refreshTokenRepo.GetByJTI(claims.ID)
+	if err != nil {
+		return "", "", fmt.Errorf("%w", apierror.Internal("failed to look up refresh token", err))
+	}
+	if current == nil {
+		return "", "", fmt.Errorf("%w", apierror.Unauthorized("unknown refresh token"))
+	}
+
+	if current.RevokedAt != nil {
+		// The jti was already redeemed (or explicitly revoked): either the
+		// legitimate client retried after a dropped response, or someone
+		// else is replaying a stolen token. Either way, the whole chain is
+		// no longer trustworthy, so kill the session and force re-login.
+		logrus.Warnf("Refresh token reuse detected for session %s (jti %s), revoking session", current.SessionID, current.JTI)
+		if err := s.revokeSessionEverywhere(current.SessionID); err != nil {
+			logrus.Errorf("Failed to revoke session %s after reuse detection: %v", current.SessionID, err)
+		}
+		return "", "", fmt.Errorf("%w", apierror.Unauthorized("refresh token has already been used, please log in again"))
+	}
+
+	user := &models.User{}
+	if err := s.userRepo.GetByID(claims.UserID, user); err != nil {
+		return "", "", fmt.Errorf("%w", apierror.Internal("failed to load user", err))
+	}
+	if !user.IsActive {
+		return "", "", fmt.Errorf("%w", apierror.Forbidden("account is deactivated"))
+	}
+
+	mfaEnrolled, err := s.isMFAEnrolled(user.ID)
+	if err != nil {
+		return "", "", fmt.Errorf("%w", apierror.Internal("failed to check MFA enrollment", err))
+	}
+	if mfaEnrolled != claims.MFAEnrolled {
+		// The user enrolled or disabled 2FA since this refresh token was
+		// issued, so the trust decision it was granted under no longer
+		// holds; force them back through login (and, if now enrolled,
+		// through the 2FA step).
+		logrus.Warnf("MFA enrollment changed for user %d since refresh token issuance, revoking session %s", user.ID, current.SessionID)
+		if err := s.revokeSessionEverywhere(current.SessionID); err != nil {
+			logrus.Errorf("Failed to revoke session %s after MFA enrollment change: %v", current.SessionID, err)
+		}
+		return "", "", fmt.Errorf("%w", apierror.Unauthorized("two-factor authentication status has changed, please log in again"))
+	}
+
+	if err := s.refreshTokenRepo.RevokeByJTI(current.JTI); err != nil {
+		return "", "", fmt.Errorf("%w", apierror.Internal("failed to revoke rotated token", err))
+	}
+
+	return s.issueLink(user.ID, user.Email, current.SessionID, &current.JTI, userAgent, ip)
+}
+
+// issueLink persists the next link in sessionID's rotation chain and signs
+// the token pair for it.
+func (s *TokenServiceImpl) issueLink(userID uint, email, sessionID string, parentJTI *string, userAgent, ip string) (string, string, error) {
+	roles, err := s.roleRepo.GetRolesByUserID(userID)
+	if err != nil {
+		return "", "", fmt.Errorf("%w", apierror.Internal("failed to load roles", err))
+	}
+
+	accessToken, err := s.jwtUtil.GenerateAccessToken(userID, email, models.RoleNames(roles), models.RoleScopes(roles), sessionID)
+	if err != nil {
+		return "", "", fmt.Errorf("%w", apierror.Internal("failed to generate access token", err))
+	}
+
+	mfaEnrolled, err := s.isMFAEnrolled(userID)
+	if err != nil {
+		return "", "", fmt.Errorf("%w", apierror.Internal("failed to check MFA enrollment", err))
+	}
+
+	jti := uuid.NewString()
+	refreshToken, err := s.jwtUtil.GenerateRefreshTokenWithClaims(userID, email, sessionID, jti, mfaEnrolled)
+	if err != nil {
+		return "", "", fmt.Errorf("%w", apierror.Internal("failed to generate refresh token", err))
+	}
+
+	now := time.Now()
+	row := &models.RefreshToken{
+		UserID:    userID,
+		SessionID: sessionID,
+		JTI:       jti,
+		ParentJTI: parentJTI,
+		IssuedAt:  now,
+		ExpiresAt: now.Add(s.jwtUtil.RefreshTokenDuration()),
+		UserAgent: userAgent,
+		IP:        ip,
+	}
+	if err := s.refreshTokenRepo.Create(row); err != nil {
+		return "", "", fmt.Errorf("%w", apierror.Internal("failed to persist refresh token", err))
+	}
+
+	return accessToken, refreshToken, nil
+}
+
+func (s *TokenServiceImpl) RevokeSession(userID uint, sessionID string) error {
+	sessions, err := s.refreshTokenRepo.GetActiveSessionsByUser(userID)
+	if err != nil {
+		return fmt.Errorf("%w", apierror.Internal("failed to look up sessions", err))
+	}
+	owned := false
+	for _, session := range sessions {
+		if session.SessionID == sessionID {
+			owned = true
+			break
+		}
+	}
+	if !owned {
+		return fmt.Errorf("%w", apierror.NotFound("session"))
+	}
+
+	if err := s.revokeSessionEverywhere(sessionID); err != nil {
+		return fmt.Errorf("%w", apierror.Internal("failed to revoke session", err))
+	}
+	return nil
+}
+
+func (s *TokenServiceImpl) RevokeAllUserSessions(userID uint) error {
+	sessions, err := s.refreshTokenRepo.GetActiveSessionsByUser(userID)
+	if err != nil {
+		return fmt.Errorf("%w", apierror.Internal("failed to look up sessions", err))
+	}
+	for _, session := range sessions {
+		s.sessionCache.Revoke(session.SessionID, s.jwtUtil.AccessTokenDuration())
+	}
+
+	if err := s.refreshTokenRepo.RevokeAllUserSessions(userID); err != nil {
+		return fmt.Errorf("%w", apierror.Internal("failed to revoke sessions", err))
+	}
+	return nil
+}
+
+func (s *TokenServiceImpl) ListActiveSessions(userID uint) ([]models.RefreshToken, error) {
+	sessions, err := s.refreshTokenRepo.GetActiveSessionsByUser(userID)
+	if err != nil {
+		return nil, fmt.Errorf("%w", apierror.Internal("failed to list sessions", err))
+	}
+	return sessions, nil
+}
@@ -2,8 +2,10 @@ package services
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"go-server/jobs"
 	"go-server/models"
 	"go-server/repositories"
 	"go-server/utils"
@@ -12,8 +14,16 @@ import (
 	"gorm.io/gorm"
 )
 
+// jobKindGenerateTopics is the kind registered with queue by
+// NewTopicService.
+const jobKindGenerateTopics = "generate_topics"
+
 type TopicService interface {
 	GenerateTopics(courseID, subjectID, chapterID uint) ([]models.Topic, error)
+	// EnqueueGenerateTopicsJob queues the same work GenerateTopics does
+	// synchronously, returning a *models.Job a client can poll instead of
+	// holding the request open for the LLM call.
+	EnqueueGenerateTopicsJob(courseID, subjectID, chapterID uint, idempotencyKey string) (*models.Job, error)
 	GetTopicsByChapterID(chapterID uint) ([]models.Topic, error)
 	GetTopicByID(topicID uint) (*models.Topic, error)
 	CreateTopics(topics []models.Topic) error
@@ -23,24 +33,30 @@ type TopicService interface {
 }
 
 type TopicServiceImpl struct {
-	topicRepo    repositories.TopicRepository
-	chapterRepo  repositories.ChapterRepository
-	subjectRepo  repositories.SubjectRepository
-	courseRepo   repositories.CourseRepository
-	geminiHelper *utils.GeminiHelper
+	topicRepo   repositories.TopicRepository
+	chapterRepo repositories.ChapterRepository
+	subjectRepo repositories.SubjectRepository
+	courseRepo  repositories.CourseRepository
+	llm         utils.LLMProvider
+	queue       *jobs.Queue
 }
 
-func NewTopicService(topicRepo repositories.TopicRepository, chapterRepo repositories.ChapterRepository, subjectRepo repositories.SubjectRepository, courseRepo repositories.CourseRepository) TopicService {
-	return &TopicServiceImpl{
-		topicRepo:    topicRepo,
-		chapterRepo:  chapterRepo,
-		subjectRepo:  subjectRepo,
-		courseRepo:   courseRepo,
-		geminiHelper: utils.NewGeminiHelper(),
+func NewTopicService(topicRepo repositories.TopicRepository, chapterRepo repositories.ChapterRepository, subjectRepo repositories.SubjectRepository, courseRepo repositories.CourseRepository, llm utils.LLMProvider, queue *jobs.Queue) TopicService {
+	s := &TopicServiceImpl{
+		topicRepo:   topicRepo,
+		chapterRepo: chapterRepo,
+		subjectRepo: subjectRepo,
+		courseRepo:  courseRepo,
+		llm:         llm,
+		queue:       queue,
 	}
+
+	queue.Register(jobKindGenerateTopics, s.handleGenerateTopicsJob)
+
+	return s
 }
 
-// GenerateTopics generates topics for a chapter using Gemini AI
+// GenerateTopics generates topics for a chapter using the configured LLM provider
 func (s *TopicServiceImpl) GenerateTopics(courseID, subjectID, chapterID uint) ([]models.Topic, error) {
 	logrus.Infof("Starting topic generation for chapter_id: %d, subject_id: %d, course_id: %d", chapterID, subjectID, courseID)
 
@@ -95,20 +111,11 @@ func (s *TopicServiceImpl) GenerateTopics(courseID, subjectID, chapterID uint) (
 		return existingTopics, nil
 	}
 
-	// Generate topics using Gemini
+	// Generate topics using the configured LLM provider
 	ctx := context.Background()
-	prompt := fmt.Sprintf(`Generate a comprehensive list of topics for the chapter '%s' in subject '%s' for the course '%s'.
-	Consider the following:
-	1. Include topics from basic to advanced level within this chapter
-	2. Each topic should be a distinct subtopic within the chapter
-	3. Topics should follow a logical learning progression
-	4. Generate maximum 6 topics for the chapter
-	5. Keep topic names concise and clear
-	6. Focus on specific concepts that can be taught individually`, chapter.Name, subject.Name, course.Name)
-
-	topicNames, err := s.geminiHelper.GenerateTopics(ctx, chapter.Name, subject.Name, course.Name)
+	topicNames, err := s.llm.GenerateTopics(ctx, chapter.Name, subject.Name, course.Name)
 	if err != nil {
-		logrus.Errorf("Error generating topics with Gemini: %v", err)
+		logrus.Errorf("Error generating topics: %v", err)
 		return nil, errors.New("failed to generate topics")
 	}
 
@@ -133,6 +140,44 @@ func (s *TopicServiceImpl) GenerateTopics(courseID, subjectID, chapterID uint) (
 	return topics, nil
 }
 
+// generateTopicsJobPayload is EnqueueGenerateTopicsJob's JSON payload.
+type generateTopicsJobPayload struct {
+	CourseID  uint `json:"course_id"`
+	SubjectID uint `json:"subject_id"`
+	ChapterID uint `json:"chapter_id"`
+}
+
+// EnqueueGenerateTopicsJob queues GenerateTopics to run on the worker pool.
+func (s *TopicServiceImpl) EnqueueGenerateTopicsJob(courseID, subjectID, chapterID uint, idempotencyKey string) (*models.Job, error) {
+	payload, err := json.Marshal(generateTopicsJobPayload{CourseID: courseID, SubjectID: subjectID, ChapterID: chapterID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode job payload: %w", err)
+	}
+	return s.queue.Enqueue(jobKindGenerateTopics, string(payload), idempotencyKey)
+}
+
+func (s *TopicServiceImpl) handleGenerateTopicsJob(jobID uint, payload string) (string, error) {
+	var p generateTopicsJobPayload
+	if err := json.Unmarshal([]byte(payload), &p); err != nil {
+		return "", fmt.Errorf("failed to decode job payload: %w", err)
+	}
+
+	topics, err := s.GenerateTopics(p.CourseID, p.SubjectID, p.ChapterID)
+	if err != nil {
+		return "", err
+	}
+
+	topicDicts := make([]map[string]interface{}, len(topics))
+	for i, topic := range topics {
+		topicDicts[i] = topic.ToDict()
+	}
+	result, err := json.Marshal(topicDicts)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode job result: %w", err)
+	}
+	return string(result), nil
+}
+
 // GetTopicsByChapterID returns all topics for a chapter
 func (s *TopicServiceImpl) GetTopicsByChapterID(chapterID uint) ([]models.Topic, error) {
 	topics, err := s.topicRepo.GetTopicsByChapterID(chapterID)
@@ -200,4 +245,4 @@ func (s *TopicServiceImpl) DeleteTopicsByChapterID(chapterID uint) error {
 		return errors.New("failed to delete topics")
 	}
 	return nil
-}
\ No newline at end of file
+}
@@ -0,0 +1,74 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go-server/utils"
+)
+
+// AzureBackend adapts the existing utils.AzureStorageHelper singleton to the
+// Backend interface. It is the default driver, preserving current behavior
+// for deployments that don't set STORAGE_PROVIDER.
+type AzureBackend struct {
+	helper *utils.AzureStorageHelper
+}
+
+// NewAzureBackend wraps the process-wide Azure Storage helper.
+func NewAzureBackend() *AzureBackend {
+	return &AzureBackend{helper: utils.GetAzureStorageHelper()}
+}
+
+func (b *AzureBackend) Name() string { return "azure" }
+
+func (b *AzureBackend) Upload(ctx context.Context, data []byte, path string) (string, error) {
+	if !b.helper.IsInitialized() {
+		return "", fmt.Errorf("azure storage not initialized - check configuration")
+	}
+	return b.helper.UploadImage(data, path)
+}
+
+func (b *AzureBackend) Delete(ctx context.Context, path string) error {
+	if !b.helper.IsInitialized() {
+		return fmt.Errorf("azure storage not initialized - check configuration")
+	}
+	return b.helper.DeleteImage(path)
+}
+
+func (b *AzureBackend) List(ctx context.Context, prefix string) ([]string, error) {
+	if !b.helper.IsInitialized() {
+		return nil, fmt.Errorf("azure storage not initialized - check configuration")
+	}
+	return b.helper.ListImages(prefix)
+}
+
+func (b *AzureBackend) Exists(ctx context.Context, path string) (bool, error) {
+	if !b.helper.IsInitialized() {
+		return false, fmt.Errorf("azure storage not initialized - check configuration")
+	}
+	return b.helper.Exists(path)
+}
+
+func (b *AzureBackend) PresignedURL(ctx context.Context, path string, ttl time.Duration) (string, error) {
+	if !b.helper.IsInitialized() {
+		return "", fmt.Errorf("azure storage not initialized - check configuration")
+	}
+	return b.helper.GenerateSASURL(path, int(ttl.Hours()))
+}
+
+// PublicURL returns path's public URL without any network call.
+func (b *AzureBackend) PublicURL(path string) string {
+	return b.helper.BlobURL(path)
+}
+
+func (b *AzureBackend) Stat(ctx context.Context, path string) (ObjectInfo, error) {
+	if !b.helper.IsInitialized() {
+		return ObjectInfo{}, fmt.Errorf("azure storage not initialized - check configuration")
+	}
+	size, lastModified, err := b.helper.Stat(path)
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+	return ObjectInfo{Size: size, LastModified: lastModified}, nil
+}
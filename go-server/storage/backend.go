@@ -0,0 +1,48 @@
+// Package storage abstracts object storage behind a single Backend
+// interface so ImageService can run against Azure Blob, S3, GCS, or the
+// local filesystem without any caller-visible difference.
+package storage
+
+import (
+	"context"
+	"time"
+)
+
+// Backend is implemented by each storage driver. path is always a
+// slash-separated key relative to the backend's root (container/bucket/base
+// directory) — callers never deal in driver-specific addressing.
+type Backend interface {
+	// Upload writes data to path, creating or overwriting it, and returns a
+	// URL the object can be fetched from.
+	Upload(ctx context.Context, data []byte, path string) (string, error)
+
+	// Delete removes the object at path.
+	Delete(ctx context.Context, path string) error
+
+	// List returns the URLs of every object whose path starts with prefix.
+	List(ctx context.Context, prefix string) ([]string, error)
+
+	// Exists reports whether an object already exists at path.
+	Exists(ctx context.Context, path string) (bool, error)
+
+	// PublicURL returns path's URL without touching the backend, e.g. to
+	// re-derive the URL of an object a cache hit already knows exists.
+	PublicURL(path string) string
+
+	// PresignedURL returns a time-limited URL for path that expires after
+	// ttl. Backends with no native expiring-link support (e.g. local) return
+	// their normal public URL.
+	PresignedURL(ctx context.Context, path string, ttl time.Duration) (string, error)
+
+	// Stat returns metadata for the object at path without downloading it.
+	Stat(ctx context.Context, path string) (ObjectInfo, error)
+
+	// Name identifies the backend for logging, e.g. "azure", "s3".
+	Name() string
+}
+
+// ObjectInfo is the metadata Stat returns for an existing object.
+type ObjectInfo struct {
+	Size         int64
+	LastModified time.Time
+}
@@ -0,0 +1,24 @@
+package storage
+
+import (
+	"fmt"
+
+	"go-server/config"
+)
+
+// NewBackend builds the Backend selected by cfg.StorageProvider ("azure",
+// the default; "s3"; "gcs"; or "local").
+func NewBackend(cfg *config.Config) (Backend, error) {
+	switch cfg.StorageProvider {
+	case "s3":
+		return NewS3Backend(cfg.S3Bucket, cfg.S3Region, cfg.S3AccessKeyID, cfg.S3SecretAccessKey)
+	case "gcs":
+		return NewGCSBackend(cfg.GCSBucket, cfg.GCSCredentialsFile)
+	case "local":
+		return NewLocalBackend(cfg.LocalStorageDir, cfg.LocalStorageBaseURL)
+	case "azure", "":
+		return NewAzureBackend(), nil
+	default:
+		return nil, fmt.Errorf("unknown STORAGE_PROVIDER %q", cfg.StorageProvider)
+	}
+}
@@ -0,0 +1,119 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"cloud.google.com/go/storage"
+	"google.golang.org/api/iterator"
+	"google.golang.org/api/option"
+)
+
+// GCSBackend stores objects in a Google Cloud Storage bucket.
+type GCSBackend struct {
+	client *storage.Client
+	bucket string
+}
+
+// NewGCSBackend builds a GCSBackend for bucket. If credentialsFile is empty,
+// it falls back to Application Default Credentials.
+func NewGCSBackend(bucket, credentialsFile string) (*GCSBackend, error) {
+	ctx := context.Background()
+	var opts []option.ClientOption
+	if credentialsFile != "" {
+		opts = append(opts, option.WithCredentialsFile(credentialsFile))
+	}
+
+	client, err := storage.NewClient(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create GCS client: %w", err)
+	}
+
+	return &GCSBackend{client: client, bucket: bucket}, nil
+}
+
+func (b *GCSBackend) Name() string { return "gcs" }
+
+func (b *GCSBackend) object(path string) *storage.ObjectHandle {
+	return b.client.Bucket(b.bucket).Object(path)
+}
+
+func (b *GCSBackend) Upload(ctx context.Context, data []byte, path string) (string, error) {
+	w := b.object(path).NewWriter(ctx)
+	w.ContentType = "image/png"
+	if _, err := w.Write(data); err != nil {
+		w.Close()
+		return "", fmt.Errorf("failed to upload object to gcs: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return "", fmt.Errorf("failed to finalize gcs upload: %w", err)
+	}
+	return b.PublicURL(path), nil
+}
+
+func (b *GCSBackend) Delete(ctx context.Context, path string) error {
+	if err := b.object(path).Delete(ctx); err != nil {
+		return fmt.Errorf("failed to delete object from gcs: %w", err)
+	}
+	return nil
+}
+
+func (b *GCSBackend) List(ctx context.Context, prefix string) ([]string, error) {
+	var urls []string
+	it := b.client.Bucket(b.bucket).Objects(ctx, &storage.Query{Prefix: prefix})
+	for {
+		attrs, err := it.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to list gcs objects: %w", err)
+		}
+		urls = append(urls, b.PublicURL(attrs.Name))
+	}
+	return urls, nil
+}
+
+func (b *GCSBackend) Exists(ctx context.Context, path string) (bool, error) {
+	_, err := b.object(path).Attrs(ctx)
+	if err == storage.ErrObjectNotExist {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to check gcs object: %w", err)
+	}
+	return true, nil
+}
+
+func (b *GCSBackend) PresignedURL(ctx context.Context, path string, ttl time.Duration) (string, error) {
+	url, err := b.client.Bucket(b.bucket).SignedURL(path, &storage.SignedURLOptions{
+		Method:  "GET",
+		Expires: time.Now().Add(ttl),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to sign gcs url: %w", err)
+	}
+	return url, nil
+}
+
+func (b *GCSBackend) Stat(ctx context.Context, path string) (ObjectInfo, error) {
+	attrs, err := b.object(path).Attrs(ctx)
+	if err != nil {
+		return ObjectInfo{}, fmt.Errorf("failed to stat gcs object: %w", err)
+	}
+	return ObjectInfo{Size: attrs.Size, LastModified: attrs.Updated}, nil
+}
+
+// PublicURL returns path's public URL without any network call.
+func (b *GCSBackend) PublicURL(path string) string {
+	return fmt.Sprintf("https://storage.googleapis.com/%s/%s", b.bucket, path)
+}
+
+var _ io.Closer = (*GCSBackend)(nil)
+
+// Close releases the underlying GCS client.
+func (b *GCSBackend) Close() error {
+	return b.client.Close()
+}
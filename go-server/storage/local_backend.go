@@ -0,0 +1,118 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// LocalBackend stores objects under a directory on disk and serves them
+// back out through the /static/images/* route mounted by MountStaticRoutes.
+// It has no native expiry, so PresignedURL just returns the normal URL.
+type LocalBackend struct {
+	baseDir string
+	baseURL string
+}
+
+// NewLocalBackend builds a LocalBackend rooted at baseDir, serving objects
+// under publicBaseURL + "/static/images/" (publicBaseURL is typically the
+// API's own origin, since this backend has no separate CDN).
+func NewLocalBackend(baseDir, publicBaseURL string) (*LocalBackend, error) {
+	if err := os.MkdirAll(baseDir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create local storage directory: %w", err)
+	}
+	return &LocalBackend{
+		baseDir: baseDir,
+		baseURL: strings.TrimRight(publicBaseURL, "/"),
+	}, nil
+}
+
+func (b *LocalBackend) Name() string { return "local" }
+
+func (b *LocalBackend) diskPath(path string) string {
+	return filepath.Join(b.baseDir, filepath.FromSlash(path))
+}
+
+func (b *LocalBackend) Upload(ctx context.Context, data []byte, path string) (string, error) {
+	dest := b.diskPath(path)
+	if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+		return "", fmt.Errorf("failed to create local storage directory: %w", err)
+	}
+	if err := os.WriteFile(dest, data, 0o644); err != nil {
+		return "", fmt.Errorf("failed to write local file: %w", err)
+	}
+	return b.PublicURL(path), nil
+}
+
+func (b *LocalBackend) Delete(ctx context.Context, path string) error {
+	if err := os.Remove(b.diskPath(path)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete local file: %w", err)
+	}
+	return nil
+}
+
+func (b *LocalBackend) List(ctx context.Context, prefix string) ([]string, error) {
+	var urls []string
+	root := b.diskPath(prefix)
+	walkRoot := filepath.Dir(root)
+	if info, err := os.Stat(root); err == nil && info.IsDir() {
+		walkRoot = root
+	}
+
+	err := filepath.Walk(walkRoot, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(b.baseDir, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if strings.HasPrefix(rel, prefix) {
+			urls = append(urls, b.PublicURL(rel))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list local files: %w", err)
+	}
+	return urls, nil
+}
+
+func (b *LocalBackend) Exists(ctx context.Context, path string) (bool, error) {
+	_, err := os.Stat(b.diskPath(path))
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to stat local file: %w", err)
+	}
+	return true, nil
+}
+
+// PresignedURL ignores ttl: local files have no expiring-link mechanism.
+func (b *LocalBackend) PresignedURL(ctx context.Context, path string, ttl time.Duration) (string, error) {
+	return b.PublicURL(path), nil
+}
+
+func (b *LocalBackend) Stat(ctx context.Context, path string) (ObjectInfo, error) {
+	info, err := os.Stat(b.diskPath(path))
+	if err != nil {
+		return ObjectInfo{}, fmt.Errorf("failed to stat local file: %w", err)
+	}
+	return ObjectInfo{Size: info.Size(), LastModified: info.ModTime()}, nil
+}
+
+// PublicURL returns path's public URL without any network call.
+func (b *LocalBackend) PublicURL(path string) string {
+	return fmt.Sprintf("%s/static/images/%s", b.baseURL, path)
+}
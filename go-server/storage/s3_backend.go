@@ -0,0 +1,133 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"time"
+
+	awssdk "github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// S3Backend stores objects in an AWS S3 bucket.
+type S3Backend struct {
+	client *s3.Client
+	bucket string
+	region string
+}
+
+// NewS3Backend builds an S3Backend for bucket in region. accessKeyID and
+// secretAccessKey may be empty to fall back to the default AWS credential
+// chain (env vars, shared config, instance role).
+func NewS3Backend(bucket, region, accessKeyID, secretAccessKey string) (*S3Backend, error) {
+	optFns := []func(*awsconfig.LoadOptions) error{awsconfig.WithRegion(region)}
+	if accessKeyID != "" && secretAccessKey != "" {
+		optFns = append(optFns, awsconfig.WithCredentialsProvider(
+			credentials.NewStaticCredentialsProvider(accessKeyID, secretAccessKey, ""),
+		))
+	}
+
+	cfg, err := awsconfig.LoadDefaultConfig(context.Background(), optFns...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS config: %w", err)
+	}
+
+	return &S3Backend{
+		client: s3.NewFromConfig(cfg),
+		bucket: bucket,
+		region: region,
+	}, nil
+}
+
+func (b *S3Backend) Name() string { return "s3" }
+
+func (b *S3Backend) Upload(ctx context.Context, data []byte, path string) (string, error) {
+	_, err := b.client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:      awssdk.String(b.bucket),
+		Key:         awssdk.String(path),
+		Body:        bytes.NewReader(data),
+		ContentType: awssdk.String("image/png"),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to upload object to s3: %w", err)
+	}
+	return b.PublicURL(path), nil
+}
+
+func (b *S3Backend) Delete(ctx context.Context, path string) error {
+	_, err := b.client.DeleteObject(ctx, &s3.DeleteObjectInput{
+		Bucket: awssdk.String(b.bucket),
+		Key:    awssdk.String(path),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete object from s3: %w", err)
+	}
+	return nil
+}
+
+func (b *S3Backend) List(ctx context.Context, prefix string) ([]string, error) {
+	var urls []string
+	paginator := s3.NewListObjectsV2Paginator(b.client, &s3.ListObjectsV2Input{
+		Bucket: awssdk.String(b.bucket),
+		Prefix: awssdk.String(prefix),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list s3 objects: %w", err)
+		}
+		for _, obj := range page.Contents {
+			urls = append(urls, b.PublicURL(awssdk.ToString(obj.Key)))
+		}
+	}
+	return urls, nil
+}
+
+func (b *S3Backend) Exists(ctx context.Context, path string) (bool, error) {
+	_, err := b.client.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket: awssdk.String(b.bucket),
+		Key:    awssdk.String(path),
+	})
+	if err != nil {
+		return false, nil
+	}
+	return true, nil
+}
+
+func (b *S3Backend) PresignedURL(ctx context.Context, path string, ttl time.Duration) (string, error) {
+	presignClient := s3.NewPresignClient(b.client)
+	req, err := presignClient.PresignGetObject(ctx, &s3.GetObjectInput{
+		Bucket: awssdk.String(b.bucket),
+		Key:    awssdk.String(path),
+	}, s3.WithPresignExpires(ttl))
+	if err != nil {
+		return "", fmt.Errorf("failed to presign s3 url: %w", err)
+	}
+	return req.URL, nil
+}
+
+func (b *S3Backend) Stat(ctx context.Context, path string) (ObjectInfo, error) {
+	out, err := b.client.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket: awssdk.String(b.bucket),
+		Key:    awssdk.String(path),
+	})
+	if err != nil {
+		return ObjectInfo{}, fmt.Errorf("failed to stat s3 object: %w", err)
+	}
+	info := ObjectInfo{}
+	if out.ContentLength != nil {
+		info.Size = *out.ContentLength
+	}
+	if out.LastModified != nil {
+		info.LastModified = *out.LastModified
+	}
+	return info, nil
+}
+
+// PublicURL returns path's public URL without any network call.
+func (b *S3Backend) PublicURL(path string) string {
+	return fmt.Sprintf("https://%s.s3.%s.amazonaws.com/%s", b.bucket, b.region, path)
+}
@@ -0,0 +1,153 @@
+package storage
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"go-server/metrics"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// refreshAheadFraction is how much of an entry's original TTL must remain
+// before GenerateSASURL serves it from cache rather than re-signing — at
+// 20%, an entry signed for e.g. an hour is renewed with 12 minutes still
+// left on the clock, so a caller never hands out a URL that's about to
+// expire.
+const refreshAheadFraction = 0.2
+
+type signedURLEntry struct {
+	url       string
+	ttl       time.Duration
+	expiresAt time.Time
+	elem      *list.Element
+}
+
+// SignedURLCache wraps a Backend's PresignedURL with an in-process LRU
+// keyed by blob path, so repeatedly requesting a signed URL for the same
+// object doesn't re-sign it on every call. Concurrent requests for a path
+// with no cached entry are coalesced with singleflight so only one of them
+// actually calls the backend. Entries are evicted on a capacity bound
+// (LRU), not a timer.
+//
+// Course/subject/chapter/topic images currently serve from Backend's plain
+// PublicURL rather than a signed one (ImageServiceImpl only calls
+// PresignedURL through this cache, via GetSignedURL/GetSignedURLs), so
+// there's no hierarchy-endpoint hot path stampeding the backend today. The
+// cache is still wired all the way through — ImageServiceImpl.DeleteImage
+// purges it, and it's what any future signed-delivery path (e.g. a private,
+// non-public-read container) should call through rather than hitting
+// Backend.PresignedURL directly.
+type SignedURLCache struct {
+	backend  Backend
+	capacity int
+
+	mu      sync.Mutex
+	entries map[string]*signedURLEntry
+	order   *list.List
+
+	group singleflight.Group
+}
+
+// NewSignedURLCache builds a cache of capacity entries in front of backend;
+// a capacity below 1 falls back to 1000 entries.
+func NewSignedURLCache(backend Backend, capacity int) *SignedURLCache {
+	if capacity < 1 {
+		capacity = 1000
+	}
+	return &SignedURLCache{
+		backend:  backend,
+		capacity: capacity,
+		entries:  make(map[string]*signedURLEntry),
+		order:    list.New(),
+	}
+}
+
+// GenerateSASURL returns a signed URL for path valid for ttl, from cache if
+// a still-fresh-enough entry exists, otherwise by calling the backend (with
+// concurrent callers for the same path coalesced onto a single call).
+func (c *SignedURLCache) GenerateSASURL(ctx context.Context, path string, ttl time.Duration) (string, error) {
+	c.mu.Lock()
+	entry, hit := c.entries[path]
+	if hit && time.Until(entry.expiresAt) > time.Duration(float64(entry.ttl)*refreshAheadFraction) {
+		c.order.MoveToFront(entry.elem)
+		c.mu.Unlock()
+		metrics.SignedURLCacheHitsTotal.Inc()
+		return entry.url, nil
+	}
+	c.mu.Unlock()
+
+	if hit {
+		metrics.SignedURLCacheRefreshesTotal.Inc()
+	} else {
+		metrics.SignedURLCacheMissesTotal.Inc()
+	}
+
+	result, err, _ := c.group.Do(path, func() (interface{}, error) {
+		url, err := c.backend.PresignedURL(ctx, path, ttl)
+		if err != nil {
+			return "", err
+		}
+		c.store(path, url, ttl)
+		return url, nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return result.(string), nil
+}
+
+// GenerateSASURLs signs every path in one pass, e.g. for a hierarchy
+// endpoint rendering many images at once.
+func (c *SignedURLCache) GenerateSASURLs(ctx context.Context, paths []string, ttl time.Duration) (map[string]string, error) {
+	urls := make(map[string]string, len(paths))
+	for _, path := range paths {
+		url, err := c.GenerateSASURL(ctx, path, ttl)
+		if err != nil {
+			return nil, fmt.Errorf("failed to sign %q: %w", path, err)
+		}
+		urls[path] = url
+	}
+	return urls, nil
+}
+
+// Purge evicts every cached entry whose path starts with prefix, so a
+// deleted or overwritten object's stale signed URL isn't served again.
+func (c *SignedURLCache) Purge(prefix string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for path, entry := range c.entries {
+		if strings.HasPrefix(path, prefix) {
+			c.order.Remove(entry.elem)
+			delete(c.entries, path)
+		}
+	}
+}
+
+func (c *SignedURLCache) store(path, url string, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if entry, ok := c.entries[path]; ok {
+		entry.url = url
+		entry.ttl = ttl
+		entry.expiresAt = time.Now().Add(ttl)
+		c.order.MoveToFront(entry.elem)
+		return
+	}
+
+	elem := c.order.PushFront(path)
+	c.entries[path] = &signedURLEntry{url: url, ttl: ttl, expiresAt: time.Now().Add(ttl), elem: elem}
+
+	if c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		if oldest != nil {
+			c.order.Remove(oldest)
+			delete(c.entries, oldest.Value.(string))
+		}
+	}
+}
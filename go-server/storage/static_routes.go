@@ -0,0 +1,17 @@
+package storage
+
+import (
+	"go-server/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MountStaticRoutes serves files directly from disk at /static/images/* when
+// running with the local storage backend. It's a no-op for every other
+// STORAGE_PROVIDER, which serve images from their own object store instead.
+func MountStaticRoutes(router *gin.Engine, cfg *config.Config) {
+	if cfg.StorageProvider != "local" {
+		return
+	}
+	router.Static("/static/images", cfg.LocalStorageDir)
+}
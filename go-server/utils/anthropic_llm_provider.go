@@ -0,0 +1,275 @@
+package utils
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const anthropicAPIVersion = "2023-06-01"
+const anthropicMessagesEndpoint = "https://api.anthropic.com/v1/messages"
+const anthropicMaxTokens = 4096
+
+// AnthropicLLMProvider generates content via Anthropic's Messages API.
+type AnthropicLLMProvider struct {
+	apiKey string
+	model  string
+}
+
+// NewAnthropicLLMProvider creates an Anthropic LLM provider. An empty
+// apiKey is allowed; the provider simply reports itself as unavailable.
+func NewAnthropicLLMProvider(apiKey, model string) *AnthropicLLMProvider {
+	return &AnthropicLLMProvider{apiKey: apiKey, model: model}
+}
+
+func (p *AnthropicLLMProvider) Name() string {
+	return "anthropic"
+}
+
+func (p *AnthropicLLMProvider) Available() bool {
+	return p.apiKey != ""
+}
+
+func (p *AnthropicLLMProvider) HealthCheck(ctx context.Context) error {
+	if !p.Available() {
+		return fmt.Errorf("no API key available for Anthropic")
+	}
+	// Anthropic has no lightweight ping endpoint; a full topic-content
+	// request with placeholder names exercises auth and reachability the
+	// same way a real call would.
+	_, err := p.GenerateTopicContent(ctx, "ping", "ping", "ping", "ping")
+	return err
+}
+
+func (p *AnthropicLLMProvider) GenerateTopicContent(ctx context.Context, topicName, chapterName, subjectName, courseName string) (string, error) {
+	body, err := json.Marshal(map[string]interface{}{
+		"model":      p.model,
+		"max_tokens": anthropicMaxTokens,
+		"messages":   []map[string]string{{"role": "user", "content": topicContentPrompt(ctx, topicName, chapterName, subjectName, courseName)}},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode Anthropic request: %w", err)
+	}
+
+	req, err := p.newRequest(ctx, body)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("Anthropic request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("Anthropic request returned status %d", resp.StatusCode)
+	}
+
+	var parsed struct {
+		Content []struct {
+			Text string `json:"text"`
+		} `json:"content"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&parsed); err != nil {
+		return "", fmt.Errorf("failed to decode Anthropic response: %w", err)
+	}
+	if len(parsed.Content) == 0 {
+		return "", fmt.Errorf("Anthropic returned no content blocks")
+	}
+
+	var sb strings.Builder
+	for _, block := range parsed.Content {
+		sb.WriteString(block.Text)
+	}
+	return sb.String(), nil
+}
+
+// StreamTopicContent streams the Messages API response via server-sent
+// events, emitting each content_block_delta's text as it arrives.
+func (p *AnthropicLLMProvider) StreamTopicContent(ctx context.Context, topicName, chapterName, subjectName, courseName string) (<-chan string, <-chan error) {
+	chunks := make(chan string)
+	errs := make(chan error, 1)
+
+	body, err := json.Marshal(map[string]interface{}{
+		"model":      p.model,
+		"max_tokens": anthropicMaxTokens,
+		"stream":     true,
+		"messages":   []map[string]string{{"role": "user", "content": topicContentPrompt(ctx, topicName, chapterName, subjectName, courseName)}},
+	})
+	if err != nil {
+		close(chunks)
+		errs <- fmt.Errorf("failed to encode Anthropic request: %w", err)
+		close(errs)
+		return chunks, errs
+	}
+
+	req, err := p.newRequest(ctx, body)
+	if err != nil {
+		close(chunks)
+		errs <- err
+		close(errs)
+		return chunks, errs
+	}
+
+	go func() {
+		defer close(chunks)
+		defer close(errs)
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			errs <- fmt.Errorf("Anthropic stream request failed: %w", err)
+			return
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			errs <- fmt.Errorf("Anthropic stream request returned status %d", resp.StatusCode)
+			return
+		}
+
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if !strings.HasPrefix(line, "data: ") {
+				continue
+			}
+
+			var event struct {
+				Type  string `json:"type"`
+				Delta struct {
+					Text string `json:"text"`
+				} `json:"delta"`
+			}
+			if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &event); err != nil {
+				continue
+			}
+			if event.Type != "content_block_delta" || event.Delta.Text == "" {
+				continue
+			}
+
+			select {
+			case chunks <- event.Delta.Text:
+			case <-ctx.Done():
+				errs <- ctx.Err()
+				return
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			errs <- fmt.Errorf("Anthropic stream read failed: %w", err)
+		}
+	}()
+
+	return chunks, errs
+}
+
+// listTool is the tool definition passed to the Messages API's tool use
+// feature for GenerateSubjects/GenerateChapters/GenerateTopics: forcing a
+// tool call, rather than parsing it out of prose, is the reliable way to
+// get a clean JSON array back from Claude.
+var listTool = map[string]interface{}{
+	"name":        "return_list",
+	"description": "Return the generated list of names.",
+	"input_schema": map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"items": map[string]interface{}{
+				"type":  "array",
+				"items": map[string]interface{}{"type": "string"},
+			},
+		},
+		"required": []string{"items"},
+	},
+}
+
+// GenerateSubjects generates subject names via Anthropic's tool use: the
+// model is forced to call listTool instead of replying in prose, so its
+// input.items is returned directly without needing to parse it out of text.
+func (p *AnthropicLLMProvider) GenerateSubjects(ctx context.Context, courseName, courseDescription string) ([]string, error) {
+	return p.generateStringList(ctx, subjectsListPrompt(ctx, courseName, courseDescription))
+}
+
+// GenerateChapters is GenerateSubjects' chapter-list counterpart.
+func (p *AnthropicLLMProvider) GenerateChapters(ctx context.Context, subjectName, courseName string) ([]string, error) {
+	return p.generateStringList(ctx, chaptersListPrompt(ctx, subjectName, courseName))
+}
+
+// GenerateTopics is GenerateSubjects' topic-list counterpart.
+func (p *AnthropicLLMProvider) GenerateTopics(ctx context.Context, chapterName, subjectName, courseName string) ([]string, error) {
+	return p.generateStringList(ctx, topicsListPrompt(ctx, chapterName, subjectName, courseName))
+}
+
+// generateStringList sends prompt to the Messages API with listTool forced
+// via tool_choice, returning the items the model passed as that tool call's
+// input.
+func (p *AnthropicLLMProvider) generateStringList(ctx context.Context, prompt string) ([]string, error) {
+	body, err := json.Marshal(map[string]interface{}{
+		"model":       p.model,
+		"max_tokens":  anthropicMaxTokens,
+		"tools":       []interface{}{listTool},
+		"tool_choice": map[string]string{"type": "tool", "name": "return_list"},
+		"messages":    []map[string]string{{"role": "user", "content": prompt}},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode Anthropic request: %w", err)
+	}
+
+	req, err := p.newRequest(ctx, body)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("Anthropic request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Anthropic request returned status %d", resp.StatusCode)
+	}
+
+	var parsed struct {
+		Content []struct {
+			Type  string `json:"type"`
+			Input struct {
+				Items []string `json:"items"`
+			} `json:"input"`
+		} `json:"content"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&parsed); err != nil {
+		return nil, fmt.Errorf("failed to decode Anthropic response: %w", err)
+	}
+
+	for _, block := range parsed.Content {
+		if block.Type == "tool_use" {
+			return block.Input.Items, nil
+		}
+	}
+
+	return nil, fmt.Errorf("Anthropic did not return a return_list tool call")
+}
+
+// EmbedText is unsupported: Anthropic does not offer an embeddings API.
+func (p *AnthropicLLMProvider) EmbedText(ctx context.Context, text string) ([]float32, error) {
+	return nil, fmt.Errorf("anthropic provider does not support embeddings")
+}
+
+func (p *AnthropicLLMProvider) newRequest(ctx context.Context, body []byte) (*http.Request, error) {
+	if !p.Available() {
+		return nil, fmt.Errorf("no API key available for Anthropic")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, anthropicMessagesEndpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build Anthropic request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("x-api-key", p.apiKey)
+	req.Header.Set("anthropic-version", anthropicAPIVersion)
+	return req, nil
+}
@@ -6,12 +6,14 @@ import (
 	"fmt"
 	"net/url"
 	"os"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
 	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
 	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/sas"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/service"
 	"github.com/sirupsen/logrus"
 )
 
@@ -20,8 +22,21 @@ type AzureStorageHelper struct {
 	accountName   string
 	containerName string
 	accountKey    string
+
+	// delegationMu guards delegationKey/delegationExpiry, the cached result
+	// of GetUserDelegationKey — used to sign SAS URLs when there's no
+	// account key (managed identity), where GenerateSASURL would otherwise
+	// have nothing to sign with.
+	delegationMu     sync.Mutex
+	delegationKey    *service.UserDelegationCredential
+	delegationExpiry time.Time
 }
 
+// delegationRefreshSkew is how far ahead of its actual expiry
+// GetUserDelegationKey treats a cached delegation key as stale, so a caller
+// never signs a SAS URL with a key that's seconds from being rejected.
+const delegationRefreshSkew = 5 * time.Minute
+
 var (
 	azureInstance *AzureStorageHelper
 	azureOnce     sync.Once
@@ -136,10 +151,14 @@ func (ash *AzureStorageHelper) UploadImage(imageData []byte, imagePath string) (
 	}
 
 	ctx := context.Background()
-	
-	// Add file extension if not present
-	if len(imagePath) > 0 && imagePath[len(imagePath)-4:] != ".png" {
-		imagePath += ".png"
+
+	// Detect the real content type from the bytes rather than assuming PNG,
+	// and add the matching extension if imagePath doesn't already end in
+	// one (strings.HasSuffix, unlike a raw length-4 slice, is safe on paths
+	// shorter than 4 bytes).
+	contentType, ext := DetectImageContentType(imageData)
+	if !strings.HasSuffix(imagePath, ext) {
+		imagePath += ext
 	}
 
 	// Get blob client
@@ -148,20 +167,64 @@ func (ash *AzureStorageHelper) UploadImage(imageData []byte, imagePath string) (
 	// Upload the image
 	_, err := blobClient.UploadBuffer(ctx, imageData, &azblob.UploadBufferOptions{
 		HTTPHeaders: &azblob.BlobHTTPHeaders{
-			BlobContentType: getStringPtr("image/png"),
+			BlobContentType: getStringPtr(contentType),
 		},
 	})
 	if err != nil {
 		return "", fmt.Errorf("failed to upload image: %w", err)
 	}
 
-	// Generate the public URL
-	imageURL := fmt.Sprintf("https://%s.blob.core.windows.net/%s/%s", ash.accountName, ash.containerName, imagePath)
-	
+	imageURL := ash.BlobURL(imagePath)
 	logrus.Infof("Image uploaded successfully to: %s", imageURL)
 	return imageURL, nil
 }
 
+// BlobURL builds the public URL for a blob path without touching storage.
+func (ash *AzureStorageHelper) BlobURL(path string) string {
+	return fmt.Sprintf("https://%s.blob.core.windows.net/%s/%s", ash.accountName, ash.containerName, path)
+}
+
+// Exists reports whether a blob already exists at path, so callers can
+// short-circuit expensive work (e.g. skip regenerating a cached image).
+func (ash *AzureStorageHelper) Exists(path string) (bool, error) {
+	if ash.client == nil {
+		return false, fmt.Errorf("Azure Storage client not initialized")
+	}
+
+	ctx := context.Background()
+	blobClient := ash.client.ServiceClient().NewContainerClient(ash.containerName).NewBlobClient(path)
+	if _, err := blobClient.GetProperties(ctx, nil); err != nil {
+		return false, nil
+	}
+	return true, nil
+}
+
+// Stat returns the size and last-modified time of an existing blob, so
+// callers can build metadata (e.g. storage.ObjectInfo) without downloading
+// the blob itself.
+func (ash *AzureStorageHelper) Stat(path string) (int64, time.Time, error) {
+	if ash.client == nil {
+		return 0, time.Time{}, fmt.Errorf("Azure Storage client not initialized")
+	}
+
+	ctx := context.Background()
+	blobClient := ash.client.ServiceClient().NewContainerClient(ash.containerName).NewBlobClient(path)
+	props, err := blobClient.GetProperties(ctx, nil)
+	if err != nil {
+		return 0, time.Time{}, fmt.Errorf("failed to stat blob: %w", err)
+	}
+
+	var size int64
+	if props.ContentLength != nil {
+		size = *props.ContentLength
+	}
+	var lastModified time.Time
+	if props.LastModified != nil {
+		lastModified = *props.LastModified
+	}
+	return size, lastModified, nil
+}
+
 // UploadImageWithSAS uploads an image and returns a SAS URL with expiry
 func (ash *AzureStorageHelper) UploadImageWithSAS(imageData []byte, imagePath string, expiryHours int) (string, error) {
 	// First upload the image
@@ -174,31 +237,86 @@ func (ash *AzureStorageHelper) UploadImageWithSAS(imageData []byte, imagePath st
 	return ash.GenerateSASURL(imagePath, expiryHours)
 }
 
-// GenerateSASURL generates a SAS URL for an existing blob
-func (ash *AzureStorageHelper) GenerateSASURL(blobPath string, expiryHours int) (string, error) {
-	if ash.accountKey == "" {
-		// If no account key, return the direct URL (assuming public access)
-		return fmt.Sprintf("https://%s.blob.core.windows.net/%s/%s", ash.accountName, ash.containerName, blobPath), nil
+// GetUserDelegationKey fetches an Azure AD user delegation key valid from
+// start to expiry and caches it for reuse until it's within
+// delegationRefreshSkew of that expiry. This is how GenerateSASURL signs
+// SAS URLs when running under managed identity: there's no account key to
+// SignWithSharedKey with, but SignWithUserDelegation only needs this key,
+// obtained with the caller's own Azure AD credentials instead.
+func (ash *AzureStorageHelper) GetUserDelegationKey(start, expiry time.Time) (*service.UserDelegationCredential, error) {
+	ash.delegationMu.Lock()
+	defer ash.delegationMu.Unlock()
+
+	if ash.delegationKey != nil && time.Now().Before(ash.delegationExpiry.Add(-delegationRefreshSkew)) {
+		return ash.delegationKey, nil
 	}
 
-	// Create SAS token
+	startStr := start.UTC().Format(sas.TimeFormat)
+	expiryStr := expiry.UTC().Format(sas.TimeFormat)
+	udc, err := ash.client.ServiceClient().GetUserDelegationCredential(context.Background(), service.KeyInfo{
+		Start:  &startStr,
+		Expiry: &expiryStr,
+	}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user delegation key: %w", err)
+	}
+
+	ash.delegationKey = udc
+	ash.delegationExpiry = expiry
+	return udc, nil
+}
+
+// GenerateSASURL generates a SAS URL for an existing blob, signing with the
+// account key if one is available (connection-string mode), or otherwise
+// with an Azure AD user delegation key (managed-identity mode) — the same
+// "pick the credential mode available at init time" pattern a registry
+// client picks basic auth vs. a token exchange. If neither signing mode is
+// available (no account key, and the delegation key request itself fails —
+// e.g. insufficient RBAC), it falls back to the direct URL, assuming the
+// container allows public read.
+func (ash *AzureStorageHelper) GenerateSASURL(blobPath string, expiryHours int) (string, error) {
 	now := time.Now().UTC()
 	expiry := now.Add(time.Duration(expiryHours) * time.Hour)
+	permissions := sas.BlobPermissions{Read: true}.String()
+
+	if ash.accountKey != "" {
+		sasQuery, err := sas.BlobSignatureValues{
+			Protocol:      sas.ProtocolHTTPS,
+			StartTime:     now,
+			ExpiryTime:    expiry,
+			Permissions:   permissions,
+			ContainerName: ash.containerName,
+			BlobName:      blobPath,
+		}.SignWithSharedKey(ash.accountName, ash.accountKey)
+		if err != nil {
+			return "", fmt.Errorf("failed to generate SAS URL: %w", err)
+		}
+		return fmt.Sprintf("https://%s.blob.core.windows.net/%s/%s?%s", ash.accountName, ash.containerName, blobPath, sasQuery), nil
+	}
 
-	sasURL, err := sas.BlobSignatureValues{
-		Protocol:      sas.ProtocolHTTPS,
-		ExpiryTime:    expiry,
-		Permissions:   sas.BlobPermissions{Read: true}.String(),
-		ContainerName: ash.containerName,
-		BlobName:      blobPath,
-	}.SignWithSharedKey(ash.accountName, ash.accountKey)
+	if ash.client != nil {
+		udc, err := ash.GetUserDelegationKey(now, expiry)
+		if err != nil {
+			logrus.Warnf("Failed to get user delegation key, falling back to public URL: %v", err)
+			return ash.BlobURL(blobPath), nil
+		}
 
-	if err != nil {
-		return "", fmt.Errorf("failed to generate SAS URL: %w", err)
+		sasQuery, err := sas.BlobSignatureValues{
+			Protocol:      sas.ProtocolHTTPS,
+			StartTime:     now,
+			ExpiryTime:    expiry,
+			Permissions:   permissions,
+			ContainerName: ash.containerName,
+			BlobName:      blobPath,
+		}.SignWithUserDelegation(udc)
+		if err != nil {
+			logrus.Warnf("Failed to sign SAS URL with user delegation key, falling back to public URL: %v", err)
+			return ash.BlobURL(blobPath), nil
+		}
+		return fmt.Sprintf("https://%s.blob.core.windows.net/%s/%s?%s", ash.accountName, ash.containerName, blobPath, sasQuery), nil
 	}
 
-	fullURL := fmt.Sprintf("https://%s.blob.core.windows.net/%s/%s?%s", ash.accountName, ash.containerName, blobPath, sasURL)
-	return fullURL, nil
+	return ash.BlobURL(blobPath), nil
 }
 
 // DeleteImage deletes an image from Azure Storage
@@ -0,0 +1,55 @@
+package utils
+
+import "strings"
+
+// unsafeContentTerms is a small blocklist of terms that should never appear
+// in generated lesson content, regardless of which LLM provider produced it
+// — this provider-agnostic check runs downstream of every provider alike
+// (services.ContentService calls it right before persisting), rather than
+// relying on each provider's own safety filtering, which differs in
+// strictness and isn't exposed uniformly through the LLMProvider interface.
+// It isn't a substitute for a real moderation model, just a last-line
+// rejection of the clearest violations before they're ever shown to a
+// learner.
+var unsafeContentTerms = []string{
+	"how to make a bomb",
+	"how to synthesize methamphetamine",
+	"child sexual abuse",
+	"kill yourself",
+}
+
+// ValidateContentSafety reports an error if content contains one of
+// unsafeContentTerms, or is empty (a signal the provider returned nothing
+// usable, e.g. its own safety filter blocked the response and left no text
+// to check). The match is case-insensitive since a blocked phrase can
+// appear in any casing.
+func ValidateContentSafety(content string) error {
+	if strings.TrimSpace(content) == "" {
+		return errEmptyContent
+	}
+
+	lower := strings.ToLower(content)
+	for _, term := range unsafeContentTerms {
+		if strings.Contains(lower, term) {
+			return &unsafeContentError{term: term}
+		}
+	}
+	return nil
+}
+
+var errEmptyContent = &unsafeContentError{term: "", empty: true}
+
+// unsafeContentError identifies which blocklist term (or emptiness) failed
+// ValidateContentSafety, so a caller logging the rejection has something
+// more specific than "content blocked".
+type unsafeContentError struct {
+	term  string
+	empty bool
+}
+
+func (e *unsafeContentError) Error() string {
+	if e.empty {
+		return "generated content was empty"
+	}
+	return "generated content matched a disallowed term"
+}
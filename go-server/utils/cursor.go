@@ -0,0 +1,42 @@
+package utils
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// Cursor is the opaque pagination position SearchRepository encodes into
+// the cursor query parameter: the last row's id and created_at. Keying on
+// both, rather than created_at alone, keeps the ordering stable even when
+// several rows share a timestamp.
+type Cursor struct {
+	LastID        uint      `json:"last_id"`
+	LastCreatedAt time.Time `json:"last_created_at"`
+}
+
+// EncodeCursor base64url-encodes c as JSON for use as an opaque pagination
+// token a client round-trips without inspecting.
+func EncodeCursor(c Cursor) string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor reverses EncodeCursor. An empty token decodes to the zero
+// Cursor, meaning "start from the first page".
+func DecodeCursor(token string) (Cursor, error) {
+	var c Cursor
+	if token == "" {
+		return c, nil
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return c, errors.New("invalid cursor")
+	}
+	if err := json.Unmarshal(data, &c); err != nil {
+		return c, errors.New("invalid cursor")
+	}
+	return c, nil
+}
@@ -0,0 +1,72 @@
+package utils
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"io"
+)
+
+// deriveEncryptionKey hashes an arbitrary-length secret (config.SecretKey)
+// down to the 32 bytes AES-256-GCM requires.
+func deriveEncryptionKey(secret string) []byte {
+	sum := sha256.Sum256([]byte(secret))
+	return sum[:]
+}
+
+// EncryptWithSecret encrypts plaintext with AES-256-GCM using a key derived
+// from secret, returning a base64 string of nonce||ciphertext. Used for
+// at-rest data (e.g. models.UserTOTP.SecretEncrypted) that the server needs
+// to read back in plaintext, unlike password/token hashes which never are.
+func EncryptWithSecret(secret, plaintext string) (string, error) {
+	block, err := aes.NewCipher(deriveEncryptionKey(secret))
+	if err != nil {
+		return "", fmt.Errorf("failed to initialize cipher: %w", err)
+	}
+
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return "", fmt.Errorf("failed to initialize GCM: %w", err)
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	sealed := gcm.Seal(nonce, nonce, []byte(plaintext), nil)
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// DecryptWithSecret reverses EncryptWithSecret.
+func DecryptWithSecret(secret, ciphertext string) (string, error) {
+	raw, err := base64.StdEncoding.DecodeString(ciphertext)
+	if err != nil {
+		return "", fmt.Errorf("invalid ciphertext encoding: %w", err)
+	}
+
+	block, err := aes.NewCipher(deriveEncryptionKey(secret))
+	if err != nil {
+		return "", fmt.Errorf("failed to initialize cipher: %w", err)
+	}
+
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return "", fmt.Errorf("failed to initialize GCM: %w", err)
+	}
+
+	nonceSize := gcm.NonceSize()
+	if len(raw) < nonceSize {
+		return "", fmt.Errorf("ciphertext too short")
+	}
+
+	nonce, sealed := raw[:nonceSize], raw[nonceSize:]
+	plaintext, err := gcm.Open(nil, nonce, sealed, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt: %w", err)
+	}
+	return string(plaintext), nil
+}
@@ -4,19 +4,25 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"go-server/logger"
 	"go-server/models"
+	"go-server/prompts"
 	"os"
 	"regexp"
 	"strings"
+	"sync"
 
 	"github.com/sirupsen/logrus"
 	"google.golang.org/genai"
 )
 
 type GeminiHelper struct {
-	client   *genai.Client
-	apiKey   string
+	apiKey    string
 	modelName string
+
+	clientOnce sync.Once
+	client     *genai.Client
+	clientErr  error
 }
 
 // NewGeminiHelper creates a new Gemini helper instance
@@ -33,27 +39,113 @@ func NewGeminiHelper() *GeminiHelper {
 	}
 }
 
-// InitClient initializes the Gemini client
+// Name identifies this provider in LLM provider-chain logs and metrics.
+func (gh *GeminiHelper) Name() string {
+	return "gemini"
+}
+
+// Available reports whether this provider is configured.
+func (gh *GeminiHelper) Available() bool {
+	return gh.apiKey != ""
+}
+
+// HealthCheck reports whether an API key is set and the Gemini client could
+// be created. It sends no request to the API, so it does not confirm that
+// Gemini is reachable or that the key is accepted.
+func (gh *GeminiHelper) HealthCheck(ctx context.Context) error {
+	return gh.InitClient(ctx)
+}
+
+// StreamTopicContent is GenerateTopicContent's streaming counterpart. It
+// drives the genai client's own streaming API, so each chunk emitted here is
+// a chunk the model itself produced, rather than GenerateTopicContent's
+// output split after the fact. ctx is expected to carry the originating
+// HTTP request's cancellation (see content_routes.go's SSE handler), so a
+// client disconnect stops the underlying Gemini call instead of letting it
+// run to completion unread.
+func (gh *GeminiHelper) StreamTopicContent(ctx context.Context, topicName, chapterName, subjectName, courseName string) (<-chan string, <-chan error) {
+	chunks := make(chan string)
+	errs := make(chan error, 1)
+
+	go func() {
+		defer close(chunks)
+		defer close(errs)
+
+		if err := gh.InitClient(ctx); err != nil {
+			errs <- err
+			return
+		}
+
+		prompt := topicContentPrompt(ctx, topicName, chapterName, subjectName, courseName)
+
+		for resp, err := range gh.client.Models.GenerateContentStream(ctx, gh.modelName, genai.Text(prompt), nil) {
+			if err != nil {
+				logger.FromContext(ctx).Errorf("Error streaming content from Gemini: %v", err)
+				errs <- err
+				return
+			}
+
+			select {
+			case chunks <- resp.Text():
+			case <-ctx.Done():
+				errs <- ctx.Err()
+				return
+			}
+		}
+	}()
+
+	return chunks, errs
+}
+
+// EmbedText returns a vector embedding for text using Gemini's embedding
+// model.
+func (gh *GeminiHelper) EmbedText(ctx context.Context, text string) ([]float32, error) {
+	if err := gh.InitClient(ctx); err != nil {
+		return nil, err
+	}
+
+	result, err := gh.client.Models.EmbedContent(ctx, "text-embedding-004", genai.Text(text), nil)
+	if err != nil {
+		logger.FromContext(ctx).Errorf("Error embedding text with Gemini: %v", err)
+		return nil, err
+	}
+	if len(result.Embeddings) == 0 {
+		return nil, fmt.Errorf("gemini returned no embeddings")
+	}
+
+	return result.Embeddings[0].Values, nil
+}
+
+// InitClient lazily creates the Gemini client on first use and reuses it for
+// every subsequent call, rather than dialing a new client per request; the
+// genai client holds its own connection pool, so recreating it per call was
+// pure overhead. ctx is only used to construct the client this first time -
+// a later call's ctx being canceled doesn't tear down the shared client.
 func (gh *GeminiHelper) InitClient(ctx context.Context) error {
 	if gh.apiKey == "" {
 		return fmt.Errorf("no API key available for Gemini")
 	}
 
-	client, err := genai.NewClient(ctx, nil)
-	if err != nil {
-		return fmt.Errorf("failed to create Gemini client: %w", err)
-	}
+	gh.clientOnce.Do(func() {
+		client, err := genai.NewClient(ctx, nil)
+		if err != nil {
+			gh.clientErr = fmt.Errorf("failed to create Gemini client: %w", err)
+			return
+		}
+		gh.client = client
+	})
 
-	gh.client = client
-	return nil
+	return gh.clientErr
 }
 
-// GenerateContent generates content using Gemini API
+// GenerateContent generates content using Gemini API. Retries and
+// circuit-breaking on a failing Gemini (or any other provider) live one
+// layer up, in ProviderChain.callWithRetry/llmCircuit, so this stays a
+// single plain call rather than duplicating that policy per provider.
 func (gh *GeminiHelper) GenerateContent(ctx context.Context, prompt string) (string, error) {
 	if err := gh.InitClient(ctx); err != nil {
 		return "", err
 	}
-	defer gh.client.Close()
 
 	result, err := gh.client.Models.GenerateContent(
 		ctx,
@@ -62,7 +154,7 @@ func (gh *GeminiHelper) GenerateContent(ctx context.Context, prompt string) (str
 		nil,
 	)
 	if err != nil {
-		logrus.Errorf("Error generating content: %v", err)
+		logger.FromContext(ctx).Errorf("Error generating content: %v", err)
 		return "", err
 	}
 
@@ -74,7 +166,6 @@ func (gh *GeminiHelper) GenerateContentWithThinking(ctx context.Context, prompt
 	if err := gh.InitClient(ctx); err != nil {
 		return "", err
 	}
-	defer gh.client.Close()
 
 	var config *genai.GenerateContentConfig
 	if disableThinking {
@@ -92,7 +183,7 @@ func (gh *GeminiHelper) GenerateContentWithThinking(ctx context.Context, prompt
 		config,
 	)
 	if err != nil {
-		logrus.Errorf("Error generating content with thinking: %v", err)
+		logger.FromContext(ctx).Errorf("Error generating content with thinking: %v", err)
 		return "", err
 	}
 
@@ -104,7 +195,6 @@ func (gh *GeminiHelper) GenerateContentWithSystemInstruction(ctx context.Context
 	if err := gh.InitClient(ctx); err != nil {
 		return "", err
 	}
-	defer gh.client.Close()
 
 	config := &genai.GenerateContentConfig{
 		SystemInstruction: genai.NewContentFromText(systemInstruction, genai.RoleUser),
@@ -117,7 +207,7 @@ func (gh *GeminiHelper) GenerateContentWithSystemInstruction(ctx context.Context
 		config,
 	)
 	if err != nil {
-		logrus.Errorf("Error generating content with system instruction: %v", err)
+		logger.FromContext(ctx).Errorf("Error generating content with system instruction: %v", err)
 		return "", err
 	}
 
@@ -129,7 +219,6 @@ func (gh *GeminiHelper) GenerateStructuredOutput(ctx context.Context, prompt str
 	if err := gh.InitClient(ctx); err != nil {
 		return "", err
 	}
-	defer gh.client.Close()
 
 	config := &genai.GenerateContentConfig{
 		ResponseMIMEType: "application/json",
@@ -143,7 +232,7 @@ func (gh *GeminiHelper) GenerateStructuredOutput(ctx context.Context, prompt str
 		config,
 	)
 	if err != nil {
-		logrus.Errorf("Error generating structured output: %v", err)
+		logger.FromContext(ctx).Errorf("Error generating structured output: %v", err)
 		return "", err
 	}
 
@@ -152,11 +241,13 @@ func (gh *GeminiHelper) GenerateStructuredOutput(ctx context.Context, prompt str
 
 // GenerateSubjects generates subjects for a course using structured output
 func (gh *GeminiHelper) GenerateSubjects(ctx context.Context, courseName, courseDescription string) ([]string, error) {
-	prompt := fmt.Sprintf(`
-Generate a comprehensive list of subjects for the course "%s" with description: "%s".
-Provide 5-8 core subjects that would be essential for this course.
-Return only the subject names as a JSON array.
-`, courseName, courseDescription)
+	prompt, _, err := prompts.Default.Render("subjects_list", prompts.UserIDFromContext(ctx), struct {
+		CourseName, CourseDescription string
+	}{courseName, courseDescription})
+	if err != nil {
+		logger.FromContext(ctx).Errorf("Error rendering subjects_list prompt: %v", err)
+		return nil, err
+	}
 
 	schema := &genai.Schema{
 		Type: genai.TypeArray,
@@ -172,7 +263,7 @@ Return only the subject names as a JSON array.
 
 	var subjects []string
 	if err := json.Unmarshal([]byte(result), &subjects); err != nil {
-		logrus.Errorf("Error unmarshaling subjects: %v", err)
+		logger.FromContext(ctx).Errorf("Error unmarshaling subjects: %v", err)
 		return nil, err
 	}
 
@@ -181,11 +272,13 @@ Return only the subject names as a JSON array.
 
 // GenerateChapters generates chapters for a subject using structured output
 func (gh *GeminiHelper) GenerateChapters(ctx context.Context, subjectName, courseName string) ([]string, error) {
-	prompt := fmt.Sprintf(`
-Generate a comprehensive list of chapters for the subject "%s" in the course "%s".
-Provide 6-10 logical chapters that would cover this subject thoroughly.
-Return only the chapter names as a JSON array.
-`, subjectName, courseName)
+	prompt, _, err := prompts.Default.Render("chapters_list", prompts.UserIDFromContext(ctx), struct {
+		SubjectName, CourseName string
+	}{subjectName, courseName})
+	if err != nil {
+		logger.FromContext(ctx).Errorf("Error rendering chapters_list prompt: %v", err)
+		return nil, err
+	}
 
 	schema := &genai.Schema{
 		Type: genai.TypeArray,
@@ -201,7 +294,7 @@ Return only the chapter names as a JSON array.
 
 	var chapters []string
 	if err := json.Unmarshal([]byte(result), &chapters); err != nil {
-		logrus.Errorf("Error unmarshaling chapters: %v", err)
+		logger.FromContext(ctx).Errorf("Error unmarshaling chapters: %v", err)
 		return nil, err
 	}
 
@@ -210,11 +303,13 @@ Return only the chapter names as a JSON array.
 
 // GenerateTopics generates topics for a chapter using structured output
 func (gh *GeminiHelper) GenerateTopics(ctx context.Context, chapterName, subjectName, courseName string) ([]string, error) {
-	prompt := fmt.Sprintf(`
-Generate a comprehensive list of topics for the chapter "%s" in subject "%s" for the course "%s".
-Provide 5-8 specific topics that would be covered in this chapter.
-Return only the topic names as a JSON array.
-`, chapterName, subjectName, courseName)
+	prompt, _, err := prompts.Default.Render("topics_list", prompts.UserIDFromContext(ctx), struct {
+		ChapterName, SubjectName, CourseName string
+	}{chapterName, subjectName, courseName})
+	if err != nil {
+		logger.FromContext(ctx).Errorf("Error rendering topics_list prompt: %v", err)
+		return nil, err
+	}
 
 	schema := &genai.Schema{
 		Type: genai.TypeArray,
@@ -230,7 +325,7 @@ Return only the topic names as a JSON array.
 
 	var topics []string
 	if err := json.Unmarshal([]byte(result), &topics); err != nil {
-		logrus.Errorf("Error unmarshaling topics: %v", err)
+		logger.FromContext(ctx).Errorf("Error unmarshaling topics: %v", err)
 		return nil, err
 	}
 
@@ -239,37 +334,13 @@ Return only the topic names as a JSON array.
 
 // GenerateTopicContent generates detailed content for a topic
 func (gh *GeminiHelper) GenerateTopicContent(ctx context.Context, topicName, chapterName, subjectName, courseName string) (string, error) {
-	prompt := fmt.Sprintf(`
-Generate detailed, in-depth content as well as a tutorial for the topic "%s", under the chapter "%s",
-under the subject "%s" for the course "%s".
-
-The content should adhere to the following detailed approach:
-
-1. **Introduction**: Start with a clear and engaging introduction that outlines what will be covered in this topic.
-
-2. **Core Concepts**: Break down the topic into fundamental concepts, explaining each with clear definitions and examples.
-
-3. **Detailed Explanation**: Provide comprehensive explanations with:
-   - Real-world examples and applications
-   - Step-by-step processes where applicable
-   - Common misconceptions and how to avoid them
-   - Best practices and industry standards
-
-4. **Practical Examples**: Include multiple practical examples that demonstrate the concepts in action.
-
-5. **Interactive Elements**: Where appropriate, include:
-   - Code examples (if technical)
-   - Diagrams descriptions
-   - Case studies
-   - Exercise suggestions
-
-6. **Summary**: Conclude with a concise summary highlighting the key takeaways.
-
-7. **Further Reading**: Suggest additional resources for deeper learning.
-
-Format the content using markdown with proper headings, bullet points, code blocks (where applicable), and emphasis.
-Make it comprehensive, educational, and engaging for learners.
-`, topicName, chapterName, subjectName, courseName)
+	prompt, _, err := prompts.Default.Render("topic_content", prompts.UserIDFromContext(ctx), struct {
+		TopicName, ChapterName, SubjectName, CourseName string
+	}{topicName, chapterName, subjectName, courseName})
+	if err != nil {
+		logger.FromContext(ctx).Errorf("Error rendering topic_content prompt: %v", err)
+		return "", err
+	}
 
 	content, err := gh.GenerateContent(ctx, prompt)
 	if err != nil {
@@ -287,14 +358,14 @@ Make it comprehensive, educational, and engaging for learners.
 func (gh *GeminiHelper) ProcessMermaidContent(content string) string {
 	// Find and replace mermaid code blocks
 	mermaidRegex := regexp.MustCompile(`(?s)` + "`" + `{3}mermaid(.*?)` + "`" + `{3}`)
-	
+
 	return mermaidRegex.ReplaceAllStringFunc(content, func(match string) string {
 		// Extract the content between ```mermaid and ```
 		lines := strings.Split(match, "\n")
 		if len(lines) < 2 {
 			return match
 		}
-		
+
 		// Remove the first line (```mermaid) and last line (```)
 		mermaidContent := strings.Join(lines[1:len(lines)-1], "\n")
 		return fmt.Sprintf(`<pre class="mermaid">%s</pre>`, mermaidContent)
@@ -344,7 +415,6 @@ func (gh *GeminiHelper) GenerateImageWithText(ctx context.Context, prompt string
 	if err := gh.InitClient(ctx); err != nil {
 		return nil, "", err
 	}
-	defer gh.client.Close()
 
 	config := &genai.GenerateContentConfig{
 		ResponseModalities: []string{"TEXT", "IMAGE"},
@@ -357,7 +427,7 @@ func (gh *GeminiHelper) GenerateImageWithText(ctx context.Context, prompt string
 		config,
 	)
 	if err != nil {
-		logrus.Errorf("Error generating image with text: %v", err)
+		logger.FromContext(ctx).Errorf("Error generating image with text: %v", err)
 		return nil, "", err
 	}
 
@@ -373,4 +443,4 @@ func (gh *GeminiHelper) GenerateImageWithText(ctx context.Context, prompt string
 	}
 
 	return imageBytes, textResponse, nil
-}
\ No newline at end of file
+}
@@ -10,8 +10,8 @@ import (
 )
 
 type GeminiImageGenerator struct {
-	client   *genai.Client
-	apiKey   string
+	client    *genai.Client
+	apiKey    string
 	modelName string
 }
 
@@ -21,7 +21,7 @@ func NewGeminiImageGenerator() *GeminiImageGenerator {
 	if apiKey == "" {
 		apiKey = os.Getenv("API_KEY")
 	}
-	
+
 	if apiKey == "" {
 		logrus.Warning("No API_KEY found in environment variables. Image generation functionality will not work.")
 		return &GeminiImageGenerator{apiKey: ""}
@@ -62,7 +62,7 @@ func (gig *GeminiImageGenerator) GenerateCourseImage(ctx context.Context, course
 
 	// Create a prompt for the image generation
 	prompt := fmt.Sprintf("Create a professional, educational 3D rendered cover image for a course titled '%s'.", courseName)
-	
+
 	if courseDescription != "" {
 		// Add brief description context if available
 		shortened := courseDescription
@@ -71,7 +71,7 @@ func (gig *GeminiImageGenerator) GenerateCourseImage(ctx context.Context, course
 		}
 		prompt += fmt.Sprintf(" The course is about: %s", shortened)
 	}
-	
+
 	prompt += " The image should be modern, clean, and visually appealing with educational elements. Use vibrant but professional colors. Make it suitable for a course thumbnail."
 
 	logrus.Infof("Generating course image with prompt: %s", prompt)
@@ -161,8 +161,8 @@ func (gig *GeminiImageGenerator) GenerateSubjectImage(ctx context.Context, subje
 	return imageBytes, nil
 }
 
-// GenerateCustomImage generates an image based on custom prompt
-func (gig *GeminiImageGenerator) GenerateCustomImage(ctx context.Context, prompt string) ([]byte, error) {
+// GenerateCustom generates an image based on custom prompt
+func (gig *GeminiImageGenerator) GenerateCustom(ctx context.Context, prompt string) ([]byte, error) {
 	if gig.apiKey == "" {
 		logrus.Error("Cannot generate image: No API key available")
 		return nil, fmt.Errorf("no API key available for image generation")
@@ -209,7 +209,17 @@ func (gig *GeminiImageGenerator) GenerateCustomImage(ctx context.Context, prompt
 	return imageBytes, nil
 }
 
-// IsAvailable checks if image generation is available
-func (gig *GeminiImageGenerator) IsAvailable() bool {
+// Available checks if image generation is available
+func (gig *GeminiImageGenerator) Available() bool {
 	return gig.apiKey != ""
-}
\ No newline at end of file
+}
+
+// Name identifies this provider in provider-chain logs and metrics.
+func (gig *GeminiImageGenerator) Name() string {
+	return "gemini"
+}
+
+// EstimatedCostUSD reflects Gemini 2.0 Flash image generation pricing.
+func (gig *GeminiImageGenerator) EstimatedCostUSD() float64 {
+	return 0.039
+}
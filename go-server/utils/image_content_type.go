@@ -0,0 +1,29 @@
+package utils
+
+import "bytes"
+
+var (
+	pngMagic  = []byte{0x89, 'P', 'N', 'G', '\r', '\n', 0x1a, '\n'}
+	jpegMagic = []byte{0xFF, 0xD8, 0xFF}
+	riffMagic = []byte("RIFF")
+	webpMagic = []byte("WEBP")
+)
+
+// DetectImageContentType sniffs data's magic number to determine its real
+// MIME type and file extension, rather than trusting a caller-supplied
+// suffix or always assuming PNG (what every image-generation provider in
+// this codebase returns isn't guaranteed to match what it was asked for).
+// Unrecognized data is reported as image/png/".png", the prior default
+// behavior, so existing PNG blobs continue to round-trip unchanged.
+func DetectImageContentType(data []byte) (contentType, ext string) {
+	switch {
+	case bytes.HasPrefix(data, pngMagic):
+		return "image/png", ".png"
+	case bytes.HasPrefix(data, jpegMagic):
+		return "image/jpeg", ".jpg"
+	case len(data) >= 12 && bytes.HasPrefix(data, riffMagic) && bytes.Equal(data[8:12], webpMagic):
+		return "image/webp", ".webp"
+	default:
+		return "image/png", ".png"
+	}
+}
@@ -0,0 +1,22 @@
+package utils
+
+import "context"
+
+// ImageProvider is implemented by every image-generation backend ImageService
+// can fall back across (Gemini, OpenAI, Stability AI, a local Stable
+// Diffusion endpoint, or the deterministic PlaceholderProvider used in tests
+// and local dev). Available reports whether the provider is configured (e.g.
+// has an API key) without making a network call.
+type ImageProvider interface {
+	Name() string
+	Available() bool
+	GenerateCourseImage(ctx context.Context, courseName, courseDescription string) ([]byte, error)
+	GenerateSubjectImage(ctx context.Context, subjectName, courseName string) ([]byte, error)
+	GenerateCustom(ctx context.Context, prompt string) ([]byte, error)
+
+	// EstimatedCostUSD is a flat per-image cost estimate for the provider's
+	// pricing tier, logged alongside latency so operators can compare
+	// generation backends. Providers with no per-call charge (local models,
+	// the placeholder) return 0.
+	EstimatedCostUSD() float64
+}
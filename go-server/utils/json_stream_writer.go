@@ -0,0 +1,84 @@
+package utils
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// streamFrame is the wire shape of a single newline-delimited JSON frame
+// written by JSONStreamWriter. Fields are omitted when unset so a progress
+// frame doesn't carry stale status/url/error keys and vice versa.
+type streamFrame struct {
+	Status      string       `json:"status,omitempty"`
+	Progress    int          `json:"progress,omitempty"`
+	ID          string       `json:"id,omitempty"`
+	URL         string       `json:"url,omitempty"`
+	ErrorDetail *errorDetail `json:"errorDetail,omitempty"`
+}
+
+type errorDetail struct {
+	Message string `json:"message"`
+}
+
+// flusher is satisfied by gin.ResponseWriter and http.ResponseWriter
+// implementations that support streaming; writers without it (e.g. a
+// bytes.Buffer in tests) simply skip the flush.
+type flusher interface {
+	Flush()
+}
+
+// JSONStreamWriter writes newline-delimited JSON progress frames to w,
+// flushing after each one so a client behind a proxy sees updates as they
+// happen instead of buffered at the end. Modeled on Docker's
+// streamformatter.JSONStreamFormatter so any long-running operation
+// (image generation, content generation, bulk uploads) can report progress
+// the same way.
+type JSONStreamWriter struct {
+	w  io.Writer
+	id string
+}
+
+// NewJSONStreamWriter creates a JSONStreamWriter that tags every frame with
+// id (e.g. "course-17"), identifying which resource is being worked on.
+func NewJSONStreamWriter(w io.Writer, id string) *JSONStreamWriter {
+	return &JSONStreamWriter{w: w, id: id}
+}
+
+// WriteStatus writes a frame reporting a new phase, e.g. "generating" or
+// "uploading".
+func (s *JSONStreamWriter) WriteStatus(status string) error {
+	return s.write(streamFrame{Status: status, ID: s.id})
+}
+
+// WriteProgress writes a frame reporting percent-complete (0-100) within
+// the current phase.
+func (s *JSONStreamWriter) WriteProgress(status string, progress int) error {
+	return s.write(streamFrame{Status: status, Progress: progress, ID: s.id})
+}
+
+// WriteComplete writes the final success frame carrying the resulting URL.
+func (s *JSONStreamWriter) WriteComplete(url string) error {
+	return s.write(streamFrame{Status: "complete", URL: url, ID: s.id})
+}
+
+// WriteError writes a terminal error frame. Docker's stream protocol has no
+// dedicated status for this; the presence of errorDetail alone signals
+// failure to the client.
+func (s *JSONStreamWriter) WriteError(err error) error {
+	return s.write(streamFrame{ID: s.id, ErrorDetail: &errorDetail{Message: err.Error()}})
+}
+
+func (s *JSONStreamWriter) write(frame streamFrame) error {
+	data, err := json.Marshal(frame)
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+	if _, err := s.w.Write(data); err != nil {
+		return err
+	}
+	if f, ok := s.w.(flusher); ok {
+		f.Flush()
+	}
+	return nil
+}
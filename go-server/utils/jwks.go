@@ -0,0 +1,70 @@
+package utils
+
+import (
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+
+	"go-server/models"
+)
+
+// JWK is one key entry in a JWKS document (RFC 7517), covering the RSA
+// ("RSA") and EC ("EC") key types produced by KeyManager.
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+// JWKS is a JSON Web Key Set document, served at GET /.well-known/jwks.json.
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// toJWK builds the public-key JWK representation for key, parsing its PEM
+// public key and branching on whether it's RSA or ECDSA.
+func toJWK(key models.SigningKey) (JWK, error) {
+	block, _ := pem.Decode([]byte(key.PublicKeyPEM))
+	if block == nil {
+		return JWK{}, fmt.Errorf("invalid public key PEM for kid %s", key.Kid)
+	}
+	public, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return JWK{}, fmt.Errorf("failed to parse public key for kid %s: %w", key.Kid, err)
+	}
+
+	switch pub := public.(type) {
+	case *rsa.PublicKey:
+		return JWK{
+			Kty: "RSA",
+			Kid: key.Kid,
+			Use: "sig",
+			Alg: key.Algorithm,
+			N:   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+		}, nil
+	case *ecdsa.PublicKey:
+		size := (pub.Curve.Params().BitSize + 7) / 8
+		return JWK{
+			Kty: "EC",
+			Kid: key.Kid,
+			Use: "sig",
+			Alg: key.Algorithm,
+			Crv: pub.Curve.Params().Name,
+			X:   base64.RawURLEncoding.EncodeToString(pub.X.FillBytes(make([]byte, size))),
+			Y:   base64.RawURLEncoding.EncodeToString(pub.Y.FillBytes(make([]byte, size))),
+		}, nil
+	default:
+		return JWK{}, fmt.Errorf("unsupported public key type for kid %s", key.Kid)
+	}
+}
@@ -16,13 +16,39 @@ var (
 type JWTClaims struct {
 	UserID uint   `json:"user_id"`
 	Email  string `json:"email"`
+	// Roles and Scopes are baked in at issuance time from the user's
+	// granted repositories.RoleRepository roles, so RequireRole/RequireScope
+	// can authorize a request from the parsed token alone.
+	Roles  []string `json:"roles,omitempty"`
+	Scopes []string `json:"scopes,omitempty"`
+	// SessionID ties a token to its refresh-token rotation chain in the
+	// refresh_tokens table. Access tokens carry it too, so AuthMiddleware can
+	// reject one whose session was revoked. RegisteredClaims.ID carries a
+	// refresh token's own jti.
+	SessionID string `json:"session_id,omitempty"`
+	// MFAEnrolled records whether the user had TOTP 2FA enabled at the
+	// moment this refresh token was issued, so ValidateToken's caller can
+	// detect enrollment changing mid-session (see TokenService.Rotate) and
+	// force re-login rather than honoring a now-stale trust decision.
+	MFAEnrolled bool `json:"mfa_enrolled,omitempty"`
 	jwt.RegisteredClaims
 }
 
+// mfaPendingTokenDuration is deliberately short: an mfa_pending token only
+// needs to survive the brief gap between submitting a password and
+// submitting a TOTP code.
+const mfaPendingTokenDuration = 5 * time.Minute
+
 type JWTUtil struct {
 	secretKey            string
 	accessTokenDuration  time.Duration
 	refreshTokenDuration time.Duration
+	// keyManager is nil by default, which keeps JWTUtil signing/verifying
+	// with the shared HS256 secret above. When set (via
+	// NewJWTUtilWithKeyManager), tokens are signed with the KeyManager's
+	// current active key instead, and verified by looking up the
+	// signing key named in the token's "kid" header.
+	keyManager *KeyManager
 }
 
 func NewJWTUtil(secretKey string, accessTokenDuration, refreshTokenDuration time.Duration) *JWTUtil {
@@ -33,11 +59,54 @@ func NewJWTUtil(secretKey string, accessTokenDuration, refreshTokenDuration time
 	}
 }
 
-// GenerateAccessToken generates a new access token
-func (j *JWTUtil) GenerateAccessToken(userID uint, email string) (string, error) {
+// NewJWTUtilWithKeyManager builds a JWTUtil that signs and verifies tokens
+// asymmetrically (RS256/ES256) using keyManager's signing_keys table
+// instead of a shared secret, so other services can verify tokens via
+// GET /.well-known/jwks.json without the secret ever leaving this service.
+func NewJWTUtilWithKeyManager(keyManager *KeyManager, accessTokenDuration, refreshTokenDuration time.Duration) *JWTUtil {
+	return &JWTUtil{
+		accessTokenDuration:  accessTokenDuration,
+		refreshTokenDuration: refreshTokenDuration,
+		keyManager:           keyManager,
+	}
+}
+
+// sign signs claims with the shared HS256 secret, or with the KeyManager's
+// active asymmetric key when one is configured, stamping its kid into the
+// token header so ValidateToken can find the matching verifying key later.
+func (j *JWTUtil) sign(claims JWTClaims) (string, error) {
+	if j.keyManager == nil {
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+		return token.SignedString([]byte(j.secretKey))
+	}
+
+	active, err := j.keyManager.Active()
+	if err != nil {
+		return "", err
+	}
+	method, private, err := j.keyManager.SigningMethodAndKey(active)
+	if err != nil {
+		return "", err
+	}
+
+	token := jwt.NewWithClaims(method, claims)
+	token.Header["kid"] = active.Kid
+	return token.SignedString(private)
+}
+
+// GenerateAccessToken generates a new access token, baking in roles/scopes
+// so RequireRole/RequireScope can authorize from the token alone. sessionID
+// ties it back to its refresh-token rotation chain, so AuthMiddleware can
+// reject it early if that session gets revoked before the token's own
+// expiry; empty for flows (e.g. the mfa_pending token) that never had a
+// session.
+func (j *JWTUtil) GenerateAccessToken(userID uint, email string, roles, scopes []string, sessionID string) (string, error) {
 	claims := JWTClaims{
-		UserID: userID,
-		Email:  email,
+		UserID:    userID,
+		Email:     email,
+		Roles:     roles,
+		Scopes:    scopes,
+		SessionID: sessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(j.accessTokenDuration)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
@@ -46,8 +115,7 @@ func (j *JWTUtil) GenerateAccessToken(userID uint, email string) (string, error)
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString([]byte(j.secretKey))
+	tokenString, err := j.sign(claims)
 	if err != nil {
 		logrus.Errorf("Error generating access token: %v", err)
 		return "", err
@@ -69,8 +137,7 @@ func (j *JWTUtil) GenerateRefreshToken(userID uint, email string) (string, error
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString([]byte(j.secretKey))
+	tokenString, err := j.sign(claims)
 	if err != nil {
 		logrus.Errorf("Error generating refresh token: %v", err)
 		return "", err
@@ -79,13 +146,97 @@ func (j *JWTUtil) GenerateRefreshToken(userID uint, email string) (string, error
 	return tokenString, nil
 }
 
+// GenerateRefreshTokenWithClaims is GenerateRefreshToken's session-aware
+// counterpart: it signs jti and sessionID into the token so
+// services.TokenService can look up, rotate, and revoke this exact token
+// server-side instead of it being a purely stateless credential. mfaEnrolled
+// is stamped in so a later Rotate can detect enrollment having changed since
+// issuance.
+func (j *JWTUtil) GenerateRefreshTokenWithClaims(userID uint, email, sessionID, jti string, mfaEnrolled bool) (string, error) {
+	claims := JWTClaims{
+		UserID:      userID,
+		Email:       email,
+		SessionID:   sessionID,
+		MFAEnrolled: mfaEnrolled,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        jti,
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(j.refreshTokenDuration)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			NotBefore: jwt.NewNumericDate(time.Now()),
+			Subject:   "refresh_token",
+		},
+	}
+
+	tokenString, err := j.sign(claims)
+	if err != nil {
+		logrus.Errorf("Error generating refresh token: %v", err)
+		return "", err
+	}
+
+	return tokenString, nil
+}
+
+// GenerateMFAPendingToken signs a short-lived token identifying a user who
+// has passed the password step of login but still needs to submit a TOTP
+// code. It carries no roles/scopes and must never be accepted anywhere but
+// the /auth/2fa/verify endpoint.
+func (j *JWTUtil) GenerateMFAPendingToken(userID uint, email string) (string, error) {
+	claims := JWTClaims{
+		UserID: userID,
+		Email:  email,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(mfaPendingTokenDuration)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			NotBefore: jwt.NewNumericDate(time.Now()),
+			Subject:   "mfa_pending",
+		},
+	}
+
+	tokenString, err := j.sign(claims)
+	if err != nil {
+		logrus.Errorf("Error generating MFA pending token: %v", err)
+		return "", err
+	}
+
+	return tokenString, nil
+}
+
+// RefreshTokenDuration exposes the configured refresh-token lifetime so
+// services.TokenService can compute a refresh_tokens row's expires_at
+// without duplicating JWTUtil's construction.
+func (j *JWTUtil) RefreshTokenDuration() time.Duration {
+	return j.refreshTokenDuration
+}
+
+// AccessTokenDuration exposes the configured access-token lifetime so
+// services.TokenService knows how long a SessionRevocationCache entry needs
+// to live to outlast any access token already issued under that session.
+func (j *JWTUtil) AccessTokenDuration() time.Duration {
+	return j.accessTokenDuration
+}
+
 // ValidateToken validates a JWT token and returns the claims
 func (j *JWTUtil) ValidateToken(tokenString string) (*JWTClaims, error) {
 	token, err := jwt.ParseWithClaims(tokenString, &JWTClaims{}, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+		if j.keyManager == nil {
+			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+				return nil, errors.New("unexpected signing method")
+			}
+			return []byte(j.secretKey), nil
+		}
+
+		kid, ok := token.Header["kid"].(string)
+		if !ok || kid == "" {
+			return nil, errors.New("token is missing kid header")
+		}
+		method, public, err := j.keyManager.VerifyingKey(kid)
+		if err != nil {
+			return nil, err
+		}
+		if token.Method.Alg() != method.Alg() {
 			return nil, errors.New("unexpected signing method")
 		}
-		return []byte(j.secretKey), nil
+		return public, nil
 	})
 
 	if err != nil {
@@ -111,4 +262,4 @@ func ExtractTokenFromHeader(authHeader string) string {
 		return authHeader[7:]
 	}
 	return ""
-}
\ No newline at end of file
+}
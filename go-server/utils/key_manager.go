@@ -0,0 +1,235 @@
+package utils
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+
+	"go-server/models"
+	"go-server/repositories"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// rsaKeyBits is the modulus size for generated RSA signing keys.
+const rsaKeyBits = 2048
+
+// KeyManager owns the signing_keys table: it signs new tokens with the
+// current active key and verifies tokens signed by any non-retired key, so
+// JWTUtil doesn't need to know about key material when running in
+// RS256/ES256 mode.
+type KeyManager struct {
+	repo      repositories.SigningKeyRepository
+	algorithm string // "RS256" or "ES256"; used only for newly generated keys
+}
+
+// NewKeyManager builds a KeyManager that generates algorithm-typed keys
+// ("RS256" or "ES256") on EnsureActiveKey/Rotate.
+func NewKeyManager(repo repositories.SigningKeyRepository, algorithm string) *KeyManager {
+	return &KeyManager{repo: repo, algorithm: algorithm}
+}
+
+// EnsureActiveKey generates and activates the first signing key if the
+// table has none yet, so a fresh deployment can start signing tokens
+// without a manual rotate call.
+func (m *KeyManager) EnsureActiveKey() error {
+	active, err := m.repo.GetActive()
+	if err != nil {
+		return err
+	}
+	if active != nil {
+		return nil
+	}
+
+	key, err := m.generateKey()
+	if err != nil {
+		return err
+	}
+	if err := m.repo.Create(key); err != nil {
+		return err
+	}
+	logrus.Infof("Generated initial %s signing key: %s", key.Algorithm, key.Kid)
+	return nil
+}
+
+// Active returns the key new tokens are signed with.
+func (m *KeyManager) Active() (*models.SigningKey, error) {
+	key, err := m.repo.GetActive()
+	if err != nil {
+		return nil, err
+	}
+	if key == nil {
+		return nil, errors.New("no active signing key; call EnsureActiveKey at startup")
+	}
+	return key, nil
+}
+
+// SigningMethodAndKey parses key's private key material, returning the
+// jwt.SigningMethod to sign with alongside the parsed key.
+func (m *KeyManager) SigningMethodAndKey(key *models.SigningKey) (jwt.SigningMethod, interface{}, error) {
+	block, _ := pem.Decode([]byte(key.PrivateKeyPEM))
+	if block == nil {
+		return nil, nil, errors.New("invalid private key PEM")
+	}
+	private, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse private key: %w", err)
+	}
+
+	method, err := signingMethodForAlgorithm(key.Algorithm)
+	if err != nil {
+		return nil, nil, err
+	}
+	return method, private, nil
+}
+
+// VerifyingKey looks up kid and returns its public key and signing method,
+// or an error if kid is unknown or retired.
+func (m *KeyManager) VerifyingKey(kid string) (jwt.SigningMethod, interface{}, error) {
+	key, err := m.repo.GetByKid(kid)
+	if err != nil {
+		return nil, nil, err
+	}
+	if key == nil || key.Status == models.SigningKeyRetired {
+		return nil, nil, fmt.Errorf("unknown or retired signing key: %s", kid)
+	}
+
+	block, _ := pem.Decode([]byte(key.PublicKeyPEM))
+	if block == nil {
+		return nil, nil, errors.New("invalid public key PEM")
+	}
+	public, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse public key: %w", err)
+	}
+
+	method, err := signingMethodForAlgorithm(key.Algorithm)
+	if err != nil {
+		return nil, nil, err
+	}
+	return method, public, nil
+}
+
+// Rotate generates a new active key and demotes the previous active key to
+// retiring, so tokens it already signed keep validating until they expire.
+func (m *KeyManager) Rotate() (*models.SigningKey, error) {
+	previous, err := m.repo.GetActive()
+	if err != nil {
+		return nil, err
+	}
+
+	next, err := m.generateKey()
+	if err != nil {
+		return nil, err
+	}
+	if err := m.repo.Create(next); err != nil {
+		return nil, err
+	}
+
+	if previous != nil {
+		if err := m.repo.UpdateStatus(previous.Kid, models.SigningKeyRetiring); err != nil {
+			return nil, err
+		}
+		logrus.Infof("Rotated signing key: %s is now active, %s is retiring", next.Kid, previous.Kid)
+	} else {
+		logrus.Infof("Rotated signing key: %s is now active", next.Kid)
+	}
+
+	return next, nil
+}
+
+// RetireKey marks kid fully retired so it is no longer accepted for
+// verification. Call this only once every token it signed has expired.
+func (m *KeyManager) RetireKey(kid string) error {
+	return m.repo.UpdateStatus(kid, models.SigningKeyRetired)
+}
+
+// JWKS returns every verifiable key's public half in JWKS format, for
+// GET /.well-known/jwks.json.
+func (m *KeyManager) JWKS() (JWKS, error) {
+	keys, err := m.repo.GetVerifiable()
+	if err != nil {
+		return JWKS{}, err
+	}
+
+	jwks := JWKS{Keys: make([]JWK, 0, len(keys))}
+	for _, key := range keys {
+		jwk, err := toJWK(key)
+		if err != nil {
+			logrus.Errorf("Skipping signing key %s in JWKS: %v", key.Kid, err)
+			continue
+		}
+		jwks.Keys = append(jwks.Keys, jwk)
+	}
+	return jwks, nil
+}
+
+func (m *KeyManager) generateKey() (*models.SigningKey, error) {
+	kid := uuid.NewString()
+
+	switch m.algorithm {
+	case "RS256":
+		return generateRSAKey(kid)
+	case "ES256":
+		return generateECDSAKey(kid)
+	default:
+		return nil, fmt.Errorf("unsupported signing algorithm: %s", m.algorithm)
+	}
+}
+
+func signingMethodForAlgorithm(algorithm string) (jwt.SigningMethod, error) {
+	switch algorithm {
+	case "RS256":
+		return jwt.SigningMethodRS256, nil
+	case "ES256":
+		return jwt.SigningMethodES256, nil
+	default:
+		return nil, fmt.Errorf("unsupported signing algorithm: %s", algorithm)
+	}
+}
+
+func generateRSAKey(kid string) (*models.SigningKey, error) {
+	private, err := rsa.GenerateKey(rand.Reader, rsaKeyBits)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate RSA key: %w", err)
+	}
+	return pemEncodeKey(kid, "RS256", private, &private.PublicKey)
+}
+
+func generateECDSAKey(kid string) (*models.SigningKey, error) {
+	private, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate ECDSA key: %w", err)
+	}
+	return pemEncodeKey(kid, "ES256", private, &private.PublicKey)
+}
+
+func pemEncodeKey(kid, algorithm string, private crypto.PrivateKey, public crypto.PublicKey) (*models.SigningKey, error) {
+	privateBytes, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal private key: %w", err)
+	}
+	publicBytes, err := x509.MarshalPKIXPublicKey(public)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal public key: %w", err)
+	}
+
+	privatePEM := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateBytes})
+	publicPEM := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicBytes})
+
+	return &models.SigningKey{
+		Kid:           kid,
+		Algorithm:     algorithm,
+		PrivateKeyPEM: string(privatePEM),
+		PublicKeyPEM:  string(publicPEM),
+		Status:        models.SigningKeyActive,
+	}, nil
+}
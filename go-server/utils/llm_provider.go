@@ -0,0 +1,98 @@
+package utils
+
+import (
+	"context"
+
+	"go-server/logger"
+	"go-server/prompts"
+)
+
+// LLMProvider is implemented by each text-generation backend (Gemini,
+// an OpenAI-compatible endpoint, Anthropic) so ContentService can run
+// against whichever is configured, or fail over between several, without
+// any caller-visible difference.
+type LLMProvider interface {
+	// Name identifies the provider in provider-chain logs and metrics.
+	Name() string
+
+	// Available reports whether this provider is configured (e.g. has an
+	// API key) and can be tried.
+	Available() bool
+
+	// HealthCheck reports whether the provider is currently reachable,
+	// independent of Available's static configuration check.
+	HealthCheck(ctx context.Context) error
+
+	// GenerateTopicContent generates detailed content for a topic.
+	GenerateTopicContent(ctx context.Context, topicName, chapterName, subjectName, courseName string) (string, error)
+
+	// GenerateSubjects generates a list of subject names for a course.
+	GenerateSubjects(ctx context.Context, courseName, courseDescription string) ([]string, error)
+
+	// GenerateChapters generates a list of chapter names for a subject.
+	GenerateChapters(ctx context.Context, subjectName, courseName string) ([]string, error)
+
+	// GenerateTopics generates a list of topic names for a chapter.
+	GenerateTopics(ctx context.Context, chapterName, subjectName, courseName string) ([]string, error)
+
+	// StreamTopicContent is GenerateTopicContent's streaming counterpart: it
+	// returns a channel of content chunks as they become available, and a
+	// channel carrying at most one error. Both channels are closed when
+	// generation finishes, successfully or not.
+	StreamTopicContent(ctx context.Context, topicName, chapterName, subjectName, courseName string) (<-chan string, <-chan error)
+
+	// EmbedText returns a vector embedding for text.
+	EmbedText(ctx context.Context, text string) ([]float32, error)
+}
+
+// topicContentPrompt renders the prompts.Registry's "topic_content"
+// template, shared by the OpenAI-compatible, Anthropic and Ollama providers
+// for GenerateTopicContent/StreamTopicContent and by
+// GeminiHelper.StreamTopicContent (GeminiHelper.GenerateTopicContent renders
+// the same template itself). ctx's attached user ID (see prompts.WithUserID) picks which
+// template version this user is A/B-assigned to.
+func topicContentPrompt(ctx context.Context, topicName, chapterName, subjectName, courseName string) string {
+	rendered, _, err := prompts.Default.Render("topic_content", prompts.UserIDFromContext(ctx), struct {
+		TopicName, ChapterName, SubjectName, CourseName string
+	}{topicName, chapterName, subjectName, courseName})
+	if err != nil {
+		logger.FromContext(ctx).Errorf("Error rendering topic_content prompt: %v", err)
+	}
+	return rendered
+}
+
+// subjectsListPrompt, chaptersListPrompt and topicsListPrompt render the
+// prompts.Registry templates shared by the OpenAI-compatible and Anthropic
+// providers for GenerateSubjects/GenerateChapters/GenerateTopics.
+// GeminiHelper renders the same templates itself, since it also needs a
+// genai.Schema alongside the prompt.
+
+func subjectsListPrompt(ctx context.Context, courseName, courseDescription string) string {
+	rendered, _, err := prompts.Default.Render("subjects_list", prompts.UserIDFromContext(ctx), struct {
+		CourseName, CourseDescription string
+	}{courseName, courseDescription})
+	if err != nil {
+		logger.FromContext(ctx).Errorf("Error rendering subjects_list prompt: %v", err)
+	}
+	return rendered
+}
+
+func chaptersListPrompt(ctx context.Context, subjectName, courseName string) string {
+	rendered, _, err := prompts.Default.Render("chapters_list", prompts.UserIDFromContext(ctx), struct {
+		SubjectName, CourseName string
+	}{subjectName, courseName})
+	if err != nil {
+		logger.FromContext(ctx).Errorf("Error rendering chapters_list prompt: %v", err)
+	}
+	return rendered
+}
+
+func topicsListPrompt(ctx context.Context, chapterName, subjectName, courseName string) string {
+	rendered, _, err := prompts.Default.Render("topics_list", prompts.UserIDFromContext(ctx), struct {
+		ChapterName, SubjectName, CourseName string
+	}{chapterName, subjectName, courseName})
+	if err != nil {
+		logger.FromContext(ctx).Errorf("Error rendering topics_list prompt: %v", err)
+	}
+	return rendered
+}
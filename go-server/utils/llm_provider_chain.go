@@ -0,0 +1,310 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// llmCircuit tracks one provider's health within a ProviderChain so a
+// repeatedly-failing upstream is skipped for a cooldown period instead of
+// being retried (and timed out) on every request.
+type llmCircuit struct {
+	mu        sync.Mutex
+	openUntil time.Time
+}
+
+func (c *llmCircuit) open(cooldown time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.openUntil = time.Now().Add(cooldown)
+}
+
+func (c *llmCircuit) isOpen() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return time.Now().Before(c.openUntil)
+}
+
+// ProviderChain tries a sequence of LLMProviders in order, applying a
+// per-provider timeout and exponential backoff on retryable errors
+// (429/5xx) before moving to the next provider. A provider whose
+// GenerateTopicContent call still fails after callWithRetry (retries used
+// up, or an error that is not retried) is circuit-broken: skipped for
+// cooldown on subsequent calls,
+// so one failing upstream doesn't add latency to every request. rateLimiter
+// caps total call volume across every generation method and every caller
+// (content, subject/chapter/topic-list generation alike), since the quota
+// it protects is the upstream provider's, not any one caller's.
+type ProviderChain struct {
+	providers   []LLMProvider
+	timeout     time.Duration
+	maxRetries  int
+	cooldown    time.Duration
+	circuits    map[string]*llmCircuit
+	rateLimiter *RateLimiter
+}
+
+// NewProviderChain builds a chain over providers (tried in the given
+// order), with perProviderTimeout bounding each attempt, maxRetries
+// additional attempts per provider on a retryable error, cooldown
+// governing how long a circuit-broken provider is skipped, and
+// permitsPerMinute capping total calls per minute across every method and
+// caller sharing this chain.
+func NewProviderChain(providers []LLMProvider, perProviderTimeout time.Duration, maxRetries int, cooldown time.Duration, permitsPerMinute int) *ProviderChain {
+	circuits := make(map[string]*llmCircuit, len(providers))
+	for _, p := range providers {
+		circuits[p.Name()] = &llmCircuit{}
+	}
+
+	return &ProviderChain{
+		providers:   providers,
+		timeout:     perProviderTimeout,
+		maxRetries:  maxRetries,
+		cooldown:    cooldown,
+		circuits:    circuits,
+		rateLimiter: NewRateLimiter(permitsPerMinute),
+	}
+}
+
+func (c *ProviderChain) Name() string {
+	return "chain"
+}
+
+func (c *ProviderChain) Available() bool {
+	for _, p := range c.providers {
+		if p.Available() {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *ProviderChain) HealthCheck(ctx context.Context) error {
+	var lastErr error
+	for _, p := range c.providers {
+		if !p.Available() {
+			continue
+		}
+		if err := p.HealthCheck(ctx); err != nil {
+			lastErr = err
+			continue
+		}
+		return nil
+	}
+	if lastErr == nil {
+		lastErr = errors.New("no LLM provider is available")
+	}
+	return lastErr
+}
+
+func (c *ProviderChain) GenerateTopicContent(ctx context.Context, topicName, chapterName, subjectName, courseName string) (string, error) {
+	if err := c.rateLimiter.Wait(ctx); err != nil {
+		return "", err
+	}
+
+	var lastErr error
+	for _, p := range c.providers {
+		if !p.Available() || c.circuits[p.Name()].isOpen() {
+			continue
+		}
+
+		content, err := c.callWithRetry(ctx, p, func(ctx context.Context) (string, error) {
+			return p.GenerateTopicContent(ctx, topicName, chapterName, subjectName, courseName)
+		})
+		if err == nil {
+			return content, nil
+		}
+
+		logrus.Warnf("LLM provider %s failed after retries: %v", p.Name(), err)
+		c.circuits[p.Name()].open(c.cooldown)
+		lastErr = err
+	}
+
+	if lastErr == nil {
+		lastErr = errors.New("no LLM provider is available")
+	}
+	return "", fmt.Errorf("all LLM providers failed: %w", lastErr)
+}
+
+// StreamTopicContent hands the request to the first available,
+// non-circuit-broken provider. Once a provider starts streaming, a
+// mid-stream failure is surfaced to the caller rather than failed over to
+// the next provider, since tokens already sent to the client can't be
+// un-sent.
+func (c *ProviderChain) StreamTopicContent(ctx context.Context, topicName, chapterName, subjectName, courseName string) (<-chan string, <-chan error) {
+	if err := c.rateLimiter.Wait(ctx); err != nil {
+		chunks := make(chan string)
+		errs := make(chan error, 1)
+		close(chunks)
+		errs <- err
+		close(errs)
+		return chunks, errs
+	}
+
+	for _, p := range c.providers {
+		if !p.Available() || c.circuits[p.Name()].isOpen() {
+			continue
+		}
+		return p.StreamTopicContent(ctx, topicName, chapterName, subjectName, courseName)
+	}
+
+	chunks := make(chan string)
+	errs := make(chan error, 1)
+	close(chunks)
+	errs <- errors.New("no LLM provider is available")
+	close(errs)
+	return chunks, errs
+}
+
+// GenerateSubjects tries each available, non-circuit-broken provider in
+// order and returns the first successful result, the same failover
+// EmbedText uses rather than GenerateTopicContent's retry-then-circuit-break
+// (a short, cheap list call isn't worth retrying against the same
+// provider before just trying the next one).
+func (c *ProviderChain) GenerateSubjects(ctx context.Context, courseName, courseDescription string) ([]string, error) {
+	if err := c.rateLimiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+
+	var lastErr error
+	for _, p := range c.providers {
+		if !p.Available() || c.circuits[p.Name()].isOpen() {
+			continue
+		}
+		subjects, err := p.GenerateSubjects(ctx, courseName, courseDescription)
+		if err == nil {
+			return subjects, nil
+		}
+		lastErr = err
+	}
+	if lastErr == nil {
+		lastErr = errors.New("no LLM provider is available")
+	}
+	return nil, fmt.Errorf("all LLM providers failed: %w", lastErr)
+}
+
+// GenerateChapters is GenerateSubjects' chapter-list counterpart.
+func (c *ProviderChain) GenerateChapters(ctx context.Context, subjectName, courseName string) ([]string, error) {
+	if err := c.rateLimiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+
+	var lastErr error
+	for _, p := range c.providers {
+		if !p.Available() || c.circuits[p.Name()].isOpen() {
+			continue
+		}
+		chapters, err := p.GenerateChapters(ctx, subjectName, courseName)
+		if err == nil {
+			return chapters, nil
+		}
+		lastErr = err
+	}
+	if lastErr == nil {
+		lastErr = errors.New("no LLM provider is available")
+	}
+	return nil, fmt.Errorf("all LLM providers failed: %w", lastErr)
+}
+
+// GenerateTopics is GenerateSubjects' topic-list counterpart.
+func (c *ProviderChain) GenerateTopics(ctx context.Context, chapterName, subjectName, courseName string) ([]string, error) {
+	if err := c.rateLimiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+
+	var lastErr error
+	for _, p := range c.providers {
+		if !p.Available() || c.circuits[p.Name()].isOpen() {
+			continue
+		}
+		topics, err := p.GenerateTopics(ctx, chapterName, subjectName, courseName)
+		if err == nil {
+			return topics, nil
+		}
+		lastErr = err
+	}
+	if lastErr == nil {
+		lastErr = errors.New("no LLM provider is available")
+	}
+	return nil, fmt.Errorf("all LLM providers failed: %w", lastErr)
+}
+
+func (c *ProviderChain) EmbedText(ctx context.Context, text string) ([]float32, error) {
+	if err := c.rateLimiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+
+	var lastErr error
+	for _, p := range c.providers {
+		if !p.Available() || c.circuits[p.Name()].isOpen() {
+			continue
+		}
+
+		embedding, err := p.EmbedText(ctx, text)
+		if err == nil {
+			return embedding, nil
+		}
+		lastErr = err
+	}
+
+	if lastErr == nil {
+		lastErr = errors.New("no LLM provider is available")
+	}
+	return nil, fmt.Errorf("all LLM providers failed: %w", lastErr)
+}
+
+// callWithRetry runs call against p under a per-attempt timeout, retrying
+// with exponential backoff while the error looks retryable (429/5xx) and
+// retries remain.
+func (c *ProviderChain) callWithRetry(ctx context.Context, p LLMProvider, call func(context.Context) (string, error)) (string, error) {
+	var lastErr error
+	for attempt := 0; attempt <= c.maxRetries; attempt++ {
+		attemptCtx, cancel := context.WithTimeout(ctx, c.timeout)
+		content, err := call(attemptCtx)
+		cancel()
+		if err == nil {
+			return content, nil
+		}
+
+		lastErr = err
+		if !isRetryableLLMError(err) || attempt == c.maxRetries {
+			break
+		}
+
+		backoff := time.Duration(math.Pow(2, float64(attempt))) * 500 * time.Millisecond
+		backoff += time.Duration(rand.Int63n(int64(backoff)/2 + 1)) // full-ish jitter so retrying callers don't all wake up in lockstep
+		logrus.Debugf("LLM provider %s attempt %d failed, retrying in %s: %v", p.Name(), attempt+1, backoff, err)
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return "", ctx.Err()
+		}
+	}
+	return "", lastErr
+}
+
+// isRetryableLLMError reports whether err looks like a transient upstream
+// failure (HTTP 429, 5xx, or a per-attempt timeout) worth retrying, as
+// opposed to a permanent one (bad request, auth failure) that would just
+// fail again.
+func isRetryableLLMError(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+
+	msg := err.Error()
+	for _, code := range []string{"429", "500", "502", "503", "504"} {
+		if strings.Contains(msg, code) {
+			return true
+		}
+	}
+	return false
+}
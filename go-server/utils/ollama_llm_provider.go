@@ -0,0 +1,261 @@
+package utils
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// OllamaLLMProvider generates content via a local or self-hosted Ollama
+// server's native API. Unlike the other providers it needs no API key:
+// Available reports whether baseURL is configured at all.
+type OllamaLLMProvider struct {
+	baseURL string
+	model   string
+}
+
+// NewOllamaLLMProvider creates an Ollama provider. An empty baseURL is
+// allowed; the provider simply reports itself as unavailable.
+func NewOllamaLLMProvider(baseURL, model string) *OllamaLLMProvider {
+	return &OllamaLLMProvider{baseURL: strings.TrimRight(baseURL, "/"), model: model}
+}
+
+func (p *OllamaLLMProvider) Name() string {
+	return "ollama"
+}
+
+func (p *OllamaLLMProvider) Available() bool {
+	return p.baseURL != ""
+}
+
+func (p *OllamaLLMProvider) HealthCheck(ctx context.Context) error {
+	if !p.Available() {
+		return fmt.Errorf("no base URL configured for Ollama")
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.baseURL+"/api/tags", nil)
+	if err != nil {
+		return fmt.Errorf("failed to build Ollama health check request: %w", err)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("Ollama health check failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("Ollama health check returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func (p *OllamaLLMProvider) GenerateTopicContent(ctx context.Context, topicName, chapterName, subjectName, courseName string) (string, error) {
+	return p.chat(ctx, topicContentPrompt(ctx, topicName, chapterName, subjectName, courseName), false)
+}
+
+// StreamTopicContent streams /api/chat's newline-delimited JSON response,
+// emitting each frame's message content as it arrives.
+func (p *OllamaLLMProvider) StreamTopicContent(ctx context.Context, topicName, chapterName, subjectName, courseName string) (<-chan string, <-chan error) {
+	chunks := make(chan string)
+	errs := make(chan error, 1)
+
+	req, err := p.newChatRequest(ctx, topicContentPrompt(ctx, topicName, chapterName, subjectName, courseName), true, "")
+	if err != nil {
+		close(chunks)
+		errs <- err
+		close(errs)
+		return chunks, errs
+	}
+
+	go func() {
+		defer close(chunks)
+		defer close(errs)
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			errs <- fmt.Errorf("Ollama stream request failed: %w", err)
+			return
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			errs <- fmt.Errorf("Ollama stream request returned status %d", resp.StatusCode)
+			return
+		}
+
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" {
+				continue
+			}
+
+			var frame struct {
+				Message struct {
+					Content string `json:"content"`
+				} `json:"message"`
+				Done bool `json:"done"`
+			}
+			if err := json.Unmarshal([]byte(line), &frame); err != nil {
+				continue
+			}
+			if frame.Message.Content != "" {
+				select {
+				case chunks <- frame.Message.Content:
+				case <-ctx.Done():
+					errs <- ctx.Err()
+					return
+				}
+			}
+			if frame.Done {
+				return
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			errs <- fmt.Errorf("Ollama stream read failed: %w", err)
+		}
+	}()
+
+	return chunks, errs
+}
+
+// GenerateSubjects generates subject names via Ollama's JSON mode: format
+// is set to "json" so the model returns a single JSON object instead of
+// prose, with the generated names under an "items" key.
+func (p *OllamaLLMProvider) GenerateSubjects(ctx context.Context, courseName, courseDescription string) ([]string, error) {
+	return p.generateStringList(ctx, subjectsListPrompt(ctx, courseName, courseDescription))
+}
+
+// GenerateChapters is GenerateSubjects' chapter-list counterpart.
+func (p *OllamaLLMProvider) GenerateChapters(ctx context.Context, subjectName, courseName string) ([]string, error) {
+	return p.generateStringList(ctx, chaptersListPrompt(ctx, subjectName, courseName))
+}
+
+// GenerateTopics is GenerateSubjects' topic-list counterpart.
+func (p *OllamaLLMProvider) GenerateTopics(ctx context.Context, chapterName, subjectName, courseName string) ([]string, error) {
+	return p.generateStringList(ctx, topicsListPrompt(ctx, chapterName, subjectName, courseName))
+}
+
+// generateStringList asks for a JSON object {"items": [...]} under prompt
+// and returns its items.
+func (p *OllamaLLMProvider) generateStringList(ctx context.Context, prompt string) ([]string, error) {
+	content, err := p.chat(ctx, prompt, true)
+	if err != nil {
+		return nil, err
+	}
+
+	var items struct {
+		Items []string `json:"items"`
+	}
+	if err := json.Unmarshal([]byte(content), &items); err != nil {
+		return nil, fmt.Errorf("failed to parse Ollama list response: %w", err)
+	}
+	return items.Items, nil
+}
+
+// chat sends prompt to /api/chat with streaming disabled, optionally
+// requesting JSON-object output, and returns the reply's content.
+func (p *OllamaLLMProvider) chat(ctx context.Context, prompt string, jsonMode bool) (string, error) {
+	format := ""
+	if jsonMode {
+		format = "json"
+	}
+
+	req, err := p.newChatRequest(ctx, prompt, false, format)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("Ollama request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("Ollama request returned status %d", resp.StatusCode)
+	}
+
+	var parsed struct {
+		Message struct {
+			Content string `json:"content"`
+		} `json:"message"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&parsed); err != nil {
+		return "", fmt.Errorf("failed to decode Ollama response: %w", err)
+	}
+	return parsed.Message.Content, nil
+}
+
+func (p *OllamaLLMProvider) newChatRequest(ctx context.Context, prompt string, stream bool, format string) (*http.Request, error) {
+	if !p.Available() {
+		return nil, fmt.Errorf("no base URL configured for Ollama")
+	}
+
+	payload := map[string]interface{}{
+		"model":    p.model,
+		"stream":   stream,
+		"messages": []map[string]string{{"role": "user", "content": prompt}},
+	}
+	if format != "" {
+		payload["format"] = format
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode Ollama request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.baseURL+"/api/chat", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build Ollama request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return req, nil
+}
+
+// EmbedText returns a vector embedding for text using Ollama's native
+// embeddings endpoint.
+func (p *OllamaLLMProvider) EmbedText(ctx context.Context, text string) ([]float32, error) {
+	if !p.Available() {
+		return nil, fmt.Errorf("no base URL configured for Ollama")
+	}
+
+	body, err := json.Marshal(map[string]interface{}{
+		"model":  p.model,
+		"prompt": text,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode Ollama embeddings request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.baseURL+"/api/embeddings", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build Ollama embeddings request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("Ollama embeddings request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Ollama embeddings request returned status %d", resp.StatusCode)
+	}
+
+	var parsed struct {
+		Embedding []float32 `json:"embedding"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&parsed); err != nil {
+		return nil, fmt.Errorf("failed to decode Ollama embeddings response: %w", err)
+	}
+	if len(parsed.Embedding) == 0 {
+		return nil, fmt.Errorf("Ollama returned no embedding")
+	}
+	return parsed.Embedding, nil
+}
@@ -0,0 +1,113 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+const openAIImageEndpoint = "https://api.openai.com/v1/images/generations"
+
+// OpenAIImageProvider generates images via OpenAI's DALL·E image API.
+type OpenAIImageProvider struct {
+	apiKey string
+	model  string
+}
+
+// NewOpenAIImageProvider creates an OpenAI image provider. An empty apiKey is
+// allowed; the provider simply reports itself as unavailable.
+func NewOpenAIImageProvider(apiKey string) *OpenAIImageProvider {
+	return &OpenAIImageProvider{apiKey: apiKey, model: "dall-e-3"}
+}
+
+// Name identifies this provider in provider-chain logs and metrics.
+func (p *OpenAIImageProvider) Name() string {
+	return "openai"
+}
+
+// Available checks if this provider is configured.
+func (p *OpenAIImageProvider) Available() bool {
+	return p.apiKey != ""
+}
+
+// EstimatedCostUSD reflects DALL·E 3 standard-quality 1024x1024 pricing.
+func (p *OpenAIImageProvider) EstimatedCostUSD() float64 {
+	return 0.04
+}
+
+// GenerateCourseImage generates a cover image for a course.
+func (p *OpenAIImageProvider) GenerateCourseImage(ctx context.Context, courseName, courseDescription string) ([]byte, error) {
+	prompt := fmt.Sprintf("A professional, educational cover image for a course titled '%s'. %s", courseName, courseDescription)
+	return p.generate(ctx, prompt)
+}
+
+// GenerateSubjectImage generates a cover image for a subject.
+func (p *OpenAIImageProvider) GenerateSubjectImage(ctx context.Context, subjectName, courseName string) ([]byte, error) {
+	prompt := fmt.Sprintf("A professional, educational cover image for the subject '%s' from the course '%s'.", subjectName, courseName)
+	return p.generate(ctx, prompt)
+}
+
+// GenerateCustom generates an image from an arbitrary prompt.
+func (p *OpenAIImageProvider) GenerateCustom(ctx context.Context, prompt string) ([]byte, error) {
+	return p.generate(ctx, prompt)
+}
+
+func (p *OpenAIImageProvider) generate(ctx context.Context, prompt string) ([]byte, error) {
+	if p.apiKey == "" {
+		return nil, fmt.Errorf("no API key available for OpenAI image generation")
+	}
+
+	body, err := json.Marshal(map[string]interface{}{
+		"model":           p.model,
+		"prompt":          prompt,
+		"n":               1,
+		"size":            "1024x1024",
+		"response_format": "b64_json",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode OpenAI request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, openAIImageEndpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build OpenAI request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+p.apiKey)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("OpenAI image request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read OpenAI response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("OpenAI image request returned status %d: %s", resp.StatusCode, respBody)
+	}
+
+	var parsed struct {
+		Data []struct {
+			B64JSON string `json:"b64_json"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(respBody, &parsed); err != nil {
+		return nil, fmt.Errorf("failed to parse OpenAI response: %w", err)
+	}
+	if len(parsed.Data) == 0 || parsed.Data[0].B64JSON == "" {
+		return nil, fmt.Errorf("OpenAI response did not contain image data")
+	}
+
+	imageBytes, err := base64.StdEncoding.DecodeString(parsed.Data[0].B64JSON)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode OpenAI image data: %w", err)
+	}
+	return imageBytes, nil
+}
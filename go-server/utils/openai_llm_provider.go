@@ -0,0 +1,310 @@
+package utils
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// OpenAICompatLLMProvider generates content via any OpenAI-compatible chat
+// completions API (OpenAI itself, or a self-hosted endpoint speaking the
+// same protocol), selected by baseURL.
+type OpenAICompatLLMProvider struct {
+	apiKey  string
+	baseURL string
+	model   string
+}
+
+// NewOpenAICompatLLMProvider creates an OpenAI-compatible LLM provider. An
+// empty apiKey is allowed; the provider simply reports itself as
+// unavailable.
+func NewOpenAICompatLLMProvider(apiKey, baseURL, model string) *OpenAICompatLLMProvider {
+	return &OpenAICompatLLMProvider{apiKey: apiKey, baseURL: strings.TrimRight(baseURL, "/"), model: model}
+}
+
+func (p *OpenAICompatLLMProvider) Name() string {
+	return "openai"
+}
+
+func (p *OpenAICompatLLMProvider) Available() bool {
+	return p.apiKey != ""
+}
+
+func (p *OpenAICompatLLMProvider) HealthCheck(ctx context.Context) error {
+	if !p.Available() {
+		return fmt.Errorf("no API key available for OpenAI-compatible provider")
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.baseURL+"/models", nil)
+	if err != nil {
+		return fmt.Errorf("failed to build health check request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+p.apiKey)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("OpenAI-compatible health check failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("OpenAI-compatible health check returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func (p *OpenAICompatLLMProvider) GenerateTopicContent(ctx context.Context, topicName, chapterName, subjectName, courseName string) (string, error) {
+	body, err := json.Marshal(map[string]interface{}{
+		"model":    p.model,
+		"messages": []map[string]string{{"role": "user", "content": topicContentPrompt(ctx, topicName, chapterName, subjectName, courseName)}},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode OpenAI-compatible request: %w", err)
+	}
+
+	req, err := p.newRequest(ctx, body)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("OpenAI-compatible request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("OpenAI-compatible request returned status %d", resp.StatusCode)
+	}
+
+	var parsed struct {
+		Choices []struct {
+			Message struct {
+				Content string `json:"content"`
+			} `json:"message"`
+		} `json:"choices"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&parsed); err != nil {
+		return "", fmt.Errorf("failed to decode OpenAI-compatible response: %w", err)
+	}
+	if len(parsed.Choices) == 0 {
+		return "", fmt.Errorf("OpenAI-compatible provider returned no choices")
+	}
+
+	return parsed.Choices[0].Message.Content, nil
+}
+
+// StreamTopicContent streams the chat completion response via server-sent
+// events, emitting each delta's content as it arrives.
+func (p *OpenAICompatLLMProvider) StreamTopicContent(ctx context.Context, topicName, chapterName, subjectName, courseName string) (<-chan string, <-chan error) {
+	chunks := make(chan string)
+	errs := make(chan error, 1)
+
+	body, err := json.Marshal(map[string]interface{}{
+		"model":    p.model,
+		"stream":   true,
+		"messages": []map[string]string{{"role": "user", "content": topicContentPrompt(ctx, topicName, chapterName, subjectName, courseName)}},
+	})
+	if err != nil {
+		close(chunks)
+		errs <- fmt.Errorf("failed to encode OpenAI-compatible request: %w", err)
+		close(errs)
+		return chunks, errs
+	}
+
+	req, err := p.newRequest(ctx, body)
+	if err != nil {
+		close(chunks)
+		errs <- err
+		close(errs)
+		return chunks, errs
+	}
+
+	go func() {
+		defer close(chunks)
+		defer close(errs)
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			errs <- fmt.Errorf("OpenAI-compatible stream request failed: %w", err)
+			return
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			errs <- fmt.Errorf("OpenAI-compatible stream request returned status %d", resp.StatusCode)
+			return
+		}
+
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if !strings.HasPrefix(line, "data: ") {
+				continue
+			}
+			data := strings.TrimPrefix(line, "data: ")
+			if data == "[DONE]" {
+				return
+			}
+
+			var frame struct {
+				Choices []struct {
+					Delta struct {
+						Content string `json:"content"`
+					} `json:"delta"`
+				} `json:"choices"`
+			}
+			if err := json.Unmarshal([]byte(data), &frame); err != nil {
+				continue
+			}
+			for _, choice := range frame.Choices {
+				if choice.Delta.Content == "" {
+					continue
+				}
+				select {
+				case chunks <- choice.Delta.Content:
+				case <-ctx.Done():
+					errs <- ctx.Err()
+					return
+				}
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			errs <- fmt.Errorf("OpenAI-compatible stream read failed: %w", err)
+		}
+	}()
+
+	return chunks, errs
+}
+
+// GenerateSubjects generates subject names via JSON mode: the OpenAI-
+// compatible chat completions API is instructed to return a single JSON
+// object (its json_object mode doesn't allow a bare top-level array), with
+// the generated names under an "items" key.
+func (p *OpenAICompatLLMProvider) GenerateSubjects(ctx context.Context, courseName, courseDescription string) ([]string, error) {
+	return p.generateStringList(ctx, subjectsListPrompt(ctx, courseName, courseDescription))
+}
+
+// GenerateChapters is GenerateSubjects' chapter-list counterpart.
+func (p *OpenAICompatLLMProvider) GenerateChapters(ctx context.Context, subjectName, courseName string) ([]string, error) {
+	return p.generateStringList(ctx, chaptersListPrompt(ctx, subjectName, courseName))
+}
+
+// GenerateTopics is GenerateSubjects' topic-list counterpart.
+func (p *OpenAICompatLLMProvider) GenerateTopics(ctx context.Context, chapterName, subjectName, courseName string) ([]string, error) {
+	return p.generateStringList(ctx, topicsListPrompt(ctx, chapterName, subjectName, courseName))
+}
+
+// generateStringList asks the chat completions API for a JSON object
+// {"items": [...]} under prompt and returns its items, using
+// response_format: json_object so the model can't wrap the list in prose.
+func (p *OpenAICompatLLMProvider) generateStringList(ctx context.Context, prompt string) ([]string, error) {
+	body, err := json.Marshal(map[string]interface{}{
+		"model":           p.model,
+		"response_format": map[string]string{"type": "json_object"},
+		"messages": []map[string]string{
+			{"role": "system", "content": `Respond with a JSON object of the form {"items": ["..."]} and nothing else.`},
+			{"role": "user", "content": prompt},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode OpenAI-compatible request: %w", err)
+	}
+
+	req, err := p.newRequest(ctx, body)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("OpenAI-compatible request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("OpenAI-compatible request returned status %d", resp.StatusCode)
+	}
+
+	var parsed struct {
+		Choices []struct {
+			Message struct {
+				Content string `json:"content"`
+			} `json:"message"`
+		} `json:"choices"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&parsed); err != nil {
+		return nil, fmt.Errorf("failed to decode OpenAI-compatible response: %w", err)
+	}
+	if len(parsed.Choices) == 0 {
+		return nil, fmt.Errorf("OpenAI-compatible provider returned no choices")
+	}
+
+	var items struct {
+		Items []string `json:"items"`
+	}
+	if err := json.Unmarshal([]byte(parsed.Choices[0].Message.Content), &items); err != nil {
+		return nil, fmt.Errorf("failed to parse OpenAI-compatible list response: %w", err)
+	}
+
+	return items.Items, nil
+}
+
+// EmbedText returns a vector embedding for text using the configured
+// OpenAI-compatible embeddings endpoint.
+func (p *OpenAICompatLLMProvider) EmbedText(ctx context.Context, text string) ([]float32, error) {
+	body, err := json.Marshal(map[string]interface{}{
+		"model": "text-embedding-3-small",
+		"input": text,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode OpenAI-compatible embeddings request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.baseURL+"/embeddings", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build OpenAI-compatible embeddings request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+p.apiKey)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("OpenAI-compatible embeddings request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("OpenAI-compatible embeddings request returned status %d", resp.StatusCode)
+	}
+
+	var parsed struct {
+		Data []struct {
+			Embedding []float32 `json:"embedding"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&parsed); err != nil {
+		return nil, fmt.Errorf("failed to decode OpenAI-compatible embeddings response: %w", err)
+	}
+	if len(parsed.Data) == 0 {
+		return nil, fmt.Errorf("OpenAI-compatible provider returned no embeddings")
+	}
+
+	return parsed.Data[0].Embedding, nil
+}
+
+func (p *OpenAICompatLLMProvider) newRequest(ctx context.Context, body []byte) (*http.Request, error) {
+	if !p.Available() {
+		return nil, fmt.Errorf("no API key available for OpenAI-compatible provider")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.baseURL+"/chat/completions", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build OpenAI-compatible request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+p.apiKey)
+	return req, nil
+}
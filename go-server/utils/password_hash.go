@@ -0,0 +1,158 @@
+package utils
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+
+	"go-server/config"
+)
+
+// Argon2Params tunes HashPassword's argon2id cost. HashPassword embeds the
+// params it was called with in the returned hash, so NeedsRehash can later
+// detect an account hashed under weaker settings than config.PasswordConfig
+// currently specifies.
+type Argon2Params struct {
+	Memory      uint32
+	Iterations  uint32
+	Parallelism uint8
+	SaltLen     uint32
+	KeyLen      uint32
+}
+
+// Argon2ParamsFromConfig builds the Argon2Params HashPassword and
+// NeedsRehash should use from cfg's operator-tunable argon2id cost
+// settings.
+func Argon2ParamsFromConfig(cfg *config.Config) Argon2Params {
+	return Argon2Params{
+		Memory:      cfg.Password.Argon2Memory,
+		Iterations:  cfg.Password.Argon2Iterations,
+		Parallelism: cfg.Password.Argon2Parallelism,
+		SaltLen:     cfg.Password.Argon2SaltLen,
+		KeyLen:      cfg.Password.Argon2KeyLen,
+	}
+}
+
+// bcryptHashPrefixes identifies a password hash produced by this service's
+// previous bcrypt scheme, so CheckPasswordHash and NeedsRehash can verify
+// or flag it instead of trying to parse it as argon2id.
+var bcryptHashPrefixes = []string{"$2a$", "$2b$", "$2y$"}
+
+func isBcryptHash(hash string) bool {
+	for _, prefix := range bcryptHashPrefixes {
+		if strings.HasPrefix(hash, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// applyPepper XORs password with a repeating pepper, an application-wide
+// secret from config.PasswordConfig.Pepper that never touches the
+// database. An empty pepper is a no-op, so deployments that don't set
+// PASSWORD_PEPPER behave exactly as before it existed.
+func applyPepper(password, pepper string) []byte {
+	if pepper == "" {
+		return []byte(password)
+	}
+
+	peppered := make([]byte, len(password))
+	for i := 0; i < len(password); i++ {
+		peppered[i] = password[i] ^ pepper[i%len(pepper)]
+	}
+	return peppered
+}
+
+// HashPassword encodes password as a PHC-format string,
+// "$argon2id$v=19$m=<memory>,t=<iterations>,p=<parallelism>$<salt>$<hash>",
+// so every parameter needed to verify it or detect it's stale travels with
+// the hash itself rather than living only in the config that produced it.
+// pepper, if non-empty, is XORed into password before hashing (see
+// applyPepper); it is never itself encoded in the returned hash.
+func HashPassword(password, pepper string, params Argon2Params) (string, error) {
+	salt := make([]byte, params.SaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("failed to generate salt: %w", err)
+	}
+
+	hash := argon2.IDKey(applyPepper(password, pepper), salt, params.Iterations, params.Memory, params.Parallelism, params.KeyLen)
+
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, params.Memory, params.Iterations, params.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(hash),
+	), nil
+}
+
+// CheckPasswordHash reports whether password matches hash. hash may be a
+// PHC-format argon2id string produced by HashPassword, or a legacy bcrypt
+// hash from before the argon2id migration (pepper is ignored against a
+// bcrypt hash: peppering was introduced alongside argon2id, so no bcrypt
+// hash was ever produced from a peppered password).
+func CheckPasswordHash(password, pepper, hash string) bool {
+	if isBcryptHash(hash) {
+		return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+	}
+
+	params, salt, want, err := parseArgon2Hash(hash)
+	if err != nil {
+		return false
+	}
+	got := argon2.IDKey(applyPepper(password, pepper), salt, params.Iterations, params.Memory, params.Parallelism, uint32(len(want)))
+	return subtle.ConstantTimeCompare(got, want) == 1
+}
+
+// NeedsRehash reports whether hash should be re-hashed under current: every
+// legacy bcrypt hash does, since argon2id is the target scheme, and an
+// argon2id hash does if it was produced under different parameters than
+// current specifies.
+func NeedsRehash(hash string, current Argon2Params) bool {
+	if isBcryptHash(hash) {
+		return true
+	}
+	params, _, _, err := parseArgon2Hash(hash)
+	if err != nil {
+		// Unparseable: can't confirm it matches current, so don't treat it
+		// as current either.
+		return true
+	}
+	return params != current
+}
+
+// parseArgon2Hash splits a PHC-format argon2id string into the params,
+// salt, and derived key it encodes.
+func parseArgon2Hash(hash string) (Argon2Params, []byte, []byte, error) {
+	// "$argon2id$v=19$m=65536,t=3,p=2$<salt>$<hash>" splits on "$" into
+	// ["", "argon2id", "v=19", "m=...,t=...,p=...", "<salt>", "<hash>"].
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return Argon2Params{}, nil, nil, fmt.Errorf("not a PHC-format argon2id hash")
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil {
+		return Argon2Params{}, nil, nil, fmt.Errorf("invalid argon2id version segment: %w", err)
+	}
+
+	var params Argon2Params
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.Memory, &params.Iterations, &params.Parallelism); err != nil {
+		return Argon2Params{}, nil, nil, fmt.Errorf("invalid argon2id parameter segment: %w", err)
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return Argon2Params{}, nil, nil, fmt.Errorf("invalid argon2id salt: %w", err)
+	}
+	keyBytes, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return Argon2Params{}, nil, nil, fmt.Errorf("invalid argon2id derived key: %w", err)
+	}
+	params.SaltLen = uint32(len(salt))
+	params.KeyLen = uint32(len(keyBytes))
+
+	return params, salt, keyBytes, nil
+}
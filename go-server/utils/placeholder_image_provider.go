@@ -0,0 +1,73 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"image"
+	"image/color"
+	"image/png"
+)
+
+const placeholderImageSize = 512
+
+// PlaceholderProvider generates a deterministic solid-color PNG derived from
+// the prompt instead of calling a real model. It is always available, so it
+// belongs last in ImageService's provider chain as a guaranteed fallback, and
+// is useful on its own for tests and local dev without any API keys.
+type PlaceholderProvider struct{}
+
+// NewPlaceholderProvider creates a PlaceholderProvider.
+func NewPlaceholderProvider() *PlaceholderProvider {
+	return &PlaceholderProvider{}
+}
+
+// Name identifies this provider in provider-chain logs and metrics.
+func (p *PlaceholderProvider) Name() string {
+	return "placeholder"
+}
+
+// Available is always true; PlaceholderProvider needs no configuration.
+func (p *PlaceholderProvider) Available() bool {
+	return true
+}
+
+// EstimatedCostUSD is always 0; no model call is made.
+func (p *PlaceholderProvider) EstimatedCostUSD() float64 {
+	return 0
+}
+
+// GenerateCourseImage returns a deterministic placeholder image for a course.
+func (p *PlaceholderProvider) GenerateCourseImage(ctx context.Context, courseName, courseDescription string) ([]byte, error) {
+	return p.generate(courseName + courseDescription)
+}
+
+// GenerateSubjectImage returns a deterministic placeholder image for a subject.
+func (p *PlaceholderProvider) GenerateSubjectImage(ctx context.Context, subjectName, courseName string) ([]byte, error) {
+	return p.generate(subjectName + courseName)
+}
+
+// GenerateCustom returns a deterministic placeholder image for a prompt.
+func (p *PlaceholderProvider) GenerateCustom(ctx context.Context, prompt string) ([]byte, error) {
+	return p.generate(prompt)
+}
+
+// generate renders a solid-color PNG whose color is derived from seed's
+// sha256 hash, so the same prompt always produces the same placeholder.
+func (p *PlaceholderProvider) generate(seed string) ([]byte, error) {
+	sum := sha256.Sum256([]byte(seed))
+	c := color.RGBA{R: sum[0], G: sum[1], B: sum[2], A: 255}
+
+	img := image.NewRGBA(image.Rect(0, 0, placeholderImageSize, placeholderImageSize))
+	for y := 0; y < placeholderImageSize; y++ {
+		for x := 0; x < placeholderImageSize; x++ {
+			img.Set(x, y, c)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,49 @@
+package utils
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// RateLimiter spaces out permits evenly across each minute, shared by every
+// caller holding a reference to it, so a single instance can cap total LLM
+// call volume process-wide regardless of how many requests or background
+// jobs are asking for a permit concurrently.
+type RateLimiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	next     time.Time
+}
+
+// NewRateLimiter builds a RateLimiter allowing permitsPerMinute permits per
+// minute. permitsPerMinute <= 0 is treated as 1, since a limiter that never
+// grants a permit would wedge every caller.
+func NewRateLimiter(permitsPerMinute int) *RateLimiter {
+	if permitsPerMinute <= 0 {
+		permitsPerMinute = 1
+	}
+	return &RateLimiter{interval: time.Minute / time.Duration(permitsPerMinute)}
+}
+
+// Wait blocks until a permit is available or ctx is canceled.
+func (r *RateLimiter) Wait(ctx context.Context) error {
+	r.mu.Lock()
+	now := time.Now()
+	if r.next.Before(now) {
+		r.next = now
+	}
+	wait := r.next.Sub(now)
+	r.next = r.next.Add(r.interval)
+	r.mu.Unlock()
+
+	if wait <= 0 {
+		return nil
+	}
+	select {
+	case <-time.After(wait):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,49 @@
+package utils
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+)
+
+// secureTokenBytes produces a 64-character hex token, sized to match the
+// raw token length callers (password reset, email verification, invites)
+// are expected to email out.
+const secureTokenBytes = 32
+
+// GenerateSecureToken returns a random 64-character hex string suitable for
+// emailing to a user as a one-time password-reset/email-verification/invite
+// link. Only its HashToken digest should ever be persisted.
+func GenerateSecureToken() (string, error) {
+	b := make([]byte, secureTokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate secure token: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// HashToken returns the SHA-256 hex digest of a raw token, the form stored
+// in models.Token.TokenHash so the raw value is never persisted.
+func HashToken(rawToken string) string {
+	sum := sha256.Sum256([]byte(rawToken))
+	return hex.EncodeToString(sum[:])
+}
+
+// GenerateNumericPIN returns a random digits-only string of the given
+// length, suitable for a code a user copies into a chat rather than clicks
+// as a link (e.g. TelegramService's account-linking PIN). Like
+// GenerateSecureToken, only its HashToken digest should be persisted.
+func GenerateNumericPIN(length int) (string, error) {
+	const digits = "0123456789"
+	pin := make([]byte, length)
+	for i := range pin {
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(len(digits))))
+		if err != nil {
+			return "", fmt.Errorf("failed to generate PIN: %w", err)
+		}
+		pin[i] = digits[n.Int64()]
+	}
+	return string(pin), nil
+}
@@ -0,0 +1,56 @@
+package utils
+
+import (
+	"sync"
+	"time"
+)
+
+// SessionRevocationCache is an in-memory, process-local record of recently
+// revoked refresh-token sessions, so AuthMiddleware can reject an
+// already-issued access token whose session was just killed (logout,
+// "sign out everywhere", or TokenService's reuse/MFA-change detection)
+// without a database round trip on every request. Entries only need to
+// survive an access token's own lifetime, after which the token expires on
+// its own and falls out of the cache on the next sweep.
+type SessionRevocationCache struct {
+	mu      sync.RWMutex
+	expires map[string]time.Time
+}
+
+// NewSessionRevocationCache builds an empty cache.
+func NewSessionRevocationCache() *SessionRevocationCache {
+	return &SessionRevocationCache{expires: make(map[string]time.Time)}
+}
+
+// Revoke marks sessionID revoked for ttl, long enough to outlive any access
+// token already issued under it (its AccessTokenDuration).
+func (c *SessionRevocationCache) Revoke(sessionID string, ttl time.Duration) {
+	if sessionID == "" {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.expires[sessionID] = time.Now().Add(ttl)
+}
+
+// IsRevoked reports whether sessionID was revoked and hasn't yet aged out of
+// the cache. An expired entry is swept on read rather than on a timer, since
+// nothing needs it gone before the next lookup touches it.
+func (c *SessionRevocationCache) IsRevoked(sessionID string) bool {
+	if sessionID == "" {
+		return false
+	}
+	c.mu.RLock()
+	expiresAt, ok := c.expires[sessionID]
+	c.mu.RUnlock()
+	if !ok {
+		return false
+	}
+	if time.Now().After(expiresAt) {
+		c.mu.Lock()
+		delete(c.expires, sessionID)
+		c.mu.Unlock()
+		return false
+	}
+	return true
+}
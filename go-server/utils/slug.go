@@ -0,0 +1,15 @@
+package utils
+
+import (
+	"regexp"
+	"strings"
+)
+
+var slugInvalidChars = regexp.MustCompile(`[^a-z0-9]+`)
+
+// Slugify converts a name into a lowercase, hyphenated, filesystem-safe slug,
+// e.g. for use as a directory or file name inside an exported archive.
+func Slugify(name string) string {
+	slug := slugInvalidChars.ReplaceAllString(strings.ToLower(name), "-")
+	return strings.Trim(slug, "-")
+}
@@ -0,0 +1,110 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+const stabilityImageEndpoint = "https://api.stability.ai/v1/generation/stable-diffusion-xl-1024-v1-0/text-to-image"
+
+// StabilityImageProvider generates images via the Stability AI REST API.
+type StabilityImageProvider struct {
+	apiKey string
+}
+
+// NewStabilityImageProvider creates a Stability AI image provider. An empty
+// apiKey is allowed; the provider simply reports itself as unavailable.
+func NewStabilityImageProvider(apiKey string) *StabilityImageProvider {
+	return &StabilityImageProvider{apiKey: apiKey}
+}
+
+// Name identifies this provider in provider-chain logs and metrics.
+func (p *StabilityImageProvider) Name() string {
+	return "stability"
+}
+
+// Available checks if this provider is configured.
+func (p *StabilityImageProvider) Available() bool {
+	return p.apiKey != ""
+}
+
+// EstimatedCostUSD reflects Stability AI's per-image credit pricing.
+func (p *StabilityImageProvider) EstimatedCostUSD() float64 {
+	return 0.002
+}
+
+// GenerateCourseImage generates a cover image for a course.
+func (p *StabilityImageProvider) GenerateCourseImage(ctx context.Context, courseName, courseDescription string) ([]byte, error) {
+	prompt := fmt.Sprintf("A professional, educational cover image for a course titled '%s'. %s", courseName, courseDescription)
+	return p.generate(ctx, prompt)
+}
+
+// GenerateSubjectImage generates a cover image for a subject.
+func (p *StabilityImageProvider) GenerateSubjectImage(ctx context.Context, subjectName, courseName string) ([]byte, error) {
+	prompt := fmt.Sprintf("A professional, educational cover image for the subject '%s' from the course '%s'.", subjectName, courseName)
+	return p.generate(ctx, prompt)
+}
+
+// GenerateCustom generates an image from an arbitrary prompt.
+func (p *StabilityImageProvider) GenerateCustom(ctx context.Context, prompt string) ([]byte, error) {
+	return p.generate(ctx, prompt)
+}
+
+func (p *StabilityImageProvider) generate(ctx context.Context, prompt string) ([]byte, error) {
+	if p.apiKey == "" {
+		return nil, fmt.Errorf("no API key available for Stability AI image generation")
+	}
+
+	body, err := json.Marshal(map[string]interface{}{
+		"text_prompts": []map[string]interface{}{{"text": prompt}},
+		"samples":      1,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode Stability AI request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, stabilityImageEndpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build Stability AI request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Authorization", "Bearer "+p.apiKey)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("Stability AI image request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Stability AI response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Stability AI image request returned status %d: %s", resp.StatusCode, respBody)
+	}
+
+	var parsed struct {
+		Artifacts []struct {
+			Base64 string `json:"base64"`
+		} `json:"artifacts"`
+	}
+	if err := json.Unmarshal(respBody, &parsed); err != nil {
+		return nil, fmt.Errorf("failed to parse Stability AI response: %w", err)
+	}
+	if len(parsed.Artifacts) == 0 || parsed.Artifacts[0].Base64 == "" {
+		return nil, fmt.Errorf("Stability AI response did not contain image data")
+	}
+
+	imageBytes, err := base64.StdEncoding.DecodeString(parsed.Artifacts[0].Base64)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode Stability AI image data: %w", err)
+	}
+	return imageBytes, nil
+}
@@ -0,0 +1,110 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// StableDiffusionImageProvider generates images via a self-hosted Stable
+// Diffusion HTTP endpoint (e.g. the AUTOMATIC1111 web UI's /sdapi/v1/txt2img
+// API), for deployments that prefer an in-house model over a paid API.
+type StableDiffusionImageProvider struct {
+	endpoint string
+}
+
+// NewStableDiffusionImageProvider creates a provider targeting endpoint. An
+// empty endpoint is allowed; the provider simply reports itself unavailable.
+func NewStableDiffusionImageProvider(endpoint string) *StableDiffusionImageProvider {
+	return &StableDiffusionImageProvider{endpoint: strings.TrimRight(endpoint, "/")}
+}
+
+// Name identifies this provider in provider-chain logs and metrics.
+func (p *StableDiffusionImageProvider) Name() string {
+	return "stable-diffusion-local"
+}
+
+// Available checks if this provider is configured.
+func (p *StableDiffusionImageProvider) Available() bool {
+	return p.endpoint != ""
+}
+
+// EstimatedCostUSD is 0: a self-hosted endpoint has no per-call charge.
+func (p *StableDiffusionImageProvider) EstimatedCostUSD() float64 {
+	return 0
+}
+
+// GenerateCourseImage generates a cover image for a course.
+func (p *StableDiffusionImageProvider) GenerateCourseImage(ctx context.Context, courseName, courseDescription string) ([]byte, error) {
+	prompt := fmt.Sprintf("A professional, educational cover image for a course titled '%s'. %s", courseName, courseDescription)
+	return p.generate(ctx, prompt)
+}
+
+// GenerateSubjectImage generates a cover image for a subject.
+func (p *StableDiffusionImageProvider) GenerateSubjectImage(ctx context.Context, subjectName, courseName string) ([]byte, error) {
+	prompt := fmt.Sprintf("A professional, educational cover image for the subject '%s' from the course '%s'.", subjectName, courseName)
+	return p.generate(ctx, prompt)
+}
+
+// GenerateCustom generates an image from an arbitrary prompt.
+func (p *StableDiffusionImageProvider) GenerateCustom(ctx context.Context, prompt string) ([]byte, error) {
+	return p.generate(ctx, prompt)
+}
+
+func (p *StableDiffusionImageProvider) generate(ctx context.Context, prompt string) ([]byte, error) {
+	if p.endpoint == "" {
+		return nil, fmt.Errorf("no endpoint configured for local Stable Diffusion image generation")
+	}
+
+	body, err := json.Marshal(map[string]interface{}{
+		"prompt":       prompt,
+		"steps":        25,
+		"width":        1024,
+		"height":       1024,
+		"sampler_name": "Euler a",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode Stable Diffusion request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.endpoint+"/sdapi/v1/txt2img", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build Stable Diffusion request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("Stable Diffusion image request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Stable Diffusion response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Stable Diffusion image request returned status %d: %s", resp.StatusCode, respBody)
+	}
+
+	var parsed struct {
+		Images []string `json:"images"`
+	}
+	if err := json.Unmarshal(respBody, &parsed); err != nil {
+		return nil, fmt.Errorf("failed to parse Stable Diffusion response: %w", err)
+	}
+	if len(parsed.Images) == 0 {
+		return nil, fmt.Errorf("Stable Diffusion response did not contain image data")
+	}
+
+	imageBytes, err := base64.StdEncoding.DecodeString(parsed.Images[0])
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode Stable Diffusion image data: %w", err)
+	}
+	return imageBytes, nil
+}
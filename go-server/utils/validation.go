@@ -1,12 +1,19 @@
 package utils
 
 import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
 	"fmt"
+	"net/http"
 	"regexp"
 	"strings"
 	"time"
 
 	"github.com/go-playground/validator/v10"
+	"github.com/sirupsen/logrus"
+
+	"go-server/config"
 )
 
 var validate *validator.Validate
@@ -69,18 +76,97 @@ func IsValidEmail(email string) bool {
 	return emailRegex.MatchString(email)
 }
 
-// IsValidPassword validates password strength
-func IsValidPassword(password string) bool {
-	// At least 6 characters
-	if len(password) < 6 {
-		return false
+// commonPasswords is a small blocklist of the passwords that show up at the
+// top of every breach-corpus frequency list (rockyou.txt, HIBP's own top-N
+// exports, etc.) — not a full zxcvbn dictionary, but enough to reject the
+// handful of passwords an attacker tries against every account first.
+var commonPasswords = map[string]bool{
+	"password":    true,
+	"password1":   true,
+	"password123": true,
+	"123456":      true,
+	"123456789":   true,
+	"12345678":    true,
+	"qwerty":      true,
+	"qwerty123":   true,
+	"111111":      true,
+	"abc123":      true,
+	"letmein":     true,
+	"welcome":     true,
+	"admin123":    true,
+	"iloveyou":    true,
+	"monkey":      true,
+	"dragon":      true,
+	"football":    true,
+	"baseball":    true,
+	"sunshine":    true,
+	"princess":    true,
+}
+
+// hibpRangeURL is the Have I Been Pwned Pwned Passwords k-anonymity
+// endpoint: a caller sends only a SHA-1 prefix and gets back every suffix
+// on record for it, so the full password hash never leaves the process.
+const hibpRangeURL = "https://api.pwnedpasswords.com/range/"
+
+// IsValidPassword enforces password strength: at least cfg.Password.
+// MinLength characters, not on the common-password blocklist, and — when
+// cfg.Password.CheckPwned is set — not found in Have I Been Pwned's
+// Pwned Passwords corpus via a k-anonymity range query. It returns a
+// human-readable reason for the first check password fails, or "" if it
+// passes all of them.
+func IsValidPassword(password string, cfg *config.Config) string {
+	if len(password) < cfg.Password.MinLength {
+		return fmt.Sprintf("password must be at least %d characters long", cfg.Password.MinLength)
 	}
-	
-	// Contains at least one letter and one number
-	hasLetter := regexp.MustCompile(`[a-zA-Z]`).MatchString(password)
-	hasNumber := regexp.MustCompile(`[0-9]`).MatchString(password)
-	
-	return hasLetter && hasNumber
+
+	if commonPasswords[strings.ToLower(password)] {
+		return "password is too common; choose a less predictable one"
+	}
+
+	if cfg.Password.CheckPwned {
+		pwned, err := isPasswordPwned(password)
+		if err != nil {
+			// Fail open: an HIBP outage shouldn't block every signup/login.
+			logrus.Warnf("Have I Been Pwned lookup failed, skipping: %v", err)
+		} else if pwned {
+			return "password has appeared in a known data breach; choose a different one"
+		}
+	}
+
+	return ""
+}
+
+// isPasswordPwned checks password against the Have I Been Pwned Pwned
+// Passwords range API using k-anonymity: only the first 5 hex characters
+// of its SHA-1 hash are sent, and the full hash is compared locally
+// against every suffix the API returns for that prefix.
+func isPasswordPwned(password string) (bool, error) {
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+	prefix, suffix := hash[:5], hash[5:]
+
+	resp, err := http.Get(hibpRangeURL + prefix)
+	if err != nil {
+		return false, fmt.Errorf("failed to query HIBP range API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("HIBP range API returned status %d", resp.StatusCode)
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if candidate, _, found := strings.Cut(line, ":"); found && candidate == suffix {
+			return true, nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return false, fmt.Errorf("failed to read HIBP range API response: %w", err)
+	}
+
+	return false, nil
 }
 
 // SanitizeString removes dangerous characters from string input
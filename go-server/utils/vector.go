@@ -0,0 +1,44 @@
+package utils
+
+import (
+	"encoding/binary"
+	"math"
+)
+
+// EncodeEmbedding serializes a []float32 to little-endian bytes, for a
+// column with no native vector type.
+func EncodeEmbedding(vector []float32) []byte {
+	buf := make([]byte, len(vector)*4)
+	for i, v := range vector {
+		binary.LittleEndian.PutUint32(buf[i*4:], math.Float32bits(v))
+	}
+	return buf
+}
+
+// DecodeEmbedding is EncodeEmbedding's inverse.
+func DecodeEmbedding(data []byte) []float32 {
+	vector := make([]float32, len(data)/4)
+	for i := range vector {
+		vector[i] = math.Float32frombits(binary.LittleEndian.Uint32(data[i*4:]))
+	}
+	return vector
+}
+
+// CosineSimilarity returns the cosine of the angle between a and b, in
+// [-1, 1]; 0 if they differ in length or either is the zero vector.
+func CosineSimilarity(a, b []float32) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}